@@ -6,20 +6,57 @@ package main
 
 import (
 	"context"
+	_ "embed"
+	"flag"
+	"fmt"
+	"os"
 
 	"github.com/drone/drone-cucumber/plugin"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
 )
 
+// readme documents the settings described by the configuration schema.
+//
+//go:embed README.md
+var readme []byte
+
 func main() {
 	logrus.SetFormatter(new(formatter))
 
+	// Print the JSON Schema of the settings for pipeline editors and catalogs
+	if len(os.Args) > 1 && (os.Args[1] == "--schema" || os.Args[1] == "-schema") {
+		schema, err := plugin.Schema(readme)
+		if err != nil {
+			logrus.Fatalf("\nFailed to generate the configuration schema: %s", err)
+		}
+		fmt.Println(string(schema))
+		os.Exit(0)
+	}
+
+	// Reject malformed values at once and warn about unknown settings
+	if err := plugin.ValidateEnvironment(os.Environ()); err != nil {
+		logrus.Fatalf("\nInput validation failed [%s]: %s", plugin.ErrorCode(err), err)
+	}
+
 	var args plugin.Args
 	if err := envconfig.Process("", &args); err != nil {
 		logrus.Fatalf("\nFailed to process arguments: %s", err)
 	}
 
+	// Command-line flags override the environment
+	overrides, err := plugin.ParseFlags(&args, os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
+		logrus.Fatalf("\nFailed to process flags: %s", err)
+	}
+
+	// Apply the shared threshold profile, the environment and the flags overriding it
+	if err := plugin.ApplyProfile(context.Background(), &args, os.Environ(), overrides); err != nil {
+		logrus.Fatalf("\nInput validation failed [%s]: %s", plugin.ErrorCode(err), err)
+	}
+
 	switch args.Level {
 	case "debug":
 		logrus.SetFormatter(textFormatter)
@@ -28,17 +65,18 @@ func main() {
 		logrus.SetFormatter(textFormatter)
 		logrus.SetLevel(logrus.TraceLevel)
 	}
+	plugin.LogConfiguration(args)
 
 	logrus.Info("Starting Cucumber to JUnit plugin execution\n")
 
 	// Validate user inputs
 	if err := plugin.ValidateInputs(args); err != nil {
-		logrus.Fatalf("\nInput validation failed: %s", err)
+		logrus.Fatalf("\nInput validation failed [%s]: %s", plugin.ErrorCode(err), err)
 	}
 
 	// Execute the plugin logic
 	if err := plugin.Exec(context.Background(), args); err != nil {
-		logrus.Fatalf("\nPlugin execution failed")
+		logrus.Fatalf("\nPlugin execution failed [%s]: %s", plugin.ErrorCode(err), err)
 	}
 
 	logrus.Info("\nPlugin execution completed successfully")
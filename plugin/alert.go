@@ -0,0 +1,181 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Constants for Alert Provider
+const (
+	AlertProviderPagerDuty = "PAGERDUTY"
+	AlertProviderOpsgenie  = "OPSGENIE"
+)
+
+// Default alert API endpoints and protected branches
+const (
+	pagerDutyEventsURL     = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieAlertsURL      = "https://api.opsgenie.com/v2/alerts"
+	defaultAlertBranches   = "main,master"
+	opsgenieMessageLength  = 130
+	pagerDutySummaryLength = 1024
+)
+
+// validateAlertArgs checks the alerting settings.
+func validateAlertArgs(args Args) error {
+	if args.AlertProvider == "" {
+		return nil
+	}
+
+	provider := strings.ToUpper(args.AlertProvider)
+	if provider != AlertProviderPagerDuty && provider != AlertProviderOpsgenie {
+		return fmt.Errorf("invalid AlertProvider value. It must be '%s' or '%s'", AlertProviderPagerDuty, AlertProviderOpsgenie)
+	}
+	if args.AlertRoutingKey == "" {
+		return fmt.Errorf("an alert routing key is required for %s alerts", provider)
+	}
+	return nil
+}
+
+// isProtectedBranch reports whether alerts are enabled for the branch.
+func isProtectedBranch(branch, branches string) bool {
+	if branches == "" {
+		branches = defaultAlertBranches
+	}
+	for _, protected := range strings.Split(branches, ",") {
+		if strings.TrimSpace(protected) == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// gateFailureSummary describes a failed gate in a single line.
+func gateFailureSummary(branch string, gateErr error) string {
+	summary := "Cucumber quality gate failed"
+	if repo := currentRepo(); repo != "" {
+		summary += " for " + repo
+	}
+	if branch != "" {
+		summary += " on " + branch
+	}
+	return summary + ": " + gateErr.Error()
+}
+
+// gateFailureDetails returns the result details attached to alerts.
+func gateFailureDetails(results Results) map[string]interface{} {
+	return map[string]interface{}{
+		"build_number":     currentBuildNumber(),
+		"build_link":       currentBuildLink(),
+		"total_features":   results.FeatureCount,
+		"total_scenarios":  results.ScenarioCount,
+		"total_steps":      results.StepCount,
+		"failed_features":  results.TotalFailedFeatures,
+		"failed_scenarios": results.TotalFailedScenarios,
+		"failed_steps":     results.TotalFailedSteps,
+		"skipped_steps":    results.SkippedTests,
+		"pending_steps":    results.PendingTests,
+		"undefined_steps":  results.UndefinedTests,
+	}
+}
+
+// notifyGateFailure notifies the configured dashboard services of a failed gate, and the
+// alerting services when alert is set.
+func notifyGateFailure(ctx context.Context, results Results, args Args, gateErr error, alert bool) {
+	if alert && args.AlertProvider != "" {
+		sendAlert(ctx, results, args, gateErr)
+	}
+	if args.GrafanaURL != "" {
+		annotateGrafana(ctx, args, gateErr)
+	}
+}
+
+// sendAlert triggers a PagerDuty or Opsgenie alert for a failed gate on a protected branch.
+func sendAlert(ctx context.Context, results Results, args Args, gateErr error) {
+	branch := currentBranch(args)
+	if !isProtectedBranch(branch, args.AlertBranches) {
+		logger.Debugf("Skipping alert for unprotected branch %s", branch)
+		return
+	}
+	triggerAlert(ctx, results, args, branch, gateFailureSummary(branch, gateErr))
+}
+
+// triggerAlert triggers a PagerDuty or Opsgenie alert with the summary.
+func triggerAlert(ctx context.Context, results Results, args Args, branch, summary string) {
+	var err error
+	switch strings.ToUpper(args.AlertProvider) {
+	case AlertProviderPagerDuty:
+		err = sendPagerDutyAlert(ctx, results, args, branch, summary)
+	case AlertProviderOpsgenie:
+		err = sendOpsgenieAlert(ctx, results, args, branch, summary)
+	}
+
+	if err != nil {
+		logger.Warnf("Failed to send %s alert: %v", args.AlertProvider, err)
+		return
+	}
+	logger.Infof("%s alert sent\n", args.AlertProvider)
+}
+
+// alertDedupKey groups the alerts of a repository and branch.
+func alertDedupKey(branch string) string {
+	return "drone-cucumber/" + currentRepo() + "/" + branch
+}
+
+// sendPagerDutyAlert triggers a PagerDuty Events API v2 alert.
+func sendPagerDutyAlert(ctx context.Context, results Results, args Args, branch, summary string) error {
+	event := map[string]interface{}{
+		"routing_key":  args.AlertRoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    alertDedupKey(branch),
+		"payload": map[string]interface{}{
+			"summary":        truncateRunes(summary, pagerDutySummaryLength, "..."),
+			"source":         firstNonEmpty(currentRepo(), "drone-cucumber"),
+			"severity":       "critical",
+			"component":      branch,
+			"custom_details": gateFailureDetails(results),
+		},
+	}
+	if link := currentBuildLink(); link != "" {
+		event["links"] = []map[string]string{{"href": link, "text": "Build"}}
+	}
+
+	return postJSON(ctx, args, firstNonEmpty(args.AlertAPIURL, pagerDutyEventsURL), event, nil)
+}
+
+// truncateRunes shortens the text to at most limit characters, ending with the ellipsis
+// when it is shortened. It cuts between characters so that the text stays valid UTF-8.
+func truncateRunes(text string, limit int, ellipsis string) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	keep := limit - len([]rune(ellipsis))
+	if keep < 0 {
+		keep = 0
+	}
+	return string(runes[:keep]) + ellipsis
+}
+
+// sendOpsgenieAlert creates an Opsgenie alert.
+func sendOpsgenieAlert(ctx context.Context, results Results, args Args, branch, summary string) error {
+	message := truncateRunes(summary, opsgenieMessageLength, "...")
+
+	details := map[string]string{}
+	for key, value := range gateFailureDetails(results) {
+		details[key] = fmt.Sprint(value)
+	}
+
+	alert := map[string]interface{}{
+		"message":     message,
+		"alias":       alertDedupKey(branch),
+		"description": summary + "\n" + currentBuildLink(),
+		"details":     details,
+		"priority":    "P1",
+		"source":      "drone-cucumber",
+		"tags":        []string{"cucumber", branch},
+	}
+
+	headers := map[string]string{"Authorization": "GenieKey " + args.AlertRoutingKey}
+	return postJSON(ctx, args, firstNonEmpty(args.AlertAPIURL, opsgenieAlertsURL), alert, headers)
+}
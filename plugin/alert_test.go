@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestSendAlert tests triggering alerts for failed gates on protected branches
+func TestSendAlert(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		branch       string
+		expectAlert  bool
+		expectHeader string
+		expectField  string
+	}{
+		{
+			name:        "PagerDuty On Protected Branch",
+			provider:    "pagerduty",
+			branch:      "main",
+			expectAlert: true,
+			expectField: "routing_key",
+		},
+		{
+			name:         "Opsgenie On Protected Branch",
+			provider:     "opsgenie",
+			branch:       "release",
+			expectAlert:  true,
+			expectHeader: "GenieKey secret-key",
+			expectField:  "alias",
+		},
+		{
+			name:        "Unprotected Branch",
+			provider:    "pagerduty",
+			branch:      "feature/login",
+			expectAlert: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var payload map[string]interface{}
+			var header string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&payload)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			args := Args{
+				AlertProvider:   tc.provider,
+				AlertRoutingKey: "secret-key",
+				AlertBranches:   "main,release",
+				AlertAPIURL:     server.URL,
+				Branch:          tc.branch,
+			}
+			sendAlert(context.Background(), Results{}, args, errors.New("failed steps count (5) exceeds the threshold (4)"))
+
+			if !tc.expectAlert {
+				if payload != nil {
+					t.Errorf("Expected no alert, got %v", payload)
+				}
+				return
+			}
+			if payload == nil {
+				t.Fatalf("Expected an alert to be sent")
+			}
+			if _, ok := payload[tc.expectField]; !ok {
+				t.Errorf("Expected field %s in payload %v", tc.expectField, payload)
+			}
+			if tc.expectHeader != "" && header != tc.expectHeader {
+				t.Errorf("Expected authorization %q, got %q", tc.expectHeader, header)
+			}
+		})
+	}
+}
+
+// TestTruncateRunes tests shortening texts without splitting their characters
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{text: "short", limit: 10, expected: "short"},
+		{text: "exactly10!", limit: 10, expected: "exactly10!"},
+		{text: "Café crème brûlée", limit: 10, expected: "Café cr..."},
+		{text: "検索の結果が表示される", limit: 6, expected: "検索の..."},
+	}
+
+	for _, tc := range tests {
+		got := truncateRunes(tc.text, tc.limit, "...")
+		if got != tc.expected || !utf8.ValidString(got) {
+			t.Errorf("truncateRunes(%q, %d) = %q, expected %q", tc.text, tc.limit, got, tc.expected)
+		}
+	}
+
+	// The Opsgenie message is cut on a character boundary
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+	summary := strings.Repeat("é", opsgenieMessageLength+1)
+	if err := sendOpsgenieAlert(context.Background(), Results{}, Args{AlertAPIURL: server.URL}, "main", summary); err != nil {
+		t.Fatal(err)
+	}
+	if message, _ := payload["message"].(string); utf8.RuneCountInString(message) != opsgenieMessageLength || !strings.HasSuffix(message, "é...") {
+		t.Errorf("Unexpected message %q", message)
+	}
+
+	// So is the PagerDuty summary
+	summary = strings.Repeat("é", pagerDutySummaryLength+1)
+	if err := sendPagerDutyAlert(context.Background(), Results{}, Args{AlertAPIURL: server.URL}, "main", summary); err != nil {
+		t.Fatal(err)
+	}
+	details, _ := payload["payload"].(map[string]interface{})
+	if message, _ := details["summary"].(string); utf8.RuneCountInString(message) != pagerDutySummaryLength || !strings.HasSuffix(message, "é...") {
+		t.Errorf("Unexpected summary %q", message)
+	}
+}
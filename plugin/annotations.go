@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// parseAnnotations parses an annotation file, either a JSON object or KEY=VALUE lines like
+// the output files of the steps, skipping the blank lines and the # comments.
+func parseAnnotations(content []byte) ([]Annotation, error) {
+	if text := strings.TrimSpace(string(content)); strings.HasPrefix(text, "{") {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &values); err != nil {
+			return nil, err
+		}
+		annotations := make([]Annotation, 0, len(values))
+		for name, value := range values {
+			text, ok := value.(string)
+			if !ok {
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return nil, err
+				}
+				text = string(encoded)
+			}
+			annotations = append(annotations, Annotation{Name: name, Value: text})
+		}
+		sort.Slice(annotations, func(i, j int) bool { return annotations[i].Name < annotations[j].Name })
+		return annotations, nil
+	}
+
+	var annotations []Annotation
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("line %d is not a KEY=VALUE pair", i+1)
+		}
+		annotations = append(annotations, Annotation{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+	return annotations, nil
+}
+
+// loadAnnotations reads the comma separated annotation files written by the previous steps
+// of the pipeline, such as the deployed version or environment drift warnings. A missing
+// file is skipped, since the step writing it may not have run, and an annotation of a later
+// file overrides the annotation of the same name of an earlier one.
+func loadAnnotations(files string) []Annotation {
+	var annotations []Annotation
+	indexes := make(map[string]int)
+	for _, file := range strings.Split(files, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			logger.Infof("No annotation file %s\n", file)
+			continue
+		}
+		if err != nil {
+			logger.Warnf("Failed to read annotation file %s: %v", file, err)
+			continue
+		}
+		parsed, err := parseAnnotations(content)
+		if err != nil {
+			logger.Warnf("Failed to parse annotation file %s: %v", file, err)
+			continue
+		}
+		for _, annotation := range parsed {
+			if index, ok := indexes[annotation.Name]; ok {
+				annotations[index] = annotation
+				continue
+			}
+			indexes[annotation.Name] = len(annotations)
+			annotations = append(annotations, annotation)
+		}
+		logger.Infof("Loaded %d annotations from %s\n", len(parsed), file)
+	}
+	return annotations
+}
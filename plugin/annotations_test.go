@@ -0,0 +1,48 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	deploy := filepath.Join(dir, "deploy.env")
+	drift := filepath.Join(dir, "drift.json")
+	invalid := filepath.Join(dir, "invalid.env")
+	if err := os.WriteFile(deploy, []byte("# Written by the deploy step\nDEPLOYED_VERSION=1.4.2\nENVIRONMENT = staging\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(drift, []byte(`{"ENVIRONMENT": "staging-eu", "DRIFT_WARNINGS": 2, "DRIFTED": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("not an annotation\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Annotation{
+		{Name: "DEPLOYED_VERSION", Value: "1.4.2"},
+		{Name: "ENVIRONMENT", Value: "staging-eu"},
+		{Name: "DRIFTED", Value: "true"},
+		{Name: "DRIFT_WARNINGS", Value: "2"},
+	}
+	files := strings.Join([]string{deploy, filepath.Join(dir, "missing.env"), invalid, drift}, ",")
+	if diff := cmp.Diff(expected, loadAnnotations(files)); diff != "" {
+		t.Errorf("Annotations mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRenderSummaryAnnotations(t *testing.T) {
+	results := Results{Annotations: []Annotation{{Name: "DEPLOYED_VERSION", Value: "1.4.2"}}}
+	summary, err := renderReportTemplate(templateSummary, reportTemplateData{Results: results}, Args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(summary, "| Annotation | Value |\n| --- | --- |\n| DEPLOYED_VERSION | 1.4.2 |\n\n| Features") {
+		t.Errorf("Expected the annotations table before the totals, got:\n%s", summary)
+	}
+}
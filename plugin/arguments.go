@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"regexp"
+	"sort"
+)
+
+// placeholderPattern matches the scenario outline placeholders left in a step text.
+var placeholderPattern = regexp.MustCompile(`<[^<>]+>`)
+
+// interpolateArguments replaces the outline placeholders left in the step texts, as some
+// reporters such as protractor-cucumber-framework write them, by the values matched by
+// the step definitions, in the order of their offsets.
+func interpolateArguments(features []Feature) {
+	for i := range features {
+		for j := range features[i].Elements {
+			steps := features[i].Elements[j].Steps
+			for k := range steps {
+				steps[k].Name = interpolateStep(steps[k])
+			}
+		}
+	}
+}
+
+// interpolateStep returns the text of the step with its placeholders replaced by the
+// matched values, taken from the match or the step arguments. Placeholders without a
+// value are kept.
+func interpolateStep(step Step) string {
+	if !placeholderPattern.MatchString(step.Name) {
+		return step.Name
+	}
+
+	arguments := step.Arguments
+	if step.Match != nil && len(step.Match.Arguments) > 0 {
+		arguments = step.Match.Arguments
+	}
+	var values []Argument
+	for _, argument := range arguments {
+		if argument.Val != nil {
+			values = append(values, argument)
+		}
+	}
+	sort.SliceStable(values, func(a, b int) bool { return values[a].Offset < values[b].Offset })
+
+	next := 0
+	return placeholderPattern.ReplaceAllStringFunc(step.Name, func(placeholder string) string {
+		if next >= len(values) {
+			return placeholder
+		}
+		next++
+		return *values[next-1].Val
+	})
+}
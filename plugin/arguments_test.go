@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestInterpolateStep tests replacing the outline placeholders by the matched values
+func TestInterpolateStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     string
+		expected string
+	}{
+		{
+			name:     "Match arguments",
+			step:     `{"name": "there are <start> cucumbers", "match": {"arguments": [{"val": "12", "offset": 10}]}}`,
+			expected: "there are 12 cucumbers",
+		},
+		{
+			name:     "Step arguments in offset order",
+			step:     `{"name": "I eat <eat> of <start>", "arguments": [{"val": "12", "offset": 12}, {"val": "5", "offset": 6}]}`,
+			expected: "I eat 5 of 12",
+		},
+		{
+			name:     "Data table argument",
+			step:     `{"name": "the <kind> users", "arguments": [{"rows": [{"cells": ["name"]}, {"cells": ["alice"]}]}]}`,
+			expected: "the <kind> users",
+		},
+		{
+			name:     "Missing values",
+			step:     `{"name": "from <from> to <to>", "match": {"location": "steps.js:4", "arguments": [{"val": "Paris", "offset": 5}]}}`,
+			expected: "from Paris to <to>",
+		},
+		{
+			name:     "No placeholders",
+			step:     `{"name": "I log in", "match": {"arguments": [{"val": "in", "offset": 6}]}}`,
+			expected: "I log in",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var step Step
+			if err := json.Unmarshal([]byte(tc.step), &step); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if name := interpolateStep(step); name != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Version is the version of the plugin, set at build time with
+// -ldflags "-X github.com/drone/drone-cucumber/plugin.Version=v1.2.3".
+var Version string
+
+// redacted replaces the values of the secret settings in the audit log.
+const redacted = "[REDACTED]"
+
+// secretSettingSuffixes are the suffixes of the environment variables holding secrets.
+var secretSettingSuffixes = []string{"_TOKEN", "_KEY", "_WEBHOOK", "_PASSWORD"}
+
+// AuditLog represents the evidence of a plugin execution for regulated release processes.
+type AuditLog struct {
+	PluginVersion string            `json:"plugin_version"`
+	Timestamp     string            `json:"timestamp"`
+	Repository    string            `json:"repository,omitempty"`
+	Branch        string            `json:"branch,omitempty"`
+	Commit        string            `json:"commit,omitempty"`
+	BuildNumber   string            `json:"build_number"`
+	Configuration map[string]string `json:"configuration"`
+	Inputs        []AuditInput      `json:"inputs"`
+	Results       Results           `json:"results"`
+	Gates         []GateResult      `json:"gates"`
+	Verdict       string            `json:"verdict"` // passed or failed
+	GateError     string            `json:"gate_error,omitempty"`
+	ErrorCode     string            `json:"error_code,omitempty"`
+}
+
+// AuditInput represents a report file read by the plugin.
+type AuditInput struct {
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// pluginVersion returns the version set at build time, or the module version and VCS
+// revision recorded by the Go toolchain.
+func pluginVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			version += "+" + setting.Value
+		}
+	}
+	return firstNonEmpty(version, "unknown")
+}
+
+// isSecretSetting reports whether the environment variable of a setting holds a secret.
+func isSecretSetting(env string) bool {
+	for _, suffix := range secretSettingSuffixes {
+		if strings.HasSuffix(env, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvedConfiguration returns the value of every configured setting by environment
+// variable, with the secrets and the passwords of URLs redacted.
+func resolvedConfiguration(args Args) map[string]string {
+	configuration := make(map[string]string)
+	value := reflect.ValueOf(args)
+	for i := 0; i < value.NumField(); i++ {
+		env := value.Type().Field(i).Tag.Get("envconfig")
+		if env == "" || value.Field(i).IsZero() {
+			continue
+		}
+		setting := fieldFlag{value.Field(i)}.String()
+		if isSecretSetting(env) {
+			setting = redacted
+		} else if parsed, err := url.Parse(setting); err == nil && parsed.User != nil {
+			setting = parsed.Redacted()
+		}
+		configuration[env] = setting
+	}
+	return configuration
+}
+
+// auditInputs returns the size and checksum of the report files.
+func auditInputs(files []string) []AuditInput {
+	inputs := make([]AuditInput, 0, len(files))
+	for _, file := range files {
+		input := AuditInput{File: filepath.ToSlash(file)}
+		if info, err := os.Stat(file); err == nil {
+			input.Size = info.Size()
+		}
+		if checksum, err := fileChecksum(file); err == nil {
+			input.SHA256 = checksum
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// newAuditLog records the configuration, inputs, results and gate verdicts of the build.
+func newAuditLog(results Results, args Args, files []string, gateErr error, now time.Time) AuditLog {
+	audit := AuditLog{
+		PluginVersion: pluginVersion(),
+		Timestamp:     now.UTC().Format(time.RFC3339),
+		Repository:    currentRepo(),
+		Branch:        currentBranch(args),
+		Commit:        currentCommit(),
+		BuildNumber:   currentBuildNumber(),
+		Configuration: resolvedConfiguration(args),
+		Inputs:        auditInputs(files),
+		Results:       results,
+		Gates:         evaluateThresholds(results, args),
+		Verdict:       "passed",
+	}
+	if gateErr != nil {
+		audit.Verdict = "failed"
+		audit.GateError = gateErr.Error()
+		audit.ErrorCode = ErrorCode(gateErr)
+	}
+	return audit
+}
+
+// writeAuditLog writes the audit log of the build as JSON.
+func writeAuditLog(path string, results Results, args Args, files []string, gateErr error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(newAuditLog(results, args, files, gateErr, time.Now()), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,245 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// baselineObjectScheme prefixes the baseline summaries stored in the upload bucket.
+const baselineObjectScheme = "s3://"
+
+// targetBranch returns the configured target branch, falling back to the target branch of
+// the pull request on pull request builds.
+func targetBranch(args Args) string {
+	if args.TargetBranch != "" {
+		return args.TargetBranch
+	}
+	if os.Getenv("DRONE_BUILD_EVENT") == "pull_request" || os.Getenv("DRONE_PULL_REQUEST") != "" {
+		return os.Getenv("DRONE_TARGET_BRANCH")
+	}
+	return ""
+}
+
+// baseline holds the failed scenarios and the duration of the last build of the target
+// branch.
+type baseline struct {
+	source     string
+	failed     map[string]bool // Identifiers of the failed scenarios
+	durationMS float64
+	scenarios  int
+}
+
+// historyBaseline returns the baseline of the last build of the branch recorded in the
+// history file.
+func historyBaseline(history *History, branch string) (baseline, bool) {
+	entries := history.branchEntries(branch)
+	if len(entries) == 0 {
+		return baseline{}, false
+	}
+	last := entries[len(entries)-1]
+	base := baseline{source: "history build #" + last.BuildNumber, failed: make(map[string]bool), durationMS: last.DurationMS, scenarios: last.TotalScenarios}
+	for _, failure := range last.Failures {
+		base.failed[scenarioIdentifier(failure.Feature, failure.Scenario)] = true
+	}
+	return base, true
+}
+
+// summaryBaseline returns the baseline of the summary of the target branch build.
+func summaryBaseline(results Results, source string) baseline {
+	base := baseline{source: source, failed: make(map[string]bool), durationMS: results.DurationMS, scenarios: results.ScenarioCount}
+	for _, scenario := range results.Scenarios {
+		if scenario.Status == "failed" {
+			base.failed[scenarioIdentifier(scenario.Feature, scenario.Scenario)] = true
+		}
+	}
+	for _, step := range results.FailedSteps {
+		base.failed[scenarioIdentifier(step.Feature, step.Scenario)] = true
+	}
+	return base
+}
+
+// fetchBaselineSummary reads the summary of the target branch build from a path, an HTTP
+// URL or an object key of the upload bucket prefixed with s3://, with {branch} replaced
+// by the target branch.
+func fetchBaselineSummary(ctx context.Context, location, branch string, args Args) (Results, error) {
+	location = strings.ReplaceAll(location, "{branch}", branch)
+
+	var content []byte
+	var err error
+	switch {
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		content, err = getURL(ctx, newHTTPClient(args), location)
+	case strings.HasPrefix(location, baselineObjectScheme):
+		config := resolveUploadConfig(args)
+		content, err = getObject(ctx, newHTTPClient(args), config, config.objectURL(strings.TrimPrefix(location, baselineObjectScheme)))
+	default:
+		content, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return Results{}, err
+	}
+
+	var results Results
+	if err := json.Unmarshal(content, &results); err != nil {
+		return Results{}, fmt.Errorf("invalid baseline summary: %v", err)
+	}
+	return results, nil
+}
+
+// getURL downloads the content of the URL.
+func getURL(ctx context.Context, client *http.Client, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readResponse(client, req)
+}
+
+// readResponse sends the request and returns the body of its successful response.
+func readResponse(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, sendError(req, err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(req, resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// compareWithBaseline compares the failed scenarios and the duration of the build with the
+// baseline of the target branch.
+func compareWithBaseline(results Results, base baseline, branch string) *BaselineComparison {
+	comparison := &BaselineComparison{
+		TargetBranch:       branch,
+		Source:             base.source,
+		DurationMS:         results.DurationMS,
+		BaselineDurationMS: base.durationMS,
+		ScenarioCount:      results.ScenarioCount,
+		BaselineScenarios:  base.scenarios,
+		NewFailures:        []string{},
+		FixedFailures:      []string{},
+	}
+	if base.durationMS > 0 {
+		comparison.DurationChange = (results.DurationMS - base.durationMS) / base.durationMS * 100
+	}
+
+	failed := summaryBaseline(results, "").failed
+	for id := range failed {
+		if !base.failed[id] {
+			comparison.NewFailures = append(comparison.NewFailures, id)
+		}
+	}
+	for id := range base.failed {
+		if !failed[id] {
+			comparison.FixedFailures = append(comparison.FixedFailures, id)
+		}
+	}
+	sort.Strings(comparison.NewFailures)
+	sort.Strings(comparison.FixedFailures)
+	comparison.Summary = baselineSummary(comparison)
+	return comparison
+}
+
+// baselineSummary describes the comparison in a sentence, such as "This change introduces
+// 3 new failing scenarios and slows the suite by 8% compared to main".
+func baselineSummary(comparison *BaselineComparison) string {
+	var summary string
+	switch len(comparison.NewFailures) {
+	case 0:
+		summary = "This change introduces no new failing scenario"
+	case 1:
+		summary = "This change introduces 1 new failing scenario"
+	default:
+		summary = fmt.Sprintf("This change introduces %d new failing scenarios", len(comparison.NewFailures))
+	}
+	if len(comparison.FixedFailures) > 0 {
+		summary += fmt.Sprintf(", fixes %d", len(comparison.FixedFailures))
+	}
+
+	change := math.Round(comparison.DurationChange)
+	switch {
+	case change > 0:
+		summary += fmt.Sprintf(" and slows the suite by %s%%", strconv.FormatFloat(change, 'f', -1, 64))
+	case change < 0:
+		summary += fmt.Sprintf(" and speeds up the suite by %s%%", strconv.FormatFloat(-change, 'f', -1, 64))
+	default:
+		summary += " and keeps the suite duration"
+	}
+	return summary + " compared to " + comparison.TargetBranch
+}
+
+// resolveBaseline compares the build with the baseline of the target branch, read from the
+// configured summary or from the history file, and returns nil when there is none.
+func resolveBaseline(ctx context.Context, results Results, history *History, args Args) *BaselineComparison {
+	branch := targetBranch(args)
+	if branch == "" || branch == currentBranch(args) {
+		return nil
+	}
+
+	if args.BaselineSummary != "" {
+		summary, err := fetchBaselineSummary(ctx, args.BaselineSummary, branch, args)
+		if err == nil {
+			return compareWithBaseline(results, summaryBaseline(summary, "summary "+redactURL(strings.ReplaceAll(args.BaselineSummary, "{branch}", branch))), branch)
+		}
+		logger.Warnf("Failed to fetch the baseline summary of %s: %v", branch, err)
+	}
+	if history != nil {
+		if base, ok := historyBaseline(history, branch); ok {
+			return compareWithBaseline(results, base, branch)
+		}
+	}
+	logger.Infof("No baseline of the target branch %s to compare with\n", branch)
+	return nil
+}
+
+// redactURL hides the query of a URL, which may hold a presigned signature.
+func redactURL(location string) string {
+	if parsed, err := url.Parse(location); err == nil && parsed.RawQuery != "" {
+		parsed.RawQuery = ""
+		return parsed.String()
+	}
+	return location
+}
+
+// logBaselineComparison logs the comparison with the target branch.
+func logBaselineComparison(comparison *BaselineComparison) {
+	logger.Infof("Comparison with %s (%s):\n", comparison.TargetBranch, comparison.Source)
+	logger.Infof("-----------------------------------------------\n")
+	logger.Infof("%s\n", comparison.Summary)
+	for _, id := range comparison.NewFailures {
+		logger.Infof("❌ New failure: %s\n", id)
+	}
+	for _, id := range comparison.FixedFailures {
+		logger.Infof("✅ Fixed: %s\n", id)
+	}
+	logger.Infof("Duration: %s ms (%s ms on %s, %s%%)\n", formatNumber(comparison.DurationMS), formatNumber(comparison.BaselineDurationMS),
+		comparison.TargetBranch, formatSignedNumber(comparison.DurationChange))
+	logger.Infof("===============================================\n")
+}
+
+// writeBaselineStats writes the comparison with the target branch to the output file.
+func writeBaselineStats(comparison *BaselineComparison, log Logger) {
+	statsMap := map[string]string{
+		"BASELINE_BRANCH":          comparison.TargetBranch,
+		"BASELINE_NEW_FAILURES":    strconv.Itoa(len(comparison.NewFailures)),
+		"BASELINE_FIXED_FAILURES":  strconv.Itoa(len(comparison.FixedFailures)),
+		"BASELINE_DURATION_CHANGE": formatNumber(comparison.DurationChange),
+		"BASELINE_SUMMARY":         comparison.Summary,
+	}
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
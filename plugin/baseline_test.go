@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompareWithBaseline(t *testing.T) {
+	history := &History{Entries: []HistoryEntry{
+		{Branch: "main", BuildNumber: "10", DurationMS: 1000, Failures: []HistoryFailure{{Feature: "Cart", Scenario: "Old"}}},
+		{Branch: "main", BuildNumber: "11", DurationMS: 1000, TotalScenarios: 12, Failures: []HistoryFailure{{Feature: "Cart", Scenario: "Flaky"}}},
+		{Branch: "feature/x", BuildNumber: "12", DurationMS: 5000},
+	}}
+	results := Results{
+		DurationMS:    1080,
+		ScenarioCount: 4,
+		Scenarios: []ScenarioResult{
+			{Feature: "Cart", Scenario: "Pay", Status: "failed"},
+			{Feature: "Cart", Scenario: "Flaky", Status: "passed"},
+			{Feature: "Search", Scenario: "Filter", Status: "failed"},
+			{Feature: "Search", Scenario: "Sort", Status: "failed"},
+		},
+	}
+
+	base, ok := historyBaseline(history, "main")
+	if !ok {
+		t.Fatal("Expected a baseline of main")
+	}
+	expected := &BaselineComparison{
+		TargetBranch:       "main",
+		Source:             "history build #11",
+		NewFailures:        []string{"Cart :: Pay", "Search :: Filter", "Search :: Sort"},
+		FixedFailures:      []string{"Cart :: Flaky"},
+		DurationMS:         1080,
+		BaselineDurationMS: 1000,
+		DurationChange:     8,
+		ScenarioCount:      4,
+		BaselineScenarios:  12,
+		Summary:            "This change introduces 3 new failing scenarios, fixes 1 and slows the suite by 8% compared to main",
+	}
+	if diff := cmp.Diff(expected, compareWithBaseline(results, base, "main")); diff != "" {
+		t.Errorf("compareWithBaseline() mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := historyBaseline(history, "release"); ok {
+		t.Error("Expected no baseline of a branch without builds")
+	}
+}
+
+func TestBaselineSummary(t *testing.T) {
+	tests := []struct {
+		comparison BaselineComparison
+		expected   string
+	}{
+		{BaselineComparison{TargetBranch: "main"}, "This change introduces no new failing scenario and keeps the suite duration compared to main"},
+		{BaselineComparison{TargetBranch: "main", NewFailures: []string{"a"}, DurationChange: -12.4}, "This change introduces 1 new failing scenario and speeds up the suite by 12% compared to main"},
+	}
+	for _, tc := range tests {
+		if got := baselineSummary(&tc.comparison); got != tc.expected {
+			t.Errorf("baselineSummary() = %q, want %q", got, tc.expected)
+		}
+	}
+}
+
+func TestResolveBaselineFromSummary(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		json.NewEncoder(w).Encode(Results{DurationMS: 2000, FailedSteps: []FailedStepDetails{{Feature: "Cart", Scenario: "Pay"}}})
+	}))
+	defer server.Close()
+
+	args := Args{Branch: "feature/x", TargetBranch: "main", BaselineSummary: server.URL + "/{branch}/summary.json?signature=secret"}
+	comparison := resolveBaseline(context.Background(), Results{DurationMS: 1000}, nil, args)
+	if comparison == nil {
+		t.Fatal("Expected a comparison with the baseline summary")
+	}
+	if requested != "/main/summary.json" {
+		t.Errorf("Expected the summary of main to be requested, got %s", requested)
+	}
+	if strings.Contains(comparison.Source, "secret") || len(comparison.FixedFailures) != 1 || comparison.DurationChange != -50 {
+		t.Errorf("Unexpected comparison: %+v", comparison)
+	}
+
+	args.TargetBranch = "feature/x"
+	if resolveBaseline(context.Background(), Results{}, nil, args) != nil {
+		t.Error("Expected no comparison of a branch with itself")
+	}
+}
@@ -0,0 +1,34 @@
+package plugin
+
+import "encoding/json"
+
+// behatReport represents a Behat JSON report nesting the features in their suites.
+type behatReport struct {
+	Suites []struct {
+		Name     string    `json:"name"`
+		Features []Feature `json:"features"`
+	} `json:"suites"`
+}
+
+// isBehatReport reports whether the content is a Behat JSON report.
+func isBehatReport(content []byte) bool {
+	return hasJSONKey(content, "suites")
+}
+
+// decodeBehatReport decodes the features of every suite of a Behat JSON report. Reports
+// written as an array of features are decoded as Cucumber JSON reports.
+func decodeBehatReport(content []byte) ([]Feature, error) {
+	if firstByte(content) == '[' {
+		return decodeCucumberReport(content)
+	}
+
+	var report behatReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+	var features []Feature
+	for _, suite := range report.Suites {
+		features = append(features, suite.Features...)
+	}
+	return features, nil
+}
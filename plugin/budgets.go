@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// durationBudget is the maximum total duration of the scenarios with a tag.
+type durationBudget struct {
+	tag    string
+	budget time.Duration
+}
+
+// parseDurationBudgets parses the comma-separated tag=duration budgets, such as
+// @smoke=5m,@regression=1h30m.
+func parseDurationBudgets(value string) ([]durationBudget, error) {
+	var budgets []durationBudget
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		tag, duration, ok := strings.Cut(entry, "=")
+		tag = strings.TrimSpace(tag)
+		if !ok || tag == "" {
+			return nil, fmt.Errorf("invalid duration budget %q: it must be tag=duration", strings.TrimSpace(entry))
+		}
+		if !strings.HasPrefix(tag, "@") {
+			tag = "@" + tag
+		}
+		budget, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("invalid duration budget of tag %s: %q is not a positive duration such as 5m", tag, strings.TrimSpace(duration))
+		}
+		budgets = append(budgets, durationBudget{tag: tag, budget: budget})
+	}
+	return budgets, nil
+}
+
+// evaluateDurationBudgets evaluates the total duration of the scenarios of every tag with a
+// budget against it.
+func evaluateDurationBudgets(results Results, args Args) []GateResult {
+	budgets, _ := parseDurationBudgets(args.DurationBudgets)
+
+	var gates []GateResult
+	for _, budget := range budgets {
+		observed := 0.0
+		for _, scenario := range results.Scenarios {
+			if hasTag(scenario.Tags, budget.tag) {
+				observed += scenario.DurationMS
+			}
+		}
+
+		threshold := float64(budget.budget.Milliseconds())
+		gate := GateResult{
+			Name:      "Duration Budget " + budget.tag,
+			Observed:  observed,
+			Threshold: threshold,
+			Margin:    threshold - observed,
+			Duration:  true,
+			Passed:    observed <= threshold,
+		}
+		if !gate.Passed {
+			gate.Message = fmt.Sprintf("duration of the %s scenarios (%s) exceeds the budget (%s)", budget.tag, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold))
+		}
+		gates = append(gates, gate)
+	}
+	return gates
+}
+
+// formatDurationMS formats a duration in milliseconds, such as 4m32.5s.
+func formatDurationMS(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
+
+// parseMaxFeatureDuration parses the maximum duration of a feature, such as 10m, and
+// returns zero when none is configured.
+func parseMaxFeatureDuration(value string) (time.Duration, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid MaxFeatureDuration value %q. It must be a positive duration such as 10m", value)
+	}
+	return duration, nil
+}
+
+// evaluateFeatureDuration evaluates the duration of the slowest feature against the
+// maximum duration of a feature, naming the feature when it exceeds it.
+func evaluateFeatureDuration(results Results, args Args) []GateResult {
+	maxDuration, _ := parseMaxFeatureDuration(args.MaxFeatureDuration)
+	if maxDuration == 0 {
+		return nil
+	}
+
+	var slowest FeatureStats
+	for _, feature := range featureDetails(results) {
+		if feature.DurationMS > slowest.DurationMS {
+			slowest = feature.FeatureStats
+		}
+	}
+
+	threshold := float64(maxDuration.Milliseconds())
+	gate := GateResult{
+		Name:      "Slowest Feature Duration",
+		Observed:  slowest.DurationMS,
+		Threshold: threshold,
+		Margin:    threshold - slowest.DurationMS,
+		Duration:  true,
+		Passed:    slowest.DurationMS <= threshold,
+	}
+	if !gate.Passed {
+		gate.Message = fmt.Sprintf("duration of the slowest feature %q (%s) exceeds the threshold (%s)", slowest.Feature, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold))
+	}
+	return []GateResult{gate}
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDurationBudgets(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []durationBudget
+		errMsg   string
+	}{
+		{"", nil, ""},
+		{"@smoke=5m, regression=1h30m", []durationBudget{{"@smoke", 5 * 60e9}, {"@regression", 90 * 60e9}}, ""},
+		{"@smoke", nil, `invalid duration budget "@smoke": it must be tag=duration`},
+		{"@smoke=5 minutes", nil, `invalid duration budget of tag @smoke: "5 minutes" is not a positive duration`},
+		{"@smoke=0s", nil, "is not a positive duration"},
+	}
+	for _, tc := range tests {
+		budgets, err := parseDurationBudgets(tc.value)
+		if tc.errMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("parseDurationBudgets(%q) error = %v, want %q", tc.value, err, tc.errMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDurationBudgets(%q) unexpected error: %v", tc.value, err)
+		}
+		if diff := cmp.Diff(tc.expected, budgets, cmp.AllowUnexported(durationBudget{})); diff != "" {
+			t.Errorf("parseDurationBudgets(%q) mismatch (-want +got):\n%s", tc.value, diff)
+		}
+	}
+}
+
+func TestEvaluateDurationBudgets(t *testing.T) {
+	results := Results{Scenarios: []ScenarioResult{
+		{Scenario: "Login", Tags: []string{"@smoke"}, DurationMS: 200000},
+		{Scenario: "Checkout", Tags: []string{"@smoke", "@payments"}, DurationMS: 132500},
+		{Scenario: "Refund", Tags: []string{"@payments"}, DurationMS: 60000},
+	}}
+
+	gates := evaluateThresholds(results, Args{DurationBudgets: "@smoke=5m,@payments=10m"})
+	if len(gates) != 2 {
+		t.Fatalf("Expected 2 gates, got %+v", gates)
+	}
+
+	smoke := gates[0]
+	if smoke.Name != "Duration Budget @smoke" || smoke.Passed || smoke.formatValue(smoke.Observed) != "5m32.5s" || smoke.formatMargin() != "-32.5s" {
+		t.Errorf("Unexpected smoke gate: %+v", smoke)
+	}
+	if smoke.Message != "duration of the @smoke scenarios (5m32.5s) exceeds the budget (5m0s)" {
+		t.Errorf("Unexpected message: %s", smoke.Message)
+	}
+	if payments := gates[1]; !payments.Passed || payments.formatMargin() != "+6m47.5s" {
+		t.Errorf("Unexpected payments gate: %+v", payments)
+	}
+}
+
+func TestEvaluateFeatureDuration(t *testing.T) {
+	results := Results{Scenarios: []ScenarioResult{
+		{Feature: "Login", Scenario: "Valid", DurationMS: 60000},
+		{Feature: "Checkout", Scenario: "Card", DurationMS: 400000},
+		{Feature: "Checkout", Scenario: "Refund", DurationMS: 200000},
+	}}
+
+	if gates := evaluateThresholds(results, Args{}); len(gates) != 0 {
+		t.Errorf("Expected no gate without a maximum, got %+v", gates)
+	}
+
+	gates := evaluateThresholds(results, Args{MaxFeatureDuration: "5m"})
+	if len(gates) != 1 || gates[0].Passed || gates[0].Observed != 600000 {
+		t.Fatalf("Unexpected gates: %+v", gates)
+	}
+	if expected := `duration of the slowest feature "Checkout" (10m0s) exceeds the threshold (5m0s)`; gates[0].Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, gates[0].Message)
+	}
+	if gates := evaluateThresholds(results, Args{MaxFeatureDuration: "15m"}); !gates[0].Passed || gates[0].formatMargin() != "+5m0s" {
+		t.Errorf("Expected the gate to pass with a margin of 5m0s, got %+v", gates[0])
+	}
+
+	for _, value := range []string{"10", "-5m", "0s"} {
+		if _, err := parseMaxFeatureDuration(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// cacheVersion is part of the cache keys and must be changed when the computed Results change.
+const cacheVersion = "17"
+
+// cacheSettings returns the settings affecting the Results computed from a file, the
+// fields of Args tagged with cache:"key", by name.
+func cacheSettings(args Args) map[string]interface{} {
+	settings := map[string]interface{}{"Version": cacheVersion}
+	value := reflect.ValueOf(args)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("cache") == "key" {
+			settings[value.Type().Field(i).Name] = value.Field(i).Interface()
+		}
+	}
+	return settings
+}
+
+// cacheKey returns the cache key of a file, derived from the hash of its content and
+// the settings affecting its Results.
+func cacheKey(filename string, args Args) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	options, err := json.Marshal(cacheSettings(args))
+	if err != nil {
+		return "", err
+	}
+	hash.Write(options)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// processFileCached processes a file, reusing the Results cached for unchanged files.
+func processFileCached(filename string, args Args) (Results, error) {
+	if args.CacheDir == "" {
+		return processFile(filename, args.SkipEmptyJSONFiles, args)
+	}
+
+	key, err := cacheKey(filename, args)
+	if err != nil {
+		return processFile(filename, args.SkipEmptyJSONFiles, args)
+	}
+	path := filepath.Join(args.CacheDir, key+".json")
+
+	if content, err := os.ReadFile(path); err == nil {
+		var results Results
+		if err := json.Unmarshal(content, &results); err == nil {
+			logger.Infof("Using cached results for file: %s", filename)
+			return results, nil
+		}
+	}
+
+	results, err := processFile(filename, args.SkipEmptyJSONFiles, args)
+	if err != nil {
+		return results, err
+	}
+	// Results computed without the step outputs are not cached to avoid reusing them later
+	if degradeLevel() >= degradeEmbeddings {
+		return results, nil
+	}
+	if err := writeCache(path, results); err != nil {
+		logger.Warnf("Failed to cache results for file %s: %v", filename, err)
+	}
+	return results, nil
+}
+
+// writeCache atomically writes the cached Results, so that concurrent invocations
+// sharing the cache directory never read a partial entry.
+func writeCache(path string, results Results) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestProcessFileCached tests reusing the cached results of unchanged files
+func TestProcessFileCached(t *testing.T) {
+	args := Args{CacheDir: t.TempDir()}
+	report := "../testdata/cucumber_report.json"
+
+	results, err := processFileCached(report, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	key, err := cacheKey(report, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(args.CacheDir, key+".json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected cache entry %s: %v", path, err)
+	}
+
+	// A tampered entry proves that the cached results are used
+	results.FeatureCount = 99
+	if err := writeCache(path, results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cached, err := processFileCached(report, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cached.FeatureCount != 99 {
+		t.Errorf("Expected the cached results, got %d features", cached.FeatureCount)
+	}
+
+	// Settings affecting the results invalidate the entry
+	args.FailedAsNotFailingStatus = true
+	fresh, err := processFileCached(report, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fresh.FeatureCount == 99 {
+		t.Errorf("Expected fresh results for different settings")
+	}
+}
+
+// TestCacheKeySettings tests that the settings affecting the results change the cache key
+func TestCacheKeySettings(t *testing.T) {
+	report := "../testdata/cucumber_report.json"
+	base, err := cacheKey(report, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args Args
+	}{
+		{"include tags", Args{IncludeTags: "@smoke"}},
+		{"exclude tags", Args{ExcludeTags: "@wip"}},
+		{"step statistics", Args{StepStatsReport: "step-stats.md"}},
+		{"pending aging", Args{PendingAgingReport: "pending-aging.md"}},
+		{"lint", Args{LintReport: "lint.md"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := cacheKey(report, tc.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if key == base {
+				t.Errorf("Expected a different cache key")
+			}
+		})
+	}
+}
+
+// cacheSettingsReport exercises the settings affecting the Results: tags, statuses, merged
+// features, screenshots, an unordered feature list and a lint warning.
+const cacheSettingsReport = `[
+	{"id": "search", "uri": "features/search.feature", "name": "Search", "keyword": "Feature", "tags": [{"name": "@wip"}], "elements": [
+		{"id": "search;find", "name": "Find", "keyword": "Scenario", "type": "scenario", "line": 3, "steps": [
+			{"keyword": "Then ", "name": "the results are shown", "line": 4, "result": {"status": "passed", "duration": 1000}},
+			{"keyword": "When ", "name": "I search", "line": 5, "result": {"status": "pending"}}
+		]}
+	]},
+	{"id": "checkout", "uri": "features/checkout.feature", "name": "Checkout", "keyword": "Feature", "tags": [{"name": "@smoke"}], "elements": [
+		{"id": "checkout;pay", "name": "Pay", "keyword": "Scenario", "type": "scenario", "line": 3, "steps": [
+			{"keyword": "Given ", "name": "a cart", "line": 4, "result": {"status": "passed", "duration": 1000}},
+			{"keyword": "When ", "name": "I pay 10 EUR", "line": 5, "result": {"status": "failed", "duration": 2000, "error_message": "Card declined"}},
+			{"keyword": "Then ", "name": "I get a receipt", "line": 6, "result": {"status": "skipped"}}
+		], "after": [{"result": {"status": "passed"}, "embeddings": [{"data": "iVBORw0KGgo=", "mime_type": "image/png"}]}]},
+		{"id": "checkout;refund", "name": "Refund", "keyword": "Scenario", "type": "scenario", "line": 8, "steps": [
+			{"keyword": "Given ", "name": "a payment", "line": 9, "result": {"status": "undefined"}}
+		]}
+	]},
+	{"id": "checkout", "uri": "features/checkout.feature", "name": "Checkout", "keyword": "Feature", "elements": [
+		{"id": "checkout;cancel", "name": "Cancel", "keyword": "Scenario", "type": "scenario", "line": 11, "steps": [
+			{"keyword": "When ", "name": "I cancel", "line": 12, "result": {"status": "passed", "duration": 1000}}
+		]}
+	]}
+]`
+
+// TestCacheKeyCoversSettings tests that every setting changing the Results computed from a
+// file is part of the cache key, so that a new setting cannot be left out of it
+func TestCacheKeyCoversSettings(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "cucumber.json")
+	if err := os.WriteFile(report, []byte(cacheSettingsReport), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Values of the settings whose placeholder value is not valid
+	samples := map[string]interface{}{
+		"ReportDialect": DialectCucumber,
+		"Categories":    `[{"name": "Payments", "paths": ["features/checkout.feature"]}]`,
+		"SortingMethod": SortingMethodAlphabetical,
+	}
+
+	base, err := processFile(report, false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	baseKey, err := cacheKey(report, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fields := reflect.TypeOf(Args{})
+	for i := 0; i < fields.NumField(); i++ {
+		name := fields.Field(i).Name
+		var args Args
+		field := reflect.ValueOf(&args).Elem().Field(i)
+		if sample, ok := samples[name]; ok {
+			field.Set(reflect.ValueOf(sample))
+		} else {
+			switch field.Kind() {
+			case reflect.Bool:
+				field.SetBool(true)
+			case reflect.String:
+				field.SetString("@smoke")
+			case reflect.Int:
+				field.SetInt(1)
+			case reflect.Float64:
+				field.SetFloat(1)
+			}
+		}
+
+		results, err := processFile(report, args.SkipEmptyJSONFiles, args)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v, add a valid sample value", name, err)
+			continue
+		}
+		key, err := cacheKey(report, args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if key == baseKey && !cmp.Equal(base, results) {
+			t.Errorf("%s changes the Results but is not part of the cache key, tag it with cache:\"key\"", name)
+		}
+	}
+}
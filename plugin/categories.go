@@ -0,0 +1,203 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// uncategorized is the category of the scenarios matching no rule.
+const uncategorized = "Uncategorized"
+
+// categoryRule is a parsed category with its compiled scenario name pattern.
+type categoryRule struct {
+	Category
+	pattern *regexp.Regexp
+}
+
+// parseCategories parses the JSON list of category rules.
+func parseCategories(value string) ([]categoryRule, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var categories []Category
+	if err := json.Unmarshal([]byte(value), &categories); err != nil {
+		return nil, fmt.Errorf("invalid category rules: %v", err)
+	}
+
+	rules := make([]categoryRule, 0, len(categories))
+	for _, category := range categories {
+		if category.Name == "" {
+			return nil, errors.New("invalid category rules: every category requires a name")
+		}
+		if len(category.Tags) == 0 && len(category.Paths) == 0 && category.NamePattern == "" {
+			return nil, fmt.Errorf("invalid category rule %s: it requires tags, paths or a name pattern", category.Name)
+		}
+		for _, pattern := range category.Paths {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid category rule %s: invalid path %q", category.Name, pattern)
+			}
+		}
+		if category.MinPassRate < 0 || category.MinPassRate > 100 || (category.MaxFailedScenarios != nil && *category.MaxFailedScenarios < 0) {
+			return nil, fmt.Errorf("invalid category rule %s: values must be non-negative and pass rates at most 100", category.Name)
+		}
+
+		rule := categoryRule{Category: category}
+		if category.NamePattern != "" {
+			pattern, err := regexp.Compile(category.NamePattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid category rule %s: %v", category.Name, err)
+			}
+			rule.pattern = pattern
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// matches reports whether the scenario has one of the tags of the rule, is in a feature
+// file matching one of its paths or has a name matching its pattern.
+func (rule categoryRule) matches(uri, name string, tags []string) bool {
+	for _, tag := range rule.Tags {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+	for _, pattern := range rule.Paths {
+		if matchesPath(pattern, uri) {
+			return true
+		}
+	}
+	return rule.pattern != nil && rule.pattern.MatchString(name)
+}
+
+// matchesPath reports whether the file or one of its parent directories matches the glob.
+func matchesPath(pattern, file string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	for file != "." && file != "/" && file != "" {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		file = path.Dir(file)
+	}
+	return false
+}
+
+// categorize returns the category of the first rule matching the scenario.
+func categorize(rules []categoryRule, uri, name string, tags []string) string {
+	for _, rule := range rules {
+		if rule.matches(uri, name, tags) {
+			return rule.Name
+		}
+	}
+	return uncategorized
+}
+
+// categoryResults counts the scenarios of each category in the order of the rules, with
+// the uncategorized scenarios last, and evaluates the category gates.
+func categoryResults(scenarios []ScenarioResult, rules []categoryRule) []CategoryResult {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(rules)+1)
+	results := make([]CategoryResult, 0, len(rules)+1)
+	for _, rule := range append(rules, categoryRule{Category: Category{Name: uncategorized}}) {
+		if _, ok := index[rule.Name]; ok {
+			continue
+		}
+		index[rule.Name] = len(results)
+		results = append(results, CategoryResult{Name: rule.Name, MinPassRate: rule.MinPassRate, MaxFailedScenarios: rule.MaxFailedScenarios})
+	}
+
+	for _, scenario := range scenarios {
+		category := &results[index[firstNonEmpty(scenario.Category, uncategorized)]]
+		category.Scenarios++
+		if scenario.Status == "failed" {
+			category.FailedScenarios++
+		} else {
+			category.PassedScenarios++
+		}
+		category.DurationMS += scenario.DurationMS
+	}
+
+	var categories []CategoryResult
+	for _, category := range results {
+		if category.Scenarios == 0 && category.Name == uncategorized {
+			continue
+		}
+		category.PassRate = percentageOf(category.PassedScenarios, category.Scenarios)
+		category.Passed = true
+		if category.MaxFailedScenarios != nil && category.FailedScenarios > *category.MaxFailedScenarios {
+			category.Passed = false
+		}
+		if category.MinPassRate > 0 && category.Scenarios > 0 && category.PassRate < category.MinPassRate {
+			category.Passed = false
+		}
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// logCategoryResults logs the scenario counts and gate verdict of each category.
+func logCategoryResults(categories []CategoryResult) {
+	if len(categories) == 0 {
+		return
+	}
+
+	logger.Infof("Categories:\n")
+	logger.Infof("-----------------------------------------------\n")
+	for _, category := range categories {
+		logger.Infof("%s %s: %d scenarios (%d passed, %d failed), pass rate %s%%, %s ms\n", gateSymbol(category.Passed), category.Name,
+			category.Scenarios, category.PassedScenarios, category.FailedScenarios, formatNumber(category.PassRate), formatNumber(category.DurationMS))
+	}
+	logger.Infof("===============================================\n")
+}
+
+// validateCategories returns an error listing the categories failing their gates.
+func validateCategories(categories []CategoryResult) error {
+	var violations []string
+	for _, category := range categories {
+		if category.Passed {
+			continue
+		}
+		violation := fmt.Sprintf("%s (%d failed scenarios, pass rate %s%%)", category.Name, category.FailedScenarios, formatNumber(category.PassRate))
+		violations = append(violations, violation)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("categories failed their gates: %s", strings.Join(violations, ", "))
+}
+
+// writeCategoryStats writes the statistics of each category to the output variables.
+func writeCategoryStats(categories []CategoryResult, log Logger) {
+	if len(categories) == 0 {
+		return
+	}
+
+	violations := 0
+	stats := map[string]string{}
+	for _, category := range categories {
+		prefix := "CATEGORY_" + envKey(category.Name)
+		stats[prefix+"_PASSED_SCENARIOS"] = strconv.Itoa(category.PassedScenarios)
+		stats[prefix+"_FAILED_SCENARIOS"] = strconv.Itoa(category.FailedScenarios)
+		stats[prefix+"_PASS_RATE"] = formatNumber(category.PassRate)
+		if !category.Passed {
+			violations++
+		}
+	}
+	stats["CATEGORY_VIOLATIONS"] = strconv.Itoa(violations)
+
+	for key, value := range stats {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParseCategories tests the validation of the category rules
+func TestParseCategories(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"Empty", "", false},
+		{"Valid", `[{"name": "checkout", "tags": ["@checkout"], "max_failed_scenarios": 0}]`, false},
+		{"Invalid JSON", `{"name": "checkout"}`, true},
+		{"Missing name", `[{"tags": ["@checkout"]}]`, true},
+		{"Missing criteria", `[{"name": "checkout"}]`, true},
+		{"Invalid path", `[{"name": "checkout", "paths": ["features/["]}]`, true},
+		{"Invalid pattern", `[{"name": "checkout", "name_pattern": "("}]`, true},
+		{"Invalid pass rate", `[{"name": "checkout", "tags": ["@checkout"], "min_pass_rate": 120}]`, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseCategories(tc.value); (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestCategoryResults tests mapping the scenarios to categories and evaluating their gates
+func TestCategoryResults(t *testing.T) {
+	value := `[
+		{"name": "checkout", "tags": ["@checkout"], "max_failed_scenarios": 0},
+		{"name": "search", "paths": ["features/search"], "min_pass_rate": 50},
+		{"name": "auth", "name_pattern": "(?i)log ?in"},
+		{"name": "checkout", "paths": ["features/*/cart.feature"]}
+	]`
+	rules, err := parseCategories(value)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	features := []Feature{
+		{URI: "features/search/by_name.feature", Name: "Search", Elements: []Element{
+			{Name: "By name", Steps: []Step{{Result: Result{Status: "passed"}}}},
+			{Name: "Login required", Tags: []Tag{{Name: "@checkout"}}, Steps: []Step{{Result: Result{Status: "failed"}}}},
+		}},
+		{URI: "features/auth/login.feature", Name: "Auth", Elements: []Element{
+			{Name: "Log in", Steps: []Step{{Result: Result{Status: "failed"}}}},
+			{Name: "Sign up", Steps: []Step{{Result: Result{Status: "passed"}}}},
+		}},
+		{URI: "features/shop/cart.feature", Name: "Cart", Elements: []Element{
+			{Name: "Add", Steps: []Step{{Result: Result{Status: "passed", Duration: 2e6}}}},
+		}},
+	}
+	results := computeStats(features, Args{Categories: value})
+
+	var categories []string
+	for _, scenario := range results.Scenarios {
+		categories = append(categories, scenario.Category)
+	}
+	if diff := cmp.Diff([]string{"search", "checkout", "auth", uncategorized, "checkout"}, categories); diff != "" {
+		t.Errorf("Categories mismatch (-want +got):\n%s", diff)
+	}
+
+	zero := 0
+	expected := []CategoryResult{
+		{Name: "checkout", Scenarios: 2, PassedScenarios: 1, FailedScenarios: 1, PassRate: 50, DurationMS: 2, MaxFailedScenarios: &zero},
+		{Name: "search", Scenarios: 1, PassedScenarios: 1, PassRate: 100, MinPassRate: 50, Passed: true},
+		{Name: "auth", Scenarios: 1, FailedScenarios: 1, Passed: true},
+		{Name: uncategorized, Scenarios: 1, PassedScenarios: 1, PassRate: 100, Passed: true},
+	}
+	got := categoryResults(results.Scenarios, rules)
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Category results mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := validateCategories(got); err == nil || err.Error() != "categories failed their gates: checkout (1 failed scenarios, pass rate 50.00%)" {
+		t.Errorf("Unexpected gate error: %v", err)
+	}
+}
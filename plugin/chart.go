@@ -0,0 +1,196 @@
+package plugin
+
+import (
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"strings"
+)
+
+// Dimensions of a panel of the trend chart of the HTML reports, in pixels.
+const (
+	chartPanelWidth  = 320
+	chartPanelHeight = 160
+	chartPadding     = 36
+)
+
+// trendChart returns an inline SVG chart of the pass rate and duration of the recent
+// builds and the current build, which is highlighted so that an outlier stands out. It
+// returns nothing without previous builds.
+func trendChart(trend *Trend) htmltemplate.HTML {
+	if trend == nil || len(trend.Entries) == 0 {
+		return ""
+	}
+	entries := append(append([]HistoryEntry{}, trend.Entries...), trend.Current)
+
+	maxDuration := 0.0
+	for _, entry := range entries {
+		maxDuration = max(maxDuration, entry.DurationMS)
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-labelledby="trend-chart-title">`,
+		2*chartPanelWidth, chartPanelHeight, 2*chartPanelWidth, chartPanelHeight)
+	fmt.Fprintf(&svg, `<title id="trend-chart-title">Pass rate and duration of the last %d builds and build #%s</title>`,
+		len(trend.Entries), html.EscapeString(trend.Current.BuildNumber))
+	chartPanel(&svg, 0, "Pass rate", entries, 100, func(entry HistoryEntry) float64 { return entry.PassRate },
+		func(value float64) string { return formatNumber(value) + "%" })
+	chartPanel(&svg, chartPanelWidth, "Duration", entries, maxDuration, func(entry HistoryEntry) float64 { return entry.DurationMS },
+		func(value float64) string { return formatNumber(value/1000) + " s" })
+	svg.WriteString(`</svg>`)
+	return htmltemplate.HTML(svg.String())
+}
+
+// chartPanel draws the values of the builds as a line on a scale from 0 to the maximum,
+// with the last build, the current one, marked.
+func chartPanel(svg *strings.Builder, offset int, title string, entries []HistoryEntry, maximum float64, value func(HistoryEntry) float64, label func(float64) string) {
+	left, top := float64(offset+chartPadding), float64(chartPadding/2)
+	width, height := float64(chartPanelWidth-chartPadding-12), float64(chartPanelHeight-chartPadding-chartPadding/2)
+	bottom := top + height
+	if maximum <= 0 {
+		maximum = 1
+	}
+	step := width
+	if len(entries) > 1 {
+		step = width / float64(len(entries)-1)
+	}
+	x := func(i int) float64 { return left + float64(i)*step }
+	y := func(v float64) float64 { return bottom - v/maximum*height }
+
+	fmt.Fprintf(svg, `<text x="%.1f" y="12" font-size="12" font-family="sans-serif">%s</text>`, left, title)
+	fmt.Fprintf(svg, `<text x="%.1f" y="%.1f" font-size="9" font-family="sans-serif" text-anchor="end">%s</text>`, left-4, top+3, html.EscapeString(label(maximum)))
+	fmt.Fprintf(svg, `<text x="%.1f" y="%.1f" font-size="9" font-family="sans-serif" text-anchor="end">0</text>`, left-4, bottom+3)
+	fmt.Fprintf(svg, `<path d="M%.1f %.1fH%.1fM%.1f %.1fV%.1fH%.1f" stroke="#999" fill="none"/>`, left, top, left+width, left, top, bottom, left+width)
+
+	points := make([]string, len(entries))
+	for i, entry := range entries {
+		points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(value(entry)))
+	}
+	fmt.Fprintf(svg, `<polyline points="%s" stroke="#3366cc" stroke-width="2" fill="none"/>`, strings.Join(points, " "))
+
+	for i, entry := range entries {
+		current := i == len(entries)-1
+		radius, color := 2.5, "#3366cc"
+		if current {
+			radius, color = 4.5, "#cf222e"
+		}
+		fmt.Fprintf(svg, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"><title>#%s: %s</title></circle>`,
+			x(i), y(value(entry)), radius, color, html.EscapeString(entry.BuildNumber), html.EscapeString(label(value(entry))))
+		// Label the first and current builds, the others are named in the tooltips
+		if i == 0 || current {
+			fmt.Fprintf(svg, `<text x="%.1f" y="%.1f" font-size="9" font-family="sans-serif" text-anchor="middle">#%s</text>`,
+				x(i), bottom+14, html.EscapeString(entry.BuildNumber))
+		}
+	}
+}
+
+// timelineLaneHeight is the height of a lane of the timeline chart, in pixels.
+const timelineLaneHeight = 12
+
+// timelineColors are the colors of the scenarios of the timeline chart by status.
+var timelineColors = map[string]string{
+	"passed": "#1a7f37",
+	"failed": "#cf222e",
+}
+
+// timelineChart returns an inline SVG Gantt chart of the timeline, with a bar per
+// scenario on its lane and the gaps during which no scenario ran shaded.
+func timelineChart(timeline *Timeline) htmltemplate.HTML {
+	if timeline == nil || len(timeline.Scenarios) == 0 {
+		return ""
+	}
+	lanes := timeline.MaxConcurrency
+	for _, scenario := range timeline.Scenarios {
+		lanes = max(lanes, scenario.Lane+1)
+	}
+
+	left, top := float64(chartPadding), float64(chartPadding/2)
+	width := float64(2*chartPanelWidth - chartPadding - 12)
+	height := float64(lanes * timelineLaneHeight)
+	bottom := top + height
+	total := max(timeline.DurationMS, 1)
+	x := func(offsetMS float64) float64 { return left + offsetMS/total*width }
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%.0f" viewBox="0 0 %d %.0f" role="img" aria-labelledby="timeline-chart-title">`,
+		2*chartPanelWidth, bottom+24, 2*chartPanelWidth, bottom+24)
+	fmt.Fprintf(&svg, `<title id="timeline-chart-title">Timeline of %d scenarios over %s ms, with %d gaps during which no scenario ran</title>`,
+		len(timeline.Scenarios), formatNumber(timeline.DurationMS), len(timeline.Gaps))
+	for _, gap := range timeline.Gaps {
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#fff3bf"><title>No scenario running for %s ms</title></rect>`,
+			x(gap.OffsetMS), top, max(x(gap.OffsetMS+gap.DurationMS)-x(gap.OffsetMS), 1), height, formatNumber(gap.DurationMS))
+	}
+	fmt.Fprintf(&svg, `<path d="M%.1f %.1fV%.1fH%.1f" stroke="#999" fill="none"/>`, left, top, bottom, left+width)
+	fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" font-size="9" font-family="sans-serif" text-anchor="middle">0 s</text>`, left, bottom+14)
+	fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" font-size="9" font-family="sans-serif" text-anchor="end">%s s</text>`, left+width, bottom+14, formatNumber(timeline.DurationMS/1000))
+
+	for _, scenario := range timeline.Scenarios {
+		color, ok := timelineColors[scenario.Status]
+		if !ok {
+			color = "#999"
+		}
+		shard := ""
+		if scenario.Shard != "" {
+			shard = " on shard " + scenario.Shard
+		}
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s"><title>%s: %s%s, from %s s for %s ms</title></rect>`,
+			x(scenario.OffsetMS), top+float64(scenario.Lane*timelineLaneHeight)+1, max(x(scenario.OffsetMS+scenario.DurationMS)-x(scenario.OffsetMS), 1),
+			timelineLaneHeight-2, color, html.EscapeString(scenarioIdentifier(scenario.Feature, scenario.Scenario)), scenario.Status,
+			html.EscapeString(shard), formatNumber(scenario.OffsetMS/1000), formatNumber(scenario.DurationMS))
+	}
+	svg.WriteString(`</svg>`)
+	return htmltemplate.HTML(svg.String())
+}
+
+// statusColors are the colors of the statuses of the status chart.
+var statusColors = map[string]string{
+	"passed":    "#1a7f37",
+	"failed":    "#cf222e",
+	"skipped":   "#8c959f",
+	"pending":   "#bf8700",
+	"undefined": "#bc4c00",
+}
+
+// statusChart returns an inline SVG chart of the scenarios and steps of the results as
+// bars stacked by status, each segment named with its count in a tooltip.
+func statusChart(results Results) htmltemplate.HTML {
+	rows := []struct {
+		title  string
+		total  int
+		counts []statusCount
+	}{
+		{"Scenarios", results.ScenarioCount, []statusCount{{"passed", results.TotalPassedScenarios}, {"failed", results.TotalFailedScenarios}}},
+		{"Steps", results.StepCount, []statusCount{{"passed", results.PassedTests}, {"failed", results.FailedTests}, {"skipped", results.SkippedTests},
+			{"pending", results.PendingTests}, {"undefined", results.UndefinedTests}}},
+	}
+
+	left, width := 80.0, float64(2*chartPanelWidth-80-12)
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-labelledby="status-chart-title">`,
+		2*chartPanelWidth, 24*len(rows)+8, 2*chartPanelWidth, 24*len(rows)+8)
+	fmt.Fprintf(&svg, `<title id="status-chart-title">%d of %d scenarios and %d of %d steps passed</title>`,
+		results.TotalPassedScenarios, results.ScenarioCount, results.PassedTests, results.StepCount)
+	for i, row := range rows {
+		y := float64(8 + 24*i)
+		fmt.Fprintf(&svg, `<text x="0" y="%.1f" font-size="12" font-family="sans-serif">%s</text>`, y+12, row.title)
+		total := max(row.total, 1)
+		x := left
+		for _, count := range row.counts {
+			if count.count == 0 {
+				continue
+			}
+			segment := float64(count.count) / float64(total) * width
+			fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="16" fill="%s"><title>%d %s</title></rect>`,
+				x, y, segment, statusColors[count.status], count.count, count.status)
+			x += segment
+		}
+	}
+	svg.WriteString(`</svg>`)
+	return htmltemplate.HTML(svg.String())
+}
+
+// statusCount is the count of a status of the status chart.
+type statusCount struct {
+	status string
+	count  int
+}
@@ -0,0 +1,193 @@
+package plugin
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// validateSigningKey checks that a PEM encoded signing key can be parsed, so that a
+// misconfigured key fails the build before the artifacts are written.
+func validateSigningKey(args Args) error {
+	if args.SigningKey == "" {
+		return nil
+	}
+	if args.ChecksumFile == "" {
+		return errors.New("SigningKey requires ChecksumFile to be set")
+	}
+	if block, _ := pem.Decode([]byte(strings.TrimSpace(args.SigningKey))); block != nil {
+		if _, err := parsePrivateKey(block); err != nil {
+			return fmt.Errorf("invalid SigningKey: %v", err)
+		}
+	}
+	return nil
+}
+
+// artifactPaths returns the files written by the plugin that exist, in the order they
+// are written, with the files of the feature details and reproduction bundle
+// directories last.
+func artifactPaths(args Args) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	reportPaths := []string{args.SummaryFile, args.JUnitOutputPath, args.HarnessTestReportPath, args.StepGapReport, args.LintReport, args.StepStatsReport, args.PendingAgingReport, args.XLSXReportPath, args.ExportResults, args.FeatureExportFile,
+		args.QuarantineFile, args.HeatmapFile, args.TimingsFile, args.TimelineFile, args.GateReport, failureOverflowFile(args), args.HTMLReportPath, args.CucumberHTMLReportPath, args.PDFReportPath, args.AuditFile}
+	for _, path := range append(reportPaths, outputFormatPaths(args)...) {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+	}
+
+	for _, dir := range []string{args.FeatureDetailsDir, args.ReproDir} {
+		if dir == "" {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if entry.Type().IsRegular() {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of a file.
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checksumManifest lists the checksums of the files in the format of sha256sum, so that
+// they can be verified with sha256sum --check.
+func checksumManifest(paths []string) ([]byte, error) {
+	var manifest strings.Builder
+	for _, path := range paths {
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&manifest, "%s  %s\n", checksum, filepath.ToSlash(path))
+	}
+	return []byte(manifest.String()), nil
+}
+
+// signManifest signs the checksum manifest with the key. A PEM encoded private key signs
+// it with ECDSA or RSA over its SHA-256 digest, as cosign verify-blob checks it with the
+// public key, or with Ed25519, and returns the signature base64 encoded. Any other key signs it
+// with HMAC-SHA256 and returns the hex encoded MAC.
+func signManifest(manifest []byte, key string) (string, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(key)))
+	if block == nil {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(manifest)
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+
+	privateKey, err := parsePrivateKey(block)
+	if err != nil {
+		return "", err
+	}
+	var signature []byte
+	digest := sha256.Sum256(manifest)
+	switch privateKey := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		signature, err = ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(privateKey, manifest)
+	default:
+		err = fmt.Errorf("unsupported signing key type %T", privateKey)
+	}
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses an unencrypted PKCS #8, EC or PKCS #1 private key.
+func parsePrivateKey(block *pem.Block) (interface{}, error) {
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if strings.Contains(block.Type, "ENCRYPTED") {
+		return nil, errors.New("encrypted signing keys are not supported, export the private key unencrypted")
+	}
+	return nil, fmt.Errorf("unsupported signing key type %s", block.Type)
+}
+
+// writeChecksums writes the checksum manifest of the artifacts, and its signature next
+// to it with a .sig extension when a signing key is configured. It returns the number of
+// artifacts and the checksum of the manifest.
+func writeChecksums(path string, args Args) (int, string, error) {
+	paths, err := artifactPaths(args)
+	if err != nil {
+		return 0, "", err
+	}
+	manifest, err := checksumManifest(paths)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, "", err
+	}
+	if err := os.WriteFile(path, manifest, 0644); err != nil {
+		return 0, "", err
+	}
+
+	if args.SigningKey != "" {
+		signature, err := signManifest(manifest, args.SigningKey)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to sign checksums: %w", err)
+		}
+		if err := os.WriteFile(path+".sig", []byte(signature+"\n"), 0644); err != nil {
+			return 0, "", err
+		}
+	}
+
+	digest := sha256.Sum256(manifest)
+	return len(paths), hex.EncodeToString(digest[:]), nil
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteChecksums tests the checksum manifest of the artifacts and its HMAC signature
+func TestWriteChecksums(t *testing.T) {
+	dir := t.TempDir()
+	summary := filepath.Join(dir, "summary.json")
+	bundle := filepath.Join(dir, "repro", "001-login")
+	os.WriteFile(summary, []byte("{}"), 0644)
+	os.MkdirAll(bundle, 0755)
+	os.WriteFile(filepath.Join(bundle, "error.txt"), []byte("boom"), 0644)
+
+	path := filepath.Join(dir, "SHA256SUMS")
+	args := Args{
+		SummaryFile:  summary,
+		TimingsFile:  filepath.Join(dir, "missing.json"),
+		ReproDir:     filepath.Join(dir, "repro"),
+		ChecksumFile: path,
+		SigningKey:   "secret",
+	}
+	count, checksum, err := writeChecksums(path, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 artifacts, got %d", count)
+	}
+
+	manifest, _ := os.ReadFile(path)
+	expected := "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a  " + filepath.ToSlash(summary) + "\n" +
+		"81f52337ebb4cb1669bb802c708807dde0519d15cb102a6313d26ad5cd821713  " + filepath.ToSlash(filepath.Join(bundle, "error.txt")) + "\n"
+	if string(manifest) != expected {
+		t.Errorf("Expected manifest:\n%s\ngot:\n%s", expected, manifest)
+	}
+	if digest := sha256.Sum256(manifest); checksum != hex.EncodeToString(digest[:]) {
+		t.Errorf("Expected the checksum of the manifest, got %s", checksum)
+	}
+
+	signature, _ := os.ReadFile(path + ".sig")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(manifest)
+	if strings.TrimSpace(string(signature)) != hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("Unexpected signature %s", signature)
+	}
+}
+
+// TestSignManifestECDSA tests signing the manifest with a PEM encoded ECDSA key
+func TestSignManifestECDSA(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	if err := validateSigningKey(Args{SigningKey: pemKey, ChecksumFile: "SHA256SUMS"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	manifest := []byte("abc  summary.json\n")
+	signature, err := signManifest(manifest, pemKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(signature)
+	digest := sha256.Sum256(manifest)
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], decoded) {
+		t.Error("Expected the signature to verify with the public key")
+	}
+
+	encrypted := string(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: []byte("x")}))
+	if err := validateSigningKey(Args{SigningKey: encrypted, ChecksumFile: "SHA256SUMS"}); err == nil {
+		t.Error("Expected an error for an encrypted key")
+	}
+}
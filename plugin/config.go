@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxSettingTypoDistance is the largest edit distance between an unknown PLUGIN_ variable
+// and a setting for the variable to be reported as a likely typo of the setting.
+const maxSettingTypoDistance = 3
+
+// settingKinds returns the type of the setting of every PLUGIN_ environment variable.
+func settingKinds() map[string]reflect.Kind {
+	kinds := make(map[string]reflect.Kind)
+	fields := reflect.TypeOf(Args{})
+	for i := 0; i < fields.NumField(); i++ {
+		if env := fields.Field(i).Tag.Get("envconfig"); env != "" {
+			kinds[env] = fields.Field(i).Type.Kind()
+		}
+	}
+	return kinds
+}
+
+// ValidateEnvironment checks the PLUGIN_ environment variables, given as KEY=VALUE pairs
+// like os.Environ returns them, before they are loaded into the settings. It reports
+// every value that is not a valid number or boolean at once, and warns about the
+// unknown variables, suggesting the setting they are likely a typo of.
+func ValidateEnvironment(environ []string) error {
+	kinds := settingKinds()
+
+	var errs []error
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, "PLUGIN_") {
+			continue
+		}
+		kind, ok := kinds[name]
+		if !ok {
+			if suggestion := closestSetting(name, kinds); suggestion != "" {
+				logger.Warnf("Unknown setting %s ignored, did you mean %s?", name, suggestion)
+			} else {
+				logger.Warnf("Unknown setting %s ignored", name)
+			}
+			continue
+		}
+		if err := validateSettingValue(kind, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s value '%s': %v", name, value, err))
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return wrapError(ErrInvalidConfig, errors.Join(errs...))
+}
+
+// validateSettingValue checks that the value parses as the type of the setting, the way
+// envconfig parses it.
+func validateSettingValue(kind reflect.Kind, value string) error {
+	switch kind {
+	case reflect.Int:
+		if _, err := strconv.ParseInt(value, 0, 64); err != nil {
+			return errors.New("not an integer")
+		}
+	case reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("not a number")
+		}
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("not a boolean, use true or false")
+		}
+	}
+	return nil
+}
+
+// closestSetting returns the setting closest to the unknown variable, or an empty string
+// when no setting is close enough to be the intended one.
+func closestSetting(name string, kinds map[string]reflect.Kind) string {
+	closest, best := "", maxSettingTypoDistance+1
+	for env := range kinds {
+		if distance := editDistance(name, env); distance < best || (distance == best && env < closest) {
+			closest, best = env, distance
+		}
+	}
+	if best > maxSettingTypoDistance {
+		return ""
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// validatePercentages checks that the percentage settings are between 0 and 100.
+func validatePercentages(args Args) error {
+	percentages := []struct {
+		name  string
+		value float64
+	}{
+		{"FailedFeaturesPercentage", args.FailedFeaturesPercentage},
+		{"FailedScenariosPercentage", args.FailedScenariosPercentage},
+		{"FailedStepsPercentage", args.FailedStepsPercentage},
+		{"PendingStepsPercentage", args.PendingStepsPercentage},
+		{"SkippedStepsPercentage", args.SkippedStepsPercentage},
+		{"UndefinedStepsPercentage", args.UndefinedStepsPercentage},
+		{"FeatureUndefinedPercentage", args.FeatureUndefinedPercentage},
+		{"QuarantineThreshold", args.QuarantineThreshold},
+		{"MaxScenarioDropPercentage", args.MaxScenarioDropPercentage},
+	}
+	for _, percentage := range percentages {
+		if percentage.value < 0 || percentage.value > 100 {
+			return fmt.Errorf("invalid %s value %v. It must be a percentage between 0 and 100", percentage.name, percentage.value)
+		}
+	}
+	return nil
+}
+
+// LogConfiguration logs the effective settings, after the defaults, environment and
+// flags are applied, at debug level with the secrets redacted.
+func LogConfiguration(args Args) {
+	configuration := resolvedConfiguration(args)
+	names := make([]string, 0, len(configuration))
+	for name := range configuration {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	logger.Debugf("Effective configuration:")
+	for _, name := range names {
+		logger.Debugf("  %s=%s", name, configuration[name])
+	}
+}
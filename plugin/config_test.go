@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		wantErr string
+	}{
+		{
+			name:    "valid values",
+			environ: []string{"PLUGIN_FAILED_STEPS_NUMBER=0x10", "PLUGIN_FAILED_STEPS_PERCENTAGE=2.5", "PLUGIN_FAIL_ON_DURATION_REGRESSION=true", "PLUGIN_JSON_REPORT_DIRECTORY=reports"},
+		},
+		{
+			name:    "unknown settings are ignored",
+			environ: []string{"PLUGIN_FAILED_FEATURE_NUMBER=abc", "PATH=/usr/bin"},
+		},
+		{
+			name:    "malformed values are reported at once",
+			environ: []string{"PLUGIN_FAILED_STEPS_PERCENTAGE=10%", "PLUGIN_FAILED_STEPS_NUMBER=three", "PLUGIN_FAIL_ON_DURATION_REGRESSION=yes"},
+			wantErr: "invalid PLUGIN_FAILED_STEPS_NUMBER value 'three': not an integer\n" +
+				"invalid PLUGIN_FAILED_STEPS_PERCENTAGE value '10%': not a number\n" +
+				"invalid PLUGIN_FAIL_ON_DURATION_REGRESSION value 'yes': not a boolean, use true or false",
+		},
+		{
+			name:    "empty number",
+			environ: []string{"PLUGIN_TREND_BUILDS="},
+			wantErr: "invalid PLUGIN_TREND_BUILDS value '': not an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEnvironment(tt.environ)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateEnvironment() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateEnvironment() error = %v, want %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("ValidateEnvironment() error is not ErrInvalidConfig")
+			}
+		})
+	}
+}
+
+func TestClosestSetting(t *testing.T) {
+	kinds := settingKinds()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"PLUGIN_FAILED_FEATURE_NUMBER", "PLUGIN_FAILED_FEATURES_NUMBER"},
+		{"PLUGIN_JSON_REPORT_DIRECTROY", "PLUGIN_JSON_REPORT_DIRECTORY"},
+		{"PLUGIN_SOMETHING_ELSE_ENTIRELY", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := closestSetting(tt.name, kinds); got != tt.want {
+				t.Errorf("closestSetting(%s) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultConfluencePageTitle is the page title template used when none is configured.
+const defaultConfluencePageTitle = "Cucumber Results - {{.Repo}} {{.Branch}}"
+
+// confluencePage represents the fields of a Confluence page used by the plugin.
+type confluencePage struct {
+	ID      string `json:"id"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+// validateConfluenceArgs checks the Confluence settings.
+func validateConfluenceArgs(args Args) error {
+	if args.ConfluenceURL == "" {
+		return nil
+	}
+	if args.ConfluenceSpace == "" {
+		return errors.New("a Confluence space key is required to publish the summary")
+	}
+	if _, err := template.New("title").Parse(args.ConfluencePageTitle); err != nil {
+		return fmt.Errorf("invalid Confluence page title template: %v", err)
+	}
+	return nil
+}
+
+// confluencePageTitle renders the page title template.
+func confluencePageTitle(args Args, now time.Time) (string, error) {
+	text := args.ConfluencePageTitle
+	if text == "" {
+		text = defaultConfluencePageTitle
+	}
+
+	tmpl, err := template.New("title").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var title bytes.Buffer
+	err = tmpl.Execute(&title, map[string]string{
+		"Repo":        currentRepo(),
+		"Branch":      currentBranch(args),
+		"BuildNumber": currentBuildNumber(),
+		"Date":        now.Format("2006-01-02"),
+	})
+	return strings.TrimSpace(title.String()), err
+}
+
+// confluenceStorageBody renders the summary and trend in the Confluence storage format.
+func confluenceStorageBody(results Results, args Args, trend *Trend, gateErr error, now time.Time) (string, error) {
+	return renderReportTemplate(templateConfluence, newReportTemplateData(results, args, trend, gateErr, now), args)
+}
+
+// confluenceHeaders returns the authorization headers, using basic authentication with
+// an API token when a username is configured and a personal access token otherwise.
+func confluenceHeaders(args Args) map[string]string {
+	if args.ConfluenceUsername != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(args.ConfluenceUsername + ":" + args.ConfluenceToken))
+		return map[string]string{"Authorization": "Basic " + credentials}
+	}
+	return map[string]string{"Authorization": "Bearer " + args.ConfluenceToken}
+}
+
+// publishConfluencePage creates or updates the Confluence page with the run summary.
+func publishConfluencePage(ctx context.Context, results Results, args Args, trend *Trend, gateErr error) {
+	now := time.Now()
+	title, err := confluencePageTitle(args, now)
+	if err != nil {
+		logger.Warnf("Failed to render Confluence page title: %v", err)
+		return
+	}
+
+	body, err := confluenceStorageBody(results, args, trend, gateErr, now)
+	if err != nil {
+		logger.Warnf("Failed to render Confluence page %s: %v", title, err)
+		return
+	}
+	if err := upsertConfluencePage(ctx, args, title, body); err != nil {
+		logger.Warnf("Failed to publish Confluence page %s: %v", title, err)
+		return
+	}
+	logger.Infof("Confluence page %s published\n", title)
+}
+
+// upsertConfluencePage updates the page with the title in the space or creates it.
+func upsertConfluencePage(ctx context.Context, args Args, title, body string) error {
+	api := strings.TrimRight(args.ConfluenceURL, "/") + "/rest/api/content"
+	headers := confluenceHeaders(args)
+
+	query := url.Values{}
+	query.Set("spaceKey", args.ConfluenceSpace)
+	query.Set("title", title)
+	query.Set("expand", "version")
+
+	var existing struct {
+		Results []confluencePage `json:"results"`
+	}
+	if err := doJSON(ctx, args, http.MethodGet, api+"?"+query.Encode(), nil, headers, &existing); err != nil {
+		return err
+	}
+
+	page := map[string]interface{}{
+		"type":  "page",
+		"title": title,
+		"space": map[string]string{"key": args.ConfluenceSpace},
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+	}
+	if args.ConfluenceParentID != "" {
+		page["ancestors"] = []map[string]string{{"id": args.ConfluenceParentID}}
+	}
+
+	if len(existing.Results) == 0 {
+		return doJSON(ctx, args, http.MethodPost, api, page, headers, nil)
+	}
+
+	current := existing.Results[0]
+	page["version"] = map[string]int{"number": current.Version.Number + 1}
+	return doJSON(ctx, args, http.MethodPut, api+"/"+current.ID, page, headers, nil)
+}
@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestPublishConfluencePage tests creating and updating the summary page
+func TestPublishConfluencePage(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       string
+		expectedMethod string
+		expectedPath   string
+		expectVersion  float64
+	}{
+		{
+			name:           "Create Page",
+			existing:       `{"results":[]}`,
+			expectedMethod: http.MethodPost,
+			expectedPath:   "/wiki/rest/api/content",
+		},
+		{
+			name:           "Update Page",
+			existing:       `{"results":[{"id":"123","version":{"number":4}}]}`,
+			expectedMethod: http.MethodPut,
+			expectedPath:   "/wiki/rest/api/content/123",
+			expectVersion:  5,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var method, path, title, auth string
+			var page map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					title = r.URL.Query().Get("title")
+					w.Write([]byte(tc.existing))
+					return
+				}
+				method, path, auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&page)
+			}))
+			defer server.Close()
+
+			args := Args{
+				ConfluenceURL:       server.URL + "/wiki",
+				ConfluenceUsername:  "qa@example.com",
+				ConfluenceToken:     "token",
+				ConfluenceSpace:     "QA",
+				ConfluenceParentID:  "42",
+				ConfluencePageTitle: "Nightly {{.Branch}}",
+				Branch:              "main",
+			}
+			results := Results{ScenarioCount: 1, FailedSteps: []FailedStepDetails{{Feature: "F", Scenario: "<S>", Step: "step"}}}
+			publishConfluencePage(context.Background(), results, args, nil, nil)
+
+			if title != "Nightly main" {
+				t.Errorf("Expected page title 'Nightly main', got %q", title)
+			}
+			if method != tc.expectedMethod || path != tc.expectedPath {
+				t.Errorf("Expected %s %s, got %s %s", tc.expectedMethod, tc.expectedPath, method, path)
+			}
+			if !strings.HasPrefix(auth, "Basic ") {
+				t.Errorf("Expected basic authentication, got %q", auth)
+			}
+
+			body, _ := json.Marshal(page["body"])
+			if !strings.Contains(string(body), "\\u0026lt;S\\u0026gt;") {
+				t.Errorf("Expected escaped scenario name in body, got %s", body)
+			}
+			if tc.expectVersion > 0 {
+				version, _ := page["version"].(map[string]interface{})
+				if version["number"] != tc.expectVersion {
+					t.Errorf("Expected version %v, got %v", tc.expectVersion, page["version"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// findConsistencyViolations returns the scenarios executed in several shards with
+// conflicting statuses. The executions of a scenario within a shard are reruns, so the
+// status of a shard is the one of its last execution.
+func findConsistencyViolations(scenarios []ScenarioResult) []ConsistencyViolation {
+	type execution struct {
+		scenario ScenarioResult
+		shards   []string
+		statuses map[string]string
+	}
+	executions := make(map[string]*execution)
+	for _, scenario := range scenarios {
+		if scenario.ID == "" || scenario.Shard == "" {
+			continue
+		}
+		exec, ok := executions[scenario.ID]
+		if !ok {
+			exec = &execution{scenario: scenario, statuses: make(map[string]string)}
+			executions[scenario.ID] = exec
+		}
+		if _, ok := exec.statuses[scenario.Shard]; !ok {
+			exec.shards = append(exec.shards, scenario.Shard)
+		}
+		exec.statuses[scenario.Shard] = scenario.Status
+	}
+
+	var violations []ConsistencyViolation
+	for id, exec := range executions {
+		conflicting := false
+		for _, shard := range exec.shards {
+			conflicting = conflicting || exec.statuses[shard] != exec.statuses[exec.shards[0]]
+		}
+		if !conflicting {
+			continue
+		}
+		violation := ConsistencyViolation{ID: id, Feature: exec.scenario.Feature, Scenario: exec.scenario.Scenario}
+		for _, shard := range exec.shards {
+			violation.Shards = append(violation.Shards, ShardStatus{Shard: shard, Status: exec.statuses[shard]})
+		}
+		violations = append(violations, violation)
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].ID < violations[j].ID })
+	return violations
+}
+
+// describe lists the status of the scenario in every shard, such as "passed in shard-1,
+// failed in shard-2".
+func (v ConsistencyViolation) describe() string {
+	statuses := make([]string, len(v.Shards))
+	for i, shard := range v.Shards {
+		statuses[i] = shard.Status + " in " + shard.Shard
+	}
+	return strings.Join(statuses, ", ")
+}
+
+// logConsistencyViolations logs the scenarios with conflicting statuses across shards.
+func logConsistencyViolations(violations []ConsistencyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	logger.Infof("Consistency Violations:\n")
+	for _, violation := range violations {
+		logger.Warnf("%s ran in several shards with conflicting statuses: %s", scenarioIdentifier(violation.Feature, violation.Scenario), violation.describe())
+	}
+}
+
+// validateConsistency returns an error if scenarios ran in several shards with
+// conflicting statuses when configured to fail, and only logs them otherwise.
+func validateConsistency(results Results, args Args) error {
+	if len(results.ConsistencyViolations) == 0 {
+		return nil
+	}
+
+	identifiers := make([]string, len(results.ConsistencyViolations))
+	for i, violation := range results.ConsistencyViolations {
+		identifiers[i] = scenarioIdentifier(violation.Feature, violation.Scenario)
+	}
+	err := wrapError(ErrInconsistentResults, fmt.Errorf("%d scenarios ran in several shards with conflicting statuses: %s",
+		len(identifiers), listNames(identifiers, maxListedNames)))
+	if !strings.EqualFold(args.ConsistencyViolationsAction, ActionFail) {
+		logger.Warnf("%s", err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindConsistencyViolations(t *testing.T) {
+	scenarios := []ScenarioResult{
+		// Rerun within a shard: the last execution counts
+		{ID: "login;valid", Feature: "Login", Scenario: "Valid", Shard: "1", Status: "failed"},
+		{ID: "login;valid", Feature: "Login", Scenario: "Valid", Shard: "1", Status: "passed"},
+		// Double execution with the same status
+		{ID: "search;find", Feature: "Search", Scenario: "Find", Shard: "1", Status: "passed"},
+		{ID: "search;find", Feature: "Search", Scenario: "Find", Shard: "2", Status: "passed"},
+		// Double execution with conflicting statuses
+		{ID: "checkout;card", Feature: "Checkout", Scenario: "Card", Shard: "2", Status: "passed"},
+		{ID: "checkout;card", Feature: "Checkout", Scenario: "Card", Shard: "3", Status: "failed"},
+		{ID: "checkout;card", Feature: "Checkout", Scenario: "Card", Shard: "3", Status: "failed"},
+		// No shard
+		{ID: "cart;add", Feature: "Cart", Scenario: "Add", Status: "passed"},
+		{ID: "cart;add", Feature: "Cart", Scenario: "Add", Status: "failed"},
+	}
+
+	expected := []ConsistencyViolation{{
+		ID: "checkout;card", Feature: "Checkout", Scenario: "Card",
+		Shards: []ShardStatus{{Shard: "2", Status: "passed"}, {Shard: "3", Status: "failed"}},
+	}}
+	violations := findConsistencyViolations(scenarios)
+	if diff := cmp.Diff(expected, violations); diff != "" {
+		t.Errorf("Consistency violations mismatch (-want +got):\n%s", diff)
+	}
+	if got := violations[0].describe(); got != "passed in 2, failed in 3" {
+		t.Errorf("Unexpected description: %s", got)
+	}
+}
+
+func TestValidateConsistency(t *testing.T) {
+	results := Results{ConsistencyViolations: []ConsistencyViolation{{ID: "checkout;card", Feature: "Checkout", Scenario: "Card"}}}
+
+	if err := validateConsistency(results, Args{}); err != nil {
+		t.Errorf("Expected only a warning by default, got %v", err)
+	}
+	err := validateConsistency(results, Args{ConsistencyViolationsAction: "fail"})
+	if !errors.Is(err, ErrInconsistentResults) || ErrorCode(err) != "INCONSISTENT_RESULTS" {
+		t.Errorf("Expected an inconsistent results error, got %v", err)
+	}
+	if err := validateConsistency(Results{}, Args{ConsistencyViolationsAction: ActionFail}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
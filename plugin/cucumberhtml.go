@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultCucumberHTMLFormatter is the command of the official Cucumber HTML formatter,
+// installed with the @cucumber/html-formatter package, reading the messages on its
+// standard input and writing the report on its standard output.
+const defaultCucumberHTMLFormatter = "cucumber-html-formatter"
+
+// validateCucumberHTMLArgs rejects the Cucumber HTML report on Windows unless the
+// formatter command is configured, since only the Linux images install the formatter.
+func validateCucumberHTMLArgs(args Args, goos string) error {
+	if args.CucumberHTMLReportPath != "" && args.CucumberHTMLFormatter == "" && goos == "windows" {
+		return errors.New("CucumberHTMLReportPath requires a CucumberHTMLFormatter on Windows, the Windows image does not install the Cucumber HTML formatter")
+	}
+	return nil
+}
+
+// cucumberMessages returns the Cucumber Messages stream of the report files: the stream
+// itself when the reports are a single stream, so that the report renders it as the
+// runner wrote it, and the messages converted from the merged features otherwise.
+func cucumberMessages(files []string, args Args) ([]byte, error) {
+	if len(files) == 1 {
+		content, err := os.ReadFile(files[0])
+		if err != nil {
+			return nil, err
+		}
+		dialect := strings.ToUpper(args.ReportDialect)
+		if dialect == DialectMessages || ((dialect == "" || dialect == DialectAuto) && isCucumberMessages(content)) {
+			return content, nil
+		}
+	}
+
+	var messages bytes.Buffer
+	if err := writeCucumberMessages(&messages, loadFeatures(files, args), time.Now()); err != nil {
+		return nil, err
+	}
+	return messages.Bytes(), nil
+}
+
+// writeCucumberHTMLReport renders the report files with the Cucumber HTML formatter.
+func writeCucumberHTMLReport(ctx context.Context, path string, files []string, args Args) error {
+	command := strings.Fields(firstNonEmpty(args.CucumberHTMLFormatter, defaultCucumberHTMLFormatter))
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return fmt.Errorf("the Cucumber HTML formatter %s is not installed: %v", command[0], err)
+	}
+
+	messages, err := cucumberMessages(files, args)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	output, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(messages)
+	cmd.Stdout = output
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return errors.New(firstLine(message))
+		}
+		return err
+	}
+	return output.Close()
+}
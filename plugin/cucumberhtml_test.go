@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateCucumberHTMLArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  Args
+		goos  string
+		valid bool
+	}{
+		{"linux", Args{CucumberHTMLReportPath: "cucumber.html"}, "linux", true},
+		{"windows", Args{CucumberHTMLReportPath: "cucumber.html"}, "windows", false},
+		{"windows with formatter", Args{CucumberHTMLReportPath: "cucumber.html", CucumberHTMLFormatter: "npx @cucumber/html-formatter"}, "windows", true},
+		{"windows without report", Args{}, "windows", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCucumberHTMLArgs(tt.args, tt.goos)
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestWriteCucumberHTMLReport(t *testing.T) {
+	messages, err := os.ReadFile("../testdata/dialects/messages.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cat echoes the messages in place of the formatter
+	tests := []struct {
+		name      string
+		files     []string
+		formatter string
+		check     func(t *testing.T, report []byte, err error)
+	}{
+		{
+			name:      "messages are passed through",
+			files:     []string{"../testdata/dialects/messages.ndjson"},
+			formatter: "cat",
+			check: func(t *testing.T, report []byte, err error) {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !bytes.Equal(report, messages) {
+					t.Errorf("Expected the messages to be passed through, got %q", report)
+				}
+			},
+		},
+		{
+			name:      "JSON reports are converted",
+			files:     []string{"../testdata/cucumber_report.json"},
+			formatter: "cat",
+			check: func(t *testing.T, report []byte, err error) {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !strings.Contains(string(report), `"gherkinDocument"`) {
+					t.Errorf("Expected converted messages, got %q", report)
+				}
+			},
+		},
+		{
+			name:      "missing formatter",
+			files:     []string{"../testdata/dialects/messages.ndjson"},
+			formatter: "cucumber-html-formatter-missing",
+			check: func(t *testing.T, report []byte, err error) {
+				if err == nil || !strings.Contains(err.Error(), "is not installed") {
+					t.Errorf("Expected a missing formatter error, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cucumber", "report.html")
+			err := writeCucumberHTMLReport(context.Background(), path, tt.files, Args{CucumberHTMLFormatter: tt.formatter})
+			report, _ := os.ReadFile(path)
+			tt.check(t, report, err)
+		})
+	}
+}
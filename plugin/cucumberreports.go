@@ -0,0 +1,381 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCucumberReportsURL is the endpoint of the Cucumber Reports service.
+const defaultCucumberReportsURL = "https://messages.cucumber.io/api/reports"
+
+// cucumberReportsLink matches the share URL in the banner returned by the Cucumber Reports service.
+var cucumberReportsLink = regexp.MustCompile(`https?://[^\s│|]+/reports/[0-9A-Za-z-]+`)
+
+// messageStatuses maps the step statuses to the Cucumber Messages statuses.
+var messageStatuses = map[string]string{
+	"passed":    "PASSED",
+	"failed":    "FAILED",
+	"skipped":   "SKIPPED",
+	"pending":   "PENDING",
+	"undefined": "UNDEFINED",
+	"ambiguous": "AMBIGUOUS",
+}
+
+// messageRun holds the pickle and test case of a scenario with the results of its steps.
+type messageRun struct {
+	pickle      map[string]interface{}
+	testCaseID  string
+	testStepIDs []string
+	results     []Result
+}
+
+// messageIDs generates sequential identifiers for the Cucumber Messages.
+type messageIDs int
+
+// next returns the next identifier.
+func (ids *messageIDs) next() string {
+	*ids++
+	return strconv.Itoa(int(*ids))
+}
+
+// messageTimestamp returns a Cucumber Messages timestamp.
+func messageTimestamp(t time.Time) map[string]int64 {
+	return map[string]int64{"seconds": t.Unix(), "nanos": int64(t.Nanosecond())}
+}
+
+// messageDuration returns a Cucumber Messages duration.
+func messageDuration(d time.Duration) map[string]int64 {
+	return map[string]int64{"seconds": int64(d / time.Second), "nanos": int64(d % time.Second)}
+}
+
+// messageStatus returns the Cucumber Messages status of a step status.
+func messageStatus(status string) string {
+	if value, ok := messageStatuses[status]; ok {
+		return value
+	}
+	return "UNKNOWN"
+}
+
+// writeCucumberMessages converts the features into a Cucumber Messages NDJSON stream.
+// Timestamps are derived from the step durations, starting at the given time.
+func writeCucumberMessages(w io.Writer, features []Feature, start time.Time) error {
+	encoder := json.NewEncoder(w)
+	var ids messageIDs
+	now := start
+	success := true
+
+	envelopes := []map[string]interface{}{
+		{"meta": map[string]interface{}{
+			"protocolVersion": "22.0.0",
+			"implementation":  map[string]string{"name": "drone-cucumber"},
+			"runtime":         map[string]string{"name": "go", "version": runtime.Version()},
+			"os":              map[string]string{"name": runtime.GOOS},
+			"cpu":             map[string]string{"name": runtime.GOARCH},
+		}},
+		{"testRunStarted": map[string]interface{}{"timestamp": messageTimestamp(now)}},
+	}
+	for _, envelope := range envelopes {
+		if err := encoder.Encode(envelope); err != nil {
+			return err
+		}
+	}
+
+	for _, feature := range features {
+		var source strings.Builder
+		fmt.Fprintf(&source, "%s: %s\n", firstNonEmpty(feature.Keyword, "Feature"), feature.Name)
+
+		var children []interface{}
+		var runs []messageRun
+		var testCases []interface{}
+		for _, element := range feature.Elements {
+			if element.Type == "background" {
+				continue
+			}
+			fmt.Fprintf(&source, "\n  %s: %s\n", firstNonEmpty(element.Keyword, "Scenario"), element.Name)
+
+			scenarioID := ids.next()
+			run := messageRun{}
+			var astSteps, pickleSteps, testSteps []interface{}
+			for _, step := range element.Steps {
+				fmt.Fprintf(&source, "    %s%s\n", step.Keyword, step.Name)
+
+				astID, pickleStepID, testStepID := ids.next(), ids.next(), ids.next()
+				astSteps = append(astSteps, map[string]interface{}{
+					"id":       astID,
+					"location": map[string]int{"line": step.Line},
+					"keyword":  step.Keyword,
+					"text":     step.Name,
+				})
+				pickleSteps = append(pickleSteps, map[string]interface{}{
+					"id":         pickleStepID,
+					"text":       step.Name,
+					"astNodeIds": []string{astID},
+				})
+				testSteps = append(testSteps, map[string]interface{}{
+					"id":                      testStepID,
+					"pickleStepId":            pickleStepID,
+					"stepDefinitionIds":       []string{},
+					"stepMatchArgumentsLists": []interface{}{},
+				})
+				run.testStepIDs = append(run.testStepIDs, testStepID)
+				run.results = append(run.results, step.Result)
+			}
+
+			var astTags, pickleTags []interface{}
+			for _, tag := range element.Tags {
+				tagID := ids.next()
+				astTags = append(astTags, map[string]interface{}{"id": tagID, "name": tag.Name, "location": map[string]int{"line": tag.Line}})
+				pickleTags = append(pickleTags, map[string]interface{}{"name": tag.Name, "astNodeId": tagID})
+			}
+			for _, tag := range feature.Tags {
+				pickleTags = append(pickleTags, map[string]interface{}{"name": tag.Name, "astNodeId": scenarioID})
+			}
+
+			children = append(children, map[string]interface{}{
+				"scenario": map[string]interface{}{
+					"id":          scenarioID,
+					"location":    map[string]int{"line": element.Line},
+					"tags":        nonNil(astTags),
+					"keyword":     firstNonEmpty(element.Keyword, "Scenario"),
+					"name":        element.Name,
+					"description": element.Description,
+					"steps":       nonNil(astSteps),
+					"examples":    []interface{}{},
+				},
+			})
+
+			pickleID := ids.next()
+			run.testCaseID = ids.next()
+			run.pickle = map[string]interface{}{
+				"id":         pickleID,
+				"uri":        feature.URI,
+				"name":       element.Name,
+				"language":   "en",
+				"steps":      nonNil(pickleSteps),
+				"tags":       nonNil(pickleTags),
+				"astNodeIds": []string{scenarioID},
+			}
+			runs = append(runs, run)
+			testCases = append(testCases, map[string]interface{}{
+				"id":        run.testCaseID,
+				"pickleId":  pickleID,
+				"testSteps": nonNil(testSteps),
+			})
+		}
+
+		featureEnvelopes := []map[string]interface{}{
+			{"source": map[string]string{
+				"uri":       feature.URI,
+				"data":      source.String(),
+				"mediaType": "text/x.cucumber.gherkin+plain",
+			}},
+			{"gherkinDocument": map[string]interface{}{
+				"uri": feature.URI,
+				"feature": map[string]interface{}{
+					"location":    map[string]int{"line": feature.Line},
+					"tags":        featureTags(feature.Tags, &ids),
+					"language":    "en",
+					"keyword":     firstNonEmpty(feature.Keyword, "Feature"),
+					"name":        feature.Name,
+					"description": feature.Description,
+					"children":    nonNil(children),
+				},
+				"comments": []interface{}{},
+			}},
+		}
+		for _, run := range runs {
+			featureEnvelopes = append(featureEnvelopes, map[string]interface{}{"pickle": run.pickle})
+		}
+		for _, testCase := range testCases {
+			featureEnvelopes = append(featureEnvelopes, map[string]interface{}{"testCase": testCase})
+		}
+		for _, envelope := range featureEnvelopes {
+			if err := encoder.Encode(envelope); err != nil {
+				return err
+			}
+		}
+
+		// Replay the executions of the test cases
+		for _, run := range runs {
+			startedID := ids.next()
+			executions := []map[string]interface{}{
+				{"testCaseStarted": map[string]interface{}{
+					"id":         startedID,
+					"testCaseId": run.testCaseID,
+					"attempt":    0,
+					"timestamp":  messageTimestamp(now),
+				}},
+			}
+
+			for i, stepID := range run.testStepIDs {
+				result := run.results[i]
+				duration := time.Duration(result.Duration)
+				if result.Status == "failed" {
+					success = false
+				}
+
+				stepResult := map[string]interface{}{
+					"status":   messageStatus(result.Status),
+					"duration": messageDuration(duration),
+				}
+				if result.ErrorMessage != "" {
+					stepResult["message"] = result.ErrorMessage
+				}
+
+				executions = append(executions, map[string]interface{}{
+					"testStepStarted": map[string]interface{}{
+						"testCaseStartedId": startedID,
+						"testStepId":        stepID,
+						"timestamp":         messageTimestamp(now),
+					},
+				})
+				now = now.Add(duration)
+				executions = append(executions, map[string]interface{}{
+					"testStepFinished": map[string]interface{}{
+						"testCaseStartedId": startedID,
+						"testStepId":        stepID,
+						"testStepResult":    stepResult,
+						"timestamp":         messageTimestamp(now),
+					},
+				})
+			}
+
+			executions = append(executions, map[string]interface{}{
+				"testCaseFinished": map[string]interface{}{
+					"testCaseStartedId": startedID,
+					"timestamp":         messageTimestamp(now),
+					"willBeRetried":     false,
+				},
+			})
+			for _, envelope := range executions {
+				if err := encoder.Encode(envelope); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return encoder.Encode(map[string]interface{}{
+		"testRunFinished": map[string]interface{}{
+			"success":   success,
+			"timestamp": messageTimestamp(now),
+		},
+	})
+}
+
+// featureTags returns the Gherkin document tags of a feature.
+func featureTags(tags []Tag, ids *messageIDs) []interface{} {
+	result := []interface{}{}
+	for _, tag := range tags {
+		result = append(result, map[string]interface{}{"id": ids.next(), "name": tag.Name, "location": map[string]int{"line": tag.Line}})
+	}
+	return result
+}
+
+// nonNil returns an empty list instead of nil so that lists are encoded as [].
+func nonNil(values []interface{}) []interface{} {
+	if values == nil {
+		return []interface{}{}
+	}
+	return values
+}
+
+// loadFeatures reads and merges the features of the report files, skipping unreadable files.
+func loadFeatures(files []string, args Args) []Feature {
+	var features []Feature
+	for _, file := range files {
+		fileFeatures, err := readFeatures(file, args.SkipEmptyJSONFiles, args)
+		if err != nil {
+			continue
+		}
+		features = append(features, fileFeatures...)
+	}
+	if args.MergeFeaturesById {
+		features = mergeFeaturesById(features)
+	}
+	return features
+}
+
+// publishCucumberReport publishes the merged report to the Cucumber Reports service
+// and returns the share URL of the report.
+func publishCucumberReport(ctx context.Context, args Args, features []Feature) (string, error) {
+	endpoint := firstNonEmpty(args.CucumberReportsURL, defaultCucumberReportsURL)
+	client := newHTTPClient(args)
+
+	// The service answers with the upload location and a banner containing the share URL
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+args.CucumberReportsToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", sendError(req, err)
+	}
+	banner, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return "", statusError(req, resp, banner)
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("no upload location returned by %s", endpoint)
+	}
+
+	var messages bytes.Buffer
+	if err := writeCucumberMessages(&messages, features, time.Now()); err != nil {
+		return "", err
+	}
+	content, err := newScrubber(args).ndjson(messages.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	upload, err := http.NewRequestWithContext(ctx, http.MethodPut, location, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	upload.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err = client.Do(upload)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload messages: %w", sendError(upload, err))
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(upload, resp); err != nil {
+		return "", fmt.Errorf("failed to upload messages: %w", err)
+	}
+
+	return cucumberReportsLink.FindString(string(banner)), nil
+}
+
+// shareCucumberReport publishes the report to the Cucumber Reports service and exports
+// the share URL as an output variable.
+func shareCucumberReport(ctx context.Context, args Args, files []string) {
+	reportURL, err := publishCucumberReport(ctx, args, loadFeatures(files, args))
+	if err != nil {
+		logger.Warnf("Failed to publish report to Cucumber Reports: %v", err)
+		return
+	}
+	if reportURL == "" {
+		logger.Warnf("Report published to Cucumber Reports but no share URL was returned")
+		return
+	}
+
+	logger.Infof("Report published to %s\n", reportURL)
+	if err := WriteEnvToFile("CUCUMBER_REPORTS_URL", reportURL, logger); err != nil {
+		logger.Errorf("Error writing %s: %s", "CUCUMBER_REPORTS_URL", err)
+	}
+}
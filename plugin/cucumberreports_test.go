@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWriteCucumberMessages tests converting the features into Cucumber Messages
+func TestWriteCucumberMessages(t *testing.T) {
+	features := []Feature{{
+		URI:     "features/login.feature",
+		Keyword: "Feature",
+		Name:    "Login",
+		Elements: []Element{{
+			Keyword: "Scenario",
+			Name:    "Invalid password",
+			Type:    "scenario",
+			Steps: []Step{
+				{Keyword: "Given ", Name: "a user", Result: Result{Status: "passed", Duration: 1500000000}},
+				{Keyword: "When ", Name: "they log in", Result: Result{Status: "failed", ErrorMessage: "denied"}},
+			},
+		}},
+	}}
+
+	var buffer bytes.Buffer
+	if err := writeCucumberMessages(&buffer, features, time.Unix(1700000000, 0)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var types, statuses []string
+	var success interface{}
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var envelope map[string]map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &envelope); err != nil {
+			t.Fatalf("Invalid envelope %s: %v", scanner.Text(), err)
+		}
+		for name, message := range envelope {
+			types = append(types, name)
+			if name == "testStepFinished" {
+				statuses = append(statuses, message["testStepResult"].(map[string]interface{})["status"].(string))
+			}
+			if name == "testRunFinished" {
+				success = message["success"]
+			}
+		}
+	}
+
+	expected := []string{
+		"meta", "testRunStarted", "source", "gherkinDocument", "pickle", "testCase", "testCaseStarted",
+		"testStepStarted", "testStepFinished", "testStepStarted", "testStepFinished", "testCaseFinished", "testRunFinished",
+	}
+	if diff := cmp.Diff(expected, types); diff != "" {
+		t.Errorf("Envelopes mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"PASSED", "FAILED"}, statuses); diff != "" {
+		t.Errorf("Statuses mismatch (-want +got):\n%s", diff)
+	}
+	if success != false {
+		t.Errorf("Expected an unsuccessful run, got %v", success)
+	}
+}
+
+// TestPublishCucumberReport tests publishing the messages to the Cucumber Reports service
+func TestPublishCucumberReport(t *testing.T) {
+	var auth string
+	var uploaded []byte
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auth = r.Header.Get("Authorization")
+			w.Header().Set("Location", server.URL+"/upload")
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("│ View your Cucumber Report at:                          │\n│ https://reports.cucumber.io/reports/1f2e-3d4c │\n"))
+		case http.MethodPut:
+			uploaded, _ = io.ReadAll(r.Body)
+		}
+	}))
+	defer server.Close()
+
+	args := Args{CucumberReportsToken: "secret", CucumberReportsURL: server.URL + "/api/reports"}
+	reportURL, err := publishCucumberReport(context.Background(), args, []Feature{{Name: "Login"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if reportURL != "https://reports.cucumber.io/reports/1f2e-3d4c" {
+		t.Errorf("Unexpected report URL %q", reportURL)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected bearer token authorization, got %q", auth)
+	}
+	if !bytes.Contains(uploaded, []byte(`"testRunFinished"`)) {
+		t.Errorf("Expected uploaded messages, got %s", uploaded)
+	}
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// scenarioOutcome is the outcome of the executions of a scenario.
+type scenarioOutcome struct {
+	failed     bool
+	durationMS float64
+}
+
+// scenarioOutcomes returns the outcome of every scenario by identifier, failed when any of
+// its executions failed.
+func scenarioOutcomes(scenarios []ScenarioResult) map[string]scenarioOutcome {
+	outcomes := make(map[string]scenarioOutcome, len(scenarios))
+	for _, scenario := range scenarios {
+		id := scenarioIdentifier(scenario.Feature, scenario.Scenario)
+		outcome := outcomes[id]
+		outcome.failed = outcome.failed || scenario.Status == "failed"
+		outcome.durationMS += scenario.DurationMS
+		outcomes[id] = outcome
+	}
+	return outcomes
+}
+
+// exportResults writes the scenario results for a later run to compare with.
+func exportResults(path string, results Results, args Args) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	exported := ExportedResults{Branch: currentBranch(args), BuildNumber: currentBuildNumber(), Scenarios: results.Scenarios}
+	if exported.Scenarios == nil {
+		exported.Scenarios = []ScenarioResult{}
+	}
+	content, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// readBaselineFile reads the results exported by a previous run.
+func readBaselineFile(path string) (ExportedResults, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ExportedResults{}, err
+	}
+	var exported ExportedResults
+	if err := json.Unmarshal(content, &exported); err != nil {
+		return ExportedResults{}, fmt.Errorf("invalid baseline file: %v", err)
+	}
+	return exported, nil
+}
+
+// compareWithBaselineFile compares the scenarios with the results exported by a previous
+// run: the scenarios failing only in the build, the scenarios passing again and the
+// scenarios slower than in the baseline by the duration regression factor.
+func compareWithBaselineFile(results Results, baseline ExportedResults, source string, args Args) *BaselineDelta {
+	factor := args.DurationRegressionFactor
+	if factor <= 0 {
+		factor = defaultDurationRegressionFactor
+	}
+
+	delta := &BaselineDelta{Source: source, NewFailures: []string{}, NewPasses: []string{}, DurationRegressions: []DurationDelta{},
+		ScenarioCount: len(results.Scenarios), BaselineScenarios: len(baseline.Scenarios)}
+	if baseline.BuildNumber != "" {
+		delta.Source += " (build #" + baseline.BuildNumber + ")"
+	}
+
+	before := scenarioOutcomes(baseline.Scenarios)
+	for id, outcome := range scenarioOutcomes(results.Scenarios) {
+		previous, ok := before[id]
+		switch {
+		case outcome.failed && !previous.failed:
+			delta.NewFailures = append(delta.NewFailures, id)
+		case !outcome.failed && ok && previous.failed:
+			delta.NewPasses = append(delta.NewPasses, id)
+		}
+		if ok && previous.durationMS > 0 && outcome.durationMS > previous.durationMS*factor {
+			delta.DurationRegressions = append(delta.DurationRegressions, DurationDelta{
+				Scenario:           id,
+				DurationMS:         outcome.durationMS,
+				BaselineDurationMS: previous.durationMS,
+				Factor:             outcome.durationMS / previous.durationMS,
+			})
+		}
+	}
+
+	sort.Strings(delta.NewFailures)
+	sort.Strings(delta.NewPasses)
+	sort.Slice(delta.DurationRegressions, func(i, j int) bool {
+		if delta.DurationRegressions[i].Factor != delta.DurationRegressions[j].Factor {
+			return delta.DurationRegressions[i].Factor > delta.DurationRegressions[j].Factor
+		}
+		return delta.DurationRegressions[i].Scenario < delta.DurationRegressions[j].Scenario
+	})
+	return delta
+}
+
+// resolveBaselineDelta compares the build with the baseline file, and returns nil when
+// there is none yet, such as on the first run.
+func resolveBaselineDelta(results Results, args Args) *BaselineDelta {
+	baseline, err := readBaselineFile(args.BaselineFile)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Infof("No baseline file %s to compare with\n", args.BaselineFile)
+		return nil
+	}
+	if err != nil {
+		logger.Warnf("Failed to read the baseline file %s: %v", args.BaselineFile, err)
+		return nil
+	}
+	return compareWithBaselineFile(results, baseline, args.BaselineFile, args)
+}
+
+// logBaselineDelta logs the changes of the scenarios since the baseline file.
+func logBaselineDelta(delta *BaselineDelta) {
+	logger.Infof("Changes since %s:\n", delta.Source)
+	logger.Infof("-----------------------------------------------\n")
+	logger.Infof("%d newly failing, %d newly passing, %d slower scenarios\n", len(delta.NewFailures), len(delta.NewPasses), len(delta.DurationRegressions))
+	for _, id := range delta.NewFailures {
+		logger.Infof("❌ Newly failing: %s\n", id)
+	}
+	for _, id := range delta.NewPasses {
+		logger.Infof("✅ Newly passing: %s\n", id)
+	}
+	for _, regression := range delta.DurationRegressions {
+		logger.Infof("🐢 Slower: %s: %s ms (%s ms, %.2fx)\n", regression.Scenario, formatNumber(regression.DurationMS),
+			formatNumber(regression.BaselineDurationMS), regression.Factor)
+	}
+	logger.Infof("===============================================\n")
+}
+
+// writeBaselineDeltaStats writes the changes since the baseline file to the output file.
+func writeBaselineDeltaStats(delta *BaselineDelta, log Logger) {
+	statsMap := map[string]string{
+		"DELTA_NEW_FAILURES":         strconv.Itoa(len(delta.NewFailures)),
+		"DELTA_NEW_PASSES":           strconv.Itoa(len(delta.NewPasses)),
+		"DELTA_DURATION_REGRESSIONS": strconv.Itoa(len(delta.DurationRegressions)),
+	}
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
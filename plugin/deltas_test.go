@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompareWithBaselineFile(t *testing.T) {
+	baseline := Results{Scenarios: []ScenarioResult{
+		{Feature: "Cart", Scenario: "Pay", Status: "passed", DurationMS: 100},
+		{Feature: "Cart", Scenario: "Flaky", Status: "failed", DurationMS: 100},
+		{Feature: "Search", Scenario: "Filter", Status: "failed", DurationMS: 100},
+		{Feature: "Search", Scenario: "Sort", Status: "passed", DurationMS: 100},
+	}}
+	results := Results{Scenarios: []ScenarioResult{
+		{Feature: "Cart", Scenario: "Pay", Status: "failed", DurationMS: 100},
+		{Feature: "Cart", Scenario: "Flaky", Status: "passed", DurationMS: 100},
+		{Feature: "Search", Scenario: "Filter", Status: "failed", DurationMS: 100},
+		{Feature: "Search", Scenario: "Sort", Status: "passed", DurationMS: 250},
+		{Feature: "Search", Scenario: "Paginate", Status: "failed", DurationMS: 100},
+	}}
+
+	path := filepath.Join(t.TempDir(), "baseline", "results.json")
+	t.Setenv("DRONE_BUILD_NUMBER", "41")
+	if err := exportResults(path, baseline, Args{}); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := readBaselineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &BaselineDelta{
+		Source:              path + " (build #41)",
+		NewFailures:         []string{"Cart :: Pay", "Search :: Paginate"},
+		NewPasses:           []string{"Cart :: Flaky"},
+		DurationRegressions: []DurationDelta{{Scenario: "Search :: Sort", DurationMS: 250, BaselineDurationMS: 100, Factor: 2.5}},
+		ScenarioCount:       len(results.Scenarios),
+		BaselineScenarios:   len(baseline.Scenarios),
+	}
+	if diff := cmp.Diff(expected, compareWithBaselineFile(results, exported, path, Args{})); diff != "" {
+		t.Errorf("compareWithBaselineFile() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluateGatesBaselineDelta(t *testing.T) {
+	results := Results{FailedTests: 2, NewFailures: 0, BaselineDelta: &BaselineDelta{NewFailures: []string{"Cart :: Pay"}}}
+	args := Args{FailOnNewFailuresOnly: true, StopBuildOnFailedReport: true}
+	if err := evaluateGates(results, args, false); err == nil || err.Error() != "build failed due to new failures. Total new failed scenarios: 1" {
+		t.Errorf("Expected the new failures of the baseline file to fail the build, got %v", err)
+	}
+
+	results.BaselineDelta.NewFailures = nil
+	if err := evaluateGates(results, args, false); err != nil {
+		t.Errorf("Expected recurring failures not to fail the build, got %v", err)
+	}
+}
+
+// TestValidateScenarioDrift tests gating on the drop of the scenario count since the baseline
+func TestValidateScenarioDrift(t *testing.T) {
+	delta := &BaselineDelta{Source: "baseline.json (build #41)", ScenarioCount: 80, BaselineScenarios: 100}
+	comparison := &BaselineComparison{TargetBranch: "main", Source: "history build #11", ScenarioCount: 95, BaselineScenarios: 100}
+
+	tests := []struct {
+		name     string
+		results  Results
+		previous []HistoryEntry
+		args     Args
+		expected string
+	}{
+		{name: "No Baseline", results: Results{}, args: Args{MaxScenarioDropPercentage: 10}},
+		{name: "Disabled", results: Results{BaselineDelta: delta}, args: Args{}},
+		{name: "Within The Drop", results: Results{Baseline: comparison}, args: Args{MaxScenarioDropPercentage: 10}},
+		{name: "Baseline File First", results: Results{BaselineDelta: delta, Baseline: comparison}, args: Args{MaxScenarioDropPercentage: 10},
+			expected: "the number of executed scenarios dropped by 20.00% since baseline.json (build #41), from 100 to 80, more than the 10.00% allowed"},
+		{name: "Warn", results: Results{BaselineDelta: delta}, args: Args{MaxScenarioDropPercentage: 10, ScenarioDropAction: "warn"}},
+		{name: "Target Branch", results: Results{Baseline: comparison}, args: Args{MaxScenarioDropPercentage: 2},
+			expected: "the number of executed scenarios dropped by 5.00% since history build #11 of main, from 100 to 95, more than the 2.00% allowed"},
+		{name: "Previous Build Of The Branch", results: Results{ScenarioCount: 60}, previous: []HistoryEntry{{Branch: "main", BuildNumber: "6", TotalScenarios: 90}, {Branch: "main", BuildNumber: "7", TotalScenarios: 100}},
+			args: Args{MaxScenarioDropPercentage: 10}, expected: "the number of executed scenarios dropped by 40.00% since history build #7 of main, from 100 to 60, more than the 10.00% allowed"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.results.ScenarioDrift = scenarioDrift(tc.results, tc.previous)
+			err := validateScenarioDrift(tc.results, tc.args)
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expected || !errors.Is(err, ErrScenarioDrift) {
+				t.Errorf("Expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
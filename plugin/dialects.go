@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constants for Report Dialect
+const (
+	DialectAuto     = "AUTO"
+	DialectCucumber = "CUCUMBER"
+	DialectWDIO     = "WDIO"
+	DialectKarate   = "KARATE"
+	DialectBehat    = "BEHAT"
+	DialectGodog    = "GODOG"
+	DialectSerenity = "SERENITY"
+	DialectMessages = "MESSAGES"
+)
+
+// reportDialect decodes the report files of a dialect, detected from their content.
+type reportDialect struct {
+	name   string
+	detect func([]byte) bool
+	decode func([]byte) ([]Feature, error)
+}
+
+// reportDialects lists the dialects in the order they are detected. The Cucumber JSON
+// format comes last since other dialects extend it.
+var reportDialects = []reportDialect{
+	{DialectWDIO, isWDIOReport, decodeWDIOReport},
+	{DialectKarate, isKarateReport, decodeKarateReport},
+	{DialectBehat, isBehatReport, decodeBehatReport},
+	{DialectGodog, isGodogEvents, decodeGodogReport},
+	{DialectMessages, isCucumberMessages, decodeCucumberMessages},
+	{DialectSerenity, isSerenityOutcome, decodeSerenityReport},
+	{DialectCucumber, func(content []byte) bool { return firstByte(content) == '[' }, decodeCucumberReport},
+}
+
+// validateDialect checks the report dialect setting.
+func validateDialect(args Args) error {
+	dialect := strings.ToUpper(args.ReportDialect)
+	names := []string{DialectAuto}
+	for _, candidate := range reportDialects {
+		if dialect == candidate.name {
+			return nil
+		}
+		names = append(names, candidate.name)
+	}
+	if dialect == "" || dialect == DialectAuto {
+		return nil
+	}
+	return fmt.Errorf("invalid ReportDialect value. It must be one of %s", strings.Join(names, ", "))
+}
+
+// streamableDialect reports whether reports of the dialect can be decoded as a stream
+// of Cucumber features.
+func streamableDialect(dialect string) bool {
+	dialect = strings.ToUpper(dialect)
+	return dialect == "" || dialect == DialectAuto || dialect == DialectCucumber
+}
+
+// detectDialect returns the dialect of the content of a report file, defaulting to
+// the Cucumber JSON format.
+func detectDialect(content []byte) string {
+	for _, dialect := range reportDialects {
+		if dialect.detect(content) {
+			return dialect.name
+		}
+	}
+	return DialectCucumber
+}
+
+// decodeReport decodes the content of a report file in the dialect, detected when AUTO.
+func decodeReport(content []byte, dialect string) ([]Feature, error) {
+	dialect = strings.ToUpper(dialect)
+	if dialect == "" || dialect == DialectAuto {
+		dialect = detectDialect(content)
+	}
+	for _, candidate := range reportDialects {
+		if candidate.name == dialect {
+			return candidate.decode(content)
+		}
+	}
+	return decodeCucumberReport(content)
+}
+
+// decodeCucumberReport decodes a Cucumber JSON report.
+func decodeCucumberReport(content []byte) ([]Feature, error) {
+	var features []Feature
+	err := json.Unmarshal(content, &features)
+	return features, err
+}
+
+// slugPattern matches the characters replaced in the identifiers derived from names.
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// synthesizeIDs sets the identifiers omitted by some reporters, such as Behat, derived
+// from the names like Cucumber does and from the line of the scenarios to tell the
+// examples of an outline apart, so that they are the same in every build.
+func synthesizeIDs(features []Feature) {
+	for i := range features {
+		feature := &features[i]
+		if feature.ID == "" {
+			feature.ID = slug(feature.Name)
+			if feature.ID == "" {
+				feature.ID = feature.URI
+			}
+		}
+		for j := range feature.Elements {
+			element := &feature.Elements[j]
+			if element.ID == "" {
+				element.ID = fmt.Sprintf("%s;%s;%d", feature.ID, slug(element.Name), element.Line)
+			}
+		}
+	}
+}
+
+// slug converts a name to a lowercase identifier with dashes.
+func slug(name string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// firstByte returns the first non-whitespace byte of the content.
+func firstByte(content []byte) byte {
+	trimmed := bytes.TrimLeft(content, " \t\r\n\ufeff")
+	if len(trimmed) == 0 {
+		return 0
+	}
+	return trimmed[0]
+}
+
+// firstArrayElement returns the first element of the content when it is a JSON array,
+// without decoding the other elements.
+func firstArrayElement(content []byte) (json.RawMessage, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') || !decoder.More() {
+		return nil, false
+	}
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return nil, false
+	}
+	return first, true
+}
+
+// hasJSONKey reports whether the content is a JSON object with the key.
+func hasJSONKey(content []byte, key string) bool {
+	if firstByte(content) != '{' {
+		return false
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(content, &object) != nil {
+		return false
+	}
+	_, ok := object[key]
+	return ok
+}
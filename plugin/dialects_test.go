@@ -0,0 +1,270 @@
+package plugin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestDetectDialect tests detecting the dialect of the report files
+func TestDetectDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Cucumber", `[{"name": "Login"}]`, DialectCucumber},
+		{"Cucumber with byte order mark", "\ufeff\n  []", DialectCucumber},
+		{"WebdriverIO wrapper", `{"metadata": {}, "features": []}`, DialectWDIO},
+		{"Karate feature", `{"name": "Users", "scenarioResults": []}`, DialectKarate},
+		{"Karate features", `[{"name": "Users", "scenarioResults": []}]`, DialectKarate},
+		{"Godog events", "{\"event\": \"TestRunStarted\"}\n{\"event\": \"TestRunFinished\"}\n", DialectGodog},
+		{"Serenity outcome", `{"title": "Withdraw cash", "userStory": {}, "testSteps": []}`, DialectSerenity},
+		{"Behat suites", `{"suites": [{"name": "default", "features": []}]}`, DialectBehat},
+		{"Unknown object", `{"name": "Login"}`, DialectCucumber},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if dialect := detectDialect([]byte(tc.content)); dialect != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, dialect)
+			}
+		})
+	}
+}
+
+// TestProcessWDIOReport tests parsing a WebdriverIO report and its environment metadata
+func TestProcessWDIOReport(t *testing.T) {
+	// Large wrapped reports fall back from streaming to decoding the whole file
+	previous := streamingThreshold
+	defer func() { streamingThreshold = previous }()
+
+	for _, threshold := range []int64{previous, 0} {
+		streamingThreshold = threshold
+		testProcessWDIOReport(t)
+	}
+}
+
+func testProcessWDIOReport(t *testing.T) {
+	for _, dialect := range []string{"", DialectWDIO} {
+		results, err := processFile("../testdata/dialects/wdio.json", false, Args{ReportDialect: dialect})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if results.ScenarioCount != 3 || results.TotalFailedScenarios != 1 {
+			t.Errorf("Expected 3 scenarios with 1 failure, got %d with %d failures", results.ScenarioCount, results.TotalFailedScenarios)
+		}
+
+		expected := []EnvironmentResult{
+			{Environment: "chrome 120.0 / Windows 11 / Desktop", Scenarios: 2, FailedScenarios: 1},
+			{Environment: "firefox 121.0 / Linux", Scenarios: 1},
+		}
+		if diff := cmp.Diff(expected, environmentBreakdown(results.Scenarios)); diff != "" {
+			t.Errorf("Environments mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+// TestValidateDialect tests the validation of the report dialect setting
+func TestValidateDialect(t *testing.T) {
+	for _, dialect := range []string{"", "auto", DialectCucumber, "wdio"} {
+		if err := validateDialect(Args{ReportDialect: dialect}); err != nil {
+			t.Errorf("Unexpected error for %q: %v", dialect, err)
+		}
+	}
+	if err := validateDialect(Args{ReportDialect: "junit"}); err == nil {
+		t.Errorf("Expected an error for an unknown dialect")
+	}
+}
+
+// TestProcessKarateReport tests parsing a Karate JSON report with durations in milliseconds
+func TestProcessKarateReport(t *testing.T) {
+	results, err := processFile("../testdata/dialects/karate.json", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []ScenarioResult{
+		{ID: "api/users.feature;0--1", Feature: "Users API", Scenario: "Create a user", Tags: []string{"@smoke"}, Status: "passed", DurationMS: 845.25, Steps: 2},
+		{ID: "api/users.feature;1--1", Feature: "Users API", Scenario: "Delete a user", Tags: []string{"@regression"}, Status: "failed", DurationMS: 400.5, Steps: 2},
+	}
+	if diff := cmp.Diff(expected, results.Scenarios); diff != "" {
+		t.Errorf("Scenarios mismatch (-want +got):\n%s", diff)
+	}
+	if len(results.FailedSteps) != 1 || results.FailedSteps[0].ErrorMessage != "status code was: 500, expected: 204" || results.FailedSteps[0].URI != "api/users.feature" || results.FailedSteps[0].StepLine != 13 {
+		t.Errorf("Expected the failed step with the scenario error, got %+v", results.FailedSteps)
+	}
+}
+
+// TestProcessBehatReport tests parsing a Behat report nesting suites and omitting IDs
+func TestProcessBehatReport(t *testing.T) {
+	results, err := processFile("../testdata/dialects/behat.json", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []ScenarioResult{
+		{ID: "shopping-cart;add-a-product;5", Feature: "Shopping cart", Scenario: "Add a product", Status: "passed", DurationMS: 2, Steps: 2},
+		{ID: "product-catalog;remove-a-product;12", Feature: "Product catalog", Scenario: "Remove a product", Status: "passed", DurationMS: 1, Steps: 1},
+		{ID: "product-catalog;remove-a-product;13", Feature: "Product catalog", Scenario: "Remove a product", Status: "failed", DurationMS: 2, Steps: 1},
+	}
+	if diff := cmp.Diff(expected, results.Scenarios); diff != "" {
+		t.Errorf("Scenarios mismatch (-want +got):\n%s", diff)
+	}
+
+	// Features without IDs are not merged together
+	merged, err := processFile("../testdata/dialects/behat.json", false, Args{MergeFeaturesById: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if merged.FeatureCount != 2 {
+		t.Errorf("Expected 2 features, got %d", merged.FeatureCount)
+	}
+}
+
+// TestSynthesizeIDs tests deriving the identifiers omitted from the reports
+func TestSynthesizeIDs(t *testing.T) {
+	features := []Feature{
+		{ID: "kept", Name: "Kept", Elements: []Element{{ID: "kept;scenario", Name: "Scenario"}}},
+		{Name: "Log in & out!", Elements: []Element{{Name: "Wrong password", Line: 9}}},
+		{URI: "features/untitled.feature", Elements: []Element{{Line: 3}}},
+	}
+	synthesizeIDs(features)
+
+	expected := []string{"kept", "kept;scenario", "log-in-out", "log-in-out;wrong-password;9", "features/untitled.feature", "features/untitled.feature;;3"}
+	var ids []string
+	for _, feature := range features {
+		ids = append(ids, feature.ID)
+		for _, element := range feature.Elements {
+			ids = append(ids, element.ID)
+		}
+	}
+	if diff := cmp.Diff(expected, ids); diff != "" {
+		t.Errorf("IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestProcessGodogReports tests parsing the godog events and cucumber formatter outputs
+func TestProcessGodogReports(t *testing.T) {
+	events, err := processFile("../testdata/dialects/godog-events.json", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []ScenarioResult{
+		{ID: "eat-godogs;eat-5-out-of-12;11", Feature: "eat godogs", Scenario: "Eat 5 out of 12", StartTimestamp: "2024-05-14T09:46:40.001Z", Tags: []string{"@inventory", "@smoke"}, Status: "passed", DurationMS: 10, Steps: 4},
+		{ID: "eat-godogs;eat-too-many;23", Feature: "eat godogs", Scenario: "Eat too many", StartTimestamp: "2024-05-14T09:46:40.011Z", Tags: []string{"@inventory"}, Status: "failed", DurationMS: 8, Steps: 4},
+	}
+	if diff := cmp.Diff(expected, events.Scenarios); diff != "" {
+		t.Errorf("Scenarios mismatch (-want +got):\n%s", diff)
+	}
+	if events.StepCount != 8 || events.SkippedTests != 1 {
+		t.Errorf("Expected 8 steps with 1 skipped, got %d with %d skipped", events.StepCount, events.SkippedTests)
+	}
+	expectedFailure := FailedStepDetails{
+		Feature:      "eat godogs",
+		Scenario:     "Eat too many",
+		Step:         "I eat <eat>",
+		StepPattern:  "I eat <eat>",
+		URI:          "features/godogs.feature",
+		ScenarioLine: 23,
+		StepLine:     18,
+		ErrorMessage: "you cannot eat 6 godogs, there are 5 available",
+		DurationMS:   5,
+		Fingerprint:  failureFingerprint("eat godogs", "Eat too many", "you cannot eat 6 godogs, there are 5 available"),
+	}
+	if len(events.FailedSteps) != 1 {
+		t.Fatalf("Expected 1 failed step, got %d", len(events.FailedSteps))
+	}
+	if diff := cmp.Diff(expectedFailure, events.FailedSteps[0]); diff != "" {
+		t.Errorf("Failed step mismatch (-want +got):\n%s", diff)
+	}
+
+	// Steps without a result are counted as skipped
+	cucumber, err := processFile("../testdata/dialects/godog-cucumber.json", false, Args{ReportDialect: DialectGodog})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cucumber.ScenarioCount != 2 || cucumber.StepCount != 3 || cucumber.SkippedTests != 1 || cucumber.TotalFailedScenarios != 1 {
+		t.Errorf("Expected 2 scenarios, 3 steps, 1 skipped and 1 failed scenario, got %+v", cucumber)
+	}
+}
+
+// TestProcessSerenityReports tests parsing Serenity BDD test outcomes with durations in milliseconds
+func TestProcessSerenityReports(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []ScenarioResult
+		failure  string
+	}{
+		{
+			file: "../testdata/dialects/serenity.json",
+			expected: []ScenarioResult{
+				{ID: "withdraw-cash;withdraw-cash-with-insufficient-funds;0", Feature: "Withdraw cash", Scenario: "Withdraw cash with insufficient funds", Tags: []string{"@regression"}, Status: "failed", DurationMS: 1250, Steps: 3},
+			},
+			failure: "Expected the withdrawal to be refused",
+		},
+		{
+			file: "../testdata/dialects/serenity-examples.json",
+			expected: []ScenarioResult{
+				{ID: "deposit-cash;deposit-cash;1", Feature: "Deposit cash", Scenario: "Deposit cash", Tags: []string{"@smoke"}, Status: "passed", DurationMS: 100, Steps: 1},
+				{ID: "deposit-cash;deposit-cash;2", Feature: "Deposit cash", Scenario: "Deposit cash", Tags: []string{"@smoke"}, Status: "failed", DurationMS: 200, Steps: 1},
+			},
+			failure: "Negative amount",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			results, err := processFile(tc.file, false, Args{ReportDialect: DialectAuto})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, results.Scenarios); diff != "" {
+				t.Errorf("Scenarios mismatch (-want +got):\n%s", diff)
+			}
+			if len(results.FailedSteps) != 1 || results.FailedSteps[0].ErrorMessage != tc.failure {
+				t.Errorf("Expected the failed step %q, got %+v", tc.failure, results.FailedSteps)
+			}
+		})
+	}
+}
+
+// TestProcessCucumberMessages tests parsing a Cucumber Messages NDJSON stream with hooks, outlines and retried attempts
+func TestProcessCucumberMessages(t *testing.T) {
+	results, err := processFile("../testdata/dialects/messages.ndjson", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []ScenarioResult{
+		{ID: "checkout;pay-by-card;5", Feature: "Checkout", Scenario: "Pay by card", StartTimestamp: "2024-05-14T09:46:40.001Z", Tags: []string{"@checkout", "@smoke"}, Status: "passed", DurationMS: 1009, Steps: 2},
+		{ID: "checkout;pay-with-voucher;13", Feature: "Checkout", Scenario: "Pay with voucher", StartTimestamp: "2024-05-14T09:46:41.02Z", Tags: []string{"@checkout"}, Status: "failed", DurationMS: 3, Steps: 1},
+		{ID: "checkout;pay-with-voucher;13", Feature: "Checkout", Scenario: "Pay with voucher", StartTimestamp: "2024-05-14T09:46:41.03Z", Tags: []string{"@checkout"}, Status: "undefined", Steps: 1, UndefinedSteps: 1},
+	}
+	if diff := cmp.Diff(expected, results.Scenarios); diff != "" {
+		t.Errorf("Scenarios mismatch (-want +got):\n%s", diff)
+	}
+	countRetries(&results)
+	if results.StepCount != 4 || results.UndefinedTests != 1 || results.RetriedScenarios != 1 {
+		t.Errorf("Expected 4 steps, 1 undefined and 1 retried scenario, got %+v", results)
+	}
+	if len(results.FailedSteps) != 1 || results.FailedSteps[0].ErrorMessage != "AssertionError: voucher expired\n    at pay.js:12" || results.FailedSteps[0].StepLine != 10 {
+		t.Errorf("Expected the failed step at line 10, got %+v", results.FailedSteps)
+	}
+
+	content, err := os.ReadFile("../testdata/dialects/messages.ndjson")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	features, err := decodeReport(content, DialectMessages)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	step := features[0].Elements[0].Steps[0]
+	if step.Keyword != "Given " || len(step.Arguments) != 1 || len(step.Arguments[0].Rows) != 2 || len(step.Embeddings) != 1 || step.Embeddings[0].Data != "Y2FydCByZWFkeQ==" {
+		t.Errorf("Unexpected first step: %+v", step)
+	}
+	if len(features[0].Elements[0].Before) != 1 || features[0].Elements[0].Before[0].Result.Status != "passed" {
+		t.Errorf("Expected a passed before hook, got %+v", features[0].Elements[0].Before)
+	}
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scenarioDrift returns the change of the number of executed scenarios since the baseline
+// file, or since the target branch when there is no baseline file, or since the previous
+// build of the branch recorded in the history otherwise, such as on branch builds. It
+// returns nil when there is no baseline or it executed no scenario.
+func scenarioDrift(results Results, previous []HistoryEntry) *ScenarioDrift {
+	var drift *ScenarioDrift
+	switch {
+	case results.BaselineDelta != nil:
+		delta := results.BaselineDelta
+		drift = &ScenarioDrift{Source: delta.Source, Scenarios: delta.ScenarioCount, BaselineScenarios: delta.BaselineScenarios}
+	case results.Baseline != nil:
+		comparison := results.Baseline
+		drift = &ScenarioDrift{Source: comparison.Source + " of " + comparison.TargetBranch, Scenarios: comparison.ScenarioCount, BaselineScenarios: comparison.BaselineScenarios}
+	case len(previous) > 0:
+		last := previous[len(previous)-1]
+		drift = &ScenarioDrift{Source: "history build #" + last.BuildNumber + " of " + last.Branch, Scenarios: results.ScenarioCount, BaselineScenarios: last.TotalScenarios}
+	default:
+		return nil
+	}
+	if drift.BaselineScenarios == 0 {
+		return nil
+	}
+	drift.Change = float64(drift.Scenarios-drift.BaselineScenarios) / float64(drift.BaselineScenarios) * 100
+	return drift
+}
+
+// logScenarioDrift logs the scenario count compared with the baseline.
+func logScenarioDrift(drift *ScenarioDrift) {
+	if drift == nil {
+		return
+	}
+	logger.Infof("🔢 Scenario Count: %d (%d in %s, %s%%)\n", drift.Scenarios, drift.BaselineScenarios, drift.Source, formatSignedNumber(drift.Change))
+}
+
+// validateScenarioDrift returns an error if the number of executed scenarios dropped by more
+// than the configured percentage since the baseline, since a tag filter or config change can
+// silently shrink the suite, and only logs it when configured to warn.
+func validateScenarioDrift(results Results, args Args) error {
+	drift := results.ScenarioDrift
+	if args.MaxScenarioDropPercentage <= 0 {
+		return nil
+	}
+	if drift == nil {
+		logger.Warnf("No baseline to compare the number of executed scenarios with, set PLUGIN_BASELINE_FILE or PLUGIN_HISTORY_FILE to gate on its drop")
+		return nil
+	}
+	if -drift.Change <= args.MaxScenarioDropPercentage {
+		return nil
+	}
+
+	err := wrapError(ErrScenarioDrift, fmt.Errorf("the number of executed scenarios dropped by %s%% since %s, from %d to %d, more than the %s%% allowed",
+		formatNumber(-drift.Change), drift.Source, drift.BaselineScenarios, drift.Scenarios, formatNumber(args.MaxScenarioDropPercentage)))
+	if strings.EqualFold(args.ScenarioDropAction, ActionWarn) {
+		logger.Warnf("%s", err)
+		return nil
+	}
+	return err
+}
+
+// writeScenarioDriftStats writes the scenario count change since the baseline to the output file.
+func writeScenarioDriftStats(drift *ScenarioDrift, log Logger) {
+	if drift == nil {
+		return
+	}
+	statsMap := map[string]string{
+		"BASELINE_SCENARIOS":    strconv.Itoa(drift.BaselineScenarios),
+		"SCENARIO_COUNT_CHANGE": formatNumber(drift.Change),
+	}
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// currentBranch returns the configured branch, falling back to the Drone environment.
+func currentBranch(args Args) string {
+	if args.Branch != "" {
+		return args.Branch
+	}
+	if branch := os.Getenv("DRONE_SOURCE_BRANCH"); branch != "" {
+		return branch
+	}
+	return os.Getenv("DRONE_COMMIT_BRANCH")
+}
+
+// currentBuildNumber returns the build number from the Drone environment.
+func currentBuildNumber() string {
+	if build := os.Getenv("DRONE_BUILD_NUMBER"); build != "" {
+		return build
+	}
+	return strconv.FormatInt(time.Now().Unix(), 10)
+}
+
+// currentRepo returns the repository name from the Drone environment.
+func currentRepo() string {
+	return os.Getenv("DRONE_REPO")
+}
+
+// currentBuildLink returns the link to the build from the Drone environment.
+func currentBuildLink() string {
+	return os.Getenv("DRONE_BUILD_LINK")
+}
+
+// currentPullRequest returns the pull request number from the Drone environment.
+func currentPullRequest() string {
+	return os.Getenv("DRONE_PULL_REQUEST")
+}
+
+// currentStepName returns the name of the pipeline step from the Drone environment.
+func currentStepName() string {
+	return os.Getenv("DRONE_STEP_NAME")
+}
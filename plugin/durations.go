@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// defaultDurationRegressionFactor is the slowdown factor used when none is configured.
+const defaultDurationRegressionFactor = 2.0
+
+// detectDurationRegressions flags scenarios whose duration exceeds the median duration
+// of the previous builds by the configured factor.
+func detectDurationRegressions(results *Results, previous []HistoryEntry, args Args) {
+	factor := args.DurationRegressionFactor
+	if factor <= 0 {
+		factor = defaultDurationRegressionFactor
+	}
+
+	durations := make(map[string][]float64)
+	for _, entry := range recentEntries(previous, args.TrendBuilds) {
+		for _, scenario := range entry.Scenarios {
+			key := failureKey(scenario.Feature, scenario.Scenario)
+			durations[key] = append(durations[key], scenario.DurationMS)
+		}
+	}
+
+	results.DurationRegressions = nil
+	for _, scenario := range results.Scenarios {
+		samples := durations[failureKey(scenario.Feature, scenario.Scenario)]
+		if len(samples) == 0 {
+			continue
+		}
+
+		medianMS := median(samples)
+		if medianMS <= 0 || scenario.DurationMS <= medianMS*factor {
+			continue
+		}
+
+		results.DurationRegressions = append(results.DurationRegressions, DurationRegression{
+			Feature:    scenario.Feature,
+			Scenario:   scenario.Scenario,
+			DurationMS: scenario.DurationMS,
+			MedianMS:   medianMS,
+			Factor:     scenario.DurationMS / medianMS,
+		})
+	}
+
+	sort.SliceStable(results.DurationRegressions, func(i, j int) bool {
+		return results.DurationRegressions[i].Factor > results.DurationRegressions[j].Factor
+	})
+}
+
+// median returns the median of the given values.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// logDurationRegressions logs the scenarios that got significantly slower.
+func logDurationRegressions(regressions []DurationRegression) {
+	if len(regressions) == 0 {
+		return
+	}
+
+	logger.Infof("Duration Regressions:\n")
+	logger.Infof("-----------------------------------------------\n")
+	for i, regression := range regressions {
+		logger.Infof("%d. Feature: %s\n", i+1, regression.Feature)
+		logger.Infof("   Scenario: %s\n", regression.Scenario)
+		logger.Infof("   Duration: %s ms (median %s ms, %.2fx slower) 🐢\n", formatNumber(regression.DurationMS), formatNumber(regression.MedianMS), regression.Factor)
+		logger.Infof("-----------------------------------------------\n")
+	}
+}
+
+// validateDurationRegressions returns an error if any scenario got significantly slower.
+func validateDurationRegressions(results Results, args Args) error {
+	if len(results.DurationRegressions) == 0 {
+		return nil
+	}
+
+	slowest := results.DurationRegressions[0]
+	if !args.FailOnDurationRegression {
+		logger.Warnf("%d scenarios got significantly slower. Slowest regression: %s (%.2fx)", len(results.DurationRegressions), slowest.Scenario, slowest.Factor)
+		return nil
+	}
+	return fmt.Errorf("%d scenarios got significantly slower than in previous builds. Slowest regression: %s (%.2fx)", len(results.DurationRegressions), slowest.Scenario, slowest.Factor)
+}
+
+// writeDurationRegressionStats writes the duration regression count to the output file.
+func writeDurationRegressionStats(results Results, log Logger) {
+	if err := WriteEnvToFile("DURATION_REGRESSIONS", strconv.Itoa(len(results.DurationRegressions)), log); err != nil {
+		log.Errorf("Error writing %s: %s", "DURATION_REGRESSIONS", err)
+	}
+}
@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"testing"
+)
+
+// TestDetectDurationRegressions tests flagging scenarios slower than their historical median
+func TestDetectDurationRegressions(t *testing.T) {
+	previous := []HistoryEntry{
+		{Scenarios: []HistoryScenario{{Feature: "F", Scenario: "Slow", DurationMS: 100}, {Feature: "F", Scenario: "Fast", DurationMS: 100}}},
+		{Scenarios: []HistoryScenario{{Feature: "F", Scenario: "Slow", DurationMS: 120}, {Feature: "F", Scenario: "Fast", DurationMS: 100}}},
+		{Scenarios: []HistoryScenario{{Feature: "F", Scenario: "Slow", DurationMS: 900}, {Feature: "F", Scenario: "Fast", DurationMS: 100}}},
+	}
+
+	results := Results{
+		Scenarios: []ScenarioResult{
+			{Feature: "F", Scenario: "Slow", DurationMS: 300},
+			{Feature: "F", Scenario: "Fast", DurationMS: 150},
+			{Feature: "F", Scenario: "Unknown", DurationMS: 5000},
+		},
+	}
+
+	detectDurationRegressions(&results, previous, Args{DurationRegressionFactor: 2})
+
+	if len(results.DurationRegressions) != 1 {
+		t.Fatalf("Expected 1 regression, got %d", len(results.DurationRegressions))
+	}
+	regression := results.DurationRegressions[0]
+	if regression.Scenario != "Slow" || regression.MedianMS != 120 || regression.Factor != 2.5 {
+		t.Errorf("Unexpected regression: %+v", regression)
+	}
+
+	if err := validateDurationRegressions(results, Args{}); err != nil {
+		t.Errorf("Expected warning only, got error: %v", err)
+	}
+	if err := validateDurationRegressions(results, Args{FailOnDurationRegression: true}); err == nil {
+		t.Errorf("Expected error when failing on duration regressions")
+	}
+}
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the plugin, matched with errors.Is.
+var (
+	ErrInvalidConfig       = errors.New("invalid configuration")
+	ErrNoReports           = errors.New("no report files")
+	ErrReadReport          = errors.New("report file not readable")
+	ErrParse               = errors.New("report file not parsable")
+	ErrTimeout             = errors.New("report processing timed out")
+	ErrPanic               = errors.New("report processing panicked")
+	ErrMissingReports      = errors.New("fewer report files than expected")
+	ErrMissingScenarios    = errors.New("scenarios of the manifest missing")
+	ErrInconsistentResults = errors.New("conflicting scenario statuses across shards")
+	ErrScenarioDrift       = errors.New("scenario count dropped since the baseline")
+	ErrGateViolation       = errors.New("quality gate violation")
+)
+
+// errorCodes maps the errors to the machine-readable codes written to the output variables.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrInvalidConfig, "INVALID_CONFIG"},
+	{ErrNoReports, "NO_REPORTS"},
+	{ErrReadReport, "READ_REPORT"},
+	{ErrParse, "PARSE"},
+	{ErrTimeout, "TIMEOUT"},
+	{ErrPanic, "PANIC"},
+	{ErrMissingReports, "MISSING_REPORTS"},
+	{ErrMissingScenarios, "MISSING_SCENARIOS"},
+	{ErrInconsistentResults, "INCONSISTENT_RESULTS"},
+	{ErrScenarioDrift, "SCENARIO_DRIFT"},
+	{ErrGateViolation, "GATE_VIOLATION"},
+}
+
+// codedError attaches one of the plugin errors to an error without changing its message.
+type codedError struct {
+	kind error
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// panicError is a panic recovered while processing a report file.
+type panicError struct {
+	value interface{}
+	stack string
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func (e *panicError) Unwrap() error {
+	return ErrPanic
+}
+
+// wrapError marks the error as being of the kind of one of the plugin errors.
+func wrapError(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{kind: kind, err: err}
+}
+
+// ErrorCode returns the machine-readable code of an error returned by the plugin,
+// UNKNOWN for other errors, or an empty string for a nil error.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return "UNKNOWN"
+}
@@ -0,0 +1,47 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestErrorCode tests the codes of the errors returned by the plugin
+func TestErrorCode(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, parseErr := processFile(invalid, false, Args{})
+	_, readErr := processFile(filepath.Join(t.TempDir(), "missing.json"), false, Args{})
+
+	tests := []struct {
+		name     string
+		err      error
+		kind     error
+		expected string
+	}{
+		{"No error", nil, nil, ""},
+		{"Invalid configuration", ValidateInputs(Args{FailedStepsNumber: -1}), ErrInvalidConfig, "INVALID_CONFIG"},
+		{"No reports", Exec(context.Background(), Args{JSONReportDirectory: "../testdata", FileIncludePattern: "*.invalid"}), ErrNoReports, "NO_REPORTS"},
+		{"Parse error", parseErr, ErrParse, "PARSE"},
+		{"Read error", readErr, ErrReadReport, "READ_REPORT"},
+		{"Wrapped file error", &fileError{file: invalid, err: parseErr}, ErrParse, "PARSE"},
+		{"Gate violation", fmt.Errorf("step: %w", wrapError(ErrGateViolation, errors.New("too many failures"))), ErrGateViolation, "GATE_VIOLATION"},
+		{"Other error", errors.New("boom"), nil, "UNKNOWN"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := ErrorCode(tc.err); code != tc.expected {
+				t.Errorf("Expected code %q, got %q (error: %v)", tc.expected, code, tc.err)
+			}
+			if tc.kind != nil && !errors.Is(tc.err, tc.kind) {
+				t.Errorf("Expected %v to match %v", tc.err, tc.kind)
+			}
+		})
+	}
+}
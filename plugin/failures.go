@@ -0,0 +1,248 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Constants for Failure Sort
+const (
+	FailureSortArrival  = "ARRIVAL"
+	FailureSortFeature  = "FEATURE"
+	FailureSortDuration = "DURATION"
+	FailureSortError    = "ERROR"
+)
+
+// validateFailureSort checks the failure sort setting.
+func validateFailureSort(args Args) error {
+	switch strings.ToUpper(args.FailureSort) {
+	case "", FailureSortArrival, FailureSortFeature, FailureSortDuration, FailureSortError:
+		return nil
+	}
+	return fmt.Errorf("invalid FailureSort value. It must be '%s', '%s', '%s' or '%s'", FailureSortArrival, FailureSortFeature, FailureSortDuration, FailureSortError)
+}
+
+// sortFailures orders the failed steps by feature file and line, by duration with the
+// slowest first, or by error signature with the most frequent errors first. The
+// failures keep the order of the reports when sorted by arrival.
+func sortFailures(steps []FailedStepDetails, method string) {
+	switch strings.ToUpper(method) {
+	case FailureSortFeature:
+		sort.SliceStable(steps, func(i, j int) bool {
+			if steps[i].Feature != steps[j].Feature {
+				return strings.ToLower(steps[i].Feature) < strings.ToLower(steps[j].Feature)
+			}
+			if steps[i].URI != steps[j].URI {
+				return steps[i].URI < steps[j].URI
+			}
+			return steps[i].StepLine < steps[j].StepLine
+		})
+	case FailureSortDuration:
+		sort.SliceStable(steps, func(i, j int) bool {
+			return steps[i].DurationMS > steps[j].DurationMS
+		})
+	case FailureSortError:
+		counts := make(map[string]int)
+		for _, step := range steps {
+			counts[normalizeErrorMessage(step.ErrorMessage)]++
+		}
+		sort.SliceStable(steps, func(i, j int) bool {
+			first, second := normalizeErrorMessage(steps[i].ErrorMessage), normalizeErrorMessage(steps[j].ErrorMessage)
+			if counts[first] != counts[second] {
+				return counts[first] > counts[second]
+			}
+			return first < second
+		})
+	}
+}
+
+// failureKey identifies a failed scenario across builds.
+func failureKey(feature, scenario string) string {
+	return feature + "\x00" + scenario
+}
+
+// Patterns replaced when normalizing error messages for fingerprinting, in order.
+var errorNormalizers = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<timestamp>"},
+	{regexp.MustCompile(`0x[0-9a-fA-F]+`), "<hex>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// failureLocation returns the feature file and line of the failed step, such as
+// features/login.feature:12, or an empty string when the report has no URI.
+func failureLocation(step FailedStepDetails) string {
+	if step.URI == "" {
+		return ""
+	}
+	line := step.StepLine
+	if line == 0 {
+		line = step.ScenarioLine
+	}
+	if line == 0 {
+		return step.URI
+	}
+	return step.URI + ":" + strconv.Itoa(line)
+}
+
+// normalizeErrorMessage removes volatile parts such as ids, timestamps and numbers
+// from an error message.
+func normalizeErrorMessage(message string) string {
+	normalized := strings.TrimSpace(message)
+	for _, normalizer := range errorNormalizers {
+		normalized = normalizer.pattern.ReplaceAllString(normalized, normalizer.replacement)
+	}
+	return strings.ToLower(normalized)
+}
+
+// failureFingerprint computes a stable fingerprint from the feature, scenario and
+// normalized error message of a failure.
+func failureFingerprint(feature, scenario, errorMessage string) string {
+	hash := sha256.Sum256([]byte(feature + "\x00" + scenario + "\x00" + normalizeErrorMessage(errorMessage)))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// historyFailures returns the distinct failures of the failed step details.
+func historyFailures(steps []FailedStepDetails) []HistoryFailure {
+	failures := make([]HistoryFailure, 0, len(steps))
+	for _, step := range steps {
+		failures = append(failures, HistoryFailure{
+			Feature:     step.Feature,
+			Scenario:    step.Scenario,
+			Fingerprint: step.Fingerprint,
+		})
+	}
+	return uniqueFailures(failures)
+}
+
+// uniqueFailures returns the failures without duplicates, in their order.
+func uniqueFailures(failures []HistoryFailure) []HistoryFailure {
+	seen := make(map[string]bool)
+	var unique []HistoryFailure
+	for _, failure := range failures {
+		key := failure.Fingerprint + "\x00" + failureKey(failure.Feature, failure.Scenario)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, failure)
+	}
+	return unique
+}
+
+// failureRecords returns the distinct failures of the results, including the failures
+// whose details were written to the overflow file or dropped to stay within the memory
+// budget, so that the history, the classification of the failures and the outcome
+// comparison see every failure.
+func failureRecords(results Results) []HistoryFailure {
+	if results.AllFailures == nil {
+		return historyFailures(results.FailedSteps)
+	}
+	return uniqueFailures(results.AllFailures)
+}
+
+// failureFingerprints returns the distinct fingerprints of the failures of the results.
+func failureFingerprints(results Results) []string {
+	var fingerprints []string
+	seen := make(map[string]bool)
+	for _, failure := range failureRecords(results) {
+		if !seen[failure.Fingerprint] {
+			seen[failure.Fingerprint] = true
+			fingerprints = append(fingerprints, failure.Fingerprint)
+		}
+	}
+	return fingerprints
+}
+
+// classifyFailures marks failures that did not occur in the last n previous builds as new
+// and orders the failed step details so that new failures come first.
+func classifyFailures(results *Results, previous []HistoryEntry, n int) {
+	previous = recentEntries(previous, n)
+
+	// Failures recorded before fingerprinting was introduced are matched by scenario.
+	known := make(map[string]bool)
+	for _, entry := range previous {
+		for _, failure := range entry.Failures {
+			if failure.Fingerprint != "" {
+				known[failure.Fingerprint] = true
+			} else {
+				known[failureKey(failure.Feature, failure.Scenario)] = true
+			}
+		}
+	}
+
+	// The scenarios are counted from every failure, the details may be trimmed
+	results.NewFailures = 0
+	results.RecurringFailures = 0
+	counted := make(map[string]bool)
+	for _, failure := range failureRecords(*results) {
+		key := failureKey(failure.Feature, failure.Scenario)
+		if counted[key] {
+			continue
+		}
+		counted[key] = true
+		if !known[failure.Fingerprint] && !known[key] {
+			results.NewFailures++
+		} else {
+			results.RecurringFailures++
+		}
+	}
+
+	for i := range results.FailedSteps {
+		step := &results.FailedSteps[i]
+		step.New = !known[step.Fingerprint] && !known[failureKey(step.Feature, step.Scenario)]
+	}
+
+	sort.SliceStable(results.FailedSteps, func(i, j int) bool {
+		return results.FailedSteps[i].New && !results.FailedSteps[j].New
+	})
+}
+
+// failureLabel returns the log label describing whether a failure is new or recurring.
+func failureLabel(step FailedStepDetails) string {
+	if step.New {
+		return " 🆕 NEW"
+	}
+	return " 🔁 RECURRING"
+}
+
+// writeFailureClassificationStats writes the new and recurring failure counts to the output file.
+func writeFailureClassificationStats(results Results, log Logger) {
+	statsMap := map[string]string{
+		"NEW_FAILED_SCENARIOS":       strconv.Itoa(results.NewFailures),
+		"RECURRING_FAILED_SCENARIOS": strconv.Itoa(results.RecurringFailures),
+	}
+
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
+
+// outcomeChanged reports whether the failures of the build differ from the failures of
+// the last previous build, so that a build still green or failing the same way as the
+// previous one is not notified again. The first build is always a change.
+func outcomeChanged(previous []HistoryEntry, results Results) bool {
+	if len(previous) == 0 {
+		return true
+	}
+	failureKeys := func(failures []HistoryFailure) map[string]bool {
+		keys := make(map[string]bool)
+		for _, failure := range failures {
+			keys[firstNonEmpty(failure.Fingerprint, scenarioIdentifier(failure.Feature, failure.Scenario))] = true
+		}
+		return keys
+	}
+	return !maps.Equal(failureKeys(previous[len(previous)-1].Failures), failureKeys(failureRecords(results)))
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestClassifyFailures tests classification of new and recurring failures
+func TestClassifyFailures(t *testing.T) {
+	previous := []HistoryEntry{
+		{BuildNumber: "1", Failures: []HistoryFailure{{Feature: "Old", Scenario: "Expired"}}},
+		{BuildNumber: "2", Failures: []HistoryFailure{{Feature: "Payment", Scenario: "Failed payment"}}},
+	}
+
+	results := Results{
+		FailedSteps: []FailedStepDetails{
+			{Feature: "Payment", Scenario: "Failed payment", Step: "step 1"},
+			{Feature: "Search", Scenario: "Search Wikipedia", Step: "step 2"},
+			{Feature: "Old", Scenario: "Expired", Step: "step 3"},
+			{Feature: "Search", Scenario: "Search Wikipedia", Step: "step 4"},
+		},
+	}
+
+	classifyFailures(&results, previous, 1)
+
+	if results.NewFailures != 2 {
+		t.Errorf("Expected 2 new failures, got %d", results.NewFailures)
+	}
+	if results.RecurringFailures != 1 {
+		t.Errorf("Expected 1 recurring failure, got %d", results.RecurringFailures)
+	}
+
+	expectedOrder := []string{"step 2", "step 3", "step 4", "step 1"}
+	for i, step := range results.FailedSteps {
+		if step.Step != expectedOrder[i] {
+			t.Errorf("Expected %s at position %d, got %s", expectedOrder[i], i, step.Step)
+		}
+	}
+}
+
+// TestClassifyTrimmedFailures tests that the failures whose details were trimmed are
+// still classified, recorded in the history and compared with the previous build
+func TestClassifyTrimmedFailures(t *testing.T) {
+	previous := []HistoryEntry{{BuildNumber: "1", Failures: []HistoryFailure{{Feature: "Payment", Scenario: "Failed payment"}}}}
+
+	results := Results{
+		FailedSteps: []FailedStepDetails{{Feature: "Payment", Scenario: "Failed payment", Step: "step 1"}},
+		AllFailures: []HistoryFailure{
+			{Feature: "Payment", Scenario: "Failed payment"},
+			{Feature: "Search", Scenario: "Search Wikipedia", Fingerprint: "abc"},
+			{Feature: "Search", Scenario: "Search Wikipedia", Fingerprint: "abc"},
+			{Feature: "Old", Scenario: "Expired", Fingerprint: "def"},
+		},
+		OverflowedFailedSteps: 3,
+	}
+
+	classifyFailures(&results, previous, 1)
+
+	if results.NewFailures != 2 || results.RecurringFailures != 1 {
+		t.Errorf("Expected 2 new and 1 recurring failures, got %d and %d", results.NewFailures, results.RecurringFailures)
+	}
+	want := []HistoryFailure{
+		{Feature: "Payment", Scenario: "Failed payment"},
+		{Feature: "Search", Scenario: "Search Wikipedia", Fingerprint: "abc"},
+		{Feature: "Old", Scenario: "Expired", Fingerprint: "def"},
+	}
+	if diff := cmp.Diff(want, newHistoryEntry(results, Args{}).Failures); diff != "" {
+		t.Errorf("History failures mismatch (-want +got):\n%s", diff)
+	}
+	if !outcomeChanged(previous, results) {
+		t.Errorf("Expected the trimmed failures to change the outcome")
+	}
+}
+
+// TestFailureFingerprint tests that fingerprints ignore volatile error details
+func TestFailureFingerprint(t *testing.T) {
+	first := failureFingerprint("Checkout", "Pay", "Timeout after 3000 ms waiting for order 7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	second := failureFingerprint("Checkout", "Pay", "Timeout after 5000 ms  waiting for order 16fd2706-8baf-433b-82eb-8c7fada847da")
+	if first != second {
+		t.Errorf("Expected equal fingerprints for equivalent errors, got %s and %s", first, second)
+	}
+
+	if other := failureFingerprint("Checkout", "Pay", "Card declined"); other == first {
+		t.Errorf("Expected different fingerprints for different errors")
+	}
+	if other := failureFingerprint("Checkout", "Refund", "Timeout after 3000 ms waiting for order 7c9e6679-7425-40de-944b-e07fc1f90ae7"); other == first {
+		t.Errorf("Expected different fingerprints for different scenarios")
+	}
+}
+
+// TestFailureLocation tests the feature file and line of the failed steps
+func TestFailureLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     FailedStepDetails
+		expected string
+	}{
+		{"Step line", FailedStepDetails{URI: "features/login.feature", ScenarioLine: 8, StepLine: 12}, "features/login.feature:12"},
+		{"Scenario line", FailedStepDetails{URI: "features/login.feature", ScenarioLine: 8}, "features/login.feature:8"},
+		{"No line", FailedStepDetails{URI: "features/login.feature"}, "features/login.feature"},
+		{"No URI", FailedStepDetails{ScenarioLine: 8, StepLine: 12}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if location := failureLocation(tc.step); location != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, location)
+			}
+		})
+	}
+}
+
+// TestSortFailures tests the ordering of the failed steps by each sort key
+func TestSortFailures(t *testing.T) {
+	steps := []FailedStepDetails{
+		{Feature: "Search", Scenario: "Find", StepLine: 7, DurationMS: 40, ErrorMessage: "Card declined"},
+		{Feature: "Checkout", Scenario: "Pay", StepLine: 12, DurationMS: 3000, ErrorMessage: "Timeout after 3000 ms"},
+		{Feature: "Checkout", Scenario: "Refund", StepLine: 4, DurationMS: 5000, ErrorMessage: "Timeout after 5000 ms"},
+	}
+
+	tests := []struct {
+		method   string
+		expected []string
+	}{
+		{FailureSortArrival, []string{"Find", "Pay", "Refund"}},
+		{FailureSortFeature, []string{"Refund", "Pay", "Find"}},
+		{FailureSortDuration, []string{"Refund", "Pay", "Find"}},
+		{"error", []string{"Pay", "Refund", "Find"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			sorted := append([]FailedStepDetails{}, steps...)
+			sortFailures(sorted, tc.method)
+			var scenarios []string
+			for _, step := range sorted {
+				scenarios = append(scenarios, step.Scenario)
+			}
+			if diff := cmp.Diff(tc.expected, scenarios); diff != "" {
+				t.Errorf("Order mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutcomeChanged(t *testing.T) {
+	failing := Results{FailedSteps: []FailedStepDetails{{Feature: "Cart", Scenario: "Pay", Fingerprint: "abc"}}}
+	previousFailing := []HistoryEntry{{Failures: []HistoryFailure{{Feature: "Cart", Scenario: "Pay", Fingerprint: "abc"}}}}
+	previousGreen := []HistoryEntry{{Failures: []HistoryFailure{{Feature: "Cart", Scenario: "Pay", Fingerprint: "abc"}}}, {}}
+
+	tests := []struct {
+		name     string
+		previous []HistoryEntry
+		results  Results
+		want     bool
+	}{
+		{"first build", nil, Results{}, true},
+		{"still green", previousGreen, Results{}, false},
+		{"same failures", previousFailing, failing, false},
+		{"new failure", previousGreen, failing, true},
+		{"fixed", previousFailing, Results{}, true},
+		{"different failure", previousFailing, Results{FailedSteps: []FailedStepDetails{{Feature: "Cart", Scenario: "Pay", Fingerprint: "def"}}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := outcomeChanged(tc.previous, tc.results); got != tc.want {
+				t.Errorf("outcomeChanged() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// featureDetails groups the scenarios and failed steps of the results by feature name, in
+// the order the features were reported.
+func featureDetails(results Results) []FeatureDetails {
+	var features []FeatureDetails
+	index := make(map[string]int)
+	feature := func(name string) *FeatureDetails {
+		i, ok := index[name]
+		if !ok {
+			i = len(features)
+			index[name] = i
+			features = append(features, FeatureDetails{
+				FeatureStats:    FeatureStats{Feature: name, Status: "passed", Statuses: map[string]int{}},
+				ScenarioResults: []ScenarioResult{},
+				FailedSteps:     []FailedStepDetails{},
+			})
+		}
+		return &features[i]
+	}
+
+	for _, scenario := range results.Scenarios {
+		details := feature(scenario.Feature)
+		details.Scenarios++
+		details.Statuses[scenario.Status]++
+		details.Status = worseStatus(details.Status, scenario.Status)
+		details.DurationMS += scenario.DurationMS
+		details.ScenarioResults = append(details.ScenarioResults, scenario)
+	}
+	for _, step := range results.FailedSteps {
+		details := feature(step.Feature)
+		details.Failures++
+		details.FailedSteps = append(details.FailedSteps, step)
+	}
+	return features
+}
+
+// featureStats returns the statistics of every feature, in the order the features were
+// reported.
+func featureStats(results Results) []FeatureStats {
+	features := featureDetails(results)
+	stats := make([]FeatureStats, 0, len(features))
+	for _, feature := range features {
+		stats = append(stats, feature.FeatureStats)
+	}
+	return stats
+}
+
+// writeFeatureExport writes the details of every feature, with its statistics, scenarios
+// and failed steps, to a single JSON file for the dashboards loading the whole breakdown.
+func writeFeatureExport(path string, results Results) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(path, featureDetails(results))
+}
+
+// writeFeatureDetails writes the details of every feature to a JSON file of the features
+// directory, and an index.json listing the statistics of the features with the path of
+// their file, so that dashboards load the details of a feature on demand. It returns the
+// number of features written.
+func writeFeatureDetails(dir string, results Results) (int, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "features"), 0755); err != nil {
+		return 0, err
+	}
+
+	features := featureDetails(results)
+	entries := make([]FeatureIndexEntry, 0, len(features))
+	used := make(map[string]bool)
+	for _, feature := range features {
+		base := firstNonEmpty(slug(feature.Feature), "feature")
+		name := base
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+		used[name] = true
+
+		file := "features/" + name + ".json"
+		if err := writeJSONFile(filepath.Join(dir, filepath.FromSlash(file)), feature); err != nil {
+			return 0, err
+		}
+		entries = append(entries, FeatureIndexEntry{FeatureStats: feature.FeatureStats, File: file})
+	}
+
+	if err := writeJSONFile(filepath.Join(dir, "index.json"), entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// writeJSONFile writes the value as indented JSON.
+func writeJSONFile(path string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteFeatureDetails(t *testing.T) {
+	results := Results{
+		Scenarios: []ScenarioResult{
+			{Feature: "Cart", Scenario: "Add", Status: "passed", DurationMS: 100},
+			{Feature: "Login!", Scenario: "Valid", Status: "passed", DurationMS: 50},
+			{Feature: "Cart", Scenario: "Remove", Status: "failed", DurationMS: 200},
+			{Feature: "Login?", Scenario: "Expired", Status: "skipped", DurationMS: 0},
+		},
+		FailedSteps: []FailedStepDetails{{Feature: "Cart", Scenario: "Remove", Step: "I remove it", ErrorMessage: "boom"}},
+	}
+	dir := t.TempDir()
+
+	count, err := writeFeatureDetails(dir, results)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 features, got %d", count)
+	}
+
+	var index []FeatureIndexEntry
+	content, _ := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err := json.Unmarshal(content, &index); err != nil {
+		t.Fatalf("Failed to parse index: %v", err)
+	}
+	expected := []FeatureIndexEntry{
+		{FeatureStats{Feature: "Cart", Status: "failed", Scenarios: 2, Statuses: map[string]int{"passed": 1, "failed": 1}, Failures: 1, DurationMS: 300}, "features/cart.json"},
+		{FeatureStats{Feature: "Login!", Status: "passed", Scenarios: 1, Statuses: map[string]int{"passed": 1}, DurationMS: 50}, "features/login.json"},
+		{FeatureStats{Feature: "Login?", Status: "skipped", Scenarios: 1, Statuses: map[string]int{"skipped": 1}}, "features/login-2.json"},
+	}
+	if diff := cmp.Diff(expected, index); diff != "" {
+		t.Errorf("Index mismatch (-want +got):\n%s", diff)
+	}
+
+	var cart FeatureDetails
+	content, _ = os.ReadFile(filepath.Join(dir, "features", "cart.json"))
+	if err := json.Unmarshal(content, &cart); err != nil {
+		t.Fatalf("Failed to parse feature details: %v", err)
+	}
+	if len(cart.ScenarioResults) != 2 || cart.ScenarioResults[1].Scenario != "Remove" || len(cart.FailedSteps) != 1 || cart.FailedSteps[0].ErrorMessage != "boom" {
+		t.Errorf("Unexpected details of the Cart feature: %+v", cart)
+	}
+}
+
+func TestWriteFeatureExport(t *testing.T) {
+	results := Results{
+		Scenarios: []ScenarioResult{
+			{Feature: "Cart", Scenario: "Add", Status: "passed", DurationMS: 100},
+			{Feature: "Login", Scenario: "Valid", Status: "passed", DurationMS: 50},
+			{Feature: "Cart", Scenario: "Remove", Status: "failed", DurationMS: 200},
+		},
+		FailedSteps: []FailedStepDetails{{Feature: "Cart", Scenario: "Remove", Step: "I remove it", ErrorMessage: "boom"}},
+	}
+
+	expectedStats := []FeatureStats{
+		{Feature: "Cart", Status: "failed", Scenarios: 2, Statuses: map[string]int{"passed": 1, "failed": 1}, Failures: 1, DurationMS: 300},
+		{Feature: "Login", Status: "passed", Scenarios: 1, Statuses: map[string]int{"passed": 1}, DurationMS: 50},
+	}
+	if diff := cmp.Diff(expectedStats, featureStats(results)); diff != "" {
+		t.Errorf("Feature statistics mismatch (-want +got):\n%s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), "export", "features.json")
+	if err := writeFeatureExport(path, results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var features []FeatureDetails
+	content, _ := os.ReadFile(path)
+	if err := json.Unmarshal(content, &features); err != nil {
+		t.Fatalf("Failed to parse the export: %v", err)
+	}
+	if len(features) != 2 || features[0].Feature != "Cart" || len(features[0].ScenarioResults) != 2 || len(features[0].FailedSteps) != 1 ||
+		features[1].Feature != "Login" || features[1].ScenarioResults[0].DurationMS != 50 {
+		t.Errorf("Unexpected export: %+v", features)
+	}
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// flagAliases are short flag names for the most common settings.
+var flagAliases = map[string]string{
+	"dir":     "PLUGIN_JSON_REPORT_DIRECTORY",
+	"include": "PLUGIN_FILE_INCLUDE_PATTERN",
+	"exclude": "PLUGIN_FILE_EXCLUDE_PATTERN",
+	"html":    "PLUGIN_HTML_REPORT_PATH",
+	"junit":   "PLUGIN_JUNIT_OUTPUT_PATH",
+}
+
+// fieldFlag sets an Args field from a command-line flag.
+type fieldFlag struct {
+	field reflect.Value
+}
+
+// String returns the current value of the field.
+func (f fieldFlag) String() string {
+	if !f.field.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(f.field.Interface())
+}
+
+// Set parses the flag value into the field.
+func (f fieldFlag) Set(value string) error {
+	switch f.field.Kind() {
+	case reflect.String:
+		f.field.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		f.field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.field.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.field.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported setting type %s", f.field.Kind())
+	}
+	return nil
+}
+
+// IsBoolFlag allows boolean flags without a value.
+func (f fieldFlag) IsBoolFlag() bool {
+	return f.field.IsValid() && f.field.Kind() == reflect.Bool
+}
+
+// flagName returns the flag name of an environment variable, such as
+// json-report-directory for PLUGIN_JSON_REPORT_DIRECTORY.
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(env, "PLUGIN_")), "_", "-")
+}
+
+// ParseFlags overrides the settings with the command-line flags. Every PLUGIN_ environment
+// variable has a flag named after it, such as --json-report-directory for
+// PLUGIN_JSON_REPORT_DIRECTORY, and the most common settings have a short alias. It
+// returns the environment variable names of the settings set by the flags, so that they
+// also override the profile applied afterwards.
+func ParseFlags(args *Args, arguments []string) ([]string, error) {
+	flags := flag.NewFlagSet("drone-cucumber", flag.ContinueOnError)
+
+	envs := make(map[string]string)
+	fields := make(map[string]reflect.Value)
+	value := reflect.ValueOf(args).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		env := value.Type().Field(i).Tag.Get("envconfig")
+		if env == "" {
+			continue
+		}
+		fields[env] = value.Field(i)
+		envs[flagName(env)] = env
+		flags.Var(fieldFlag{value.Field(i)}, flagName(env), "overrides "+env)
+	}
+	for alias, env := range flagAliases {
+		if field, ok := fields[env]; ok {
+			envs[alias] = env
+			flags.Var(fieldFlag{field}, alias, "alias of --"+flagName(env))
+		}
+	}
+
+	if err := flags.Parse(arguments); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	var set []string
+	flags.Visit(func(f *flag.Flag) {
+		set = append(set, envs[f.Name])
+	})
+	return set, nil
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParseFlags tests overriding the settings with command-line flags
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		arguments   []string
+		expected    Args
+		set         []string
+		expectError bool
+	}{
+		{
+			name:      "Flags Override Environment",
+			arguments: []string{"--dir", "./reports", "--failed-steps-percentage=12.5", "--trend-builds", "5", "--merge-features-by-id"},
+			expected: Args{
+				JSONReportDirectory:   "./reports",
+				FileIncludePattern:    "*.json",
+				FailedStepsPercentage: 12.5,
+				TrendBuilds:           5,
+				MergeFeaturesById:     true,
+			},
+			set: []string{"PLUGIN_JSON_REPORT_DIRECTORY", "PLUGIN_FAILED_STEPS_PERCENTAGE", "PLUGIN_MERGE_FEATURES_BY_ID", "PLUGIN_TREND_BUILDS"},
+		},
+		{
+			name:      "Unset Flags Keep Environment",
+			arguments: []string{"--log-level=debug"},
+			expected:  Args{JSONReportDirectory: "./env", FileIncludePattern: "*.json", Level: "debug"},
+			set:       []string{"PLUGIN_LOG_LEVEL"},
+		},
+		{
+			name:        "Invalid Number",
+			arguments:   []string{"--trend-builds", "five"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown Flag",
+			arguments:   []string{"--unknown"},
+			expectError: true,
+		},
+		{
+			name:        "Positional Argument",
+			arguments:   []string{"./reports"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := Args{JSONReportDirectory: "./env", FileIncludePattern: "*.json"}
+			set, err := ParseFlags(&args, tc.arguments)
+			if (err != nil) != tc.expectError {
+				t.Fatalf("Expected error: %v, got %v", tc.expectError, err)
+			}
+			if tc.expectError {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, args); diff != "" {
+				t.Errorf("Args mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.set, set); diff != "" {
+				t.Errorf("Set settings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// rerunScenarios processes the reports of the rerun of the failed scenarios and returns
+// their scenario results.
+func rerunScenarios(ctx context.Context, args Args) ([]ScenarioResult, error) {
+	files, err := locateFiles(args.RerunReportDirectory, args.FileIncludePattern, args.FileExcludePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := fileTimeout(args)
+	outcomes := processFiles(ctx, files, workerCount(files, args), func(ctx context.Context, file string) (Results, error) {
+		return processFileWithDeadline(ctx, file, args, timeout)
+	})
+
+	var scenarios []ScenarioResult
+	for _, outcome := range outcomes {
+		if outcome.err != nil {
+			logger.Warnf("Skipped rerun report %s: %v", outcome.file, outcome.err)
+			continue
+		}
+		scenarios = append(scenarios, outcome.results.Scenarios...)
+	}
+	return scenarios, nil
+}
+
+// reconcileReruns marks the failed scenarios whose last rerun passed as flaky. When the
+// flaky scenarios are ignored, their failures are taken out of the counts the gates check,
+// and the features failing only because of them count as passed.
+func reconcileReruns(results *Results, reruns []ScenarioResult, ignore bool) {
+	passedOnRerun := make(map[string]bool)
+	for _, scenario := range reruns {
+		passedOnRerun[scenarioIdentifier(scenario.Feature, scenario.Scenario)] = scenario.Status == "passed"
+	}
+
+	flaky := make(map[string]bool)
+	failedFeatures := make(map[string]bool)
+	for i := range results.Scenarios {
+		scenario := &results.Scenarios[i]
+		if scenario.Status != "failed" {
+			continue
+		}
+		id := scenarioIdentifier(scenario.Feature, scenario.Scenario)
+		if !passedOnRerun[id] {
+			failedFeatures[scenario.Feature] = true
+			continue
+		}
+		scenario.Flaky = true
+		flaky[id] = true
+		if ignore {
+			results.TotalFailedScenarios--
+			results.TotalPassedScenarios++
+		}
+	}
+
+	results.FlakyScenarios = make([]string, 0, len(flaky))
+	for id := range flaky {
+		results.FlakyScenarios = append(results.FlakyScenarios, id)
+	}
+	sort.Strings(results.FlakyScenarios)
+
+	flakyFeatures := make(map[string]bool)
+	for i := range results.FailedSteps {
+		step := &results.FailedSteps[i]
+		if !flaky[scenarioIdentifier(step.Feature, step.Scenario)] {
+			continue
+		}
+		step.Flaky = true
+		flakyFeatures[step.Feature] = true
+		if ignore {
+			results.FailedTests--
+			results.TotalFailedSteps--
+		}
+	}
+	if ignore {
+		for feature := range flakyFeatures {
+			if !failedFeatures[feature] {
+				results.TotalFailedFeatures--
+				results.TotalPassedFeatures++
+			}
+		}
+	}
+}
+
+// logFlakyScenarios logs the scenarios that failed and then passed on rerun.
+func logFlakyScenarios(flaky []string, ignored bool) {
+	if len(flaky) == 0 {
+		logger.Infof("No scenario passed only on rerun\n")
+		return
+	}
+
+	if ignored {
+		logger.Infof("%d flaky scenarios passed on rerun, their failures are ignored by the gates:\n", len(flaky))
+	} else {
+		logger.Infof("%d flaky scenarios passed on rerun:\n", len(flaky))
+	}
+	for _, id := range flaky {
+		logger.Infof("🔀 Flaky: %s\n", id)
+	}
+}
+
+// writeFlakyStats writes the number of flaky scenarios to the output file.
+func writeFlakyStats(results Results, log Logger) {
+	if err := WriteEnvToFile("FLAKY_SCENARIOS", strconv.Itoa(len(results.FlakyScenarios)), log); err != nil {
+		log.Errorf("Error writing FLAKY_SCENARIOS: %s", err)
+	}
+}
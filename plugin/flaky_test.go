@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReconcileReruns(t *testing.T) {
+	newResults := func() Results {
+		return Results{
+			FailedTests: 3, TotalFailedSteps: 3, TotalFailedScenarios: 3, TotalPassedScenarios: 1, TotalFailedFeatures: 2, TotalPassedFeatures: 0,
+			Scenarios: []ScenarioResult{
+				{Feature: "Cart", Scenario: "Pay", Status: "failed"},
+				{Feature: "Cart", Scenario: "Browse", Status: "passed"},
+				{Feature: "Search", Scenario: "Filter", Status: "failed"},
+				{Feature: "Search", Scenario: "Sort", Status: "failed"},
+			},
+			FailedSteps: []FailedStepDetails{
+				{Feature: "Cart", Scenario: "Pay", Step: "I pay"},
+				{Feature: "Search", Scenario: "Filter", Step: "I filter"},
+				{Feature: "Search", Scenario: "Sort", Step: "I sort"},
+			},
+		}
+	}
+	reruns := []ScenarioResult{
+		{Feature: "Cart", Scenario: "Pay", Status: "passed"},
+		{Feature: "Search", Scenario: "Filter", Status: "passed"},
+		{Feature: "Search", Scenario: "Sort", Status: "passed"},
+		{Feature: "Search", Scenario: "Sort", Status: "failed"},
+	}
+
+	tests := []struct {
+		name     string
+		ignore   bool
+		failed   [4]int // failed steps, scenarios, passed scenarios and failed features
+		expected []string
+	}{
+		{name: "reported", ignore: false, failed: [4]int{3, 3, 1, 2}, expected: []string{"Cart :: Pay", "Search :: Filter"}},
+		{name: "ignored", ignore: true, failed: [4]int{1, 1, 3, 1}, expected: []string{"Cart :: Pay", "Search :: Filter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := newResults()
+			reconcileReruns(&results, reruns, tt.ignore)
+			if diff := cmp.Diff(tt.expected, results.FlakyScenarios); diff != "" {
+				t.Errorf("Flaky scenarios mismatch (-want +got):\n%s", diff)
+			}
+			got := [4]int{results.FailedTests, results.TotalFailedScenarios, results.TotalPassedScenarios, results.TotalFailedFeatures}
+			if got != tt.failed {
+				t.Errorf("Expected counts %v, got %v", tt.failed, got)
+			}
+			if !results.Scenarios[0].Flaky || results.Scenarios[3].Flaky || !results.FailedSteps[1].Flaky || results.FailedSteps[2].Flaky {
+				t.Errorf("Unexpected flaky marks: %+v %+v", results.Scenarios, results.FailedSteps)
+			}
+		})
+	}
+}
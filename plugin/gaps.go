@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// stepParameterPattern matches the step text parts replaced by Cucumber expression
+// parameters: quoted strings, decimal numbers and integers.
+var stepParameterPattern = regexp.MustCompile(`"[^"]*"|'[^']*'|-?\b\d+\.\d+\b|-?\b\d+\b`)
+
+// expressionEscaper escapes the characters with a meaning in Cucumber expressions.
+var expressionEscaper = strings.NewReplacer(`\`, `\\`, `(`, `\(`, `{`, `\{`, `/`, `\/`)
+
+// primaryKeyword returns the Given, When or Then keyword a step keyword stands for,
+// resolving And, But and * to the keyword of the previous step.
+func primaryKeyword(previous, keyword string) string {
+	keyword = strings.TrimSpace(keyword)
+	switch keyword {
+	case "And", "But", "*", "":
+		return firstNonEmpty(previous, "Given")
+	}
+	return keyword
+}
+
+// stepExpression suggests the Cucumber expression of a step definition matching the
+// step text, with parameters for the quoted strings and numbers.
+func stepExpression(text string) string {
+	return replaceStepParameters(text, expressionEscaper.Replace)
+}
+
+// normalizeStepText replaces the quoted strings and numbers of the step text with
+// placeholders, so that "I wait 5 seconds" and "I wait 10 seconds" are aggregated as the
+// logical step "I wait {int} seconds".
+func normalizeStepText(text string) string {
+	return replaceStepParameters(text, func(s string) string { return s })
+}
+
+// replaceStepParameters replaces the parameters of the step text with the {string},
+// {float} and {int} placeholders, escaping the rest of the text.
+func replaceStepParameters(text string, escape func(string) string) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	var normalized strings.Builder
+	last := 0
+	for _, match := range stepParameterPattern.FindAllStringIndex(text, -1) {
+		normalized.WriteString(escape(text[last:match[0]]))
+		switch value := text[match[0]:match[1]]; {
+		case strings.HasPrefix(value, `"`) || strings.HasPrefix(value, `'`):
+			normalized.WriteString("{string}")
+		case strings.Contains(value, "."):
+			normalized.WriteString("{float}")
+		default:
+			normalized.WriteString("{int}")
+		}
+		last = match[1]
+	}
+	normalized.WriteString(escape(text[last:]))
+	return normalized.String()
+}
+
+// addStepGap records an undefined step, deduplicated by keyword and expression, with
+// the scenario it occurred in.
+func addStepGap(gaps []StepGap, keyword, text, scenario string) []StepGap {
+	return mergeStepGaps(gaps, []StepGap{{
+		Keyword:    keyword,
+		Expression: stepExpression(text),
+		Example:    text,
+		Count:      1,
+		Scenarios:  []string{scenario},
+	}})
+}
+
+// mergeStepGaps adds the occurrences and scenarios of the gaps to the total.
+func mergeStepGaps(total, gaps []StepGap) []StepGap {
+	for _, gap := range gaps {
+		index := -1
+		for i := range total {
+			if total[i].Keyword == gap.Keyword && total[i].Expression == gap.Expression {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			total = append(total, StepGap{Keyword: gap.Keyword, Expression: gap.Expression, Example: gap.Example})
+			index = len(total) - 1
+		}
+
+		existing := &total[index]
+		existing.Count += gap.Count
+		for _, scenario := range gap.Scenarios {
+			if !slices.Contains(existing.Scenarios, scenario) {
+				existing.Scenarios = append(existing.Scenarios, scenario)
+			}
+		}
+	}
+	return total
+}
+
+// sortStepGaps orders the gaps by the number of occurrences, the most frequent first.
+func sortStepGaps(gaps []StepGap) {
+	sort.SliceStable(gaps, func(i, j int) bool {
+		if gaps[i].Count != gaps[j].Count {
+			return gaps[i].Count > gaps[j].Count
+		}
+		return gaps[i].Keyword+" "+gaps[i].Expression < gaps[j].Keyword+" "+gaps[j].Expression
+	})
+}
+
+// logStepGaps logs the undefined steps with their suggested definitions.
+func logStepGaps(gaps []StepGap) {
+	if len(gaps) == 0 {
+		return
+	}
+
+	logger.Infof("Step Definition Gaps (%d undefined steps):\n", len(gaps))
+	logger.Infof("-----------------------------------------------\n")
+	for i, gap := range gaps {
+		logger.Infof("%d. %s %s\n", i+1, gap.Keyword, gap.Expression)
+		logger.Infof("   Occurrences: %d in %d scenarios\n", gap.Count, len(gap.Scenarios))
+		logger.Infof("   Example: %s\n", gap.Example)
+	}
+	logger.Infof("-----------------------------------------------\n")
+}
+
+// writeStepGapReport writes the gap report as Markdown when the path ends with .md and
+// as JSON otherwise.
+func writeStepGapReport(path string, gaps []StepGap, args Args) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".md") {
+		if gaps == nil {
+			gaps = []StepGap{}
+		}
+		content, err := json.MarshalIndent(gaps, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0644)
+	}
+
+	report, err := renderReportTemplate(templateStepGaps, struct{ Gaps []StepGap }{gaps}, args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
+
+// evaluateFeatureUndefinedSteps checks the undefined steps percentage of every feature, so
+// that a new feature file full of unimplemented steps is flagged even when the suite-wide
+// percentage stays under its threshold. The gate reports the feature with the highest
+// percentage.
+func evaluateFeatureUndefinedSteps(results Results, args Args) []GateResult {
+	threshold := args.FeatureUndefinedPercentage
+	if threshold <= 0 {
+		return nil
+	}
+
+	var features []string
+	steps := make(map[string]int)
+	undefined := make(map[string]int)
+	for _, scenario := range results.Scenarios {
+		if _, ok := steps[scenario.Feature]; !ok {
+			features = append(features, scenario.Feature)
+		}
+		steps[scenario.Feature] += scenario.Steps
+		undefined[scenario.Feature] += scenario.UndefinedSteps
+	}
+
+	worst, highest, exceeding := "", 0.0, 0
+	for _, feature := range features {
+		percentage := percentageOf(undefined[feature], steps[feature])
+		if percentage > threshold {
+			exceeding++
+		}
+		if percentage > highest {
+			worst, highest = feature, percentage
+		}
+	}
+
+	gate := GateResult{
+		Name:       "Feature Undefined Steps Percentage",
+		Observed:   highest,
+		Threshold:  threshold,
+		Margin:     threshold - highest,
+		Percentage: true,
+		Passed:     highest <= threshold,
+	}
+	if !gate.Passed {
+		gate.Message = fmt.Sprintf("undefined steps percentage of the feature %q (%s) exceeds the threshold (%s) in %d features", worst, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold), exceeding)
+	}
+	return []GateResult{gate}
+}
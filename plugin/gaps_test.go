@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestStepExpression tests suggesting the Cucumber expressions of undefined steps
+func TestStepExpression(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"I have 42 cukes", "I have {int} cukes"},
+		{`I log in as "alice" with 'secret'`, "I log in as {string} with {string}"},
+		{"the total is -3.50 EUR", "the total is {float} EUR"},
+		{"version v2 of the  API", "version v2 of the API"},
+		{"I open /home (logged in) {draft}", `I open \/home \(logged in) \{draft}`},
+	}
+
+	for _, tc := range tests {
+		if expression := stepExpression(tc.text); expression != tc.expected {
+			t.Errorf("Expected %q for %q, got %q", tc.expected, tc.text, expression)
+		}
+	}
+}
+
+// TestStepGaps tests aggregating the undefined steps across scenarios and files
+func TestStepGaps(t *testing.T) {
+	features := []Feature{{
+		Name: "Cart",
+		Elements: []Element{
+			{Name: "Add", Steps: []Step{
+				{Keyword: "Given ", Name: "I have 2 products", Result: Result{Status: "undefined"}},
+				{Keyword: "And ", Name: `I add "Lamp"`, Result: Result{Status: "undefined"}},
+			}},
+			{Name: "Remove", Steps: []Step{
+				{Keyword: "When ", Name: "I log in", Result: Result{Status: "passed"}},
+				{Keyword: "But ", Name: `I add "Desk"`, Result: Result{Status: "undefined"}},
+			}},
+		},
+	}}
+	first := computeStats(features, Args{})
+	second := computeStats(features[:1], Args{UndefinedAsNotFailingStatus: true})
+
+	total := Results{}
+	aggregateResults(&total, first)
+	aggregateResults(&total, second)
+	sortStepGaps(total.StepGaps)
+
+	expected := []StepGap{
+		{Keyword: "Given", Expression: "I add {string}", Example: `I add "Lamp"`, Count: 2, Scenarios: []string{"Cart :: Add"}},
+		{Keyword: "Given", Expression: "I have {int} products", Example: "I have 2 products", Count: 2, Scenarios: []string{"Cart :: Add"}},
+		{Keyword: "When", Expression: "I add {string}", Example: `I add "Desk"`, Count: 2, Scenarios: []string{"Cart :: Remove"}},
+	}
+	if diff := cmp.Diff(expected, total.StepGaps); diff != "" {
+		t.Errorf("Step gaps mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestWriteStepGapReport tests writing the gap report as Markdown and JSON
+func TestWriteStepGapReport(t *testing.T) {
+	gaps := []StepGap{{Keyword: "Given", Expression: "I have {int} products", Example: "I have 2 products", Count: 3, Scenarios: []string{"Cart :: Add", "Cart :: Remove"}}}
+	dir := t.TempDir()
+
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{"gaps.md", []string{"## Given I have {int} products", "3 occurrences in 2 scenarios", "Given(\"I have {int} products\")", "- Cart :: Remove"}},
+		{"gaps.json", []string{`"expression": "I have {int} products"`, `"count": 3`}},
+	}
+	for _, tc := range tests {
+		path := filepath.Join(dir, tc.file)
+		if err := writeStepGapReport(path, gaps, Args{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, _ := os.ReadFile(path)
+		for _, expected := range tc.expected {
+			if !strings.Contains(string(content), expected) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", tc.file, expected, content)
+			}
+		}
+	}
+}
+
+// TestEvaluateFeatureUndefinedSteps tests flagging the features with many undefined steps
+func TestEvaluateFeatureUndefinedSteps(t *testing.T) {
+	results := Results{
+		StepCount:      20,
+		UndefinedTests: 3,
+		Scenarios: []ScenarioResult{
+			{Feature: "Checkout", Scenario: "Pay by card", Steps: 8},
+			{Feature: "Checkout", Scenario: "Pay by voucher", Steps: 8, UndefinedSteps: 1},
+			{Feature: "Refunds", Scenario: "Refund an order", Steps: 4, UndefinedSteps: 2},
+		},
+	}
+
+	if gates := evaluateFeatureUndefinedSteps(results, Args{}); gates != nil {
+		t.Errorf("Expected no gate without a threshold, got %+v", gates)
+	}
+
+	// The suite-wide percentage is 15%, the Refunds feature is at 50%
+	gates := evaluateFeatureUndefinedSteps(results, Args{FeatureUndefinedPercentage: 20})
+	if len(gates) != 1 || gates[0].Passed || gates[0].Observed != 50 {
+		t.Fatalf("Expected the Refunds feature to fail the gate, got %+v", gates)
+	}
+	if expected := `undefined steps percentage of the feature "Refunds" (50.00%) exceeds the threshold (20.00%) in 1 features`; gates[0].Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, gates[0].Message)
+	}
+	if err := validateThresholds(results, Args{UndefinedStepsPercentage: 20, FeatureUndefinedPercentage: 60}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// writeGateReport writes the evaluation of the thresholds as Markdown when the path ends
+// with .md, as an HTML page when it ends with .html and as JSON otherwise.
+func writeGateReport(path string, results Results, args Args, trend *Trend, gateErr error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data := newReportTemplateData(results, args, trend, gateErr, time.Now())
+	var template string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md":
+		template = templateGates
+	case ".html", ".htm":
+		template = templateGatesHTML
+	default:
+		if data.Gates == nil {
+			data.Gates = []GateResult{}
+		}
+		content, err := json.MarshalIndent(data.Gates, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0644)
+	}
+
+	report, err := renderReportTemplate(template, data, args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGateReport(t *testing.T) {
+	results := Results{FailedTests: 2, StepCount: 40}
+	args := Args{FailedStepsNumber: 1, FailedStepsPercentage: 10}
+	gateErr := errors.New("failed steps count (2) exceeds the threshold (1)")
+	dir := t.TempDir()
+
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{"gates.md", []string{
+			"**FAILED**: failed steps count (2) exceeds the threshold (1)",
+			"| Failed Steps | 2 | 1 | -1 | ❌ |",
+			"| Failed Steps Percentage | 5.00% | 10.00% | +5.00% | ✅ |",
+		}},
+		{"gates.html", []string{
+			`<strong class="failed">FAILED</strong>: failed steps count (2) exceeds the threshold (1)`,
+			`<tr class="failed"><td>Failed Steps</td><td class="number">2</td><td class="number">1</td><td class="number">-1</td><td>❌ Failed</td></tr>`,
+		}},
+		{"gates.json", []string{`"name": "Failed Steps"`, `"margin": -1`, `"margin": 5`}},
+	}
+	for _, tc := range tests {
+		path := filepath.Join(dir, tc.file)
+		if err := writeGateReport(path, results, args, nil, gateErr); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, _ := os.ReadFile(path)
+		for _, expected := range tc.expected {
+			if !strings.Contains(string(content), expected) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", tc.file, expected, content)
+			}
+		}
+	}
+}
+
+func TestWriteGateReportTrend(t *testing.T) {
+	trend := computeTrend([]HistoryEntry{
+		{BuildNumber: "41", PassRate: 98, DurationMS: 60000},
+		{BuildNumber: "42", PassRate: 97, DurationMS: 62000},
+	}, HistoryEntry{BuildNumber: "43", PassRate: 80, DurationMS: 120000}, 10)
+	path := filepath.Join(t.TempDir(), "gates.html")
+	if err := writeGateReport(path, Results{}, Args{}, &trend, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	for _, expected := range []string{
+		`<title id="trend-chart-title">Pass rate and duration of the last 2 builds and build #43</title>`,
+		`<circle cx="308.0" cy="39.2" r="4.5" fill="#cf222e"><title>#43: 80.00%</title></circle>`,
+		`<title>#41: 60.00 s</title>`,
+		"Build #43: pass rate 80.00%, average of the last 2 builds 97.50% (-17.50%, REGRESSED)",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected gate report to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultGitHubAPIURL is the GitHub API used when none is configured.
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// githubCommentsPerPage is the number of comments listed per request while looking for
+// the comment of a previous run.
+const githubCommentsPerPage = 100
+
+// githubCommentLimit is the maximum number of characters of a comment body accepted by
+// GitHub.
+const githubCommentLimit = 65536
+
+// githubComment represents the fields of a pull request comment used by the plugin.
+type githubComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// githubCommentMarker returns the hidden marker identifying the comment of the step, so
+// that a re-run updates it and that several steps of the pipeline keep their own comment.
+func githubCommentMarker() string {
+	if step := currentStepName(); step != "" {
+		return fmt.Sprintf("<!-- drone-cucumber:%s -->", step)
+	}
+	return "<!-- drone-cucumber -->"
+}
+
+// githubHeaders returns the authorization and API version headers.
+func githubHeaders(args Args) map[string]string {
+	return map[string]string{
+		"Authorization":        "Bearer " + args.GitHubToken,
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+}
+
+// publishGitHubComment posts the Markdown summary as a comment of the pull request of the
+// build, or updates the comment of a previous run. Builds of no pull request are skipped.
+// The link to the published report follows the summary, left out of the scrubbing like
+// the link targets of the notifications.
+func publishGitHubComment(ctx context.Context, results Results, args Args, reportURL string, trend *Trend, gateErr error) {
+	repo, pullRequest := currentRepo(), currentPullRequest()
+	if repo == "" || pullRequest == "" {
+		logger.Infof("No pull request to comment on, DRONE_REPO or DRONE_PULL_REQUEST is not set\n")
+		return
+	}
+
+	marker, link := githubCommentMarker(), ""
+	if reportURL != "" {
+		link = "\n[View Report](" + reportURL + ")\n"
+	}
+	summary, err := githubCommentSummary(results, args, trend, gateErr, githubCommentLimit-utf8.RuneCountInString(marker)-utf8.RuneCountInString(link)-1)
+	if err != nil {
+		logger.Warnf("Failed to render the pull request comment: %v", err)
+		return
+	}
+	if err := upsertGitHubComment(ctx, args, repo, pullRequest, marker+"\n"+summary+link); err != nil {
+		logger.Warnf("Failed to comment on pull request #%s of %s: %v", pullRequest, repo, err)
+		return
+	}
+	logger.Infof("Summary commented on pull request #%s of %s\n", pullRequest, repo)
+}
+
+// githubCommentSummary returns the scrubbed Markdown summary within the limit of
+// characters. The failed steps that do not fit are left out and counted in a "…and N
+// more" line, and the summary is truncated when it does not fit without them.
+func githubCommentSummary(results Results, args Args, trend *Trend, gateErr error, limit int) (string, error) {
+	now := time.Now()
+	render := func(shown int) (string, error) {
+		trimmed := results
+		if shown < len(results.FailedSteps) {
+			trimmed.FailedSteps = results.FailedSteps[:shown]
+			trimmed.OverflowedFailedSteps += len(results.FailedSteps) - shown
+			trimmed.FailedStepsOverflowFile = ""
+		}
+		summary, err := renderReportTemplate(templateSummary, newReportTemplateData(trimmed, args, trend, gateErr, now), args)
+		return newScrubber(args).text(summary), err
+	}
+
+	summary, err := render(len(results.FailedSteps))
+	if err != nil || utf8.RuneCountInString(summary) <= limit {
+		return summary, err
+	}
+
+	// Keep as many failed steps as fit
+	shown := -1
+	for low, high := 0, len(results.FailedSteps)-1; low <= high; {
+		middle := (low + high) / 2
+		text, err := render(middle)
+		if err != nil {
+			return "", err
+		}
+		if utf8.RuneCountInString(text) <= limit {
+			shown, summary, low = middle, text, middle+1
+		} else {
+			high = middle - 1
+		}
+	}
+	if shown < 0 {
+		return truncateRunes(summary, limit, "…"), nil
+	}
+	return summary, nil
+}
+
+// upsertGitHubComment updates the comment of the pull request carrying the marker of the
+// step or creates it. The body is sent as is, the summary being scrubbed beforehand, so
+// that the marker matched by the next run is not altered by the scrub rules.
+func upsertGitHubComment(ctx context.Context, args Args, repo, pullRequest, body string) error {
+	api := strings.TrimRight(firstNonEmpty(args.GitHubAPIURL, defaultGitHubAPIURL), "/") + "/repos/" + repo
+	headers := githubHeaders(args)
+	marker := githubCommentMarker()
+	content, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+
+	for page := 1; ; page++ {
+		var comments []githubComment
+		url := fmt.Sprintf("%s/issues/%s/comments?per_page=%d&page=%d", api, pullRequest, githubCommentsPerPage, page)
+		if err := doJSON(ctx, args, http.MethodGet, url, nil, headers, &comments); err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.Body, marker) {
+				return sendJSON(ctx, args, http.MethodPatch, fmt.Sprintf("%s/issues/comments/%d", api, comment.ID), content, headers, nil)
+			}
+		}
+		if len(comments) < githubCommentsPerPage {
+			break
+		}
+	}
+	return sendJSON(ctx, args, http.MethodPost, fmt.Sprintf("%s/issues/%s/comments", api, pullRequest), content, headers, nil)
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestPublishGitHubComment tests creating and updating the pull request comment
+func TestPublishGitHubComment(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       string
+		scrubRules     string
+		reportURL      string
+		expectedMethod string
+		expectedPath   string
+	}{
+		{
+			name:           "Create Comment",
+			existing:       `[{"id": 7, "body": "LGTM"}]`,
+			expectedMethod: http.MethodPost,
+			expectedPath:   "/api/repos/acme/shop/issues/42/comments",
+		},
+		{
+			name:           "Update Comment",
+			existing:       `[{"id": 7, "body": "LGTM"}, {"id": 9, "body": "<!-- drone-cucumber:cucumber -->\n## Cucumber Test Report"}]`,
+			expectedMethod: http.MethodPatch,
+			expectedPath:   "/api/repos/acme/shop/issues/comments/9",
+		},
+		{
+			name:           "Update Comment With Scrub Rules",
+			existing:       `[{"id": 9, "body": "<!-- drone-cucumber:cucumber -->\n## Cucumber Test Report"}]`,
+			scrubRules:     `[{"pattern": "cucumber"}]`,
+			reportURL:      "https://reports.example.com/cucumber/42.html",
+			expectedMethod: http.MethodPatch,
+			expectedPath:   "/api/repos/acme/shop/issues/comments/9",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DRONE_REPO", "acme/shop")
+			t.Setenv("DRONE_PULL_REQUEST", "42")
+			t.Setenv("DRONE_STEP_NAME", "cucumber")
+
+			var method, path, auth string
+			var comment map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					if r.URL.Path != "/api/repos/acme/shop/issues/42/comments" || r.URL.Query().Get("page") != "1" {
+						t.Errorf("Unexpected comments request %s", r.URL)
+					}
+					w.Write([]byte(tc.existing))
+					return
+				}
+				method, path, auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&comment)
+			}))
+			defer server.Close()
+
+			args := Args{GitHubToken: "token", GitHubAPIURL: server.URL + "/api/", ScrubRules: tc.scrubRules}
+			publishGitHubComment(context.Background(), Results{ScenarioCount: 1, TotalPassedScenarios: 1}, args, tc.reportURL, nil, nil)
+
+			if method != tc.expectedMethod || path != tc.expectedPath {
+				t.Errorf("Expected %s %s, got %s %s", tc.expectedMethod, tc.expectedPath, method, path)
+			}
+			if auth != "Bearer token" {
+				t.Errorf("Unexpected authorization %q", auth)
+			}
+			if !strings.HasPrefix(comment["body"], "<!-- drone-cucumber:cucumber -->\n## Cucumber Test Report") {
+				t.Errorf("Unexpected comment body:\n%s", comment["body"])
+			}
+			if tc.reportURL != "" && !strings.HasSuffix(comment["body"], "\n[View Report]("+tc.reportURL+")\n") {
+				t.Errorf("Expected a link to the report, got:\n%s", comment["body"])
+			}
+		})
+	}
+}
+
+// TestGitHubCommentSummaryLimit tests that the failed steps beyond the comment limit are
+// counted instead of listed
+func TestGitHubCommentSummaryLimit(t *testing.T) {
+	var results Results
+	for i := 0; i < 2000; i++ {
+		results.FailedSteps = append(results.FailedSteps, FailedStepDetails{
+			Feature: "Checkout", Scenario: fmt.Sprintf("Pay %d", i), Step: "I pay",
+			ErrorMessage: "expected the payment to succeed but the gateway returned a timeout after 30 seconds",
+		})
+	}
+	results.TotalFailedSteps = len(results.FailedSteps)
+
+	summary, err := githubCommentSummary(results, Args{}, nil, nil, githubCommentLimit)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if length := utf8.RuneCountInString(summary); length > githubCommentLimit {
+		t.Errorf("Expected at most %d characters, got %d", githubCommentLimit, length)
+	}
+	shown := strings.Count(summary, "| Checkout |")
+	if shown == 0 || shown == len(results.FailedSteps) {
+		t.Fatalf("Expected some of the failed steps to be listed, got %d", shown)
+	}
+	if more := fmt.Sprintf("…and %d more", len(results.FailedSteps)-shown); !strings.Contains(summary, more) {
+		t.Errorf("Expected the summary to end with %q", more)
+	}
+
+	small, err := githubCommentSummary(Results{FailedSteps: results.FailedSteps[:3]}, Args{}, nil, nil, githubCommentLimit)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Count(small, "| Checkout |") != 3 || strings.Contains(small, "more") {
+		t.Errorf("Expected every failed step to be listed, got:\n%s", small)
+	}
+}
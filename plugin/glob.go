@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// defaultIncludePattern matches the reports when no include pattern is configured.
+const defaultIncludePattern = "**/*.json"
+
+// validateFilePattern checks that the file pattern is a valid glob.
+func validateFilePattern(name, pattern string) error {
+	if pattern != "" && !doublestar.ValidatePattern(filepath.ToSlash(pattern)) {
+		return fmt.Errorf("invalid %s %q: not a valid glob", name, pattern)
+	}
+	return nil
+}
+
+// splitPatterns splits a comma-separated list of file patterns. The commas inside the
+// braces of an alternative, such as *.{json,ndjson}, do not separate patterns.
+func splitPatterns(value string) []string {
+	var patterns []string
+	depth, start := 0, 0
+	for i, char := range value {
+		switch char {
+		case '{':
+			depth++
+		case '}':
+			depth = max(depth-1, 0)
+		case ',':
+			if depth == 0 {
+				patterns = append(patterns, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	patterns = append(patterns, value[start:])
+
+	var trimmed []string
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			trimmed = append(trimmed, pattern)
+		}
+	}
+	return trimmed
+}
+
+// validateExcludePatterns checks that every pattern of a comma-separated list is a valid glob.
+func validateExcludePatterns(name, value string) error {
+	for _, pattern := range splitPatterns(value) {
+		if err := validateFilePattern(name, pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// excludeFiles removes the files of the directory matching one of the comma-separated
+// exclude patterns, relative to the directory like the include pattern.
+func excludeFiles(directory string, files []string, excludePatterns string) []string {
+	patterns := splitPatterns(excludePatterns)
+	if len(patterns) == 0 {
+		return files
+	}
+	if directory == "" {
+		directory = "."
+	}
+
+	var kept []string
+	for _, file := range files {
+		relative, err := filepath.Rel(directory, file)
+		if err != nil {
+			relative = file
+		}
+		excluded := false
+		for _, pattern := range patterns {
+			if matched, _ := doublestar.Match(filepath.ToSlash(pattern), filepath.ToSlash(relative)); matched {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			logger.Debugf("Excluded file: %s", file)
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
+
+// globFiles returns the files of the directory matching the pattern, sorted. The pattern
+// is relative to the directory unless it is absolute, may start with `..` path elements,
+// and supports `**` matching any number of directories and `{a,b}` alternatives in
+// addition to the wildcards of filepath.Match. An empty pattern matches the JSON files
+// of the directory tree.
+func globFiles(directory, pattern string) ([]string, error) {
+	if directory == "" {
+		directory = "."
+	}
+	if pattern == "" {
+		pattern = defaultIncludePattern
+	}
+	// The glob walks the file system from the static base of the pattern, so that the
+	// absolute and parent directories are reachable
+	base, rest := doublestar.SplitPattern(filepath.ToSlash(pattern))
+	root := filepath.FromSlash(base)
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(directory, root)
+	}
+	matches, err := doublestar.Glob(os.DirFS(root), rest, doublestar.WithFilesOnly())
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, len(matches))
+	for i, match := range matches {
+		files[i] = filepath.Join(root, filepath.FromSlash(match))
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGlobFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"root.json", "api/cucumber.json", "api/module/nested/cucumber.json", "ui/cucumber.ndjson", "ui/notes.txt", "dir.json/report.xml"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("[]"), 0644)
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"*.json", []string{"root.json"}},
+		{"**/*.json", []string{"api/cucumber.json", "api/module/nested/cucumber.json", "root.json"}},
+		{"api/**/cucumber.json", []string{"api/cucumber.json", "api/module/nested/cucumber.json"}},
+		{"**/*.{json,ndjson}", []string{"api/cucumber.json", "api/module/nested/cucumber.json", "root.json", "ui/cucumber.ndjson"}},
+		{"**/*.xml", []string{"dir.json/report.xml"}},
+		{"", []string{"api/cucumber.json", "api/module/nested/cucumber.json", "root.json"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			files, err := globFiles(dir, tc.pattern)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := make([]string, len(tc.expected))
+			for i, file := range tc.expected {
+				expected[i] = filepath.Join(dir, filepath.FromSlash(file))
+			}
+			if diff := cmp.Diff(expected, files); diff != "" {
+				t.Errorf("Files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// Absolute patterns ignore the directory, and patterns may reach its parents
+	absolute, err := globFiles("elsewhere", filepath.ToSlash(filepath.Join(dir, "api"))+"/**/*.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "api", "cucumber.json"), filepath.Join(dir, "api", "module", "nested", "cucumber.json")}
+	if diff := cmp.Diff(expected, absolute); diff != "" {
+		t.Errorf("Absolute pattern files mismatch (-want +got):\n%s", diff)
+	}
+	parent, err := globFiles(filepath.Join(dir, "api", "module"), "../../ui/*.ndjson")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{filepath.Join(dir, "ui", "cucumber.ndjson")}, parent); diff != "" {
+		t.Errorf("Parent pattern files mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := validateFilePattern("FileIncludePattern", "**/*.{json"); err == nil {
+		t.Errorf("Expected an error for an unclosed brace")
+	}
+}
+
+func TestExcludeFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"cucumber.json", "web/node_modules/pkg/cucumber.json", "old-reports/2023/cucumber.json", "api/cucumber.json", "api/cucumber.rerun.json"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("[]"), 0644)
+	}
+
+	tests := []struct {
+		exclude  string
+		expected []string
+	}{
+		{"", []string{"api/cucumber.json", "api/cucumber.rerun.json", "cucumber.json", "old-reports/2023/cucumber.json", "web/node_modules/pkg/cucumber.json"}},
+		{"**/node_modules/**, **/old-reports/**", []string{"api/cucumber.json", "api/cucumber.rerun.json", "cucumber.json"}},
+		{"**/*.{rerun,tmp}.json,cucumber.json", []string{"api/cucumber.json", "old-reports/2023/cucumber.json", "web/node_modules/pkg/cucumber.json"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.exclude, func(t *testing.T) {
+			files, err := locateFiles(dir, "**/*.json", tc.exclude)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := make([]string, len(tc.expected))
+			for i, file := range tc.expected {
+				expected[i] = filepath.Join(dir, filepath.FromSlash(file))
+			}
+			if diff := cmp.Diff(expected, files); diff != "" {
+				t.Errorf("Files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := locateFiles(dir, "**/*.json", "**"); err == nil {
+		t.Errorf("Expected an error when every file is excluded")
+	}
+	if err := validateExcludePatterns("FileExcludePattern", "**/node_modules/**,[a-"); err == nil {
+		t.Errorf("Expected an error for an invalid exclude pattern")
+	}
+}
@@ -0,0 +1,193 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// godogEvent represents an event of the godog events formatter, one JSON object per
+// line. The timestamps are expressed in milliseconds since the epoch.
+type godogEvent struct {
+	Event     string `json:"event"`
+	Location  string `json:"location"`
+	Source    string `json:"source"`
+	Timestamp int64  `json:"timestamp"`
+	Status    string `json:"status"`
+	Summary   string `json:"summary"`
+}
+
+// isGodogEvents reports whether the content is the output of the godog events formatter.
+func isGodogEvents(content []byte) bool {
+	if firstByte(content) != '{' {
+		return false
+	}
+	var event map[string]json.RawMessage
+	if json.NewDecoder(bytes.NewReader(content)).Decode(&event) != nil {
+		return false
+	}
+	_, ok := event["event"]
+	return ok
+}
+
+// decodeGodogReport decodes the output of the godog events formatter into Cucumber
+// features, taking the names from the Gherkin sources and the step durations from the
+// timestamps of their events. Reports of the godog cucumber formatter are decoded as
+// Cucumber JSON reports.
+func decodeGodogReport(content []byte) ([]Feature, error) {
+	if firstByte(content) == '[' {
+		return decodeCucumberReport(content)
+	}
+
+	var (
+		features []Feature
+		sources  = make(map[string][]string)
+		indexes  = make(map[string]int)
+		element  *Element
+		started  = make(map[string]int64)
+	)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	for decoder.More() {
+		var event godogEvent
+		if err := decoder.Decode(&event); err != nil {
+			return nil, err
+		}
+		uri, line := splitLocation(event.Location)
+
+		switch event.Event {
+		case "TestSource":
+			lines := sourceLines(event.Source)
+			sources[uri] = lines
+			feature := Feature{URI: uri, Keyword: "Feature", Line: line}
+			for i, text := range lines {
+				if name, ok := strings.CutPrefix(strings.TrimSpace(text), "Feature:"); ok {
+					feature.Name = strings.TrimSpace(name)
+					feature.Line = i + 1
+					feature.Tags = gherkinTags(lines, i)
+					break
+				}
+			}
+			indexes[uri] = len(features)
+			features = append(features, feature)
+		case "TestCaseStarted":
+			index, ok := indexes[uri]
+			if !ok {
+				continue
+			}
+			features[index].Elements = append(features[index].Elements, godogScenario(sources[uri], line))
+			element = &features[index].Elements[len(features[index].Elements)-1]
+			if event.Timestamp > 0 {
+				element.StartTimestamp = time.UnixMilli(event.Timestamp).UTC().Format(time.RFC3339Nano)
+			}
+		case "TestStepStarted":
+			started[event.Location] = event.Timestamp
+		case "TestStepFinished":
+			if element == nil {
+				continue
+			}
+			keyword, name := splitStep(sourceLine(sources[uri], line))
+			step := Step{Keyword: keyword, Name: name, Line: line, Result: Result{Status: event.Status}}
+			if start, ok := started[event.Location]; ok && event.Timestamp > start {
+				step.Result.Duration = (event.Timestamp - start) * 1e6
+			}
+			if event.Status == "failed" {
+				step.Result.ErrorMessage = event.Summary
+			}
+			element.Steps = append(element.Steps, step)
+		case "TestCaseFinished":
+			element = nil
+		}
+	}
+	return features, nil
+}
+
+// godogScenario creates the scenario starting at the line of a Gherkin source. The
+// examples of an outline start at their row and take the name of the outline.
+func godogScenario(lines []string, line int) Element {
+	element := Element{Keyword: "Scenario", Line: line, Type: "scenario"}
+	for i := line - 1; i >= 0 && i < len(lines); i-- {
+		text := strings.TrimSpace(lines[i])
+		for _, keyword := range scenarioKeywords {
+			if name, ok := strings.CutPrefix(text, keyword); ok {
+				element.Keyword = strings.TrimSuffix(keyword, ":")
+				element.Name = strings.TrimSpace(name)
+				element.Tags = gherkinTags(lines, i)
+				return element
+			}
+		}
+	}
+	return element
+}
+
+// gherkinTags returns the tags on the lines preceding a line of a Gherkin source.
+func gherkinTags(lines []string, index int) []Tag {
+	var tags []Tag
+	for i := index - 1; i >= 0; i-- {
+		text := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(text, "@") {
+			break
+		}
+		for _, tag := range strings.Fields(text) {
+			tags = append(tags, Tag{Name: tag, Line: i + 1})
+		}
+	}
+	return tags
+}
+
+// splitLocation splits a "uri:line" location.
+func splitLocation(location string) (string, int) {
+	separator := strings.LastIndex(location, ":")
+	if separator < 0 {
+		return location, 0
+	}
+	line, err := strconv.Atoi(location[separator+1:])
+	if err != nil {
+		return location, 0
+	}
+	return location[:separator], line
+}
+
+// sourceLines splits a Gherkin source into lines.
+func sourceLines(source string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(source))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// sourceLine returns a line of a Gherkin source, numbered from 1.
+func sourceLine(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+// splitStep splits a step line into its keyword, followed by a space, and its text.
+func splitStep(text string) (string, string) {
+	keyword, name, ok := strings.Cut(text, " ")
+	if !ok {
+		return "", text
+	}
+	return keyword + " ", strings.TrimSpace(name)
+}
+
+// fillEmptyResults marks the steps reported without a result, as godog occasionally
+// does for the steps it did not run, as skipped so that they are counted.
+func fillEmptyResults(features []Feature) {
+	for i := range features {
+		for j := range features[i].Elements {
+			steps := features[i].Elements[j].Steps
+			for k := range steps {
+				if steps[k].Result.Status == "" {
+					steps[k].Result.Status = "skipped"
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Google Chat card colors and limits
+const (
+	googleChatColorPassed        = "#2eb886"
+	googleChatColorFailed        = "#e01e5a"
+	defaultGoogleChatMaxFailures = 5
+	googleChatTextLimit          = 2000
+)
+
+// googleChatMessage builds the cards v2 message for the results and the quality gates of
+// the build.
+func googleChatMessage(results Results, gates []GateResult, args Args, reportURL string, gateErr error) map[string]interface{} {
+	status, color := "passed ✅", googleChatColorPassed
+	if gateErr != nil {
+		status, color = "failed ❌", googleChatColorFailed
+	}
+
+	title := "Cucumber results " + status
+	if repo := currentRepo(); repo != "" {
+		title = fmt.Sprintf("%s: Cucumber results %s", repo, status)
+	}
+
+	var details []string
+	if branch := currentBranch(args); branch != "" {
+		details = append(details, "Branch: "+branch)
+	}
+	if build := currentBuildNumber(); build != "" {
+		details = append(details, "Build: #"+build)
+	}
+
+	sections := []interface{}{
+		map[string]interface{}{
+			"header": "Totals",
+			"widgets": []interface{}{
+				googleChatText("Features", fmt.Sprintf("%d (%d failed)", results.FeatureCount, results.TotalFailedFeatures)),
+				googleChatText("Scenarios", fmt.Sprintf("%d (%d failed)", results.ScenarioCount, results.TotalFailedScenarios)),
+				googleChatText("Steps", fmt.Sprintf("%d (%d failed)", results.StepCount, results.TotalFailedSteps)),
+				googleChatText("Pass Rate", formatNumber(percentageOf(results.TotalPassedScenarios, results.ScenarioCount))+"%"),
+				googleChatText("Skipped / Pending / Undefined", fmt.Sprintf("%d / %d / %d", results.SkippedTests, results.PendingTests, results.UndefinedTests)),
+				googleChatText("Duration", formatNumber(results.DurationMS/1000)+" s"),
+			},
+		},
+	}
+
+	if len(results.Annotations) > 0 {
+		var widgets []interface{}
+		for _, annotation := range results.Annotations {
+			widgets = append(widgets, googleChatText(annotation.Name, annotation.Value))
+		}
+		sections = append(sections, map[string]interface{}{"header": "Annotations", "widgets": widgets})
+	}
+
+	if len(gates) > 0 || gateErr != nil {
+		var widgets []interface{}
+		for _, gate := range gates {
+			widgets = append(widgets, googleChatText(gate.Name,
+				fmt.Sprintf("%s / %s %s", gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold), gateSymbol(gate.Passed))))
+		}
+		if gateErr != nil {
+			widgets = append(widgets, googleChatParagraph(fmt.Sprintf(`<font color="%s"><b>Gate failure:</b></font> %s`, googleChatColorFailed, html.EscapeString(truncateRunes(gateErr.Error(), googleChatTextLimit, "…")))))
+		}
+		sections = append(sections, map[string]interface{}{"header": "Quality Gates", "widgets": widgets})
+	}
+
+	if failures := googleChatFailures(results, args.GoogleChatMaxFailures); len(failures) > 0 {
+		sections = append(sections, map[string]interface{}{
+			"header":                    "Top Failures",
+			"collapsible":               len(failures) > 2,
+			"uncollapsibleWidgetsCount": 2,
+			"widgets":                   failures,
+		})
+	}
+
+	var buttons []interface{}
+	if link := currentBuildLink(); link != "" {
+		buttons = append(buttons, googleChatButton("View Build", link))
+	}
+	if reportURL != "" {
+		buttons = append(buttons, googleChatButton("View Report", reportURL))
+	}
+	if len(buttons) > 0 {
+		sections = append(sections, map[string]interface{}{
+			"widgets": []interface{}{map[string]interface{}{"buttonList": map[string]interface{}{"buttons": buttons}}},
+		})
+	}
+
+	return map[string]interface{}{
+		"text": fmt.Sprintf("%s: %d scenarios, %d failed", title, results.ScenarioCount, results.TotalFailedScenarios),
+		"cardsV2": []interface{}{
+			map[string]interface{}{
+				"cardId": "cucumber-results",
+				"card": map[string]interface{}{
+					"header": map[string]interface{}{
+						"title":    fmt.Sprintf(`<font color="%s">%s</font>`, color, html.EscapeString(title)),
+						"subtitle": strings.Join(details, "  |  "),
+					},
+					"sections": sections,
+				},
+			},
+		},
+	}
+}
+
+// googleChatFailures returns the widgets of the top failures, new failures first.
+func googleChatFailures(results Results, max int) []interface{} {
+	steps := results.FailedSteps
+	if max <= 0 {
+		max = defaultGoogleChatMaxFailures
+	}
+
+	var widgets []interface{}
+	for i, step := range steps {
+		if i == max {
+			break
+		}
+		label := ""
+		if step.New {
+			label = " 🆕"
+		}
+		text := map[string]interface{}{
+			"topLabel":    fmt.Sprintf("%s › %s%s", step.Feature, step.Scenario, label),
+			"text":        fmt.Sprintf("<b>%s</b>: %s", html.EscapeString(step.Step), html.EscapeString(firstLine(step.ErrorMessage))),
+			"wrapText":    true,
+			"bottomLabel": failureLocation(step),
+			"startIcon":   map[string]interface{}{"knownIcon": "BOOKMARK"},
+		}
+		if step.SessionURL != "" {
+			text["button"] = googleChatButton("Session", step.SessionURL)
+		}
+		if step.SourceURL != "" {
+			text["onClick"] = map[string]interface{}{"openLink": map[string]interface{}{"url": step.SourceURL}}
+		}
+		widgets = append(widgets, map[string]interface{}{"decoratedText": text})
+	}
+	if more := moreFailures(len(steps)-min(len(steps), max), results); more != "" {
+		widgets = append(widgets, googleChatParagraph("<i>"+html.EscapeString(more)+"</i>"))
+	}
+	return widgets
+}
+
+// googleChatText returns a decorated text widget with a label.
+func googleChatText(label, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"decoratedText": map[string]interface{}{"topLabel": label, "text": html.EscapeString(text)},
+	}
+}
+
+// googleChatParagraph returns a text paragraph widget.
+func googleChatParagraph(text string) map[string]interface{} {
+	return map[string]interface{}{"textParagraph": map[string]interface{}{"text": text}}
+}
+
+// googleChatButton returns a button opening a link.
+func googleChatButton(text, url string) map[string]interface{} {
+	return map[string]interface{}{
+		"text":    text,
+		"onClick": map[string]interface{}{"openLink": map[string]interface{}{"url": url}},
+	}
+}
+
+// notifyGoogleChat posts the results to the Google Chat webhook.
+func notifyGoogleChat(ctx context.Context, results Results, args Args, reportURL string, gateErr error) {
+	if !shouldNotify(args.GoogleChatNotifyOn, gateErr) {
+		return
+	}
+	postGoogleChat(ctx, args, googleChatMessage(results, evaluateThresholds(results, args), args, reportURL, gateErr))
+}
+
+// postGoogleChat posts the message to the Google Chat webhook.
+func postGoogleChat(ctx context.Context, args Args, message map[string]interface{}) {
+	if err := postJSON(ctx, args, args.GoogleChatWebhook, message, nil); err != nil {
+		logger.Warnf("Failed to send Google Chat notification: %v", err)
+		return
+	}
+	logger.Infof("Google Chat notification sent\n")
+}
@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestNotifyGoogleChat tests the cards v2 message posted to the Google Chat webhook
+func TestNotifyGoogleChat(t *testing.T) {
+	t.Setenv("DRONE_BUILD_LINK", "https://drone.example.com/octocat/hello/42")
+
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&message)
+		content, _ := json.Marshal(message)
+		body = string(content)
+	}))
+	defer server.Close()
+
+	results := Results{
+		ScenarioCount:        2,
+		TotalFailedScenarios: 1,
+		StepCount:            4,
+		FailedSteps: []FailedStepDetails{
+			{Feature: "Checkout", Scenario: "Pay", Step: "I pay", ErrorMessage: "Card <declined>\nstack trace"},
+			{Feature: "Checkout", Scenario: "Refund", Step: "I refund", ErrorMessage: "Timeout"},
+		},
+	}
+	args := Args{GoogleChatWebhook: server.URL, GoogleChatMaxFailures: 1, FailedStepsPercentage: 10}
+
+	notifyGoogleChat(context.Background(), results, args, "https://reports.example.com/42.html", errors.New("failed steps percentage (25.00%) exceeds the threshold (10.00%)"))
+
+	for _, expected := range []string{
+		`"cardsV2"`,
+		"Failed Steps Percentage",
+		`Card \u0026lt;declined\u0026gt;`,
+		"and 1 more",
+		"https://reports.example.com/42.html",
+		"https://drone.example.com/octocat/hello/42",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected message to contain %q, got %s", expected, body)
+		}
+	}
+	if strings.Contains(body, "stack trace") || strings.Contains(body, "Timeout") {
+		t.Errorf("Expected only the first line of the first failure, got %s", body)
+	}
+
+	// Successful builds are skipped when notifying on failures only
+	body = ""
+	args.GoogleChatNotifyOn = "failure"
+	notifyGoogleChat(context.Background(), Results{}, args, "", nil)
+	if body != "" {
+		t.Errorf("Expected no notification for a successful build, got %s", body)
+	}
+}
+
+// TestGoogleChatGateFailureLimit tests cutting a long gate failure on a character boundary
+func TestGoogleChatGateFailureLimit(t *testing.T) {
+	message := googleChatMessage(Results{}, nil, Args{}, "", errors.New(strings.Repeat("é", 3*googleChatTextLimit)))
+	content, _ := json.Marshal(message)
+	body := string(content)
+	if !strings.Contains(body, strings.Repeat("é", googleChatTextLimit-1)+"…") || strings.Contains(body, strings.Repeat("é", googleChatTextLimit)) {
+		t.Errorf("Expected the gate failure to be cut to %d characters, got %s", googleChatTextLimit, body)
+	}
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// grafanaAnnotation builds the annotation posted to Grafana for a failed gate.
+func grafanaAnnotation(args Args, gateErr error, now time.Time) map[string]interface{} {
+	branch := currentBranch(args)
+	tags := []string{"cucumber", "gate-failure"}
+	if branch != "" {
+		tags = append(tags, "branch:"+branch)
+	}
+	if build := currentBuildNumber(); build != "" {
+		tags = append(tags, "build:"+build)
+	}
+	for _, tag := range strings.Split(args.GrafanaTags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	text := gateFailureSummary(branch, gateErr)
+	if link := currentBuildLink(); link != "" {
+		text += ` <a href="` + link + `">Build</a>`
+	}
+
+	annotation := map[string]interface{}{
+		"time": now.UnixMilli(),
+		"tags": tags,
+		"text": text,
+	}
+	if args.GrafanaDashboardUID != "" {
+		annotation["dashboardUID"] = args.GrafanaDashboardUID
+	}
+	if args.GrafanaPanelID > 0 {
+		annotation["panelId"] = args.GrafanaPanelID
+	}
+	return annotation
+}
+
+// annotateGrafana posts an annotation for a failed gate to Grafana.
+func annotateGrafana(ctx context.Context, args Args, gateErr error) {
+	url := strings.TrimRight(args.GrafanaURL, "/") + "/api/annotations"
+	headers := map[string]string{}
+	if args.GrafanaToken != "" {
+		headers["Authorization"] = "Bearer " + args.GrafanaToken
+	}
+
+	if err := postJSON(ctx, args, url, grafanaAnnotation(args, gateErr, time.Now()), headers); err != nil {
+		logger.Warnf("Failed to post Grafana annotation: %v", err)
+		return
+	}
+	logger.Infof("Grafana annotation posted for the failed quality gate\n")
+}
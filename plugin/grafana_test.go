@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAnnotateGrafana tests posting an annotation for a failed gate
+func TestAnnotateGrafana(t *testing.T) {
+	t.Setenv("DRONE_BUILD_NUMBER", "42")
+
+	var path, auth string
+	var annotation map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&annotation)
+	}))
+	defer server.Close()
+
+	args := Args{
+		GrafanaURL:          server.URL + "/",
+		GrafanaToken:        "token",
+		GrafanaDashboardUID: "deployments",
+		GrafanaPanelID:      4,
+		GrafanaTags:         "e2e, checkout",
+		Branch:              "main",
+	}
+	annotateGrafana(context.Background(), args, errors.New("build failed due to failed tests"))
+
+	if path != "/api/annotations" || auth != "Bearer token" {
+		t.Errorf("Unexpected request: path=%s auth=%s", path, auth)
+	}
+	if annotation["dashboardUID"] != "deployments" || annotation["panelId"] != float64(4) {
+		t.Errorf("Unexpected dashboard target: %v", annotation)
+	}
+
+	expected := []interface{}{"cucumber", "gate-failure", "branch:main", "build:42", "e2e", "checkout"}
+	tags, _ := annotation["tags"].([]interface{})
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected tags %v, got %v", expected, tags)
+	}
+	for i := range expected {
+		if tags[i] != expected[i] {
+			t.Errorf("Expected tag %v at %d, got %v", expected[i], i, tags[i])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Constants for Log Groups
+const (
+	LogGroupsAuto   = "AUTO"
+	LogGroupsGitHub = "GITHUB"
+	LogGroupsDrone  = "DRONE"
+	LogGroupsNone   = "NONE"
+)
+
+// logGroups is the style of the log group markers.
+var logGroups = LogGroupsNone
+
+// validateLogGroups checks the log groups setting.
+func validateLogGroups(args Args) error {
+	switch strings.ToUpper(args.LogGroups) {
+	case "", LogGroupsAuto, LogGroupsGitHub, LogGroupsDrone, LogGroupsNone:
+		return nil
+	}
+	return fmt.Errorf("invalid LogGroups value. It must be '%s', '%s', '%s' or '%s'", LogGroupsAuto, LogGroupsGitHub, LogGroupsDrone, LogGroupsNone)
+}
+
+// configureLogGroups selects the style of the log group markers, detecting the CI
+// system when set to AUTO.
+func configureLogGroups(args Args) {
+	logGroups = strings.ToUpper(args.LogGroups)
+	if logGroups != "" && logGroups != LogGroupsAuto {
+		return
+	}
+
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		logGroups = LogGroupsGitHub
+	case os.Getenv("DRONE") == "true" || os.Getenv("HARNESS_BUILD_ID") != "":
+		logGroups = LogGroupsDrone
+	default:
+		logGroups = LogGroupsNone
+	}
+}
+
+// startLogGroup starts a collapsible log section and returns the function ending it.
+// Sections must not be nested since GitHub Actions does not support nested groups.
+func startLogGroup(title string) func() {
+	switch logGroups {
+	case LogGroupsGitHub:
+		logger.Infof("::group::%s\n", title)
+		return func() { logger.Infof("::endgroup::\n") }
+	case LogGroupsDrone:
+		logger.Infof(">>> %s\n", title)
+		return func() { logger.Infof("<<< %s\n", title) }
+	default:
+		return func() {}
+	}
+}
@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestStartLogGroup tests the log group markers of the CI systems
+func TestStartLogGroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		setting  string
+		expected []string
+	}{
+		{
+			name:     "GitHub Actions",
+			env:      map[string]string{"GITHUB_ACTIONS": "true"},
+			expected: []string{"INFO ::group::Summary", "INFO ::endgroup::"},
+		},
+		{
+			name:     "Drone",
+			env:      map[string]string{"DRONE": "true"},
+			expected: []string{"INFO >>> Summary", "INFO <<< Summary"},
+		},
+		{
+			name:     "Disabled",
+			env:      map[string]string{"GITHUB_ACTIONS": "true"},
+			setting:  "none",
+			expected: []string{},
+		},
+		{
+			name:     "Forced",
+			setting:  "github",
+			expected: []string{"INFO ::group::Summary", "INFO ::endgroup::"},
+		},
+	}
+
+	previous, previousGroups := logger, logGroups
+	defer func() { logger, logGroups = previous, previousGroups }()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GITHUB_ACTIONS", "")
+			t.Setenv("DRONE", "")
+			t.Setenv("HARNESS_BUILD_ID", "")
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			recorder := newRecordingLogger()
+			logger = recorder
+
+			configureLogGroups(Args{LogGroups: tc.setting})
+			startLogGroup("Summary")()
+
+			if diff := cmp.Diff(tc.expected, *recorder.messages); diff != "" {
+				t.Errorf("Markers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
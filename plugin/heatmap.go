@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// buildHeatmap builds the feature by build matrix of the last n entries.
+func buildHeatmap(entries []HistoryEntry, n int) Heatmap {
+	entries = recentEntries(entries, n)
+
+	heatmap := Heatmap{}
+	rows := make(map[string]*HeatmapFeature)
+	for i, entry := range entries {
+		heatmap.Builds = append(heatmap.Builds, entry.BuildNumber)
+
+		failed := make(map[string]bool)
+		for _, failure := range entry.Failures {
+			failed[failureKey(failure.Feature, failure.Scenario)] = true
+		}
+
+		for _, scenario := range entry.Scenarios {
+			row, ok := rows[scenario.Feature]
+			if !ok {
+				row = &HeatmapFeature{
+					Feature: scenario.Feature,
+					Cells:   make([]HeatmapCell, len(entries)),
+				}
+				rows[scenario.Feature] = row
+			}
+
+			cell := &row.Cells[i]
+			cell.Scenarios++
+			if failed[failureKey(scenario.Feature, scenario.Scenario)] {
+				cell.Failed++
+			}
+		}
+	}
+
+	for _, row := range rows {
+		for i := range row.Cells {
+			cell := &row.Cells[i]
+			cell.Build = heatmap.Builds[i]
+			switch {
+			case cell.Scenarios == 0:
+				cell.Status = ""
+			case cell.Failed > 0:
+				cell.Status = "failed"
+				row.FailedBuilds++
+			default:
+				cell.Status = "passed"
+			}
+		}
+		heatmap.Features = append(heatmap.Features, *row)
+	}
+
+	// Chronically failing features first
+	sort.Slice(heatmap.Features, func(i, j int) bool {
+		a, b := heatmap.Features[i], heatmap.Features[j]
+		if a.FailedBuilds != b.FailedBuilds {
+			return a.FailedBuilds > b.FailedBuilds
+		}
+		return a.Feature < b.Feature
+	})
+
+	return heatmap
+}
+
+// writeHeatmap writes the heatmap as CSV if the path has a .csv extension, otherwise as JSON.
+func writeHeatmap(path string, heatmap Heatmap) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return writeHeatmapCSV(path, heatmap)
+	}
+
+	content, err := json.MarshalIndent(heatmap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// writeHeatmapCSV writes one row per feature with the number of failed scenarios per build.
+// Builds in which the feature did not run are left empty.
+func writeHeatmapCSV(path string, heatmap Heatmap) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(append([]string{"feature"}, heatmap.Builds...))
+	for _, row := range heatmap.Features {
+		record := []string{row.Feature}
+		for _, cell := range row.Cells {
+			if cell.Scenarios == 0 {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, strconv.Itoa(cell.Failed))
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
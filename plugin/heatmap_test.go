@@ -0,0 +1,47 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBuildHeatmap tests the feature by build matrix and its CSV export
+func TestBuildHeatmap(t *testing.T) {
+	entries := []HistoryEntry{
+		{
+			BuildNumber: "1",
+			Scenarios:   []HistoryScenario{{Feature: "Search", Scenario: "A"}, {Feature: "Checkout", Scenario: "B"}},
+			Failures:    []HistoryFailure{{Feature: "Checkout", Scenario: "B"}},
+		},
+		{
+			BuildNumber: "2",
+			Scenarios:   []HistoryScenario{{Feature: "Checkout", Scenario: "B"}, {Feature: "Checkout", Scenario: "C"}},
+			Failures:    []HistoryFailure{{Feature: "Checkout", Scenario: "B"}, {Feature: "Checkout", Scenario: "C"}},
+		},
+	}
+
+	heatmap := buildHeatmap(entries, 10)
+
+	if len(heatmap.Builds) != 2 || len(heatmap.Features) != 2 {
+		t.Fatalf("Unexpected heatmap dimensions: %+v", heatmap)
+	}
+	checkout := heatmap.Features[0]
+	if checkout.Feature != "Checkout" || checkout.FailedBuilds != 2 || checkout.Cells[1].Failed != 2 {
+		t.Errorf("Unexpected checkout row: %+v", checkout)
+	}
+	search := heatmap.Features[1]
+	if search.Cells[0].Status != "passed" || search.Cells[1].Status != "" {
+		t.Errorf("Unexpected search row: %+v", search)
+	}
+
+	path := filepath.Join(t.TempDir(), "heatmap.csv")
+	if err := writeHeatmap(path, heatmap); err != nil {
+		t.Fatalf("Unexpected error writing heatmap: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	expected := "feature,1,2\nCheckout,1,2\nSearch,0,\n"
+	if string(content) != expected {
+		t.Errorf("Expected CSV %q, got %q", expected, string(content))
+	}
+}
@@ -0,0 +1,200 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// loadHistory reads the history file. A missing file results in an empty history.
+func loadHistory(path string) (*History, error) {
+	history := &History{}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, err
+	}
+
+	if len(content) == 0 {
+		return history, nil
+	}
+
+	if err := json.Unmarshal(content, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// save writes the history file, creating the parent directory if required.
+func (h *History) save(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// branchEntries returns the entries recorded for the given branch, oldest first.
+func (h *History) branchEntries(branch string) []HistoryEntry {
+	var entries []HistoryEntry
+	for _, entry := range h.Entries {
+		if entry.Branch == branch {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// recentEntries returns the last n entries, or the default number of builds if n is not set.
+func recentEntries(entries []HistoryEntry, n int) []HistoryEntry {
+	if n <= 0 {
+		n = defaultTrendBuilds
+	}
+	return lastEntries(entries, n)
+}
+
+// lastEntries returns at most the last n entries.
+func lastEntries(entries []HistoryEntry, n int) []HistoryEntry {
+	if n <= 0 {
+		return nil
+	}
+	if len(entries) > n {
+		return entries[len(entries)-n:]
+	}
+	return entries
+}
+
+// add appends an entry to the history.
+func (h *History) add(entry HistoryEntry) {
+	h.Entries = append(h.Entries, entry)
+}
+
+// prune removes entries and timings older than maxAgeDays and keeps at most maxBuilds
+// entries per branch. A limit of zero disables it. It returns the number of removed entries.
+func (h *History) prune(maxBuilds, maxAgeDays int, now time.Time) int {
+	cutoff := int64(0)
+	if maxAgeDays > 0 {
+		cutoff = now.AddDate(0, 0, -maxAgeDays).Unix()
+	}
+
+	// Count the entries per branch so that the oldest ones can be dropped
+	remaining := make(map[string]int)
+	for _, entry := range h.Entries {
+		if entry.Timestamp >= cutoff {
+			remaining[entry.Branch]++
+		}
+	}
+
+	kept := h.Entries[:0]
+	for _, entry := range h.Entries {
+		if entry.Timestamp < cutoff {
+			continue
+		}
+		if maxBuilds > 0 && remaining[entry.Branch] > maxBuilds {
+			remaining[entry.Branch]--
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	removed := len(h.Entries) - len(kept)
+	h.Entries = kept
+
+	// Forget the timings of the scenarios not executed since the cutoff
+	for id, timing := range h.Timings {
+		if timing.LastSeen < cutoff {
+			delete(h.Timings, id)
+		}
+	}
+
+	// Forget the unimplemented steps of the branches not built since the cutoff
+	pending := h.PendingSteps[:0]
+	for _, step := range h.PendingSteps {
+		if step.LastSeen >= cutoff {
+			pending = append(pending, step)
+		}
+	}
+	h.PendingSteps = pending
+	return removed
+}
+
+// newHistoryEntry creates a history entry for the current build.
+func newHistoryEntry(results Results, args Args) HistoryEntry {
+	passRate := 0.0
+	if results.ScenarioCount > 0 {
+		passRate = float64(results.TotalPassedScenarios) / float64(results.ScenarioCount) * 100
+	}
+
+	return HistoryEntry{
+		Branch:          currentBranch(args),
+		BuildNumber:     currentBuildNumber(),
+		Timestamp:       time.Now().Unix(),
+		TotalScenarios:  results.ScenarioCount,
+		FailedScenarios: results.TotalFailedScenarios,
+		PassRate:        passRate,
+		DurationMS:      results.DurationMS,
+		Retries:         results.TotalRetries,
+		Failures:        failureRecords(results),
+		Scenarios:       historyScenarios(results.Scenarios),
+		Tags:            historyTags(results.Scenarios),
+	}
+}
+
+// historyScenarios returns the scenario durations to store in the history file.
+func historyScenarios(scenarios []ScenarioResult) []HistoryScenario {
+	var records []HistoryScenario
+	for _, scenario := range scenarios {
+		records = append(records, HistoryScenario{
+			Feature:    scenario.Feature,
+			Scenario:   scenario.Scenario,
+			DurationMS: scenario.DurationMS,
+		})
+	}
+	return records
+}
+
+// openHistory loads the history file, starting a new history if it cannot be read.
+func openHistory(path string) *History {
+	history, err := loadHistory(path)
+	if err != nil {
+		logger.Warnf("Failed to load history file %s, starting a new history: %v", path, err)
+		return &History{}
+	}
+	return history
+}
+
+// recordHistory logs and exports the trend of the recent builds, records the current
+// build and returns the trend.
+func recordHistory(history *History, results Results, args Args) Trend {
+	entry := newHistoryEntry(results, args)
+	trend := computeTrend(history.branchEntries(entry.Branch), entry, args.TrendBuilds)
+	logTrend(trend)
+	writeTrendStats(trend, logger)
+
+	history.add(entry)
+	if args.TimingsFile != "" {
+		history.mergeTimings(scenarioTimings(results.Scenarios), args.TimingsDecay, time.Now())
+	}
+	if removed := history.prune(args.HistoryMaxBuilds, args.HistoryMaxAgeDays, time.Now()); removed > 0 {
+		logger.Infof("Pruned %d entries from history file %s\n", removed, args.HistoryFile)
+	}
+	if err := history.save(args.HistoryFile); err != nil {
+		logger.Warnf("Failed to save history file %s: %v", args.HistoryFile, err)
+	}
+	return trend
+}
@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestHistoryRoundTrip tests saving and loading the history file
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "history.json")
+
+	history, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading missing history: %v", err)
+	}
+	if len(history.Entries) != 0 {
+		t.Fatalf("Expected empty history, got %d entries", len(history.Entries))
+	}
+
+	history.add(HistoryEntry{Branch: "main", BuildNumber: "1", PassRate: 90})
+	history.add(HistoryEntry{Branch: "feature", BuildNumber: "2", PassRate: 50})
+	history.add(HistoryEntry{Branch: "main", BuildNumber: "3", PassRate: 100})
+	if err := history.save(path); err != nil {
+		t.Fatalf("Unexpected error saving history: %v", err)
+	}
+
+	loaded, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading history: %v", err)
+	}
+	if diff := cmp.Diff(history, loaded); diff != "" {
+		t.Errorf("History mismatch (-want +got):\n%s", diff)
+	}
+
+	main := loaded.branchEntries("main")
+	if len(main) != 2 || main[0].BuildNumber != "1" || main[1].BuildNumber != "3" {
+		t.Errorf("Unexpected entries for branch main: %v", main)
+	}
+}
+
+// TestComputeTrend tests the trend computation over previous builds
+func TestComputeTrend(t *testing.T) {
+	previous := []HistoryEntry{
+		{BuildNumber: "1", PassRate: 50},
+		{BuildNumber: "2", PassRate: 80},
+		{BuildNumber: "3", PassRate: 90},
+	}
+
+	tests := []struct {
+		name      string
+		current   float64
+		builds    int
+		entries   int
+		average   float64
+		direction string
+	}{
+		{
+			name:      "Improved",
+			current:   100,
+			builds:    2,
+			entries:   2,
+			average:   85,
+			direction: TrendImproved,
+		},
+		{
+			name:      "Regressed",
+			current:   60,
+			builds:    10,
+			entries:   3,
+			average:   (50 + 80 + 90) / 3.0,
+			direction: TrendRegressed,
+		},
+		{
+			name:      "Stable",
+			current:   90,
+			builds:    1,
+			entries:   1,
+			average:   90,
+			direction: TrendStable,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			trend := computeTrend(previous, HistoryEntry{PassRate: tc.current}, tc.builds)
+			if len(trend.Entries) != tc.entries {
+				t.Errorf("Expected %d entries, got %d", tc.entries, len(trend.Entries))
+			}
+			if trend.AveragePassRate != tc.average {
+				t.Errorf("Expected average %.2f, got %.2f", tc.average, trend.AveragePassRate)
+			}
+			if trend.Direction != tc.direction {
+				t.Errorf("Expected direction %s, got %s", tc.direction, trend.Direction)
+			}
+		})
+	}
+}
+
+// TestHistoryPrune tests the retention of history entries per branch
+func TestHistoryPrune(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	day := int64(24 * 60 * 60)
+
+	history := &History{
+		Entries: []HistoryEntry{
+			{Branch: "main", BuildNumber: "1", Timestamp: now.Unix() - 40*day},
+			{Branch: "main", BuildNumber: "2", Timestamp: now.Unix() - 3*day},
+			{Branch: "dev", BuildNumber: "3", Timestamp: now.Unix() - 2*day},
+			{Branch: "main", BuildNumber: "4", Timestamp: now.Unix() - 2*day},
+			{Branch: "main", BuildNumber: "5", Timestamp: now.Unix() - day},
+		},
+	}
+
+	removed := history.prune(2, 30, now)
+
+	if removed != 2 {
+		t.Errorf("Expected 2 removed entries, got %d", removed)
+	}
+	var builds []string
+	for _, entry := range history.Entries {
+		builds = append(builds, entry.BuildNumber)
+	}
+	if diff := cmp.Diff([]string{"3", "4", "5"}, builds); diff != "" {
+		t.Errorf("Remaining builds mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"encoding/base64"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// imageMimeType matches the media types of the images inlined in the HTML report.
+var imageMimeType = regexp.MustCompile(`^image/[a-z0-9.+-]+$`)
+
+// FeatureBreakdown represents the scenarios of a feature in the HTML report.
+type FeatureBreakdown struct {
+	Name            string
+	Scenarios       []ScenarioResult
+	FailedScenarios int
+	DurationMS      float64
+}
+
+// featureBreakdown groups the scenarios by feature, in the order the features are first
+// reported.
+func featureBreakdown(scenarios []ScenarioResult) []FeatureBreakdown {
+	var features []FeatureBreakdown
+	indexes := make(map[string]int)
+	for _, scenario := range scenarios {
+		index, ok := indexes[scenario.Feature]
+		if !ok {
+			index = len(features)
+			indexes[scenario.Feature] = index
+			features = append(features, FeatureBreakdown{Name: scenario.Feature})
+		}
+		feature := &features[index]
+		feature.Scenarios = append(feature.Scenarios, scenario)
+		feature.DurationMS += scenario.DurationMS
+		if scenario.Status == "failed" {
+			feature.FailedScenarios++
+		}
+	}
+	return features
+}
+
+// failureScreenshots returns the image embeddings of the failed step and of the after
+// hooks of its scenario, where the screenshots are usually taken.
+func failureScreenshots(element Element, step Step) []Embedding {
+	var screenshots []Embedding
+	for _, steps := range [][]Step{{step}, element.After} {
+		for _, step := range steps {
+			for _, embedding := range step.Embeddings {
+				if imageMimeType.MatchString(strings.ToLower(embedding.MimeType)) {
+					screenshots = append(screenshots, embedding)
+				}
+			}
+		}
+	}
+	return screenshots
+}
+
+// imageDataURI returns the image embedding as a data: URI, or an empty URL when its
+// data is not base64 encoded.
+func imageDataURI(embedding Embedding) htmltemplate.URL {
+	mimeType := strings.ToLower(embedding.MimeType)
+	if !imageMimeType.MatchString(mimeType) {
+		return ""
+	}
+	if _, err := base64.StdEncoding.DecodeString(embedding.Data); err != nil {
+		return ""
+	}
+	return htmltemplate.URL("data:" + mimeType + ";base64," + embedding.Data)
+}
+
+// writeHTMLReport writes the results as a self-contained HTML page, with its styles and
+// charts inlined so that it can be attached to the build as a single file.
+func writeHTMLReport(path string, results Results, args Args, trend *Trend, gateErr error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	report, err := renderReportTemplate(templateHTMLReport, newReportTemplateData(results, args, trend, gateErr, time.Now()), args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
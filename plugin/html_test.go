@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFeatureBreakdown(t *testing.T) {
+	scenarios := []ScenarioResult{
+		{Feature: "Checkout", Scenario: "Pay by card", Status: "passed", DurationMS: 100},
+		{Feature: "Search", Scenario: "Find a book", Status: "passed", DurationMS: 50},
+		{Feature: "Checkout", Scenario: "Pay by voucher", Status: "failed", DurationMS: 25},
+	}
+	expected := []FeatureBreakdown{
+		{Name: "Checkout", Scenarios: []ScenarioResult{scenarios[0], scenarios[2]}, FailedScenarios: 1, DurationMS: 125},
+		{Name: "Search", Scenarios: []ScenarioResult{scenarios[1]}, DurationMS: 50},
+	}
+	if diff := cmp.Diff(expected, featureBreakdown(scenarios)); diff != "" {
+		t.Errorf("Breakdown mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	results := Results{
+		FeatureCount: 1, ScenarioCount: 2, StepCount: 4, PassedTests: 3, FailedTests: 1, TotalFailedScenarios: 1, TotalPassedScenarios: 1,
+		Scenarios: []ScenarioResult{
+			{Feature: "Checkout", Scenario: "Pay by card", Environment: "chrome 120.0", Status: "passed", DurationMS: 100},
+			{Feature: "Checkout", Scenario: "Pay by voucher", Environment: "chrome 120.0", Status: "failed", DurationMS: 25},
+		},
+		FailedSteps: []FailedStepDetails{{
+			Feature: "Checkout", Scenario: "Pay by voucher", Step: "I pay with a voucher", URI: "features/checkout.feature", StepLine: 12,
+			ErrorMessage: "expected <script>alert(1)</script>", SourceURL: "https://github.com/acme/shop/blob/main/features/checkout.feature#L12",
+			SessionURL: "https://automate.example.com/sessions/42",
+		}},
+	}
+	path := filepath.Join(t.TempDir(), "reports", "report.html")
+
+	err := writeHTMLReport(path, results, Args{FailedScenariosNumber: 0, FailedStepsNumber: 1}, nil, errors.New("failed scenarios exceed the threshold"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report := string(content)
+
+	for _, expected := range []string{
+		`<html lang="en">`,
+		`<a class="skip" href="#main">`,
+		`❌ FAILED</strong>: failed scenarios exceed the threshold`,
+		`role="img" aria-labelledby="status-chart-title"`,
+		`<th scope="row">Failed Steps</th>`,
+		`<summary><span class="failed" aria-hidden="true">❌</span><span class="visually-hidden">Failed:</span> Checkout :: Pay by voucher: I pay with a voucher</summary>`,
+		`<dd>features/checkout.feature:12</dd>`,
+		`<a href="https://github.com/acme/shop/blob/main/features/checkout.feature#L12">`,
+		`<a href="https://automate.example.com/sessions/42">Recorded browser session</a>`,
+		`<pre>expected &lt;script&gt;alert(1)&lt;/script&gt;</pre>`,
+		`<details open>`,
+		`<td>chrome 120.0</td>`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected the report to contain %q", expected)
+		}
+	}
+	if strings.Contains(report, "<script") || strings.Contains(report, "<link") {
+		t.Errorf("Expected a self-contained report without scripts or stylesheets")
+	}
+}
+
+// TestFailureScreenshots tests collecting the images of the failed step and its after hooks
+func TestFailureScreenshots(t *testing.T) {
+	png := Embedding{Data: "iVBORw0KGgo=", MimeType: "image/png", Name: "checkout.png"}
+	element := Element{After: []Step{{Embeddings: []Embedding{png, {Data: "bG9n", MimeType: "text/plain"}}}}}
+	step := Step{Embeddings: []Embedding{{Data: "/9j/4A==", MimeType: "IMAGE/JPEG"}}}
+
+	screenshots := failureScreenshots(element, step)
+	if diff := cmp.Diff([]Embedding{{Data: "/9j/4A==", MimeType: "IMAGE/JPEG"}, png}, screenshots); diff != "" {
+		t.Errorf("Screenshots mismatch (-want +got):\n%s", diff)
+	}
+
+	tests := []struct {
+		embedding Embedding
+		want      string
+	}{
+		{png, "data:image/png;base64,iVBORw0KGgo="},
+		{Embedding{Data: "/9j/4A==", MimeType: "IMAGE/JPEG"}, "data:image/jpeg;base64,/9j/4A=="},
+		{Embedding{Data: "not base64!", MimeType: "image/png"}, ""},
+		{Embedding{Data: "bG9n", MimeType: "text/html"}, ""},
+	}
+	for _, tc := range tests {
+		if got := string(imageDataURI(tc.embedding)); got != tc.want {
+			t.Errorf("imageDataURI(%+v) = %q, want %q", tc.embedding, got, tc.want)
+		}
+	}
+}
+
+// TestWriteHTMLReportScreenshots tests inlining the screenshots as data URIs
+func TestWriteHTMLReportScreenshots(t *testing.T) {
+	results := Results{FailedSteps: []FailedStepDetails{{
+		Feature: "Checkout", Scenario: "Pay", Step: "I pay",
+		Screenshots: []Embedding{{Data: "iVBORw0KGgo=", MimeType: "image/png", Name: "checkout.png"}},
+	}}}
+	path := filepath.Join(t.TempDir(), "report.html")
+
+	if err := writeHTMLReport(path, results, Args{}, nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `<figure><img src="data:image/png;base64,iVBORw0KGgo=" alt="Screenshot of the failed step"><figcaption>checkout.png</figcaption></figure>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("Expected the report to inline the screenshot, got:\n%s", content)
+	}
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// defaultHTTPTimeout is the timeout of an attempt of a request to an external service.
+const defaultHTTPTimeout = 30 * time.Second
+
+// httpClients holds the HTTP clients by their settings, so that the requests of a run
+// share their client, its connections and the loaded CA bundle.
+var httpClients sync.Map
+
+// httpClientKey returns the settings of the HTTP client of the arguments.
+func httpClientKey(args Args) string {
+	return fmt.Sprintf("%q %q %q %d %d %d %g %d %d", args.Proxy, args.NoProxy, args.CABundle, args.HTTPTimeoutSeconds,
+		args.HTTPMaxAttempts, args.HTTPRetryBackoffMS, args.HTTPRateLimit, args.HTTPCircuitBreakerThreshold, args.HTTPCircuitCooldownSeconds)
+}
+
+// newHTTPClient returns the HTTP client used for requests to external services, built
+// on first use for the settings of the run. It honors the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables, which can be overridden by the plugin proxy settings,
+// trusts the custom CA bundle, and retries, rate limits and times out the requests with
+// the resilience settings.
+func newHTTPClient(args Args) *http.Client {
+	key := httpClientKey(args)
+	if client, ok := httpClients.Load(key); ok {
+		return client.(*http.Client)
+	}
+	client, _ := httpClients.LoadOrStore(key, buildHTTPClient(args))
+	return client.(*http.Client)
+}
+
+// buildHTTPClient builds the HTTP client of the settings.
+func buildHTTPClient(args Args) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc(args)
+
+	if args.CABundle != "" {
+		pool, err := loadCABundle(args.CABundle)
+		if err != nil {
+			logger.Warnf("Failed to load CA bundle %s: %v", args.CABundle, err)
+		} else {
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		}
+	}
+
+	return &http.Client{
+		Transport: newResilientTransport(transport, args),
+	}
+}
+
+// proxyFunc returns the proxy selection of the HTTP transport.
+func proxyFunc(args Args) func(*http.Request) (*url.URL, error) {
+	config := httpproxy.FromEnvironment()
+	if args.Proxy != "" {
+		config.HTTPProxy = args.Proxy
+		config.HTTPSProxy = args.Proxy
+	}
+	if args.NoProxy != "" {
+		config.NoProxy = args.NoProxy
+	}
+
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// loadCABundle returns the system certificate pool extended with the PEM encoded
+// certificates of the bundle.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+	return pool, nil
+}
+
+// validateHTTPArgs checks the proxy and CA bundle settings.
+func validateHTTPArgs(args Args) error {
+	if args.Proxy != "" {
+		if u, err := url.Parse(args.Proxy); err != nil || u.Host == "" {
+			return fmt.Errorf("invalid Proxy value %q. It must be a URL such as http://proxy:3128", args.Proxy)
+		}
+	}
+	if args.CABundle != "" {
+		if _, err := loadCABundle(args.CABundle); err != nil {
+			return fmt.Errorf("invalid CA bundle %s: %v", args.CABundle, err)
+		}
+	}
+	return nil
+}
+
+// postJSON posts the JSON payload to an external service.
+func postJSON(ctx context.Context, args Args, url string, payload interface{}, headers map[string]string) error {
+	return doJSON(ctx, args, http.MethodPost, url, payload, headers, nil)
+}
+
+// doJSON sends a request with an optional JSON payload, whose text fields are scrubbed by
+// the scrub rules, to an external service and decodes the JSON response into out if it is not nil.
+func doJSON(ctx context.Context, args Args, method, url string, payload interface{}, headers map[string]string, out interface{}) error {
+	var content []byte
+	if payload != nil {
+		var err error
+		if content, err = json.Marshal(payload); err != nil {
+			return err
+		}
+		if content, err = newScrubber(args).payload(content); err != nil {
+			return err
+		}
+	}
+	return sendJSON(ctx, args, method, url, content, headers, out)
+}
+
+// sendJSON sends a request with the JSON content, sent as is and omitted when nil, to an
+// external service and decodes the JSON response into out if it is not nil. The callers
+// scrub the content.
+func sendJSON(ctx context.Context, args Args, method, url string, content []byte, headers map[string]string, out interface{}) error {
+	var body io.Reader
+	if content != nil {
+		body = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if content != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := newHTTPClient(args).Do(req)
+	if err != nil {
+		return sendError(req, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(req, resp); err != nil {
+		return err
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package plugin
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProxyFunc tests the proxy selection of the plugin proxy settings
+func TestProxyFunc(t *testing.T) {
+	proxy := proxyFunc(Args{Proxy: "http://proxy.example.com:3128", NoProxy: "internal.example.com"})
+
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "Proxied",
+			url:      "https://hooks.slack.com/services/a",
+			expected: "http://proxy.example.com:3128",
+		},
+		{
+			name:     "No Proxy",
+			url:      "https://internal.example.com/api",
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+			u, err := proxy(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := ""
+			if u != nil {
+				got = u.String()
+			}
+			if got != tc.expected {
+				t.Errorf("Expected proxy %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestValidateHTTPArgs tests validation of the proxy and CA bundle settings
+func TestValidateHTTPArgs(t *testing.T) {
+	invalidBundle := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(invalidBundle, []byte("not a certificate"), 0644)
+
+	if err := validateHTTPArgs(Args{Proxy: "http://proxy:3128"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateHTTPArgs(Args{Proxy: "proxy"}); err == nil {
+		t.Errorf("Expected error for proxy without scheme")
+	}
+	if err := validateHTTPArgs(Args{CABundle: invalidBundle}); err == nil {
+		t.Errorf("Expected error for invalid CA bundle")
+	}
+}
+
+// TestNewHTTPClientShared tests that the requests with the same settings share a client
+func TestNewHTTPClientShared(t *testing.T) {
+	args := Args{HTTPTimeoutSeconds: 7, HTTPMaxAttempts: 2}
+	if newHTTPClient(args) != newHTTPClient(args) {
+		t.Errorf("Expected the same client for the same settings")
+	}
+	args.SlackChannel = "#qa"
+	if newHTTPClient(args) != newHTTPClient(Args{HTTPTimeoutSeconds: 7, HTTPMaxAttempts: 2}) {
+		t.Errorf("Expected the same client for the same HTTP settings")
+	}
+	if newHTTPClient(args) == newHTTPClient(Args{HTTPTimeoutSeconds: 8, HTTPMaxAttempts: 2}) {
+		t.Errorf("Expected another client for other settings")
+	}
+}
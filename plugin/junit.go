@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// buildJUnitReport maps features to test suites and scenarios to test cases.
+func buildJUnitReport(results Results) JUnitTestSuites {
+	failures := make(map[string][]FailedStepDetails)
+	for _, step := range results.FailedSteps {
+		key := failureKey(step.Feature, step.Scenario)
+		failures[key] = append(failures[key], step)
+	}
+
+	report := JUnitTestSuites{Name: "Cucumber"}
+	suites := make(map[string]int)
+	durations := make(map[string]float64)
+	for _, scenario := range results.Scenarios {
+		index, ok := suites[scenario.Feature]
+		if !ok {
+			index = len(report.Suites)
+			suites[scenario.Feature] = index
+			report.Suites = append(report.Suites, JUnitTestSuite{Name: scenario.Feature})
+		}
+		suite := &report.Suites[index]
+
+		testCase := JUnitTestCase{
+			ClassName: scenario.Feature,
+			Name:      scenario.Scenario,
+			Time:      formatJUnitSeconds(scenario.DurationMS),
+		}
+
+		switch scenario.Status {
+		case "failed":
+			steps := failures[failureKey(scenario.Feature, scenario.Scenario)]
+			testCase.Failure = junitFailure(steps)
+			suite.Failures++
+			report.Failures++
+		case "skipped", "pending", "undefined":
+			testCase.Skipped = &JUnitSkipped{Message: scenario.Status}
+			suite.Skipped++
+			report.Skipped++
+		}
+
+		suite.Tests++
+		durations[scenario.Feature] += scenario.DurationMS
+		suite.Time = formatJUnitSeconds(durations[scenario.Feature])
+		suite.TestCases = append(suite.TestCases, testCase)
+		report.Tests++
+	}
+	report.Time = formatJUnitSeconds(results.DurationMS)
+
+	return report
+}
+
+// junitFailure describes the failed steps of a scenario.
+func junitFailure(steps []FailedStepDetails) *JUnitFailure {
+	failure := &JUnitFailure{Type: "failed"}
+	var details []string
+	for _, step := range steps {
+		if failure.Message == "" {
+			failure.Message = step.ErrorMessage
+		}
+		detail := "Step: " + step.Step
+		if location := failureLocation(step); location != "" {
+			detail += fmt.Sprintf("\nLocation: %s (scenario line %d)", location, step.ScenarioLine)
+		}
+		if step.SourceURL != "" {
+			detail += "\nSource: " + step.SourceURL
+		}
+		details = append(details, detail+"\n"+step.ErrorMessage)
+	}
+	failure.Contents = strings.Join(details, "\n\n")
+	return failure
+}
+
+// formatJUnitSeconds formats a duration in milliseconds as seconds.
+func formatJUnitSeconds(durationMS float64) string {
+	return fmt.Sprintf("%.3f", durationMS/1000)
+}
+
+// junitOutputPaths returns the paths of the JUnit XML reports to write, the generic output
+// and the Harness test report, once each.
+func junitOutputPaths(args Args) []string {
+	var paths []string
+	for _, path := range []string{args.JUnitOutputPath, args.HarnessTestReportPath} {
+		if path != "" && (len(paths) == 0 || paths[0] != path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// writeJUnitReport writes the results as a JUnit XML report.
+func writeJUnitReport(path string, results Results) error {
+	content, err := xml.MarshalIndent(buildJUnitReport(results), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), content...), 0644)
+}
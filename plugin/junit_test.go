@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWriteJUnitReport tests mapping features to test suites and scenarios to test cases
+func TestWriteJUnitReport(t *testing.T) {
+	results, err := processFile("../testdata/cucumber_report.json", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error processing file: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	if err := writeJUnitReport(path, results); err != nil {
+		t.Fatalf("Unexpected error writing report: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report JUnitTestSuites
+	if err := xml.Unmarshal(content, &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+
+	if report.Tests != 4 || report.Failures != 3 || len(report.Suites) != 2 {
+		t.Errorf("Unexpected report totals: tests=%d failures=%d suites=%d", report.Tests, report.Failures, len(report.Suites))
+	}
+	suite := report.Suites[1]
+	if suite.Name != "Payment Gateway" || suite.Tests != 2 || suite.Failures != 1 {
+		t.Errorf("Unexpected suite: %+v", suite)
+	}
+	failed := suite.TestCases[1]
+	if failed.Failure == nil || failed.Failure.Message != "Payment details are invalid." {
+		t.Errorf("Expected failure details for %s, got %+v", failed.Name, failed.Failure)
+	}
+}
+
+func TestJUnitOutputPaths(t *testing.T) {
+	tests := []struct {
+		args     Args
+		expected []string
+	}{
+		{Args{}, nil},
+		{Args{JUnitOutputPath: "junit.xml"}, []string{"junit.xml"}},
+		{Args{JUnitOutputPath: "junit.xml", HarnessTestReportPath: "harness.xml"}, []string{"junit.xml", "harness.xml"}},
+		{Args{JUnitOutputPath: "junit.xml", HarnessTestReportPath: "junit.xml"}, []string{"junit.xml"}},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.expected, junitOutputPaths(tt.args)); diff != "" {
+			t.Errorf("junitOutputPaths(%+v) mismatch (-want +got):\n%s", tt.args, diff)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// karateFeature represents a feature result of the Karate JSON report.
+type karateFeature struct {
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	RelativePath    string           `json:"relativePath"`
+	ScenarioResults []karateScenario `json:"scenarioResults"`
+}
+
+// karateScenario represents a scenario result of the Karate JSON report.
+type karateScenario struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Line         int          `json:"line"`
+	SectionIndex int          `json:"sectionIndex"`
+	ExampleIndex int          `json:"exampleIndex"`
+	Tags         []string     `json:"tags"`
+	Error        string       `json:"error"`
+	StepResults  []karateStep `json:"stepResults"`
+}
+
+// karateStep represents a step result of the Karate JSON report. Unlike the Cucumber
+// JSON format, the durations are expressed in milliseconds.
+type karateStep struct {
+	Step struct {
+		Line   int    `json:"line"`
+		Prefix string `json:"prefix"`
+		Text   string `json:"text"`
+	} `json:"step"`
+	Result struct {
+		Status       string  `json:"status"`
+		Millis       float64 `json:"millis"`
+		Nanos        int64   `json:"nanos"`
+		ErrorMessage string  `json:"errorMessage"`
+	} `json:"result"`
+}
+
+// isKarateReport reports whether the content is a Karate JSON feature result or an
+// array of them.
+func isKarateReport(content []byte) bool {
+	return hasJSONKey(content, "scenarioResults") || isKarateArray(content)
+}
+
+// decodeKarateReport decodes a Karate JSON feature result, or an array of them, into
+// Cucumber features. Karate reports written in the Cucumber JSON format are decoded
+// as such.
+func decodeKarateReport(content []byte) ([]Feature, error) {
+	var karateFeatures []karateFeature
+	if firstByte(content) == '[' {
+		if !isKarateArray(content) {
+			return decodeCucumberReport(content)
+		}
+		if err := json.Unmarshal(content, &karateFeatures); err != nil {
+			return nil, err
+		}
+	} else {
+		var feature karateFeature
+		if err := json.Unmarshal(content, &feature); err != nil {
+			return nil, err
+		}
+		karateFeatures = append(karateFeatures, feature)
+	}
+
+	features := make([]Feature, 0, len(karateFeatures))
+	for _, karate := range karateFeatures {
+		features = append(features, karateToFeature(karate))
+	}
+	return features, nil
+}
+
+// isKarateArray reports whether the content is an array of Karate JSON feature results.
+func isKarateArray(content []byte) bool {
+	first, ok := firstArrayElement(content)
+	return ok && hasJSONKey(first, "scenarioResults")
+}
+
+// karateToFeature maps a Karate feature result to a Cucumber feature.
+func karateToFeature(karate karateFeature) Feature {
+	uri := strings.TrimPrefix(karate.RelativePath, "classpath:")
+	feature := Feature{
+		ID:          uri,
+		URI:         uri,
+		Keyword:     "Feature",
+		Name:        karate.Name,
+		Description: karate.Description,
+		Line:        1,
+	}
+
+	for _, scenario := range karate.ScenarioResults {
+		element := Element{
+			ID:          fmt.Sprintf("%s;%d-%d", uri, scenario.SectionIndex, scenario.ExampleIndex),
+			Keyword:     "Scenario",
+			Name:        scenario.Name,
+			Description: scenario.Description,
+			Line:        scenario.Line,
+			Type:        "scenario",
+		}
+		for _, tag := range scenario.Tags {
+			if !strings.HasPrefix(tag, "@") {
+				tag = "@" + tag
+			}
+			element.Tags = append(element.Tags, Tag{Name: tag})
+		}
+
+		for _, karateStep := range scenario.StepResults {
+			duration := karateStep.Result.Nanos
+			if duration == 0 {
+				duration = int64(karateStep.Result.Millis * 1e6)
+			}
+			step := Step{
+				Keyword: karateStep.Step.Prefix + " ",
+				Name:    karateStep.Step.Text,
+				Line:    karateStep.Step.Line,
+				Result: Result{
+					Status:       karateStep.Result.Status,
+					Duration:     duration,
+					ErrorMessage: karateStep.Result.ErrorMessage,
+				},
+			}
+			if step.Result.Status == "failed" && step.Result.ErrorMessage == "" {
+				step.Result.ErrorMessage = scenario.Error
+			}
+			element.Steps = append(element.Steps, step)
+		}
+		feature.Elements = append(feature.Elements, element)
+	}
+	return feature
+}
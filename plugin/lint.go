@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Lint rules of the scenario anti-patterns visible in the reports
+const (
+	LintThenBeforeWhen = "then-before-when"
+	LintRepeatedStep   = "repeated-step"
+	LintNoThen         = "no-then"
+)
+
+// maxLintWarningsLogged is the number of lint warnings listed in the log, the others are
+// only written to the lint report.
+const maxLintWarningsLogged = 10
+
+// lintEnabled reports whether the scenarios are linted.
+func lintEnabled(args Args) bool {
+	return args.LintReport != "" || args.FailOnLintWarnings
+}
+
+// lintScenario flags the anti-patterns of a scenario: a Then step before a When step,
+// consecutive identical steps and a scenario without any Then step. And, But and * steps
+// take the keyword of the previous step, and scenarios using other keywords than Given,
+// When and Then, such as translated ones, are not linted.
+func lintScenario(feature Feature, element Element) []LintWarning {
+	if element.Type == "background" || len(element.Steps) == 0 {
+		return nil
+	}
+
+	warning := func(rule string, line int, message string) LintWarning {
+		return LintWarning{Rule: rule, Feature: feature.Name, Scenario: element.Name, URI: feature.URI, Line: line, Message: message}
+	}
+
+	var warnings []LintWarning
+	keyword, thenLine, hasThen, thenBeforeWhen := "", 0, false, false
+	for i, step := range element.Steps {
+		keyword = primaryKeyword(keyword, step.Keyword)
+		switch keyword {
+		case "Given":
+		case "When":
+			if hasThen && !thenBeforeWhen {
+				thenBeforeWhen = true
+				warnings = append(warnings, warning(LintThenBeforeWhen, thenLine, fmt.Sprintf("Then step on line %d comes before the When step %q", thenLine, step.Name)))
+			}
+		case "Then":
+			if !hasThen {
+				thenLine = step.Line
+			}
+			hasThen = true
+		default:
+			return nil
+		}
+
+		// A step executed again on the same line after failing is a retry rather than a repetition
+		if i > 0 {
+			previous := element.Steps[i-1]
+			if previous.Name == step.Name && previous.Line != step.Line {
+				warnings = append(warnings, warning(LintRepeatedStep, step.Line, fmt.Sprintf("step %q repeats the previous step", step.Name)))
+			}
+		}
+	}
+	if !hasThen {
+		warnings = append(warnings, warning(LintNoThen, element.Line, "scenario has no Then step checking an outcome"))
+	}
+	return warnings
+}
+
+// logLintWarnings logs the first lint warnings and their total.
+func logLintWarnings(warnings []LintWarning, args Args) {
+	if !lintEnabled(args) {
+		return
+	}
+	if len(warnings) == 0 {
+		logger.Infof("🧹 No scenario anti-pattern found\n")
+		return
+	}
+
+	logger.Infof("🧹 %d scenario anti-patterns found:\n", len(warnings))
+	for i, warning := range warnings {
+		if i == maxLintWarningsLogged {
+			logger.Infof("   …and %d more\n", len(warnings)-maxLintWarningsLogged)
+			break
+		}
+		logger.Infof("   [%s] %s :: %s (%s:%d): %s\n", warning.Rule, warning.Feature, warning.Scenario, warning.URI, warning.Line, warning.Message)
+	}
+}
+
+// validateLintWarnings returns an error if the scenarios have anti-patterns and the lint
+// warnings fail the build.
+func validateLintWarnings(results Results, args Args) error {
+	if !args.FailOnLintWarnings || len(results.LintWarnings) == 0 {
+		return nil
+	}
+	first := results.LintWarnings[0]
+	return fmt.Errorf("%d scenario anti-patterns found. First: %s in %s :: %s", len(results.LintWarnings), first.Rule, first.Feature, first.Scenario)
+}
+
+// writeLintReport writes the lint warnings as Markdown when the path has a .md extension,
+// and as JSON otherwise.
+func writeLintReport(path string, warnings []LintWarning, args Args) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".md") {
+		if warnings == nil {
+			warnings = []LintWarning{}
+		}
+		content, err := json.MarshalIndent(warnings, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0644)
+	}
+
+	report, err := renderReportTemplate(templateLint, struct{ Warnings []LintWarning }{warnings}, args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
+
+// writeLintStats writes the number of lint warnings to the output file.
+func writeLintStats(results Results, args Args, log Logger) {
+	if !lintEnabled(args) {
+		return
+	}
+	if err := WriteEnvToFile("LINT_WARNINGS", strconv.Itoa(len(results.LintWarnings)), log); err != nil {
+		log.Errorf("Error writing LINT_WARNINGS: %s", err)
+	}
+}
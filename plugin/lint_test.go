@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestLintScenario tests flagging the scenario anti-patterns
+func TestLintScenario(t *testing.T) {
+	feature := Feature{Name: "Checkout", URI: "features/checkout.feature"}
+	step := func(keyword, name string, line int) Step {
+		return Step{Keyword: keyword + " ", Name: name, Line: line}
+	}
+
+	tests := []struct {
+		name     string
+		element  Element
+		expected []LintWarning
+	}{
+		{
+			name:    "well formed",
+			element: Element{Name: "Pay", Line: 3, Steps: []Step{step("Given", "a cart", 4), step("When", "I pay", 5), step("Then", "the order is placed", 6), step("And", "a receipt is sent", 7)}},
+		},
+		{
+			name:    "then before when",
+			element: Element{Name: "Pay", Line: 3, Steps: []Step{step("Given", "a cart", 4), step("Then", "the total is shown", 5), step("When", "I pay", 6), step("Then", "the order is placed", 7)}},
+			expected: []LintWarning{
+				{Rule: LintThenBeforeWhen, Feature: "Checkout", Scenario: "Pay", URI: "features/checkout.feature", Line: 5, Message: `Then step on line 5 comes before the When step "I pay"`},
+			},
+		},
+		{
+			name:    "repeated step without then",
+			element: Element{Name: "Browse", Line: 9, Steps: []Step{step("Given", "a cart", 10), step("When", "I scroll", 11), step("And", "I scroll", 12)}},
+			expected: []LintWarning{
+				{Rule: LintRepeatedStep, Feature: "Checkout", Scenario: "Browse", URI: "features/checkout.feature", Line: 12, Message: `step "I scroll" repeats the previous step`},
+				{Rule: LintNoThen, Feature: "Checkout", Scenario: "Browse", URI: "features/checkout.feature", Line: 9, Message: "scenario has no Then step checking an outcome"},
+			},
+		},
+		{
+			name:    "retried step",
+			element: Element{Name: "Pay", Line: 3, Steps: []Step{step("When", "I pay", 4), step("When", "I pay", 4), step("Then", "the order is placed", 5)}},
+		},
+		{
+			name:    "background",
+			element: Element{Type: "background", Steps: []Step{step("Given", "a cart", 2)}},
+		},
+		{
+			name:    "translated keywords",
+			element: Element{Name: "Payer", Steps: []Step{step("Soit", "un panier", 2)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, lintScenario(feature, tt.element)); diff != "" {
+				t.Errorf("Lint warnings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestWriteLintReport tests writing the lint warnings and failing the gate on them
+func TestWriteLintReport(t *testing.T) {
+	warnings := []LintWarning{{Rule: LintNoThen, Feature: "Checkout", Scenario: "Browse | scroll", URI: "features/checkout.feature", Line: 9, Message: "scenario has no Then step checking an outcome"}}
+	path := filepath.Join(t.TempDir(), "lint.md")
+	if err := writeLintReport(path, warnings, Args{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), `| no-then | Checkout :: Browse \| scroll | features/checkout.feature:9 | scenario has no Then step checking an outcome |`) {
+		t.Errorf("Unexpected lint report:\n%s", content)
+	}
+
+	results := Results{LintWarnings: warnings}
+	if err := validateLintWarnings(results, Args{LintReport: path}); err != nil {
+		t.Errorf("Expected the warnings not to fail the build, got %v", err)
+	}
+	if err := validateLintWarnings(results, Args{FailOnLintWarnings: true}); err == nil || !strings.Contains(err.Error(), "1 scenario anti-patterns found") {
+		t.Errorf("Expected the warnings to fail the build, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import "github.com/sirupsen/logrus"
+
+// Logger is the logging interface used by the plugin. Embedders can route the logs to
+// their own logger with SetLogger.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	WithFields(fields map[string]interface{}) Logger
+}
+
+// logger is the Logger used by the plugin, logging to the standard logrus logger by default.
+var logger Logger = NewLogrusLogger(logrus.StandardLogger())
+
+// SetLogger replaces the Logger used by the plugin.
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// logrusLogger adapts a logrus entry to the Logger interface.
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// NewLogrusLogger returns a Logger writing to the logrus logger.
+func NewLogrusLogger(l *logrus.Logger) Logger {
+	return logrusLogger{entry: logrus.NewEntry(l)}
+}
+
+// Debugf logs a message at the debug level.
+func (l logrusLogger) Debugf(format string, args ...interface{}) {
+	l.entry.Debugf(format, args...)
+}
+
+// Infof logs a message at the info level.
+func (l logrusLogger) Infof(format string, args ...interface{}) {
+	l.entry.Infof(format, args...)
+}
+
+// Warnf logs a message at the warning level.
+func (l logrusLogger) Warnf(format string, args ...interface{}) {
+	l.entry.Warnf(format, args...)
+}
+
+// Errorf logs a message at the error level.
+func (l logrusLogger) Errorf(format string, args ...interface{}) {
+	l.entry.Errorf(format, args...)
+}
+
+// WithFields returns a Logger adding the fields to the messages.
+func (l logrusLogger) WithFields(fields map[string]interface{}) Logger {
+	return logrusLogger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
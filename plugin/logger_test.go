@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingLogger records the logged messages.
+type recordingLogger struct {
+	messages *[]string
+	fields   map[string]interface{}
+}
+
+func newRecordingLogger() recordingLogger {
+	return recordingLogger{messages: &[]string{}}
+}
+
+func (l recordingLogger) record(level, format string, args ...interface{}) {
+	message := level + " " + strings.TrimSpace(fmt.Sprintf(format, args...))
+	for key, value := range l.fields {
+		message += fmt.Sprintf(" %s=%v", key, value)
+	}
+	*l.messages = append(*l.messages, message)
+}
+
+func (l recordingLogger) Debugf(format string, args ...interface{}) {
+	l.record("DEBUG", format, args...)
+}
+func (l recordingLogger) Infof(format string, args ...interface{}) { l.record("INFO", format, args...) }
+func (l recordingLogger) Warnf(format string, args ...interface{}) { l.record("WARN", format, args...) }
+func (l recordingLogger) Errorf(format string, args ...interface{}) {
+	l.record("ERROR", format, args...)
+}
+
+func (l recordingLogger) WithFields(fields map[string]interface{}) Logger {
+	return recordingLogger{messages: l.messages, fields: fields}
+}
+
+// TestSetLogger tests routing the logs to a custom logger
+func TestSetLogger(t *testing.T) {
+	previous := logger
+	recorder := newRecordingLogger()
+	SetLogger(recorder)
+	defer SetLogger(previous)
+
+	results := Results{FeatureCount: 1, ScenarioCount: 2, StepCount: 4, FailedTests: 1}
+	args := Args{FailedStepsNumber: 0, FailedStepsPercentage: 10}
+	if err := evaluateGates(results, args, false); err == nil {
+		t.Fatal("Expected the gates to fail")
+	}
+
+	for _, expected := range []string{
+		"INFO Failed Steps Percentage: 25.00% (Threshold: 10.00%) ❌",
+		"ERROR failed steps percentage (25.00%) exceeds the threshold (10.00%)",
+		"Failed=1",
+	} {
+		found := false
+		for _, message := range *recorder.messages {
+			if strings.Contains(message, expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a log message containing %q, got %v", expected, *recorder.messages)
+		}
+	}
+}
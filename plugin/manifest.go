@@ -0,0 +1,162 @@
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manifestSeparator separates the feature and scenario names of a manifest entry.
+const manifestSeparator = " :: "
+
+// maxListedNames is the number of scenario names listed in an error message.
+const maxListedNames = 10
+
+// Gherkin keywords of the scenarios listed in a manifest generated from feature files.
+var scenarioKeywords = []string{"Scenario Outline:", "Scenario Template:", "Scenario:", "Example:"}
+
+// scenarioIdentifier identifies a scenario in a manifest.
+func scenarioIdentifier(feature, scenario string) string {
+	return strings.TrimSpace(feature) + manifestSeparator + strings.TrimSpace(scenario)
+}
+
+// listNames joins the first names of the list, noting how many more are left out, so
+// that the error messages stay readable when thousands of scenarios are affected.
+func listNames(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s, and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+}
+
+// loadManifest reads the expected scenarios from a manifest file listing one
+// "Feature :: Scenario" identifier per line, or generates them from a .feature file
+// or a directory of .feature files.
+func loadManifest(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		var scenarios []string
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || filepath.Ext(file) != ".feature" {
+				return err
+			}
+			featureScenarios, err := readFeatureFile(file)
+			scenarios = append(scenarios, featureScenarios...)
+			return err
+		})
+		return scenarios, err
+	}
+	if filepath.Ext(path) == ".feature" {
+		return readFeatureFile(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var scenarios []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		feature, scenario, ok := strings.Cut(line, manifestSeparator)
+		if !ok {
+			return nil, fmt.Errorf("invalid manifest entry %q. It must be 'Feature%sScenario'", line, manifestSeparator)
+		}
+		scenarios = append(scenarios, scenarioIdentifier(feature, scenario))
+	}
+	return scenarios, scanner.Err()
+}
+
+// readFeatureFile lists the scenarios of a Gherkin feature file.
+func readFeatureFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseFeatureScenarios(file)
+}
+
+// parseFeatureScenarios lists the scenarios of a Gherkin document written in English,
+// ignoring the doc strings where keywords have no meaning.
+func parseFeatureScenarios(r io.Reader) ([]string, error) {
+	var (
+		feature   string
+		scenarios []string
+		docString string
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if docString != "" {
+			if strings.HasPrefix(line, docString) {
+				docString = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(line, `"""`) || strings.HasPrefix(line, "```") {
+			docString = line[:3]
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(line, "Feature:"); ok {
+			feature = name
+			continue
+		}
+		for _, keyword := range scenarioKeywords {
+			if name, ok := strings.CutPrefix(line, keyword); ok {
+				scenarios = append(scenarios, scenarioIdentifier(feature, name))
+				break
+			}
+		}
+	}
+	return scenarios, scanner.Err()
+}
+
+// missingScenarios returns the expected scenarios absent from the results, in the
+// order of the manifest.
+func missingScenarios(expected []string, results []ScenarioResult) []string {
+	found := make(map[string]bool, len(results))
+	for _, scenario := range results {
+		found[scenarioIdentifier(scenario.Feature, scenario.Scenario)] = true
+	}
+
+	var missing []string
+	for _, scenario := range expected {
+		if !found[scenario] {
+			missing = append(missing, scenario)
+			found[scenario] = true
+		}
+	}
+	return missing
+}
+
+// validateManifest returns an error if scenarios of the manifest are missing from the
+// reports when configured to fail, and only logs them otherwise.
+func validateManifest(results Results, args Args) error {
+	if len(results.MissingScenarios) == 0 {
+		return nil
+	}
+
+	err := wrapError(ErrMissingScenarios, fmt.Errorf("%d scenarios of the manifest are missing from the reports: %s",
+		len(results.MissingScenarios), listNames(results.MissingScenarios, maxListedNames)))
+	if !strings.EqualFold(args.MissingScenariosAction, ActionFail) {
+		logger.Warnf("%s", err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestLoadManifest tests reading the manifest files and generating manifests from feature files
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.txt")
+	features := filepath.Join(dir, "features")
+	if err := os.MkdirAll(filepath.Join(features, "checkout"), 0755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	files := map[string]string{
+		manifest: "# Smoke suite\nLogin :: Valid password\n\nLogin :: Locked account\n",
+		filepath.Join(features, "login.feature"): `@smoke
+Feature: Login
+  Scenario: Valid password
+    Given a user
+    """
+    Scenario: not a scenario
+    """
+  Scenario Outline: Locked account
+    Examples:
+      | attempts |
+      | 3        |
+`,
+		filepath.Join(features, "checkout", "pay.feature"): "Feature: Checkout\n  Rule: Cards\n    Example: Card declined\n",
+		filepath.Join(features, "README.md"):               "Scenario: ignored",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{"Manifest file", manifest, []string{"Login :: Valid password", "Login :: Locked account"}},
+		{"Feature file", filepath.Join(features, "login.feature"), []string{"Login :: Valid password", "Login :: Locked account"}},
+		{"Feature directory", features, []string{"Checkout :: Card declined", "Login :: Valid password", "Login :: Locked account"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scenarios, err := loadManifest(tc.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, scenarios); diff != "" {
+				t.Errorf("Scenarios mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	invalid := filepath.Join(dir, "invalid.txt")
+	if err := os.WriteFile(invalid, []byte("Login - Valid password\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := loadManifest(invalid); err == nil {
+		t.Errorf("Expected an error for an invalid manifest entry")
+	}
+}
+
+// TestValidateManifest tests reporting the scenarios of the manifest missing from the reports
+func TestValidateManifest(t *testing.T) {
+	expected := []string{"Login :: Valid password", "Login :: Locked account", "Checkout :: Card declined", "Login :: Locked account"}
+	scenarios := []ScenarioResult{{Feature: "Login", Scenario: "Valid password"}, {Feature: "Search", Scenario: "Find"}}
+
+	results := Results{MissingScenarios: missingScenarios(expected, scenarios)}
+	if diff := cmp.Diff([]string{"Login :: Locked account", "Checkout :: Card declined"}, results.MissingScenarios); diff != "" {
+		t.Errorf("Missing scenarios mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := validateManifest(results, Args{}); err != nil {
+		t.Errorf("Expected only a warning by default, got %v", err)
+	}
+	if err := validateManifest(results, Args{MissingScenariosAction: "fail"}); !errors.Is(err, ErrMissingScenarios) {
+		t.Errorf("Expected a missing scenarios error, got %v", err)
+	}
+	if err := validateManifest(Results{}, Args{MissingScenariosAction: ActionFail}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestListNames tests bounding the scenario names of the error messages
+func TestListNames(t *testing.T) {
+	names := []string{"A :: 1", "A :: 2", "A :: 3", "A :: 4"}
+	if got := listNames(names, 4); got != "A :: 1, A :: 2, A :: 3, A :: 4" {
+		t.Errorf("Unexpected list: %s", got)
+	}
+	if got := listNames(names, 2); got != "A :: 1, A :: 2, and 2 more" {
+		t.Errorf("Unexpected bounded list: %s", got)
+	}
+}
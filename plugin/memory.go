@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// Levels of optional details dropped as the heap approaches the memory budget. The
+// counts stay accurate at every level, and the failures of the dropped records are
+// still classified and recorded in the history from Results.AllFailures.
+const (
+	degradeNone          = iota
+	degradeEmbeddings    // Outputs and embeddings of the steps are dropped after decoding
+	degradeErrorMessages // Error messages are truncated to their first line
+	degradeStepRecords   // Failed step records beyond degradedMaxFailedSteps are dropped
+)
+
+// degradeThresholds are the fractions of the memory budget at which each level starts.
+var degradeThresholds = []float64{0, 0.70, 0.85, 0.95}
+
+// degradeDescriptions describe the details dropped at each level.
+var degradeDescriptions = []string{
+	"",
+	"dropping the outputs and embeddings of the steps, browser session links may be missing",
+	"truncating the error messages to their first line",
+	fmt.Sprintf("dropping the failed step records beyond the first %d", degradedMaxFailedSteps),
+}
+
+// degradedMaxFailedSteps is the number of failed step records kept at the highest level.
+const degradedMaxFailedSteps = 100
+
+// memoryBudget holds the configured heap budget in bytes and the current level.
+var memoryBudget struct {
+	limit uint64
+	level atomic.Int32
+}
+
+// heapUsage returns the allocated heap in bytes, overridden in tests.
+var heapUsage = func() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// configureMemoryBudget applies the memory budget setting.
+func configureMemoryBudget(args Args) {
+	memoryBudget.limit = uint64(args.MemoryBudgetMB) << 20
+	memoryBudget.level.Store(degradeNone)
+}
+
+// degradeLevel returns the current level without measuring the heap.
+func degradeLevel() int {
+	return int(memoryBudget.level.Load())
+}
+
+// checkMemoryBudget measures the heap, raises the level when it approaches the budget
+// and logs the details dropped from then on. The level never goes down, so that the
+// details of the results are dropped consistently.
+func checkMemoryBudget() int {
+	if memoryBudget.limit == 0 {
+		return degradeNone
+	}
+
+	usage := float64(heapUsage())
+	level := degradeLevel()
+	for level+1 < len(degradeThresholds) && usage >= degradeThresholds[level+1]*float64(memoryBudget.limit) {
+		level++
+	}
+
+	for {
+		current := degradeLevel()
+		if level <= current {
+			return current
+		}
+		if memoryBudget.level.CompareAndSwap(int32(current), int32(level)) {
+			for raised := current + 1; raised <= level; raised++ {
+				logger.Warnf("Heap usage of %d MB is approaching the memory budget of %d MB: %s",
+					uint64(usage)>>20, memoryBudget.limit>>20, degradeDescriptions[raised])
+			}
+			return level
+		}
+	}
+}
+
+// stripStepDetails drops the outputs and embeddings of the steps and hooks of a feature.
+func stripStepDetails(feature *Feature) {
+	for i := range feature.Elements {
+		element := &feature.Elements[i]
+		for _, steps := range [][]Step{element.Before, element.Steps, element.After} {
+			for j := range steps {
+				steps[j].Output = nil
+				steps[j].Embeddings = nil
+			}
+		}
+	}
+}
+
+// degradeResults drops the details of the file results according to the level, given
+// the number of failed step records already retained. It returns the number of
+// dropped failed step records.
+func degradeResults(results *Results, level, retained int) int {
+	if level >= degradeErrorMessages {
+		for i := range results.FailedSteps {
+			results.FailedSteps[i].ErrorMessage = firstLine(results.FailedSteps[i].ErrorMessage)
+		}
+	}
+
+	if level < degradeStepRecords {
+		return 0
+	}
+	keep := degradedMaxFailedSteps - retained
+	if keep < 0 {
+		keep = 0
+	}
+	if len(results.FailedSteps) <= keep {
+		return 0
+	}
+	dropped := len(results.FailedSteps) - keep
+	results.FailedSteps = results.FailedSteps[:keep:keep]
+	return dropped
+}
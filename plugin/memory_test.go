@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestCheckMemoryBudget tests the degradation levels reached as the heap grows
+func TestCheckMemoryBudget(t *testing.T) {
+	previous := heapUsage
+	defer func() { heapUsage = previous }()
+	recorder := newRecordingLogger()
+	previousLogger := logger
+	logger = recorder
+	defer func() { logger = previousLogger }()
+
+	configureMemoryBudget(Args{MemoryBudgetMB: 100})
+	defer configureMemoryBudget(Args{})
+
+	tests := []struct {
+		usageMB  uint64
+		expected int
+	}{
+		{10, degradeNone},
+		{72, degradeEmbeddings},
+		{96, degradeStepRecords},
+		{20, degradeStepRecords}, // The level never goes down
+	}
+	for _, tc := range tests {
+		heapUsage = func() uint64 { return tc.usageMB << 20 }
+		if level := checkMemoryBudget(); level != tc.expected {
+			t.Errorf("Usage of %d MB: expected level %d, got %d", tc.usageMB, tc.expected, level)
+		}
+	}
+
+	if len(*recorder.messages) != 3 {
+		t.Errorf("Expected one warning per level, got %v", *recorder.messages)
+	}
+}
+
+// TestDegradeResults tests that the details are dropped according to the level
+func TestDegradeResults(t *testing.T) {
+	newResults := func(count int) Results {
+		results := Results{FailedTests: count}
+		for i := 0; i < count; i++ {
+			results.FailedSteps = append(results.FailedSteps, FailedStepDetails{Step: fmt.Sprint(i), ErrorMessage: "Timeout\n\tat step.js:12"})
+		}
+		return results
+	}
+
+	results := newResults(3)
+	if dropped := degradeResults(&results, degradeEmbeddings, 0); dropped != 0 || results.FailedSteps[0].ErrorMessage != "Timeout\n\tat step.js:12" {
+		t.Errorf("Expected the details to be kept, got %d dropped and %+v", dropped, results.FailedSteps[0])
+	}
+
+	results = newResults(3)
+	if dropped := degradeResults(&results, degradeErrorMessages, 0); dropped != 0 || results.FailedSteps[0].ErrorMessage != "Timeout" {
+		t.Errorf("Expected the error messages to be truncated, got %d dropped and %+v", dropped, results.FailedSteps[0])
+	}
+
+	results = newResults(5)
+	if dropped := degradeResults(&results, degradeStepRecords, degradedMaxFailedSteps-2); dropped != 3 || len(results.FailedSteps) != 2 || results.FailedTests != 5 {
+		t.Errorf("Expected 2 records kept and accurate counts, got %d dropped and %+v", dropped, results)
+	}
+}
+
+// TestDegradeResultsClassification tests that the failures of the dropped records are
+// still classified and recorded in the history
+func TestDegradeResultsClassification(t *testing.T) {
+	var results Results
+	for i := 0; i < 5; i++ {
+		results.FailedSteps = append(results.FailedSteps, FailedStepDetails{Feature: "Cart", Scenario: fmt.Sprint(i), Fingerprint: fmt.Sprint(i)})
+	}
+	results.AllFailures = historyFailures(results.FailedSteps)
+	degradeResults(&results, degradeStepRecords, degradedMaxFailedSteps-2)
+
+	previous := []HistoryEntry{{Failures: []HistoryFailure{{Feature: "Cart", Scenario: "4", Fingerprint: "4"}}}}
+	classifyFailures(&results, previous, 1)
+	if results.NewFailures != 4 || results.RecurringFailures != 1 {
+		t.Errorf("Expected 4 new and 1 recurring failures, got %d and %d", results.NewFailures, results.RecurringFailures)
+	}
+	if failures := newHistoryEntry(results, Args{}).Failures; len(failures) != 5 {
+		t.Errorf("Expected the 5 failures in the history, got %+v", failures)
+	}
+}
+
+// TestStripStepDetails tests that the outputs and embeddings of the steps and hooks are dropped
+func TestStripStepDetails(t *testing.T) {
+	step := Step{Name: "step", Output: []string{"log"}, Embeddings: []Embedding{{Data: "aGVsbG8=", MimeType: "text/plain"}}}
+	feature := Feature{Elements: []Element{{Before: []Step{step}, Steps: []Step{step}, After: []Step{step}}}}
+	stripStepDetails(&feature)
+
+	for _, steps := range [][]Step{feature.Elements[0].Before, feature.Elements[0].Steps, feature.Elements[0].After} {
+		if steps[0].Output != nil || steps[0].Embeddings != nil || steps[0].Name != "step" {
+			t.Errorf("Expected the outputs and embeddings to be dropped, got %+v", steps[0])
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// messageEnvelopeKeys are the keys of the envelopes opening a Cucumber Messages stream.
+var messageEnvelopeKeys = []string{"meta", "source", "gherkinDocument", "pickle", "stepDefinition", "hook", "parameterType", "testRunStarted", "testCase"}
+
+// messageEnvelope represents the envelopes of a Cucumber Messages stream read to rebuild
+// the features, the others are ignored.
+type messageEnvelope struct {
+	GherkinDocument  *messageGherkinDocument  `json:"gherkinDocument"`
+	Pickle           *messagePickle           `json:"pickle"`
+	TestCase         *messageTestCase         `json:"testCase"`
+	TestCaseStarted  *messageTestCaseStarted  `json:"testCaseStarted"`
+	TestStepFinished *messageTestStepFinished `json:"testStepFinished"`
+	Attachment       *messageAttachment       `json:"attachment"`
+}
+
+// messageGherkinDocument represents the parsed Gherkin source of a feature file.
+type messageGherkinDocument struct {
+	URI     string `json:"uri"`
+	Feature *struct {
+		Location    messageLocation       `json:"location"`
+		Tags        []messageTag          `json:"tags"`
+		Keyword     string                `json:"keyword"`
+		Name        string                `json:"name"`
+		Description string                `json:"description"`
+		Children    []messageFeatureChild `json:"children"`
+	} `json:"feature"`
+}
+
+// messageFeatureChild represents a background, a scenario or a rule of a feature.
+type messageFeatureChild struct {
+	Rule *struct {
+		Tags     []messageTag          `json:"tags"`
+		Children []messageFeatureChild `json:"children"`
+	} `json:"rule"`
+	Background *messageScenario `json:"background"`
+	Scenario   *messageScenario `json:"scenario"`
+}
+
+// messageScenario represents a scenario, a scenario outline or a background.
+type messageScenario struct {
+	ID          string          `json:"id"`
+	Location    messageLocation `json:"location"`
+	Tags        []messageTag    `json:"tags"`
+	Keyword     string          `json:"keyword"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Steps       []struct {
+		ID       string          `json:"id"`
+		Location messageLocation `json:"location"`
+		Keyword  string          `json:"keyword"`
+	} `json:"steps"`
+	Examples []struct {
+		Tags      []messageTag `json:"tags"`
+		TableBody []struct {
+			ID       string          `json:"id"`
+			Location messageLocation `json:"location"`
+		} `json:"tableBody"`
+	} `json:"examples"`
+}
+
+// messageTag represents a tag of the Gherkin source.
+type messageTag struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Location messageLocation `json:"location"`
+}
+
+// messageLocation represents a location in the Gherkin source.
+type messageLocation struct {
+	Line int `json:"line"`
+}
+
+// messagePickle represents a scenario, or an example of an outline, compiled for execution.
+type messagePickle struct {
+	ID         string   `json:"id"`
+	URI        string   `json:"uri"`
+	Name       string   `json:"name"`
+	AstNodeIDs []string `json:"astNodeIds"` // The scenario, followed by the example row for outlines
+	Tags       []struct {
+		Name      string `json:"name"`
+		AstNodeID string `json:"astNodeId"`
+	} `json:"tags"`
+	Steps []struct {
+		ID         string   `json:"id"`
+		Text       string   `json:"text"`
+		AstNodeIDs []string `json:"astNodeIds"`
+		Argument   *struct {
+			DocString *struct {
+				Content string `json:"content"`
+			} `json:"docString"`
+			DataTable *struct {
+				Rows []struct {
+					Cells []struct {
+						Value string `json:"value"`
+					} `json:"cells"`
+				} `json:"rows"`
+			} `json:"dataTable"`
+		} `json:"argument"`
+	} `json:"steps"`
+}
+
+// messageTestCase represents the test steps, hooks and pickle steps, of a pickle.
+type messageTestCase struct {
+	ID        string `json:"id"`
+	PickleID  string `json:"pickleId"`
+	TestSteps []struct {
+		ID           string `json:"id"`
+		PickleStepID string `json:"pickleStepId"`
+		HookID       string `json:"hookId"`
+	} `json:"testSteps"`
+}
+
+// messageTestCaseStarted represents an attempt to execute a test case.
+type messageTestCaseStarted struct {
+	ID         string      `json:"id"`
+	TestCaseID string      `json:"testCaseId"`
+	Timestamp  messageTime `json:"timestamp"`
+}
+
+// messageTestStepFinished represents the result of a test step of an attempt.
+type messageTestStepFinished struct {
+	TestCaseStartedID string `json:"testCaseStartedId"`
+	TestStepID        string `json:"testStepId"`
+	TestStepResult    struct {
+		Status    string      `json:"status"`
+		Duration  messageTime `json:"duration"`
+		Message   string      `json:"message"`
+		Exception *struct {
+			Message string `json:"message"`
+		} `json:"exception"`
+	} `json:"testStepResult"`
+}
+
+// messageAttachment represents data attached to a test step, such as a screenshot.
+type messageAttachment struct {
+	TestCaseStartedID string `json:"testCaseStartedId"`
+	TestStepID        string `json:"testStepId"`
+	Body              string `json:"body"`
+	ContentEncoding   string `json:"contentEncoding"`
+	MediaType         string `json:"mediaType"`
+	FileName          string `json:"fileName"`
+}
+
+// messageTime represents a Cucumber Messages timestamp or duration.
+type messageTime struct {
+	Seconds int64 `json:"seconds"`
+	Nanos   int64 `json:"nanos"`
+}
+
+// messageNode is the Gherkin element of an identifier referenced by the pickles.
+type messageNode struct {
+	keyword     string
+	name        string
+	description string
+	line        int
+}
+
+// messageStepSlot locates the step of a test step in its scenario.
+type messageStepSlot struct {
+	hook   string // "before" or "after" for hooks, empty for steps
+	index  int
+	parent *Element
+}
+
+// step returns the step of the slot.
+func (s messageStepSlot) step() *Step {
+	switch s.hook {
+	case "before":
+		return &s.parent.Before[s.index]
+	case "after":
+		return &s.parent.After[s.index]
+	}
+	return &s.parent.Steps[s.index]
+}
+
+// isCucumberMessages reports whether the content is a Cucumber Messages NDJSON stream,
+// as written by cucumber-js 8+ and cucumber-jvm 7+.
+func isCucumberMessages(content []byte) bool {
+	if firstByte(content) != '{' {
+		return false
+	}
+	var envelope map[string]json.RawMessage
+	if json.NewDecoder(bytes.NewReader(content)).Decode(&envelope) != nil {
+		return false
+	}
+	for _, key := range messageEnvelopeKeys {
+		if _, ok := envelope[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeCucumberMessages decodes a Cucumber Messages NDJSON stream into Cucumber features,
+// with a scenario per attempt of a test case so that the retries are counted like those
+// of the reruns. The names, keywords and lines are taken from the Gherkin documents and
+// the pickles, the results and attachments from the test step envelopes.
+func decodeCucumberMessages(content []byte) ([]Feature, error) {
+	var (
+		features   []Feature
+		indexes    = make(map[string]int)
+		nodes      = make(map[string]messageNode)
+		pickles    = make(map[string]*messagePickle)
+		testCases  = make(map[string]*messageTestCase)
+		executions = make(map[string]map[string]messageStepSlot)
+	)
+	// Scenarios are appended to the features as their executions start, so they are
+	// collected apart and assigned at the end to keep the slots pointing to them.
+	var elements [][]*Element
+
+	featureIndex := func(uri string) int {
+		index, ok := indexes[uri]
+		if !ok {
+			index = len(features)
+			indexes[uri] = index
+			features = append(features, Feature{URI: uri, Keyword: "Feature", Name: uri})
+			elements = append(elements, nil)
+		}
+		return index
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	for decoder.More() {
+		var envelope messageEnvelope
+		if err := decoder.Decode(&envelope); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case envelope.GherkinDocument != nil && envelope.GherkinDocument.Feature != nil:
+			document := envelope.GherkinDocument
+			index := featureIndex(document.URI)
+			features[index] = Feature{
+				URI:         document.URI,
+				Keyword:     document.Feature.Keyword,
+				Name:        document.Feature.Name,
+				Description: strings.TrimSpace(document.Feature.Description),
+				Line:        document.Feature.Location.Line,
+				Tags:        messageTags(document.Feature.Tags),
+			}
+			indexMessageNodes(document.Feature.Children, nodes)
+			for _, tag := range document.Feature.Tags {
+				nodes[tag.ID] = messageNode{name: tag.Name, line: tag.Location.Line}
+			}
+		case envelope.Pickle != nil:
+			pickles[envelope.Pickle.ID] = envelope.Pickle
+		case envelope.TestCase != nil:
+			testCases[envelope.TestCase.ID] = envelope.TestCase
+		case envelope.TestCaseStarted != nil:
+			started := envelope.TestCaseStarted
+			testCase, ok := testCases[started.TestCaseID]
+			if !ok {
+				continue
+			}
+			pickle, ok := pickles[testCase.PickleID]
+			if !ok {
+				continue
+			}
+			element, slots := messageScenarioElement(pickle, testCase, nodes)
+			if started.Timestamp.Seconds > 0 {
+				element.StartTimestamp = time.Unix(started.Timestamp.Seconds, started.Timestamp.Nanos).UTC().Format(time.RFC3339Nano)
+			}
+			index := featureIndex(pickle.URI)
+			elements[index] = append(elements[index], element)
+			executions[started.ID] = slots
+		case envelope.TestStepFinished != nil:
+			finished := envelope.TestStepFinished
+			slot, ok := executions[finished.TestCaseStartedID][finished.TestStepID]
+			if !ok {
+				continue
+			}
+			result := finished.TestStepResult
+			step := slot.step()
+			step.Result = Result{
+				Status:   strings.ToLower(result.Status),
+				Duration: result.Duration.Seconds*int64(time.Second) + result.Duration.Nanos,
+			}
+			if step.Result.Status == "unknown" {
+				step.Result.Status = ""
+			}
+			step.Result.ErrorMessage = result.Message
+			if step.Result.ErrorMessage == "" && result.Exception != nil {
+				step.Result.ErrorMessage = result.Exception.Message
+			}
+		case envelope.Attachment != nil:
+			attachment := envelope.Attachment
+			slot, ok := executions[attachment.TestCaseStartedID][attachment.TestStepID]
+			if !ok {
+				continue
+			}
+			data := attachment.Body
+			if !strings.EqualFold(attachment.ContentEncoding, "BASE64") {
+				data = base64.StdEncoding.EncodeToString([]byte(attachment.Body))
+			}
+			step := slot.step()
+			step.Embeddings = append(step.Embeddings, Embedding{Data: data, MimeType: attachment.MediaType, Name: attachment.FileName})
+		}
+	}
+
+	var decoded []Feature
+	for i, feature := range features {
+		for _, element := range elements[i] {
+			feature.Elements = append(feature.Elements, *element)
+		}
+		if len(feature.Elements) > 0 {
+			decoded = append(decoded, feature)
+		}
+	}
+	return decoded, nil
+}
+
+// indexMessageNodes records the scenarios, steps, tags and example rows of the Gherkin
+// document by identifier, descending into the rules.
+func indexMessageNodes(children []messageFeatureChild, nodes map[string]messageNode) {
+	for _, child := range children {
+		if child.Rule != nil {
+			indexMessageNodes(child.Rule.Children, nodes)
+			for _, tag := range child.Rule.Tags {
+				nodes[tag.ID] = messageNode{name: tag.Name, line: tag.Location.Line}
+			}
+		}
+		for _, scenario := range []*messageScenario{child.Background, child.Scenario} {
+			if scenario == nil {
+				continue
+			}
+			nodes[scenario.ID] = messageNode{keyword: scenario.Keyword, name: scenario.Name, description: strings.TrimSpace(scenario.Description), line: scenario.Location.Line}
+			for _, step := range scenario.Steps {
+				nodes[step.ID] = messageNode{keyword: step.Keyword, line: step.Location.Line}
+			}
+			for _, tag := range scenario.Tags {
+				nodes[tag.ID] = messageNode{name: tag.Name, line: tag.Location.Line}
+			}
+			for _, examples := range scenario.Examples {
+				for _, tag := range examples.Tags {
+					nodes[tag.ID] = messageNode{name: tag.Name, line: tag.Location.Line}
+				}
+				for _, row := range examples.TableBody {
+					nodes[row.ID] = messageNode{line: row.Location.Line}
+				}
+			}
+		}
+	}
+}
+
+// messageScenarioElement creates the scenario of an attempt of the test case, with its
+// steps and hooks waiting for their results, and the slots of its test steps. The hooks
+// preceding the first step are before hooks, the others after hooks.
+func messageScenarioElement(pickle *messagePickle, testCase *messageTestCase, nodes map[string]messageNode) (*Element, map[string]messageStepSlot) {
+	element := &Element{Name: pickle.Name, Type: "scenario", Keyword: "Scenario", Steps: []Step{}}
+	if len(pickle.AstNodeIDs) > 0 {
+		scenario := nodes[pickle.AstNodeIDs[0]]
+		element.Keyword = firstNonEmpty(scenario.keyword, element.Keyword)
+		element.Description = scenario.description
+		element.Line = scenario.line
+	}
+	// The examples of an outline start at their row
+	if len(pickle.AstNodeIDs) > 1 {
+		element.Line = nodes[pickle.AstNodeIDs[len(pickle.AstNodeIDs)-1]].line
+	}
+	for _, tag := range pickle.Tags {
+		element.Tags = append(element.Tags, Tag{Name: tag.Name, Line: nodes[tag.AstNodeID].line})
+	}
+
+	pickleSteps := make(map[string]int, len(pickle.Steps))
+	for i, step := range pickle.Steps {
+		pickleSteps[step.ID] = i
+	}
+
+	slots := make(map[string]messageStepSlot, len(testCase.TestSteps))
+	for _, testStep := range testCase.TestSteps {
+		index, ok := pickleSteps[testStep.PickleStepID]
+		if !ok {
+			if len(element.Steps) == 0 {
+				slots[testStep.ID] = messageStepSlot{hook: "before", index: len(element.Before), parent: element}
+				element.Before = append(element.Before, Step{})
+			} else {
+				slots[testStep.ID] = messageStepSlot{hook: "after", index: len(element.After), parent: element}
+				element.After = append(element.After, Step{})
+			}
+			continue
+		}
+
+		pickleStep := pickle.Steps[index]
+		step := Step{Name: pickleStep.Text}
+		if len(pickleStep.AstNodeIDs) > 0 {
+			node := nodes[pickleStep.AstNodeIDs[0]]
+			step.Keyword, step.Line = node.keyword, node.line
+		}
+		if argument := pickleStep.Argument; argument != nil {
+			switch {
+			case argument.DocString != nil:
+				step.Arguments = []Argument{{Content: argument.DocString.Content}}
+			case argument.DataTable != nil:
+				var rows []Row
+				for _, row := range argument.DataTable.Rows {
+					cells := make([]string, len(row.Cells))
+					for i, cell := range row.Cells {
+						cells[i] = cell.Value
+					}
+					rows = append(rows, Row{Cells: cells})
+				}
+				step.Arguments = []Argument{{Rows: rows}}
+			}
+		}
+		slots[testStep.ID] = messageStepSlot{index: len(element.Steps), parent: element}
+		element.Steps = append(element.Steps, step)
+	}
+	return element, slots
+}
+
+// messageTags returns the tags of the Gherkin source.
+func messageTags(tags []messageTag) []Tag {
+	var result []Tag
+	for _, tag := range tags {
+		result = append(result, Tag{Name: tag.Name, Line: tag.Location.Line})
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Constants for Rounding Mode
+const (
+	RoundingHalfUp   = "HALF_UP"
+	RoundingHalfEven = "HALF_EVEN"
+	RoundingDown     = "DOWN"
+	RoundingUp       = "UP"
+)
+
+// Default and maximum number of decimal places of the percentages and durations.
+const (
+	defaultDecimalPlaces = 2
+	maxDecimalPlaces     = 10
+)
+
+// numberFormat holds the decimal places and rounding mode of the formatted numbers.
+var numberFormat = struct {
+	decimals int
+	rounding string
+}{defaultDecimalPlaces, RoundingHalfUp}
+
+// validateNumberFormat checks the decimal places and rounding mode settings.
+func validateNumberFormat(args Args) error {
+	if args.DecimalPlaces != "" {
+		decimals, err := strconv.Atoi(args.DecimalPlaces)
+		if err != nil || decimals < 0 || decimals > maxDecimalPlaces {
+			return fmt.Errorf("invalid DecimalPlaces value. It must be a number between 0 and %d", maxDecimalPlaces)
+		}
+	}
+
+	switch strings.ToUpper(args.RoundingMode) {
+	case "", RoundingHalfUp, RoundingHalfEven, RoundingDown, RoundingUp:
+		return nil
+	}
+	return fmt.Errorf("invalid RoundingMode value. It must be '%s', '%s', '%s' or '%s'", RoundingHalfUp, RoundingHalfEven, RoundingDown, RoundingUp)
+}
+
+// configureNumberFormat applies the decimal places and rounding mode settings.
+func configureNumberFormat(args Args) {
+	numberFormat.decimals = defaultDecimalPlaces
+	if decimals, err := strconv.Atoi(args.DecimalPlaces); err == nil {
+		numberFormat.decimals = decimals
+	}
+	numberFormat.rounding = RoundingHalfUp
+	if args.RoundingMode != "" {
+		numberFormat.rounding = strings.ToUpper(args.RoundingMode)
+	}
+}
+
+// formatNumber formats a percentage or duration with the configured precision.
+func formatNumber(value float64) string {
+	return roundDecimal(value, numberFormat.decimals, numberFormat.rounding)
+}
+
+// formatSignedNumber formats a difference with the configured precision and an explicit sign.
+func formatSignedNumber(value float64) string {
+	formatted := formatNumber(value)
+	if strings.HasPrefix(formatted, "-") {
+		return formatted
+	}
+	return "+" + formatted
+}
+
+// roundDecimal rounds the shortest decimal representation of the value, so that 99.995
+// rounds half up to 100.00 although its binary representation is slightly lower.
+func roundDecimal(value float64, decimals int, mode string) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return strconv.FormatFloat(value, 'f', decimals, 64)
+	}
+
+	rat, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return strconv.FormatFloat(value, 'f', decimals, 64)
+	}
+	rat.Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+
+	// Truncate toward zero, then adjust by one unit away from zero depending on the mode
+	quotient, remainder := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		// Compare twice the remainder with the denominator to locate the half
+		half := new(big.Int).Abs(remainder)
+		half.Lsh(half, 1)
+		comparison := half.Cmp(rat.Denom())
+
+		awayFromZero := false
+		switch mode {
+		case RoundingUp:
+			awayFromZero = true
+		case RoundingDown:
+		case RoundingHalfEven:
+			awayFromZero = comparison > 0 || (comparison == 0 && quotient.Bit(0) == 1)
+		default:
+			awayFromZero = comparison >= 0
+		}
+		if awayFromZero {
+			quotient.Add(quotient, big.NewInt(int64(rat.Sign())))
+		}
+	}
+
+	digits := new(big.Int).Abs(quotient).String()
+	if decimals > 0 {
+		if len(digits) <= decimals {
+			digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+	}
+	if quotient.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import "testing"
+
+// TestRoundDecimal tests rounding numbers with the supported rounding modes
+func TestRoundDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		decimals int
+		mode     string
+		expected string
+	}{
+		{name: "Half Up", value: 99.995, decimals: 2, mode: RoundingHalfUp, expected: "100.00"},
+		{name: "Half Up Below Half", value: 99.994, decimals: 2, mode: RoundingHalfUp, expected: "99.99"},
+		{name: "Half Even Down", value: 2.5, decimals: 0, mode: RoundingHalfEven, expected: "2"},
+		{name: "Half Even Up", value: 3.5, decimals: 0, mode: RoundingHalfEven, expected: "4"},
+		{name: "Down", value: 99.999, decimals: 2, mode: RoundingDown, expected: "99.99"},
+		{name: "Up", value: 99.991, decimals: 2, mode: RoundingUp, expected: "100.00"},
+		{name: "Negative Half Up", value: -1.005, decimals: 2, mode: RoundingHalfUp, expected: "-1.01"},
+		{name: "Negative Down", value: -0.004, decimals: 2, mode: RoundingDown, expected: "0.00"},
+		{name: "More Decimals", value: 0.05, decimals: 4, mode: RoundingHalfUp, expected: "0.0500"},
+		{name: "Small Value", value: 0.001, decimals: 3, mode: RoundingHalfUp, expected: "0.001"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := roundDecimal(tc.value, tc.decimals, tc.mode); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestValidateNumberFormat tests validating the decimal places and rounding mode
+func TestValidateNumberFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        Args
+		expectError bool
+	}{
+		{name: "Defaults", args: Args{}},
+		{name: "Valid", args: Args{DecimalPlaces: "0", RoundingMode: "half_even"}},
+		{name: "Invalid Decimal Places", args: Args{DecimalPlaces: "eleven"}, expectError: true},
+		{name: "Too Many Decimal Places", args: Args{DecimalPlaces: "11"}, expectError: true},
+		{name: "Invalid Rounding Mode", args: Args{RoundingMode: "BANKERS"}, expectError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateNumberFormat(tc.args); (err != nil) != tc.expectError {
+				t.Errorf("Expected error: %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
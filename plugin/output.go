@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Constants for Output Mode
+const (
+	OutputModeAppend    = "APPEND"
+	OutputModeOverwrite = "OVERWRITE"
+)
+
+// OutputNamespaceAuto namespaces the output variables with the name of the step.
+const OutputNamespaceAuto = "AUTO"
+
+// outputTarget is the file the output variables of the invocation are written to.
+var outputTarget struct {
+	sync.Mutex
+	path      string // Defaults to DRONE_OUTPUT
+	prefix    string // Prefix of the keys derived from the namespace
+	truncate  bool   // Whether the file is emptied before the first variable is written
+	truncated bool
+
+	typedPath string                 // File of the typed values, not written when empty
+	typedKeys map[string]bool        // Keys of the typed values, all keys when empty
+	typed     map[string]interface{} // Typed values written so far by key
+}
+
+// validateOutputArgs checks the output file settings.
+func validateOutputArgs(args Args) error {
+	switch strings.ToUpper(args.OutputMode) {
+	case "", OutputModeAppend, OutputModeOverwrite:
+		return nil
+	}
+	return fmt.Errorf("invalid OutputMode value. It must be '%s' or '%s'", OutputModeAppend, OutputModeOverwrite)
+}
+
+// configureOutput sets the file and the namespace of the output variables of the invocation.
+func configureOutput(args Args) {
+	outputTarget.Lock()
+	defer outputTarget.Unlock()
+
+	outputTarget.path = args.OutputFile
+	outputTarget.truncate = strings.EqualFold(args.OutputMode, OutputModeOverwrite)
+	outputTarget.truncated = false
+
+	namespace := args.OutputNamespace
+	if strings.EqualFold(namespace, OutputNamespaceAuto) {
+		namespace = os.Getenv("DRONE_STEP_NAME")
+		if namespace == "" {
+			logger.Warnf("No step name to namespace the output variables with, DRONE_STEP_NAME is not set")
+		}
+	}
+	outputTarget.prefix = ""
+	if key := envKey(namespace); key != "" {
+		outputTarget.prefix = key + "_"
+	}
+
+	outputTarget.typedPath = args.TypedOutputFile
+	outputTarget.typedKeys = make(map[string]bool)
+	for _, key := range strings.Split(args.TypedOutputs, ",") {
+		if key = strings.ToUpper(strings.TrimSpace(key)); key != "" {
+			outputTarget.typedKeys[key] = true
+		}
+	}
+	outputTarget.typed = make(map[string]interface{})
+}
+
+// decimalPattern matches the decimal numbers written to the output variables, such as
+// the percentages and durations.
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+\.[0-9]+$`)
+
+// typedValue returns the value as a number when it is an integer or a decimal number,
+// as a boolean when it is true or false, and as a string otherwise.
+func typedValue(value string) interface{} {
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return number
+	}
+	if decimalPattern.MatchString(value) {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	}
+	if value == "true" || value == "false" {
+		return value == "true"
+	}
+	return value
+}
+
+// writeTypedOutput records the typed value of a selected key and rewrites the JSON file
+// of the typed values, so that the file is complete whichever way the run ends.
+func writeTypedOutput(key, value string) error {
+	if outputTarget.typedPath == "" || (len(outputTarget.typedKeys) > 0 && !outputTarget.typedKeys[key]) {
+		return nil
+	}
+	outputTarget.typed[outputTarget.prefix+key] = typedValue(value)
+	content, err := json.MarshalIndent(outputTarget.typed, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputTarget.typedPath, append(content, '\n'), 0644)
+}
+
+// writeOutput writes the output variable to the output file, replacing the line of the
+// key when it was already written so that every key appears once.
+func writeOutput(key, value string) error {
+	outputTarget.Lock()
+	defer outputTarget.Unlock()
+
+	if err := writeTypedOutput(key, value); err != nil {
+		return err
+	}
+
+	path := firstNonEmpty(outputTarget.path, os.Getenv("DRONE_OUTPUT"))
+	key = outputTarget.prefix + key
+
+	var lines []string
+	if !outputTarget.truncate || outputTarget.truncated {
+		content, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(content) > 0 {
+			lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		}
+	}
+
+	line := key + "=" + value
+	replaced := false
+	for i := range lines {
+		if strings.HasPrefix(lines[i], key+"=") {
+			lines[i] = line
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	outputTarget.truncated = true
+	return nil
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteOutput tests the output file, mode and namespace of the output variables
+func TestWriteOutput(t *testing.T) {
+	t.Cleanup(func() { configureOutput(Args{}) })
+	t.Setenv("DRONE_STEP_NAME", "cucumber-report-api")
+
+	tests := []struct {
+		name     string
+		args     Args
+		existing string
+		expected string
+	}{
+		{
+			name:     "Append",
+			existing: "OTHER=1\n",
+			expected: "OTHER=1\nFAILED_STEPS=2\nPASSED_STEPS=5\n",
+		},
+		{
+			name:     "Overwrite",
+			args:     Args{OutputMode: "overwrite"},
+			existing: "OTHER=1\nFAILED_STEPS=9\n",
+			expected: "FAILED_STEPS=2\nPASSED_STEPS=5\n",
+		},
+		{
+			name:     "Automatic namespace",
+			args:     Args{OutputNamespace: "auto"},
+			existing: "FAILED_STEPS=9\n",
+			expected: "FAILED_STEPS=9\nCUCUMBER_REPORT_API_FAILED_STEPS=2\nCUCUMBER_REPORT_API_PASSED_STEPS=5\n",
+		},
+		{
+			name:     "Namespace",
+			args:     Args{OutputNamespace: "ui"},
+			expected: "UI_FAILED_STEPS=2\nUI_PASSED_STEPS=5\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output.env")
+			os.WriteFile(path, []byte(tc.existing), 0644)
+			tc.args.OutputFile = path
+			configureOutput(tc.args)
+
+			// A key written again replaces its previous value
+			for _, entry := range [][2]string{{"FAILED_STEPS", "1"}, {"PASSED_STEPS", "5"}, {"FAILED_STEPS", "2"}} {
+				if err := WriteEnvToFile(entry[0], entry[1], logger); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if content, _ := os.ReadFile(path); string(content) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+
+	if err := validateOutputArgs(Args{OutputMode: "replace"}); err == nil {
+		t.Error("Expected an error for an invalid mode")
+	}
+}
+
+// TestWriteTypedOutput tests the JSON file of the typed output variables
+func TestWriteTypedOutput(t *testing.T) {
+	t.Cleanup(func() { configureOutput(Args{}) })
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		args     Args
+		expected string
+	}{
+		{
+			name:     "All keys",
+			args:     Args{},
+			expected: "{\n  \"ERROR\": false,\n  \"ERROR_MESSAGE\": \"\",\n  \"FAILED_SCENARIOS\": 3,\n  \"PASS_RATE\": 97.5,\n  \"REPORT_URL\": \"https://example.com/1.0\"\n}\n",
+		},
+		{
+			name:     "Selected keys with a namespace",
+			args:     Args{TypedOutputs: "failed_scenarios, pass_rate", OutputNamespace: "api"},
+			expected: "{\n  \"API_FAILED_SCENARIOS\": 3,\n  \"API_PASS_RATE\": 97.5\n}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.args.OutputFile = filepath.Join(dir, "output.env")
+			tc.args.TypedOutputFile = filepath.Join(dir, "outputs.json")
+			configureOutput(tc.args)
+
+			for _, entry := range [][2]string{{"FAILED_SCENARIOS", "1"}, {"PASS_RATE", "97.50"}, {"ERROR", "false"}, {"ERROR_MESSAGE", ""}, {"REPORT_URL", "https://example.com/1.0"}, {"FAILED_SCENARIOS", "3"}} {
+				if err := WriteEnvToFile(entry[0], entry[1], logger); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if content, _ := os.ReadFile(tc.args.TypedOutputFile); string(content) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
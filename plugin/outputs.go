@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultOutputDir is the directory the output formats are written to when none is configured.
+const defaultOutputDir = "cucumber-reports"
+
+// reportOutput holds the dataset every output format is rendered from.
+type reportOutput struct {
+	results Results
+	args    Args
+	trend   *Trend
+	gateErr error
+}
+
+// outputFormat is a report format written to the output directory under a fixed name.
+type outputFormat struct {
+	name  string
+	file  string
+	write func(path string, output reportOutput) error
+}
+
+// outputFormats are the formats selectable in the output formats setting.
+var outputFormats = []outputFormat{
+	{name: "html", file: "report.html", write: func(path string, output reportOutput) error {
+		return writeHTMLReport(path, output.results, output.args, output.trend, output.gateErr)
+	}},
+	{name: "junit", file: "junit.xml", write: func(path string, output reportOutput) error {
+		return writeJUnitReport(path, output.results)
+	}},
+	{name: "json", file: "summary.json", write: func(path string, output reportOutput) error {
+		return writeSummary(path, output.results)
+	}},
+	{name: "markdown", file: "summary.md", write: func(path string, output reportOutput) error {
+		return writeMarkdownSummary(path, output.results, output.args, output.trend, output.gateErr)
+	}},
+	{name: "xlsx", file: "cucumber-results.xlsx", write: func(path string, output reportOutput) error {
+		return writeXLSXReport(path, output.results)
+	}},
+	{name: "pdf", file: "report.pdf", write: func(path string, output reportOutput) error {
+		return writePDFReport(path, output.results, output.args, output.trend, output.gateErr)
+	}},
+}
+
+// parseOutputFormats parses the comma-separated list of output formats, case-insensitively
+// and ignoring duplicates, in the order of the list.
+func parseOutputFormats(value string) ([]outputFormat, error) {
+	var formats []outputFormat
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || selected[name] {
+			continue
+		}
+		format, ok := lookupOutputFormat(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid OutputFormats value %s. It must be one of %s", name, strings.Join(outputFormatNames(), ", "))
+		}
+		selected[name] = true
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// lookupOutputFormat returns the output format of the name.
+func lookupOutputFormat(name string) (outputFormat, bool) {
+	for _, format := range outputFormats {
+		if format.name == name {
+			return format, true
+		}
+	}
+	return outputFormat{}, false
+}
+
+// outputFormatNames returns the names of the output formats.
+func outputFormatNames() []string {
+	names := make([]string, 0, len(outputFormats))
+	for _, format := range outputFormats {
+		names = append(names, format.name)
+	}
+	return names
+}
+
+// outputDir returns the directory the output formats are written to.
+func outputDir(args Args) string {
+	return firstNonEmpty(args.OutputDir, defaultOutputDir)
+}
+
+// outputFormatPath returns the path of the output format of the name, or an empty path
+// when it is not selected.
+func outputFormatPath(args Args, name string) string {
+	formats, _ := parseOutputFormats(args.OutputFormats)
+	for _, format := range formats {
+		if format.name == name {
+			return filepath.Join(outputDir(args), format.file)
+		}
+	}
+	return ""
+}
+
+// outputFormatPaths returns the paths of the selected output formats.
+func outputFormatPaths(args Args) []string {
+	formats, _ := parseOutputFormats(args.OutputFormats)
+	paths := make([]string, 0, len(formats))
+	for _, format := range formats {
+		paths = append(paths, filepath.Join(outputDir(args), format.file))
+	}
+	return paths
+}
+
+// writeOutputFormats writes the selected output formats to the output directory
+// concurrently, all rendered from the same results. A format failing to be written is
+// logged and does not stop the others.
+func writeOutputFormats(output reportOutput) {
+	formats, _ := parseOutputFormats(output.args.OutputFormats)
+	if len(formats) == 0 {
+		return
+	}
+
+	dir := outputDir(output.args)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Warnf("Failed to create output directory %s: %v", dir, err)
+		return
+	}
+
+	errs := make([]error, len(formats))
+	var wg sync.WaitGroup
+	for i, format := range formats {
+		wg.Add(1)
+		go func(i int, format outputFormat) {
+			defer wg.Done()
+			errs[i] = format.write(filepath.Join(dir, format.file), output)
+		}(i, format)
+	}
+	wg.Wait()
+
+	for i, format := range formats {
+		path := filepath.Join(dir, format.file)
+		if errs[i] != nil {
+			logger.Warnf("Failed to write %s output %s: %v", format.name, path, errs[i])
+		} else {
+			logger.Infof("Output format %s written to %s\n", format.name, path)
+		}
+	}
+}
+
+// writeMarkdownSummary writes the Markdown summary, the job summary of GitHub Actions, to
+// its own file.
+func writeMarkdownSummary(path string, results Results, args Args, trend *Trend, gateErr error) error {
+	summary, err := renderReportTemplate(templateSummary, newReportTemplateData(results, args, trend, gateErr, time.Now()), args)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(summary+"\n"), 0644)
+}
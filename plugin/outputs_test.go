@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParseOutputFormats tests selecting the output formats
+func TestParseOutputFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+		err      string
+	}{
+		{name: "Empty", value: ""},
+		{name: "Formats", value: "html, JUnit,json,markdown,html", expected: []string{"html", "junit", "json", "markdown"}},
+		{name: "Unknown", value: "html,csv", err: "invalid OutputFormats value csv. It must be one of html, junit, json, markdown, xlsx, pdf"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			formats, err := parseOutputFormats(tc.value)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			var names []string
+			for _, format := range formats {
+				names = append(names, format.name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("Output formats mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestWriteOutputFormats tests writing every selected format to the output directory
+func TestWriteOutputFormats(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	args := Args{OutputFormats: "html,junit,json,markdown", OutputDir: dir}
+	results := Results{FeatureCount: 1, ScenarioCount: 1, TotalPassedScenarios: 1,
+		Scenarios: []ScenarioResult{{Feature: "Checkout", Scenario: "Pay", Status: "passed"}}}
+
+	writeOutputFormats(reportOutput{results: results, args: args})
+
+	expected := map[string]string{
+		"report.html":  "<html",
+		"junit.xml":    "<testsuites",
+		"summary.json": `"feature_count": 1`,
+		"summary.md":   "## Cucumber Test Report",
+	}
+	for file, content := range expected {
+		written, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+			continue
+		}
+		if !strings.Contains(string(written), content) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", file, content, written)
+		}
+	}
+	if diff := cmp.Diff([]string{filepath.Join(dir, "report.html"), filepath.Join(dir, "junit.xml"), filepath.Join(dir, "summary.json"), filepath.Join(dir, "summary.md")},
+		outputFormatPaths(args)); diff != "" {
+		t.Errorf("Output paths mismatch (-want +got):\n%s", diff)
+	}
+	if got := uploadedReport(args); got != filepath.Join(dir, "report.html") {
+		t.Errorf("Expected the HTML output to be uploaded, got %s", got)
+	}
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultFailureOverflowFile is the file receiving the failed step details beyond
+// MaxFailedDetails when none is configured.
+const defaultFailureOverflowFile = "failures.ndjson"
+
+// failureOverflow streams the failed step details beyond the retained maximum to a
+// newline-delimited JSON file, so that a failure storm neither exhausts the memory nor
+// loses details. The file is only created when a detail overflows.
+type failureOverflow struct {
+	max     int
+	path    string
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	count   int
+	err     error
+}
+
+// failureOverflowFile returns the path of the overflow file, or an empty string when the
+// number of failed step details is not limited.
+func failureOverflowFile(args Args) string {
+	if args.MaxFailedDetails <= 0 {
+		return ""
+	}
+	return firstNonEmpty(args.FailedDetailsOverflowFile, defaultFailureOverflowFile)
+}
+
+// newFailureOverflow returns the overflow of the failed step details, or nil when their
+// number is not limited.
+func newFailureOverflow(args Args) *failureOverflow {
+	if args.MaxFailedDetails <= 0 {
+		return nil
+	}
+	return &failureOverflow{max: args.MaxFailedDetails, path: failureOverflowFile(args)}
+}
+
+// retain keeps the failed step details of the file results within the maximum, given
+// the number already retained, and writes the others to the overflow file.
+func (o *failureOverflow) retain(results *Results, retained int) {
+	if o == nil {
+		return
+	}
+	keep := max(o.max-retained, 0)
+	if len(results.FailedSteps) <= keep {
+		return
+	}
+
+	for _, step := range results.FailedSteps[keep:] {
+		o.write(step)
+	}
+	o.count += len(results.FailedSteps) - keep
+	results.FailedSteps = results.FailedSteps[:keep:keep]
+}
+
+// moreFailures returns the line noting the failed step details that are not listed,
+// given the number hidden by the output itself and those written to the overflow file,
+// or an empty string when every detail is listed.
+func moreFailures(hidden int, results Results) string {
+	more := hidden + results.OverflowedFailedSteps
+	if more <= 0 {
+		return ""
+	}
+	if results.FailedStepsOverflowFile == "" {
+		return fmt.Sprintf("…and %d more", more)
+	}
+	return fmt.Sprintf("…and %d more (see %s)", more, results.FailedStepsOverflowFile)
+}
+
+// write appends a failed step detail to the overflow file, opening it on first use. The
+// first error stops the writes and is reported by close.
+func (o *failureOverflow) write(step FailedStepDetails) {
+	if o.err != nil {
+		return
+	}
+	if o.file == nil {
+		if o.file, o.err = os.Create(o.path); o.err != nil {
+			return
+		}
+		o.writer = bufio.NewWriter(o.file)
+		o.encoder = json.NewEncoder(o.writer)
+		o.encoder.SetEscapeHTML(false)
+	}
+	o.err = o.encoder.Encode(step)
+}
+
+// close flushes and closes the overflow file and returns the first error of its writes.
+func (o *failureOverflow) close() error {
+	if o == nil || o.file == nil {
+		return nil
+	}
+	if o.err == nil {
+		o.err = o.writer.Flush()
+	}
+	if err := o.file.Close(); o.err == nil {
+		o.err = err
+	}
+	return o.err
+}
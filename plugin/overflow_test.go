@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFailureOverflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.ndjson")
+	overflow := newFailureOverflow(Args{MaxFailedDetails: 3, FailedDetailsOverflowFile: path})
+
+	first := Results{FailedSteps: []FailedStepDetails{{Scenario: "A"}, {Scenario: "B"}}}
+	overflow.retain(&first, 0)
+	if len(first.FailedSteps) != 2 {
+		t.Errorf("Expected the details within the maximum to be retained, got %d", len(first.FailedSteps))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no overflow file before a detail overflows, got %v", err)
+	}
+
+	second := Results{FailedSteps: []FailedStepDetails{{Scenario: "C"}, {Scenario: "D"}, {Scenario: "E"}}}
+	overflow.retain(&second, 2)
+	if diff := cmp.Diff([]FailedStepDetails{{Scenario: "C"}}, second.FailedSteps); diff != "" {
+		t.Errorf("Retained details mismatch (-want +got):\n%s", diff)
+	}
+	if err := overflow.close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if overflow.count != 2 {
+		t.Errorf("Expected 2 overflowed details, got %d", overflow.count)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the overflow file: %v", err)
+	}
+	defer file.Close()
+	var scenarios []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var step FailedStepDetails
+		if err := json.Unmarshal(scanner.Bytes(), &step); err != nil {
+			t.Fatalf("Invalid line %q: %v", scanner.Text(), err)
+		}
+		scenarios = append(scenarios, step.Scenario)
+	}
+	if diff := cmp.Diff([]string{"D", "E"}, scenarios); diff != "" {
+		t.Errorf("Overflowed details mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMoreFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		hidden  int
+		results Results
+		want    string
+	}{
+		{"every detail listed", 0, Results{}, ""},
+		{"hidden by the output", 2, Results{}, "…and 2 more"},
+		{"overflowed", 1, Results{OverflowedFailedSteps: 3, FailedStepsOverflowFile: "failures.ndjson"}, "…and 4 more (see failures.ndjson)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := moreFailures(tc.hidden, tc.results); got != tc.want {
+				t.Errorf("moreFailures() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFailureOverflowRendered(t *testing.T) {
+	results := Results{
+		FailedSteps:             []FailedStepDetails{{Feature: "Cart", Scenario: "Pay", Step: "I pay"}},
+		OverflowedFailedSteps:   2,
+		FailedStepsOverflowFile: "failures.ndjson",
+	}
+	const more = "…and 2 more (see failures.ndjson)"
+
+	for _, name := range []string{templateSummary, templateHTMLReport, templateConfluence} {
+		text, err := renderReportTemplate(name, newReportTemplateData(results, Args{}, nil, nil, time.Now()), Args{})
+		if err != nil {
+			t.Fatalf("Unexpected error rendering %s: %v", name, err)
+		}
+		if !strings.Contains(text, more) {
+			t.Errorf("Expected %s to mention the overflowed failures, got:\n%s", name, text)
+		}
+	}
+	if text := slackFailures(results, 5); !strings.Contains(text, more) {
+		t.Errorf("Expected the Slack failures to mention the overflowed failures, got:\n%s", text)
+	}
+}
+
+func TestFailureOverflowDisabled(t *testing.T) {
+	overflow := newFailureOverflow(Args{FailedDetailsOverflowFile: "failures.ndjson"})
+	if overflow != nil || failureOverflowFile(Args{}) != "" {
+		t.Fatalf("Expected no overflow without a maximum")
+	}
+
+	results := Results{FailedSteps: make([]FailedStepDetails, 5)}
+	overflow.retain(&results, 100)
+	if len(results.FailedSteps) != 5 || overflow.close() != nil {
+		t.Errorf("Expected every detail to be retained, got %d", len(results.FailedSteps))
+	}
+}
@@ -0,0 +1,316 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Layout of the PDF report on A4 pages, in points.
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+	pdfFontSize   = 10.0
+	pdfLineHeight = 14.0
+	pdfChartSize  = 160.0
+)
+
+// pdfDocument lays out text, tables and charts on the pages of a PDF document using
+// the standard Helvetica fonts, which need no embedding.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// newPage starts a new page at its top margin.
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensureSpace starts a new page unless the height fits above the bottom margin.
+func (d *pdfDocument) ensureSpace(height float64) {
+	if len(d.pages) == 0 || d.y-height < pdfMargin {
+		d.newPage()
+	}
+}
+
+// page returns the content stream of the current page.
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// text draws a single line of text with its baseline at the position.
+func (d *pdfDocument) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// paragraph draws the text wrapped to the width of the page.
+func (d *pdfDocument) paragraph(size float64, bold bool, s string) {
+	lineHeight := size * 1.4
+	for _, line := range wrapPDFText(s, pdfPageWidth-2*pdfMargin, size) {
+		d.ensureSpace(lineHeight)
+		d.y -= lineHeight
+		d.text(pdfMargin, d.y, size, bold, line)
+	}
+}
+
+// heading draws a section heading, keeping room for the first lines of the section.
+func (d *pdfDocument) heading(s string) {
+	d.ensureSpace(4 * pdfLineHeight)
+	d.y -= pdfLineHeight
+	d.paragraph(14, true, s)
+	d.y -= 4
+}
+
+// row draws a table row, truncating the cells to the width of their column.
+func (d *pdfDocument) row(widths []float64, bold bool, cells ...string) {
+	d.ensureSpace(pdfLineHeight)
+	d.y -= pdfLineHeight
+	x := pdfMargin
+	for i, cell := range cells {
+		d.text(x, d.y, pdfFontSize, bold, truncatePDFText(cell, widths[i]-6, pdfFontSize))
+		x += widths[i]
+	}
+}
+
+// passRateChart draws the pass rate of the recent builds and the current build as a
+// line chart on a 0 to 100% scale.
+func (d *pdfDocument) passRateChart(entries []HistoryEntry) {
+	d.ensureSpace(pdfChartSize + 2*pdfLineHeight)
+	left, bottom := pdfMargin+30, d.y-pdfChartSize
+	width, height := pdfPageWidth-2*pdfMargin-40, pdfChartSize-pdfLineHeight
+	page := d.page()
+
+	// Axes and grid lines every 25%
+	fmt.Fprintf(page, "0.8 G 0.5 w\n")
+	for rate := 0.0; rate <= 100; rate += 25 {
+		y := bottom + rate/100*height
+		fmt.Fprintf(page, "%.2f %.2f m %.2f %.2f l S\n", left, y, left+width, y)
+		d.text(pdfMargin, y-3, 8, false, fmt.Sprintf("%.0f%%", rate))
+	}
+	fmt.Fprintf(page, "0 G 1 w %.2f %.2f m %.2f %.2f l %.2f %.2f l S\n", left, bottom+height, left, bottom, left+width, bottom)
+
+	step := width
+	if len(entries) > 1 {
+		step = width / float64(len(entries)-1)
+	}
+	fmt.Fprintf(page, "0.2 0.4 0.8 RG 1.5 w\n")
+	for i, entry := range entries {
+		operator := "l"
+		if i == 0 {
+			operator = "m"
+		}
+		fmt.Fprintf(page, "%.2f %.2f %s\n", left+float64(i)*step, bottom+entry.PassRate/100*height, operator)
+	}
+	fmt.Fprintf(page, "S 0 G\n")
+
+	// Label the builds, skipping labels that would overlap
+	every := 1 + len(entries)*30/int(width)
+	for i, entry := range entries {
+		if i%every == 0 || i == len(entries)-1 {
+			d.text(left+float64(i)*step-8, bottom-12, 8, false, "#"+entry.BuildNumber)
+		}
+	}
+	d.y = bottom - 2*pdfLineHeight
+}
+
+// bytes assembles the pages into a PDF document.
+func (d *pdfDocument) bytes(title string, now time.Time) []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// The catalog, page tree, fonts and document information come before the pages
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (drone-cucumber) /CreationDate (D:%s) >>", pdfEscape(title), now.UTC().Format("20060102150405Z")))
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// pdfEscape encodes the text in the Windows-1252 subset shared with Latin-1, replacing
+// the other characters such as emojis, and escapes the string delimiters.
+func pdfEscape(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r == '\t':
+			escaped.WriteByte(' ')
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+			continue
+		case r < 0x7f:
+			escaped.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+	return escaped.String()
+}
+
+// pdfTextWidth estimates the width of the text from the average Helvetica glyph width.
+func pdfTextWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * 0.52
+}
+
+// wrapPDFText wraps the text on spaces and line breaks to fit the width, breaking the
+// words longer than a line such as URLs.
+func wrapPDFText(s string, width, size float64) []string {
+	maxRunes := int(width / (size * 0.52))
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for runes := []rune(word); len(runes) > maxRunes; runes = []rune(word) {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, string(runes[:maxRunes]))
+				word = string(runes[maxRunes:])
+			}
+			if line != "" && pdfTextWidth(line+" "+word, size) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncatePDFText shortens the text with an ellipsis to fit the width.
+func truncatePDFText(s string, width, size float64) string {
+	runes := []rune(s)
+	for len(runes) > 0 && pdfTextWidth(string(runes), size) > width {
+		runes = runes[:len(runes)-1]
+		if pdfTextWidth(string(runes)+"...", size) <= width {
+			return string(runes) + "..."
+		}
+	}
+	return string(runes)
+}
+
+// renderPDFReport renders the summary, gate results, trend and failed steps as a PDF
+// document for the sign-off of releases.
+func renderPDFReport(results Results, args Args, trend *Trend, gateErr error, now time.Time) []byte {
+	title := "Cucumber Test Report"
+	doc := &pdfDocument{}
+	doc.paragraph(20, true, title)
+	doc.paragraph(pdfFontSize, false, fmt.Sprintf("%s %s, build #%s, %s", currentRepo(), currentBranch(args), currentBuildNumber(), now.UTC().Format(time.RFC1123)))
+
+	status := "PASSED"
+	if gateErr != nil {
+		status = "FAILED"
+	}
+	doc.y -= pdfLineHeight / 2
+	doc.paragraph(14, true, "Status: "+status)
+	if gateErr != nil {
+		doc.paragraph(pdfFontSize, false, "Gate failure: "+gateErr.Error())
+	}
+
+	doc.heading("Summary")
+	summaryWidths := []float64{200, 295}
+	for _, row := range [][2]string{
+		{"Total Features", fmt.Sprint(results.FeatureCount)},
+		{"Total Scenarios", fmt.Sprint(results.ScenarioCount)},
+		{"Total Steps", fmt.Sprint(results.StepCount)},
+		{"Failed Features", fmt.Sprint(results.TotalFailedFeatures)},
+		{"Failed Scenarios", fmt.Sprint(results.TotalFailedScenarios)},
+		{"Failed Steps", fmt.Sprint(results.TotalFailedSteps)},
+		{"Skipped Steps", fmt.Sprint(results.SkippedTests)},
+		{"Pending Steps", fmt.Sprint(results.PendingTests)},
+		{"Undefined Steps", fmt.Sprint(results.UndefinedTests)},
+		{"Pass Rate", formatNumber(percentageOf(results.TotalPassedScenarios, results.ScenarioCount)) + "%"},
+		{"Duration", formatNumber(results.DurationMS) + " ms"},
+	} {
+		doc.row(summaryWidths, false, row[0], row[1])
+	}
+
+	if gates := evaluateThresholds(results, args); len(gates) > 0 {
+		doc.heading("Quality Gates")
+		gateWidths := []float64{195, 100, 100, 100}
+		doc.row(gateWidths, true, "Gate", "Observed", "Threshold", "Verdict")
+		for _, gate := range gates {
+			verdict := "PASSED"
+			if !gate.Passed {
+				verdict = "FAILED"
+			}
+			doc.row(gateWidths, false, gate.Name, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold), verdict)
+		}
+	}
+
+	if trend != nil && len(trend.Entries) > 0 {
+		doc.heading("Pass Rate Trend")
+		doc.passRateChart(append(append([]HistoryEntry{}, trend.Entries...), trend.Current))
+		doc.paragraph(pdfFontSize, false, fmt.Sprintf("Average pass rate of the last %d builds: %s%% (%s%%, %s)",
+			len(trend.Entries), formatNumber(trend.AveragePassRate), formatSignedNumber(trend.PassRateDelta), trend.Direction))
+	}
+
+	if len(results.FailedSteps) > 0 {
+		doc.heading(fmt.Sprintf("Failed Steps (%d)", len(results.FailedSteps)))
+		for i, step := range results.FailedSteps {
+			doc.ensureSpace(4 * pdfLineHeight)
+			doc.paragraph(pdfFontSize, true, fmt.Sprintf("%d. %s / %s", i+1, step.Feature, step.Scenario))
+			doc.paragraph(pdfFontSize, false, "Step: "+step.Step)
+			if location := failureLocation(step); location != "" {
+				doc.paragraph(pdfFontSize, false, "Location: "+location)
+			}
+			doc.paragraph(pdfFontSize, false, "Error: "+firstLine(step.ErrorMessage))
+			doc.y -= pdfLineHeight / 2
+		}
+	}
+	if more := moreFailures(0, results); more != "" {
+		doc.paragraph(pdfFontSize, false, more)
+	}
+
+	return doc.bytes(title, now)
+}
+
+// writePDFReport writes the PDF report to the path, creating its directory.
+func writePDFReport(path string, results Results, args Args, trend *Trend, gateErr error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, renderPDFReport(results, args, trend, gateErr, time.Now()), 0644)
+}
@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRenderPDFReport tests rendering the PDF report and the consistency of its cross-reference table
+func TestRenderPDFReport(t *testing.T) {
+	results := Results{FeatureCount: 2, ScenarioCount: 4, TotalPassedScenarios: 3, TotalFailedScenarios: 1, FailedTests: 1}
+	for i := 0; i < 60; i++ {
+		results.FailedSteps = append(results.FailedSteps, FailedStepDetails{
+			Feature:      "Checkout (EU)",
+			Scenario:     fmt.Sprintf("Pay with card %d", i),
+			Step:         "I pay €10 😀",
+			URI:          "features/checkout.feature",
+			StepLine:     12,
+			ErrorMessage: "expected \"paid\"\nstack trace",
+		})
+	}
+	trend := &Trend{
+		Entries:   []HistoryEntry{{BuildNumber: "41", PassRate: 100}, {BuildNumber: "42", PassRate: 50}},
+		Current:   HistoryEntry{BuildNumber: "43", PassRate: 75},
+		Direction: TrendStable,
+	}
+	args := Args{FailedScenariosNumber: 2}
+	now := time.Date(2024, 5, 14, 10, 42, 7, 0, time.UTC)
+
+	content := renderPDFReport(results, args, trend, errors.New("failed scenarios count (3) exceeds the threshold (2)"), now)
+
+	for _, expected := range []string{
+		"%PDF-1.4",
+		"(Status: FAILED) Tj",
+		"(Failed Scenarios) Tj",
+		"(Pass Rate Trend) Tj",
+		"(#43) Tj",
+		`(1. Checkout \(EU\) / Pay with card 0) Tj`,
+		`(Step: I pay ?10 ?) Tj`,
+		"(Location: features/checkout.feature:12) Tj",
+		"/CreationDate (D:20240514104207Z)",
+	} {
+		if !bytes.Contains(content, []byte(expected)) {
+			t.Errorf("Expected the PDF to contain %q", expected)
+		}
+	}
+	if pages := bytes.Count(content, []byte("/Type /Page ")); pages < 2 {
+		t.Errorf("Expected the failed steps to span several pages, got %d", pages)
+	}
+
+	// Every object must start at the offset recorded in the cross-reference table
+	startxref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(content)
+	if startxref == nil {
+		t.Fatalf("Missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	lines := strings.Split(string(content[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for object := 1; object < count; object++ {
+		offset, _ := strconv.Atoi(strings.Fields(lines[2+object])[0])
+		if prefix := fmt.Sprintf("%d 0 obj", object); !bytes.HasPrefix(content[offset:], []byte(prefix)) {
+			t.Errorf("Expected object %d at offset %d", object, offset)
+		}
+	}
+}
+
+// TestWrapPDFText tests wrapping the text to the width of the page
+func TestWrapPDFText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Short", "one two", []string{"one two"}},
+		{"Wrapped", "one two three", []string{"one two", "three"}},
+		{"Line breaks", "one\ntwo", []string{"one", "two"}},
+		{"Long word", "abcdefghijkl", []string{"abcdefghi", "jkl"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lines := wrapPDFText(tc.text, 48, 10)
+			if strings.Join(lines, "|") != strings.Join(tc.expected, "|") {
+				t.Errorf("Expected %q, got %q", tc.expected, lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPendingStepsLogged is the number of oldest unimplemented steps logged.
+const maxPendingStepsLogged = 10
+
+// pendingStepKey identifies an unimplemented step of a scenario across builds, whether
+// it is undefined or pending, so that adding a stub keeps its age.
+func pendingStepKey(feature, scenario, step string) string {
+	return scenarioIdentifier(feature, scenario) + " :: " + step
+}
+
+// trackPendingSteps records the unimplemented steps of the build on the branch in the
+// history, keeping the build and date they were first seen, forgets the steps of the
+// branch that got implemented and returns the ages of the current ones, oldest first.
+func (h *History) trackPendingSteps(branch string, steps []UnimplementedStep, build string, now time.Time) ([]PendingStepAge, int) {
+	current := make(map[string]UnimplementedStep, len(steps))
+	for _, step := range steps {
+		current[pendingStepKey(step.Feature, step.Scenario, step.Step)] = step
+	}
+
+	implemented := 0
+	seen := make(map[string]bool, len(current))
+	kept := h.PendingSteps[:0]
+	for _, recorded := range h.PendingSteps {
+		if recorded.Branch != branch {
+			kept = append(kept, recorded)
+			continue
+		}
+		key := pendingStepKey(recorded.Feature, recorded.Scenario, recorded.Step)
+		step, ok := current[key]
+		if !ok || seen[key] {
+			implemented++
+			continue
+		}
+		seen[key] = true
+		recorded.Status = step.Status
+		recorded.LastSeen = now.Unix()
+		recorded.Builds++
+		kept = append(kept, recorded)
+	}
+	for _, step := range steps {
+		key := pendingStepKey(step.Feature, step.Scenario, step.Step)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, HistoryPendingStep{
+			Branch:         branch,
+			Feature:        step.Feature,
+			Scenario:       step.Scenario,
+			Step:           step.Step,
+			Status:         step.Status,
+			FirstSeenBuild: build,
+			FirstSeen:      now.Unix(),
+			LastSeen:       now.Unix(),
+			Builds:         1,
+		})
+	}
+	h.PendingSteps = kept
+
+	var ages []PendingStepAge
+	for _, recorded := range h.PendingSteps {
+		if recorded.Branch != branch {
+			continue
+		}
+		ages = append(ages, PendingStepAge{
+			Feature:        recorded.Feature,
+			Scenario:       recorded.Scenario,
+			Step:           recorded.Step,
+			Status:         recorded.Status,
+			FirstSeenBuild: recorded.FirstSeenBuild,
+			FirstSeen:      time.Unix(recorded.FirstSeen, 0).UTC().Format(time.RFC3339),
+			AgeDays:        int(now.Sub(time.Unix(recorded.FirstSeen, 0)).Hours() / 24),
+			Builds:         recorded.Builds,
+		})
+	}
+	sort.SliceStable(ages, func(i, j int) bool { return ages[i].FirstSeen < ages[j].FirstSeen })
+	return ages, implemented
+}
+
+// logPendingAging logs the oldest unimplemented steps.
+func logPendingAging(ages []PendingStepAge, implemented int) {
+	if implemented > 0 {
+		logger.Infof("%d pending or undefined steps were implemented since the previous build\n", implemented)
+	}
+	if len(ages) == 0 {
+		return
+	}
+
+	logger.Infof("Oldest Unimplemented Steps (%d pending or undefined steps):\n", len(ages))
+	for i, age := range ages {
+		if i == maxPendingStepsLogged {
+			logger.Infof("…and %d more\n", len(ages)-maxPendingStepsLogged)
+			break
+		}
+		logger.Infof("%d. %s (%s) in %s, for %d days and %d builds since build #%s\n", i+1, age.Step, age.Status,
+			scenarioIdentifier(age.Feature, age.Scenario), age.AgeDays, age.Builds, age.FirstSeenBuild)
+	}
+}
+
+// writePendingAgingReport writes the ages of the unimplemented steps as Markdown when the
+// path ends with .md and as JSON otherwise.
+func writePendingAgingReport(path string, ages []PendingStepAge, args Args) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".md") {
+		if ages == nil {
+			ages = []PendingStepAge{}
+		}
+		content, err := json.MarshalIndent(ages, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0644)
+	}
+
+	report, err := renderReportTemplate(templatePendingAging, struct{ Steps []PendingStepAge }{ages}, args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
+
+// writePendingAgingStats writes the number of unimplemented steps and the age of the
+// oldest to the output variables.
+func writePendingAgingStats(ages []PendingStepAge, log Logger) {
+	oldest := 0
+	if len(ages) > 0 {
+		oldest = ages[0].AgeDays
+	}
+	statsMap := map[string]string{
+		"UNIMPLEMENTED_STEPS":            strconv.Itoa(len(ages)),
+		"OLDEST_UNIMPLEMENTED_STEP_DAYS": strconv.Itoa(oldest),
+	}
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
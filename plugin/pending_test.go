@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTrackPendingSteps(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	history := &History{PendingSteps: []HistoryPendingStep{{Branch: "develop", Feature: "Login", Scenario: "Valid", Step: "I log in", FirstSeen: start.Unix(), LastSeen: start.Unix(), Builds: 1}}}
+
+	history.trackPendingSteps("main", []UnimplementedStep{
+		{Feature: "Checkout", Scenario: "Card", Step: "I pay by card", Status: "undefined"},
+		{Feature: "Checkout", Scenario: "Refund", Step: "I refund", Status: "pending"},
+	}, "1", start)
+
+	ages, implemented := history.trackPendingSteps("main", []UnimplementedStep{
+		{Feature: "Search", Scenario: "Find", Step: "I search", Status: "undefined"},
+		{Feature: "Checkout", Scenario: "Card", Step: "I pay by card", Status: "pending"},
+		{Feature: "Checkout", Scenario: "Card", Step: "I pay by card", Status: "pending"},
+	}, "2", start.Add(10*24*time.Hour))
+
+	if implemented != 1 {
+		t.Errorf("Expected 1 implemented step, got %d", implemented)
+	}
+	expected := []PendingStepAge{
+		{Feature: "Checkout", Scenario: "Card", Step: "I pay by card", Status: "pending", FirstSeenBuild: "1", FirstSeen: "2024-05-01T09:00:00Z", AgeDays: 10, Builds: 2},
+		{Feature: "Search", Scenario: "Find", Step: "I search", Status: "undefined", FirstSeenBuild: "2", FirstSeen: "2024-05-11T09:00:00Z", AgeDays: 0, Builds: 1},
+	}
+	if diff := cmp.Diff(expected, ages); diff != "" {
+		t.Errorf("Ages mismatch (-want +got):\n%s", diff)
+	}
+	if len(history.PendingSteps) != 3 || history.PendingSteps[0].Branch != "develop" {
+		t.Errorf("Expected the steps of the other branches to be kept, got %+v", history.PendingSteps)
+	}
+
+	path := filepath.Join(t.TempDir(), "pending.md")
+	if err := writePendingAgingReport(path, ages, Args{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if row := "| I pay by card | pending | Checkout :: Card | 10 days | 2 | #1 on 2024-05-01 |"; !strings.Contains(string(content), row) {
+		t.Errorf("Expected the report to contain %q, got:\n%s", row, content)
+	}
+
+	if err := validateInputs(Args{PendingAgingReport: path}); err == nil || !strings.Contains(err.Error(), "requires a HistoryFile") {
+		t.Errorf("Expected a missing history file error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Constants for Output Style
+const (
+	OutputStyleAuto  = "AUTO"
+	OutputStylePlain = "PLAIN"
+	OutputStyleRich  = "RICH"
+)
+
+// plainReplacer replaces the emoji of the log messages with ASCII labels. Decorative
+// emoji are removed along with the space following them.
+var plainReplacer = strings.NewReplacer(
+	"✅", "[PASS]",
+	"❌", "[FAIL]",
+	"⚠️", "[WARN]",
+	"🆕", "[NEW]",
+	"🔁", "[RECURRING]",
+	"🐢", "[SLOWER]",
+	"📈", "[UP]",
+	"📉", "[DOWN]",
+	"➖", "[=]",
+	"📁 ", "",
+	"📄 ", "",
+	"🔍 ", "",
+	"⏸️ ", "",
+	"🔄 ", "",
+	"❓ ", "",
+	"⏱️ ", "",
+	"🎥 ", "",
+	"›", ">",
+	"…", "...",
+	"—", "-",
+)
+
+// plainLogger is a Logger writing messages without emoji for log indexers.
+type plainLogger struct {
+	Logger
+}
+
+// plainText replaces the emoji of the message with ASCII labels and drops the remaining
+// pictographs with their presentation selectors. The letters of other scripts, such as
+// the accented or CJK names of the features and scenarios, are kept.
+func plainText(format string, args ...interface{}) string {
+	message := plainReplacer.Replace(fmt.Sprintf(format, args...))
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII && (unicode.Is(unicode.So, r) || unicode.Is(unicode.Variation_Selector, r) || r == '\u200d') {
+			return -1
+		}
+		return r
+	}, message)
+}
+
+// Debugf logs a plain message at the debug level.
+func (l plainLogger) Debugf(format string, args ...interface{}) {
+	l.Logger.Debugf("%s", plainText(format, args...))
+}
+
+// Infof logs a plain message at the info level.
+func (l plainLogger) Infof(format string, args ...interface{}) {
+	l.Logger.Infof("%s", plainText(format, args...))
+}
+
+// Warnf logs a plain message at the warning level.
+func (l plainLogger) Warnf(format string, args ...interface{}) {
+	l.Logger.Warnf("%s", plainText(format, args...))
+}
+
+// Errorf logs a plain message at the error level.
+func (l plainLogger) Errorf(format string, args ...interface{}) {
+	l.Logger.Errorf("%s", plainText(format, args...))
+}
+
+// WithFields returns a plain Logger adding the fields to the messages.
+func (l plainLogger) WithFields(fields map[string]interface{}) Logger {
+	return plainLogger{l.Logger.WithFields(fields)}
+}
+
+// validateOutputStyle checks the output style setting.
+func validateOutputStyle(args Args) error {
+	switch strings.ToUpper(args.OutputStyle) {
+	case "", OutputStyleAuto, OutputStylePlain, OutputStyleRich:
+		return nil
+	}
+	return fmt.Errorf("invalid OutputStyle value. It must be '%s', '%s' or '%s'", OutputStyleAuto, OutputStylePlain, OutputStyleRich)
+}
+
+// plainOutput reports whether the plain output is used. It is selected automatically
+// when the standard output is not a terminal.
+func plainOutput(style string, terminal bool) bool {
+	switch strings.ToUpper(style) {
+	case OutputStylePlain:
+		return true
+	case OutputStyleRich:
+		return false
+	default:
+		return !terminal
+	}
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// configureOutputStyle switches the logger to the plain output when selected.
+func configureOutputStyle(args Args) {
+	if _, plain := logger.(plainLogger); plain || !plainOutput(args.OutputStyle, isTerminal(os.Stdout)) {
+		return
+	}
+	logger = plainLogger{logger}
+}
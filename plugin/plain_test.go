@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestPlainLogger tests converting the log messages to ASCII
+func TestPlainLogger(t *testing.T) {
+	recorder := newRecordingLogger()
+	plain := plainLogger{recorder}
+
+	plain.Infof("📁 Total Features: %d\n", 3)
+	plain.Infof("%s: %s (Threshold: %s) %s\n", "Failed Steps", "2", "1", gateSymbol(false))
+	plain.Warnf("%s › %s failed in 3 of 5 builds ⚠️ …", "Checkout", "Pay")
+	plain.WithFields(map[string]interface{}{"File": "a.json"}).Errorf("⏱️ Duration: 12 ms 🐢")
+	plain.Infof("%s %s › %s 🧪\n", gateSymbol(true), "Café", "検索の結果")
+
+	expected := []string{
+		"INFO Total Features: 3",
+		"INFO Failed Steps: 2 (Threshold: 1) [FAIL]",
+		"WARN Checkout > Pay failed in 3 of 5 builds [WARN] ...",
+		"ERROR Duration: 12 ms [SLOWER] File=a.json",
+		"INFO [PASS] Café > 検索の結果",
+	}
+	if diff := cmp.Diff(expected, *recorder.messages); diff != "" {
+		t.Errorf("Messages mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestPlainOutput tests selecting the plain output
+func TestPlainOutput(t *testing.T) {
+	tests := []struct {
+		style    string
+		terminal bool
+		expected bool
+	}{
+		{style: "", terminal: true, expected: false},
+		{style: "", terminal: false, expected: true},
+		{style: "auto", terminal: false, expected: true},
+		{style: "PLAIN", terminal: true, expected: true},
+		{style: "rich", terminal: false, expected: false},
+	}
+
+	for _, tc := range tests {
+		if got := plainOutput(tc.style, tc.terminal); got != tc.expected {
+			t.Errorf("plainOutput(%q, %v): expected %v, got %v", tc.style, tc.terminal, tc.expected, got)
+		}
+	}
+}
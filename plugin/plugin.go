@@ -2,17 +2,16 @@ package plugin
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
-
-	"github.com/sirupsen/logrus"
+	"time"
 )
 
 // Constants for Sorting Method
@@ -21,11 +20,12 @@ const (
 	SortingMethodAlphabetical = "ALPHABETICAL"
 )
 
-// Args represents the plugin's configurable arguments.
+// Args represents the plugin's configurable arguments. The settings affecting the Results
+// computed from a report file are tagged with cache:"key" to be part of the cache keys.
 type Args struct {
 	FileIncludePattern          string  `envconfig:"PLUGIN_FILE_INCLUDE_PATTERN"`
 	FileExcludePattern          string  `envconfig:"PLUGIN_FILE_EXCLUDE_PATTERN"`
-	FailedAsNotFailingStatus    bool    `envconfig:"PLUGIN_FAILED_AS_NOT_FAILING_STATUS"`
+	FailedAsNotFailingStatus    bool    `envconfig:"PLUGIN_FAILED_AS_NOT_FAILING_STATUS" cache:"key"`
 	FailedFeaturesNumber        int     `envconfig:"PLUGIN_FAILED_FEATURES_NUMBER"`
 	FailedFeaturesPercentage    float64 `envconfig:"PLUGIN_FAILED_FEATURES_PERCENTAGE"`
 	FailedScenariosNumber       int     `envconfig:"PLUGIN_FAILED_SCENARIOS_NUMBER"`
@@ -33,33 +33,341 @@ type Args struct {
 	FailedStepsNumber           int     `envconfig:"PLUGIN_FAILED_STEPS_NUMBER"`
 	FailedStepsPercentage       float64 `envconfig:"PLUGIN_FAILED_STEPS_PERCENTAGE"`
 	JSONReportDirectory         string  `envconfig:"PLUGIN_JSON_REPORT_DIRECTORY"`
-	MergeFeaturesById           bool    `envconfig:"PLUGIN_MERGE_FEATURES_BY_ID"`
-	PendingAsNotFailingStatus   bool    `envconfig:"PLUGIN_PENDING_AS_NOT_FAILING_STATUS"`
+	RerunReportDirectory        string  `envconfig:"PLUGIN_RERUN_REPORT_DIRECTORY"`
+	AnnotationFiles             string  `envconfig:"PLUGIN_ANNOTATION_FILES"`
+	IgnoreFlaky                 bool    `envconfig:"PLUGIN_IGNORE_FLAKY"`
+	MergeFeaturesById           bool    `envconfig:"PLUGIN_MERGE_FEATURES_BY_ID" cache:"key"`
+	PendingAsNotFailingStatus   bool    `envconfig:"PLUGIN_PENDING_AS_NOT_FAILING_STATUS" cache:"key"`
 	PendingStepsNumber          int     `envconfig:"PLUGIN_PENDING_STEPS_NUMBER"`
 	PendingStepsPercentage      float64 `envconfig:"PLUGIN_PENDING_STEPS_PERCENTAGE"`
-	SkipEmptyJSONFiles          bool    `envconfig:"PLUGIN_SKIP_EMPTY_JSON_FILES"`
-	SkippedAsNotFailingStatus   bool    `envconfig:"PLUGIN_SKIPPED_AS_NOT_FAILING_STATUS"`
+	SkipEmptyJSONFiles          bool    `envconfig:"PLUGIN_SKIP_EMPTY_JSON_FILES" cache:"key"`
+	SkippedAsNotFailingStatus   bool    `envconfig:"PLUGIN_SKIPPED_AS_NOT_FAILING_STATUS" cache:"key"`
 	SkippedStepsNumber          int     `envconfig:"PLUGIN_SKIPPED_STEPS_NUMBER"`
 	SkippedStepsPercentage      float64 `envconfig:"PLUGIN_SKIPPED_STEPS_PERCENTAGE"`
-	SortingMethod               string  `envconfig:"PLUGIN_SORTING_METHOD"`
+	SortingMethod               string  `envconfig:"PLUGIN_SORTING_METHOD" cache:"key"`
 	StopBuildOnFailedReport     bool    `envconfig:"PLUGIN_STOP_BUILD_ON_FAILED_REPORT"`
-	UndefinedAsNotFailingStatus bool    `envconfig:"PLUGIN_UNDEFINED_AS_NOT_FAILING_STATUS"`
+	UndefinedAsNotFailingStatus bool    `envconfig:"PLUGIN_UNDEFINED_AS_NOT_FAILING_STATUS" cache:"key"`
 	UndefinedStepsNumber        int     `envconfig:"PLUGIN_UNDEFINED_STEPS_NUMBER"`
 	UndefinedStepsPercentage    float64 `envconfig:"PLUGIN_UNDEFINED_STEPS_PERCENTAGE"`
+	FeatureUndefinedPercentage  float64 `envconfig:"PLUGIN_FEATURE_UNDEFINED_STEPS_PERCENTAGE"`
+	MaxRetriedScenarios         int     `envconfig:"PLUGIN_MAX_RETRIED_SCENARIOS"`
+	IncludeTags                 string  `envconfig:"PLUGIN_INCLUDE_TAGS" cache:"key"`
+	ExcludeTags                 string  `envconfig:"PLUGIN_EXCLUDE_TAGS" cache:"key"`
 	Level                       string  `envconfig:"PLUGIN_LOG_LEVEL"`
+	ConfigFile                  string  `envconfig:"PLUGIN_CONFIG_FILE"`
+	ConfigSHA256                string  `envconfig:"PLUGIN_CONFIG_SHA256"`
+	ConfigCacheDir              string  `envconfig:"PLUGIN_CONFIG_CACHE_DIR"`
+	ConfigToken                 string  `envconfig:"PLUGIN_CONFIG_TOKEN"`
+	Profile                     string  `envconfig:"PLUGIN_PROFILE"`
+	HistoryFile                 string  `envconfig:"PLUGIN_HISTORY_FILE"`
+	TrendBuilds                 int     `envconfig:"PLUGIN_TREND_BUILDS"`
+	Branch                      string  `envconfig:"PLUGIN_BRANCH"`
+	HistoryMaxBuilds            int     `envconfig:"PLUGIN_HISTORY_MAX_BUILDS"`
+	HistoryMaxAgeDays           int     `envconfig:"PLUGIN_HISTORY_MAX_AGE_DAYS"`
+	FailOnNewFailuresOnly       bool    `envconfig:"PLUGIN_FAIL_ON_NEW_FAILURES_ONLY"`
+	DurationRegressionFactor    float64 `envconfig:"PLUGIN_DURATION_REGRESSION_FACTOR"`
+	FailOnDurationRegression    bool    `envconfig:"PLUGIN_FAIL_ON_DURATION_REGRESSION"`
+	QuarantineBuilds            int     `envconfig:"PLUGIN_QUARANTINE_BUILDS"`
+	QuarantineThreshold         float64 `envconfig:"PLUGIN_QUARANTINE_THRESHOLD"`
+	QuarantineFile              string  `envconfig:"PLUGIN_QUARANTINE_FILE"`
+	SLAs                        string  `envconfig:"PLUGIN_SLAS"`
+	DurationBudgets             string  `envconfig:"PLUGIN_DURATION_BUDGETS"`
+	MaxFeatureDuration          string  `envconfig:"PLUGIN_MAX_FEATURE_DURATION"`
+	HeatmapFile                 string  `envconfig:"PLUGIN_HEATMAP_FILE"`
+	HeatmapBuilds               int     `envconfig:"PLUGIN_HEATMAP_BUILDS"`
+	JUnitOutputPath             string  `envconfig:"PLUGIN_JUNIT_OUTPUT_PATH"`
+	HarnessTestReportPath       string  `envconfig:"PLUGIN_HARNESS_TEST_REPORT_PATH"`
+	UploadProvider              string  `envconfig:"PLUGIN_UPLOAD_PROVIDER"`
+	UploadBucket                string  `envconfig:"PLUGIN_UPLOAD_BUCKET"`
+	UploadPrefix                string  `envconfig:"PLUGIN_UPLOAD_PREFIX"`
+	UploadACL                   string  `envconfig:"PLUGIN_UPLOAD_ACL"`
+	UploadRegion                string  `envconfig:"PLUGIN_UPLOAD_REGION"`
+	UploadEndpoint              string  `envconfig:"PLUGIN_UPLOAD_ENDPOINT"`
+	UploadPathStyle             bool    `envconfig:"PLUGIN_UPLOAD_PATH_STYLE"`
+	UploadAccessKey             string  `envconfig:"PLUGIN_UPLOAD_ACCESS_KEY"`
+	UploadSecretKey             string  `envconfig:"PLUGIN_UPLOAD_SECRET_KEY"`
+	UploadSessionToken          string  `envconfig:"PLUGIN_UPLOAD_SESSION_TOKEN"`
+	UploadReport                string  `envconfig:"PLUGIN_UPLOAD_REPORT"`
+	Proxy                       string  `envconfig:"PLUGIN_PROXY"`
+	NoProxy                     string  `envconfig:"PLUGIN_NO_PROXY"`
+	CABundle                    string  `envconfig:"PLUGIN_CA_BUNDLE"`
+	AlertProvider               string  `envconfig:"PLUGIN_ALERT_PROVIDER"`
+	AlertRoutingKey             string  `envconfig:"PLUGIN_ALERT_ROUTING_KEY"`
+	AlertBranches               string  `envconfig:"PLUGIN_ALERT_BRANCHES"`
+	AlertAPIURL                 string  `envconfig:"PLUGIN_ALERT_API_URL"`
+	GrafanaURL                  string  `envconfig:"PLUGIN_GRAFANA_URL"`
+	GrafanaToken                string  `envconfig:"PLUGIN_GRAFANA_TOKEN"`
+	GrafanaDashboardUID         string  `envconfig:"PLUGIN_GRAFANA_DASHBOARD_UID"`
+	GrafanaPanelID              int     `envconfig:"PLUGIN_GRAFANA_PANEL_ID"`
+	GrafanaTags                 string  `envconfig:"PLUGIN_GRAFANA_TAGS"`
+	SlackWebhook                string  `envconfig:"PLUGIN_SLACK_WEBHOOK"`
+	SlackChannel                string  `envconfig:"PLUGIN_SLACK_CHANNEL"`
+	SlackNotifyOn               string  `envconfig:"PLUGIN_SLACK_NOTIFY_ON"`
+	SlackMaxFailures            int     `envconfig:"PLUGIN_SLACK_MAX_FAILURES"`
+	ConfluenceURL               string  `envconfig:"PLUGIN_CONFLUENCE_URL"`
+	ConfluenceUsername          string  `envconfig:"PLUGIN_CONFLUENCE_USERNAME"`
+	ConfluenceToken             string  `envconfig:"PLUGIN_CONFLUENCE_TOKEN"`
+	ConfluenceSpace             string  `envconfig:"PLUGIN_CONFLUENCE_SPACE"`
+	ConfluenceParentID          string  `envconfig:"PLUGIN_CONFLUENCE_PARENT_ID"`
+	ConfluencePageTitle         string  `envconfig:"PLUGIN_CONFLUENCE_PAGE_TITLE"`
+	GitHubToken                 string  `envconfig:"PLUGIN_GITHUB_TOKEN"`
+	GitHubAPIURL                string  `envconfig:"PLUGIN_GITHUB_API_URL"`
+	GoogleChatWebhook           string  `envconfig:"PLUGIN_GOOGLE_CHAT_WEBHOOK"`
+	GoogleChatNotifyOn          string  `envconfig:"PLUGIN_GOOGLE_CHAT_NOTIFY_ON"`
+	GoogleChatMaxFailures       int     `envconfig:"PLUGIN_GOOGLE_CHAT_MAX_FAILURES"`
+	TestLinkURL                 string  `envconfig:"PLUGIN_TESTLINK_URL"`
+	TestLinkDevKey              string  `envconfig:"PLUGIN_TESTLINK_DEV_KEY"`
+	TestLinkPlanID              int     `envconfig:"PLUGIN_TESTLINK_PLAN_ID"`
+	TestLinkBuild               string  `envconfig:"PLUGIN_TESTLINK_BUILD"`
+	TestLinkPlatform            string  `envconfig:"PLUGIN_TESTLINK_PLATFORM"`
+	TestLinkTagPrefix           string  `envconfig:"PLUGIN_TESTLINK_TAG_PREFIX"`
+	CucumberReportsToken        string  `envconfig:"PLUGIN_CUCUMBER_REPORTS_TOKEN"`
+	CucumberReportsURL          string  `envconfig:"PLUGIN_CUCUMBER_REPORTS_URL"`
+	FileTimeoutSeconds          int     `envconfig:"PLUGIN_FILE_TIMEOUT_SECONDS"`
+	CacheDir                    string  `envconfig:"PLUGIN_CACHE_DIR"`
+	DecimalPlaces               string  `envconfig:"PLUGIN_DECIMAL_PLACES"`
+	RoundingMode                string  `envconfig:"PLUGIN_ROUNDING_MODE"`
+	OutputStyle                 string  `envconfig:"PLUGIN_OUTPUT_STYLE"`
+	LogGroups                   string  `envconfig:"PLUGIN_LOG_GROUPS"`
+	SummaryFile                 string  `envconfig:"PLUGIN_SUMMARY_FILE"`
+	FeatureExportFile           string  `envconfig:"PLUGIN_FEATURE_EXPORT_FILE"`
+	FeatureDetailsDir           string  `envconfig:"PLUGIN_FEATURE_DETAILS_DIR"`
+	TimelineFile                string  `envconfig:"PLUGIN_TIMELINE_FILE"`
+	MaxFailedDetailsLogged      int     `envconfig:"PLUGIN_MAX_FAILED_DETAILS_LOGGED"`
+	MaxFailedDetails            int     `envconfig:"PLUGIN_MAX_FAILED_DETAILS"`
+	FailedDetailsOverflowFile   string  `envconfig:"PLUGIN_FAILED_DETAILS_OVERFLOW_FILE"`
+	SCMProvider                 string  `envconfig:"PLUGIN_SCM_PROVIDER"`
+	SCMPathPrefix               string  `envconfig:"PLUGIN_SCM_PATH_PREFIX"`
+	FailureSort                 string  `envconfig:"PLUGIN_FAILURE_SORT"`
+	ExpectedReportCount         int     `envconfig:"PLUGIN_EXPECTED_REPORT_COUNT"`
+	MissingReportsAction        string  `envconfig:"PLUGIN_MISSING_REPORTS_ACTION"`
+	MemoryBudgetMB              int     `envconfig:"PLUGIN_MEMORY_BUDGET_MB"`
+	MaxWorkers                  int     `envconfig:"PLUGIN_MAX_WORKERS"`
+	FileMemoryMB                int     `envconfig:"PLUGIN_FILE_MEMORY_MB"`
+	ScenarioManifest            string  `envconfig:"PLUGIN_SCENARIO_MANIFEST"`
+	MissingScenariosAction      string  `envconfig:"PLUGIN_MISSING_SCENARIOS_ACTION"`
+	ShardPattern                string  `envconfig:"PLUGIN_SHARD_PATTERN"`
+	ShardImbalanceFactor        float64 `envconfig:"PLUGIN_SHARD_IMBALANCE_FACTOR"`
+	ConsistencyViolationsAction string  `envconfig:"PLUGIN_CONSISTENCY_VIOLATIONS_ACTION"`
+	ReportDialect               string  `envconfig:"PLUGIN_REPORT_DIALECT" cache:"key"`
+	HTMLReportPath              string  `envconfig:"PLUGIN_HTML_REPORT_PATH"`
+	HTMLEmbedScreenshots        bool    `envconfig:"PLUGIN_HTML_EMBED_SCREENSHOTS" cache:"key"`
+	CucumberHTMLReportPath      string  `envconfig:"PLUGIN_CUCUMBER_HTML_REPORT_PATH"`
+	CucumberHTMLFormatter       string  `envconfig:"PLUGIN_CUCUMBER_HTML_FORMATTER"`
+	OutputFormats               string  `envconfig:"PLUGIN_OUTPUT_FORMATS"`
+	OutputDir                   string  `envconfig:"PLUGIN_OUTPUT_DIR"`
+	SkipStepSummary             bool    `envconfig:"PLUGIN_SKIP_STEP_SUMMARY"`
+	PDFReportPath               string  `envconfig:"PLUGIN_PDF_REPORT_PATH"`
+	XLSXReportPath              string  `envconfig:"PLUGIN_XLSX_REPORT_PATH"`
+	ReproDir                    string  `envconfig:"PLUGIN_REPRO_DIR"`
+	ReproRerunCommand           string  `envconfig:"PLUGIN_REPRO_RERUN_COMMAND"`
+	StepGapReport               string  `envconfig:"PLUGIN_STEP_GAP_REPORT"`
+	LintReport                  string  `envconfig:"PLUGIN_LINT_REPORT" cache:"key"`
+	FailOnLintWarnings          bool    `envconfig:"PLUGIN_FAIL_ON_LINT_WARNINGS" cache:"key"`
+	StepStatsReport             string  `envconfig:"PLUGIN_STEP_STATS_REPORT" cache:"key"`
+	PendingAgingReport          string  `envconfig:"PLUGIN_PENDING_AGING_REPORT" cache:"key"`
+	ReportGroups                string  `envconfig:"PLUGIN_REPORT_GROUPS"`
+	GateReport                  string  `envconfig:"PLUGIN_GATE_REPORT"`
+	Categories                  string  `envconfig:"PLUGIN_CATEGORIES" cache:"key"`
+	NotificationRoutes          string  `envconfig:"PLUGIN_NOTIFICATION_ROUTES"`
+	NotifyOnlyOnChange          bool    `envconfig:"PLUGIN_NOTIFY_ONLY_ON_CHANGE"`
+	TargetBranch                string  `envconfig:"PLUGIN_TARGET_BRANCH"`
+	BaselineSummary             string  `envconfig:"PLUGIN_BASELINE_SUMMARY"`
+	BaselineFile                string  `envconfig:"PLUGIN_BASELINE_FILE"`
+	ExportResults               string  `envconfig:"PLUGIN_EXPORT_RESULTS"`
+	MaxScenarioDropPercentage   float64 `envconfig:"PLUGIN_MAX_SCENARIO_DROP_PERCENTAGE"`
+	ScenarioDropAction          string  `envconfig:"PLUGIN_SCENARIO_DROP_ACTION"`
+	TimingsFile                 string  `envconfig:"PLUGIN_TIMINGS_FILE"`
+	TimingsDecay                float64 `envconfig:"PLUGIN_TIMINGS_DECAY"`
+	ChecksumFile                string  `envconfig:"PLUGIN_CHECKSUM_FILE"`
+	SigningKey                  string  `envconfig:"PLUGIN_SIGNING_KEY"`
+	AuditFile                   string  `envconfig:"PLUGIN_AUDIT_FILE"`
+	ScrubRules                  string  `envconfig:"PLUGIN_SCRUB_RULES"`
+	RedactNames                 string  `envconfig:"PLUGIN_REDACT_NAMES"`
+	HTTPTimeoutSeconds          int     `envconfig:"PLUGIN_HTTP_TIMEOUT_SECONDS"`
+	HTTPMaxAttempts             int     `envconfig:"PLUGIN_HTTP_MAX_ATTEMPTS"`
+	HTTPRetryBackoffMS          int     `envconfig:"PLUGIN_HTTP_RETRY_BACKOFF_MS"`
+	HTTPRateLimit               float64 `envconfig:"PLUGIN_HTTP_RATE_LIMIT"`
+	HTTPCircuitBreakerThreshold int     `envconfig:"PLUGIN_HTTP_CIRCUIT_BREAKER_THRESHOLD"`
+	HTTPCircuitCooldownSeconds  int     `envconfig:"PLUGIN_HTTP_CIRCUIT_COOLDOWN_SECONDS"`
+	TemplateDir                 string  `envconfig:"PLUGIN_TEMPLATE_DIR"`
+	OutputFile                  string  `envconfig:"PLUGIN_OUTPUT_FILE"`
+	OutputMode                  string  `envconfig:"PLUGIN_OUTPUT_MODE"`
+	OutputNamespace             string  `envconfig:"PLUGIN_OUTPUT_NAMESPACE"`
+	TypedOutputFile             string  `envconfig:"PLUGIN_TYPED_OUTPUT_FILE"`
+	TypedOutputs                string  `envconfig:"PLUGIN_TYPED_OUTPUTS"`
 }
 
 // ValidateInputs ensures the user inputs meet the plugin requirements.
 func ValidateInputs(args Args) error {
-	if args.FileIncludePattern == "" {
-		args.FileIncludePattern = "**/*.json" // Default pattern
-	}
+	return wrapError(ErrInvalidConfig, validateInputs(args))
+}
 
+// validateInputs checks the settings.
+func validateInputs(args Args) error {
 	if args.FailedFeaturesNumber < 0 || args.FailedScenariosNumber < 0 || args.FailedStepsNumber < 0 ||
-		args.PendingStepsNumber < 0 || args.SkippedStepsNumber < 0 || args.UndefinedStepsNumber < 0 {
+		args.PendingStepsNumber < 0 || args.SkippedStepsNumber < 0 || args.UndefinedStepsNumber < 0 || args.MaxRetriedScenarios < 0 ||
+		args.TrendBuilds < 0 || args.HistoryMaxBuilds < 0 || args.HistoryMaxAgeDays < 0 || args.DurationRegressionFactor < 0 || args.QuarantineBuilds < 0 || args.HeatmapBuilds < 0 || args.SlackMaxFailures < 0 || args.GoogleChatMaxFailures < 0 || args.FileTimeoutSeconds < 0 || args.MaxFailedDetailsLogged < 0 || args.MaxFailedDetails < 0 || args.ExpectedReportCount < 0 || args.MemoryBudgetMB < 0 || args.MaxWorkers < 0 || args.FileMemoryMB < 0 ||
+		args.HTTPTimeoutSeconds < 0 || args.HTTPMaxAttempts < 0 || args.HTTPRetryBackoffMS < 0 || args.HTTPRateLimit < 0 || args.HTTPCircuitBreakerThreshold < 0 || args.HTTPCircuitCooldownSeconds < 0 {
 		return errors.New("threshold values must be non-negative. Check the configured values")
 	}
 
+	if err := validatePercentages(args); err != nil {
+		return err
+	}
+
+	if err := validateFilePattern("FileIncludePattern", args.FileIncludePattern); err != nil {
+		return err
+	}
+
+	if err := validateExcludePatterns("FileExcludePattern", args.FileExcludePattern); err != nil {
+		return err
+	}
+
+	if _, err := parseSLAs(args.SLAs); err != nil {
+		return err
+	}
+
+	if _, err := parseDurationBudgets(args.DurationBudgets); err != nil {
+		return err
+	}
+
+	if _, err := parseMaxFeatureDuration(args.MaxFeatureDuration); err != nil {
+		return err
+	}
+
+	if _, err := parseCategories(args.Categories); err != nil {
+		return err
+	}
+
+	if _, err := parseReportGroups(args.ReportGroups); err != nil {
+		return err
+	}
+
+	if _, err := parseNotificationRoutes(args); err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(args.BaselineSummary, baselineObjectScheme) && args.UploadBucket == "" {
+		return errors.New("a BaselineSummary object key requires an UploadBucket")
+	}
+
+	if args.PendingAgingReport != "" && args.HistoryFile == "" {
+		return errors.New("PendingAgingReport requires a HistoryFile to track the unimplemented steps across builds")
+	}
+
+	if args.NotifyOnlyOnChange && args.HistoryFile == "" {
+		return errors.New("NotifyOnlyOnChange requires a HistoryFile to compare the build with the previous build")
+	}
+
+	if _, err := parseScrubRules(args.ScrubRules); err != nil {
+		return err
+	}
+
+	if err := validateTemplateDir(args); err != nil {
+		return err
+	}
+
+	if err := validateTimingsDecay(args); err != nil {
+		return err
+	}
+
+	if err := validateSigningKey(args); err != nil {
+		return err
+	}
+
+	if err := validateNumberFormat(args); err != nil {
+		return err
+	}
+
+	if err := validateOutputStyle(args); err != nil {
+		return err
+	}
+
+	if err := validateOutputArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateLogGroups(args); err != nil {
+		return err
+	}
+
+	if err := validateRedactNames(args); err != nil {
+		return err
+	}
+
+	if err := validateUploadArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateHTTPArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateAlertArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateConfluenceArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateTestLinkArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateSCMArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateFailureSort(args); err != nil {
+		return err
+	}
+
+	if err := validateShardArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateDialect(args); err != nil {
+		return err
+	}
+
+	if err := validateReproArgs(args); err != nil {
+		return err
+	}
+
+	if err := validateCucumberHTMLArgs(args, runtime.GOOS); err != nil {
+		return err
+	}
+
+	if args.MissingReportsAction != "" && !strings.EqualFold(args.MissingReportsAction, ActionFail) && !strings.EqualFold(args.MissingReportsAction, ActionWarn) {
+		return fmt.Errorf("invalid MissingReportsAction value. It must be '%s' or '%s'", ActionFail, ActionWarn)
+	}
+
+	if args.MissingScenariosAction != "" && !strings.EqualFold(args.MissingScenariosAction, ActionFail) && !strings.EqualFold(args.MissingScenariosAction, ActionWarn) {
+		return fmt.Errorf("invalid MissingScenariosAction value. It must be '%s' or '%s'", ActionFail, ActionWarn)
+	}
+
+	if _, err := parseOutputFormats(args.OutputFormats); err != nil {
+		return err
+	}
+
+	if args.ScenarioDropAction != "" && !strings.EqualFold(args.ScenarioDropAction, ActionFail) && !strings.EqualFold(args.ScenarioDropAction, ActionWarn) {
+		return fmt.Errorf("invalid ScenarioDropAction value. It must be '%s' or '%s'", ActionFail, ActionWarn)
+	}
+
+	if args.ConsistencyViolationsAction != "" && !strings.EqualFold(args.ConsistencyViolationsAction, ActionFail) && !strings.EqualFold(args.ConsistencyViolationsAction, ActionWarn) {
+		return fmt.Errorf("invalid ConsistencyViolationsAction value. It must be '%s' or '%s'", ActionFail, ActionWarn)
+	}
+
+	if args.SlackNotifyOn != "" && !strings.EqualFold(args.SlackNotifyOn, NotifyOnAlways) && !strings.EqualFold(args.SlackNotifyOn, NotifyOnFailure) {
+		return fmt.Errorf("invalid SlackNotifyOn value. It must be '%s' or '%s'", NotifyOnAlways, NotifyOnFailure)
+	}
+
+	if args.GoogleChatNotifyOn != "" && !strings.EqualFold(args.GoogleChatNotifyOn, NotifyOnAlways) && !strings.EqualFold(args.GoogleChatNotifyOn, NotifyOnFailure) {
+		return fmt.Errorf("invalid GoogleChatNotifyOn value. It must be '%s' or '%s'", NotifyOnAlways, NotifyOnFailure)
+	}
+
 	// Set default SortingMethod to NATURAL if not provided
 	if args.SortingMethod == "" {
 		args.SortingMethod = SortingMethodNatural
@@ -73,107 +381,618 @@ func ValidateInputs(args Args) error {
 	return nil
 }
 
+// fileError records a report file that could not be processed.
+type fileError struct {
+	file string
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return fmt.Sprintf("failed to process file %s: %v", e.file, e.err)
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
 // Exec handles Cucumber JSON report processing and logs details.
 func Exec(ctx context.Context, args Args) error {
-	files, err := locateFiles(args.JSONReportDirectory, args.FileIncludePattern, args.FileExcludePattern)
+	configureNumberFormat(args)
+	configureOutputStyle(args)
+	configureLogGroups(args)
+	configureMemoryBudget(args)
+	configureOutput(args)
+
+	// Locate the files of every report group, or of the report directory without groups
+	var files []string
+	var fileGroups map[string][]string
+	var err error
+	groups, _ := parseReportGroups(args.ReportGroups)
+	if len(groups) > 0 {
+		files, fileGroups, err = locateGroupFiles(groups, args)
+	} else {
+		files, err = locateFiles(args.JSONReportDirectory, args.FileIncludePattern, args.FileExcludePattern)
+	}
 	if err != nil {
-		logger := logrus.WithError(err)
-		logger.Error("Error locating files")
-		return errors.New("failed to locate files: " + err.Error())
+		logger.WithFields(map[string]interface{}{"error": err}).Errorf("Error locating files")
+		return writePartialResults(Results{}, args, wrapError(ErrNoReports, errors.New("failed to locate files: "+err.Error())))
 	}
 
 	if len(files) == 0 {
-		return errors.New("no Cucumber JSON report files found. Check the report file pattern")
+		return writePartialResults(Results{}, args, wrapError(ErrNoReports, errors.New("no Cucumber JSON report files found. Check the report file pattern")))
 	}
 
-	var (
-		resultsChan = make(chan Results, len(files))
-		errorsChan  = make(chan error, len(files))
-	)
-
-	var wg sync.WaitGroup
-	maxWorkers := 5 // Adjust this based on system capacity
-	sem := make(chan struct{}, maxWorkers)
-
-	for _, file := range files {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(f string) {
-
-			defer wg.Done()
-			defer func() { <-sem }()
-			res, err := processFile(f, args.SkipEmptyJSONFiles, args)
-			if err != nil {
-				errorsChan <- fmt.Errorf("failed to process file %s: %w", f, err)
-				return
-			}
-			resultsChan <- res
-		}(file)
-	}
-	wg.Wait()
+	maxWorkers := workerCount(files, args)
+	timeout := fileTimeout(args)
+	endGroup := startLogGroup(fmt.Sprintf("Processing %d report files", len(files)))
+	logger.Infof("Processing %d files with %d workers", len(files), maxWorkers)
+	outcomes := processFiles(ctx, files, maxWorkers, func(ctx context.Context, file string) (Results, error) {
+		return processFileWithDeadline(ctx, file, args, timeout)
+	})
 
 	var aggregatedResults Results
 	var skippedFiles []string
-
-	var mu sync.Mutex
-	for i := 0; i < len(files); i++ {
-		select {
-		case res := <-resultsChan:
-			mu.Lock()
-			aggregatedResults.FeatureCount += res.FeatureCount
-			aggregatedResults.ScenarioCount += res.ScenarioCount
-			aggregatedResults.StepCount += res.StepCount
-			aggregatedResults.PassedTests += res.PassedTests
-			aggregatedResults.FailedTests += res.FailedTests
-			aggregatedResults.SkippedTests += res.SkippedTests
-			aggregatedResults.PendingTests += res.PendingTests
-			aggregatedResults.UndefinedTests += res.UndefinedTests
-			aggregatedResults.DurationMS += res.DurationMS
-			aggregatedResults.FailedSteps = append(aggregatedResults.FailedSteps, res.FailedSteps...)
-			aggregatedResults.TotalFailedFeatures += res.TotalFailedFeatures
-			aggregatedResults.TotalPassedFeatures += res.TotalPassedFeatures
-			aggregatedResults.TotalFailedScenarios += res.TotalFailedScenarios
-			aggregatedResults.TotalPassedScenarios += res.TotalPassedScenarios
-			aggregatedResults.TotalFailedSteps += res.TotalFailedSteps
-			aggregatedResults.TotalPassedSteps += res.TotalPassedSteps
-			mu.Unlock()
-		case err := <-errorsChan:
-			logrus.Warn(err)
-			if e, ok := err.(*os.PathError); ok {
-				skippedFiles = append(skippedFiles, e.Path)
+	var droppedFailedSteps int
+	shards := make(map[string]*ShardResult)
+	shardPattern, _ := regexp.Compile(args.ShardPattern)
+	overflow := newFailureOverflow(args)
+	groupTotals := make(map[string]*Results)
+	for _, outcome := range outcomes {
+		if outcome.err != nil {
+			aggregatedResults.FileErrors = append(aggregatedResults.FileErrors, newFileError(outcome.file, outcome.err))
+			skippedFiles = append(skippedFiles, outcome.file)
+			continue
+		}
+		res := outcome.results
+		// Keep the fingerprints of every failure before the details are trimmed
+		res.AllFailures = historyFailures(res.FailedSteps)
+		overflow.retain(&res, len(aggregatedResults.FailedSteps))
+		droppedFailedSteps += degradeResults(&res, checkMemoryBudget(), len(aggregatedResults.FailedSteps))
+		if args.ShardPattern != "" {
+			if label := shardLabel(shardPattern, outcome.file); label != "" {
+				for i := range res.Scenarios {
+					res.Scenarios[i].Shard = label
+				}
+				addShardResults(shards, label, res)
 			}
 		}
+		aggregateResults(&aggregatedResults, res)
+		for _, name := range fileGroups[outcome.file] {
+			if groupTotals[name] == nil {
+				groupTotals[name] = &Results{}
+			}
+			aggregateResults(groupTotals[name], res)
+			groupTotals[name].ReportCount++
+		}
+	}
+	aggregatedResults.Shards = shardResults(shards, args.ShardImbalanceFactor)
+	aggregatedResults.ConsistencyViolations = findConsistencyViolations(aggregatedResults.Scenarios)
+	countRetries(&aggregatedResults)
+	sortStepGaps(aggregatedResults.StepGaps)
+	sortStepStats(aggregatedResults.StepStats)
+	aggregatedResults.Environments = environmentBreakdown(aggregatedResults.Scenarios)
+	categories, _ := parseCategories(args.Categories)
+	aggregatedResults.Categories = categoryResults(aggregatedResults.Scenarios, categories)
+	if len(groups) > 0 {
+		aggregatedResults.ReportGroups = reportGroupResults(groups, groupTotals)
 	}
 
 	// Log skipped files
 	if len(skippedFiles) > 0 {
-		logrus.Warnf("Skipped %d files due to errors: %v", len(skippedFiles), skippedFiles)
+		logger.Warnf("Skipped %d files due to errors: %v", len(skippedFiles), skippedFiles)
+	}
+	aggregatedResults.ReportCount = len(files) - len(skippedFiles)
+	if args.ScenarioManifest != "" {
+		if expected, err := loadManifest(args.ScenarioManifest); err != nil {
+			logger.Warnf("Failed to read scenario manifest %s: %v", args.ScenarioManifest, err)
+		} else {
+			aggregatedResults.MissingScenarios = missingScenarios(expected, aggregatedResults.Scenarios)
+			logger.Infof("Found %d of the %d scenarios of the manifest", len(expected)-len(aggregatedResults.MissingScenarios), len(expected))
+		}
+	}
+	if overflow != nil && overflow.count > 0 {
+		aggregatedResults.OverflowedFailedSteps = overflow.count
+		if err := overflow.close(); err != nil {
+			logger.Warnf("Failed to write the %d failed step details beyond the first %d to %s: %v", overflow.count, args.MaxFailedDetails, overflow.path, err)
+		} else {
+			aggregatedResults.FailedStepsOverflowFile = overflow.path
+			logger.Warnf("Retained the first %d failed step details, the %d others are written to %s", args.MaxFailedDetails, overflow.count, overflow.path)
+		}
+	}
+	if droppedFailedSteps > 0 {
+		logger.Warnf("Dropped %d failed step records to stay within the memory budget. The counts, the failure classification and the history still include them", droppedFailedSteps)
+	}
+	endGroup()
+
+	// Reconcile the failed scenarios with their rerun to tell the flaky ones apart
+	if args.RerunReportDirectory != "" {
+		endGroup = startLogGroup("Rerun Reconciliation")
+		if reruns, err := rerunScenarios(ctx, args); err != nil {
+			logger.Warnf("Failed to locate the rerun reports in %s: %v", args.RerunReportDirectory, err)
+		} else {
+			reconcileReruns(&aggregatedResults, reruns, args.IgnoreFlaky)
+			logFlakyScenarios(aggregatedResults.FlakyScenarios, args.IgnoreFlaky)
+			writeFlakyStats(aggregatedResults, logger)
+		}
+		endGroup()
 	}
 
+	// Attach the metadata of the previous steps, such as the deployed version
+	if args.AnnotationFiles != "" {
+		aggregatedResults.Annotations = loadAnnotations(args.AnnotationFiles)
+	}
+
+	// Link the failed steps to their feature files in the repository
+	linkFailures(&aggregatedResults, args)
+	sortFailures(aggregatedResults.FailedSteps, args.FailureSort)
+
+	// Break the results down by feature
+	aggregatedResults.Features = featureStats(aggregatedResults)
+
+	// Hide the names from the external services when configured
+	configureNameRedaction(args, aggregatedResults)
+
+	// Compare failures and durations with the recent builds recorded in the history file
+	var history *History
+	var previous []HistoryEntry
+	if args.HistoryFile != "" {
+		history = openHistory(args.HistoryFile)
+		previous = history.branchEntries(currentBranch(args))
+		classifyFailures(&aggregatedResults, previous, args.TrendBuilds)
+		detectDurationRegressions(&aggregatedResults, previous, args)
+		recommendQuarantine(&aggregatedResults, previous, args)
+	}
+
+	// Compare the build with the last build of the target branch on pull requests
+	if args.BaselineSummary != "" || history != nil {
+		aggregatedResults.Baseline = resolveBaseline(ctx, aggregatedResults, history, args)
+	}
+
+	// Compare the scenarios with the results exported by a previous run
+	if args.BaselineFile != "" {
+		aggregatedResults.BaselineDelta = resolveBaselineDelta(aggregatedResults, args)
+	}
+	aggregatedResults.ScenarioDrift = scenarioDrift(aggregatedResults, previous)
+
 	// Log aggregated results
-	logAggregatedResults(aggregatedResults)
+	endGroup = startLogGroup("Cucumber Test Report Summary")
+	logAggregatedResults(aggregatedResults, args)
+	logScenarioDrift(aggregatedResults.ScenarioDrift)
+	logShardResults(aggregatedResults.Shards)
+	logConsistencyViolations(aggregatedResults.ConsistencyViolations)
+	logEnvironmentBreakdown(aggregatedResults.Environments)
+	logCategoryResults(aggregatedResults.Categories)
+	logReportGroups(aggregatedResults.ReportGroups)
+	logStepGaps(aggregatedResults.StepGaps)
+	logLintWarnings(aggregatedResults.LintWarnings, args)
+	logFailingSteps(aggregatedResults.StepStats)
+	endGroup()
+
+	if aggregatedResults.Baseline != nil {
+		endGroup = startLogGroup("Baseline Comparison")
+		logBaselineComparison(aggregatedResults.Baseline)
+		writeBaselineStats(aggregatedResults.Baseline, logger)
+		endGroup()
+	}
+	if aggregatedResults.BaselineDelta != nil {
+		endGroup = startLogGroup("Baseline Delta")
+		logBaselineDelta(aggregatedResults.BaselineDelta)
+		writeBaselineDeltaStats(aggregatedResults.BaselineDelta, logger)
+		endGroup()
+	}
 
 	// Write stats to file
-	writeTestStats(aggregatedResults, logrus.New())
+	writeTestStats(aggregatedResults, logger)
+	writeShardStats(aggregatedResults.Shards, logger)
+	writeCategoryStats(aggregatedResults.Categories, logger)
+	writeReportGroupStats(aggregatedResults.ReportGroups, logger)
+	writeLintStats(aggregatedResults, args, logger)
+	writeScenarioDriftStats(aggregatedResults.ScenarioDrift, logger)
+	if err := WriteEnvToFile("SKIPPED_FILES", strconv.Itoa(len(skippedFiles)), logger); err != nil {
+		logger.Errorf("Error writing SKIPPED_FILES: %s", err)
+	}
+
+	// Write the JSON summary with every failed step
+	if args.SummaryFile != "" {
+		if err := writeSummary(args.SummaryFile, aggregatedResults); err != nil {
+			logger.Warnf("Failed to write summary %s: %v", args.SummaryFile, err)
+		}
+	}
+
+	// Export the scenario results for the next run to compare with
+	if args.ExportResults != "" {
+		if err := exportResults(args.ExportResults, aggregatedResults, args); err != nil {
+			logger.Warnf("Failed to export the results to %s: %v", args.ExportResults, err)
+		}
+	}
+
+	// Write the per-feature breakdown for the dashboards loading it at once
+	if args.FeatureExportFile != "" {
+		if err := writeFeatureExport(args.FeatureExportFile, aggregatedResults); err != nil {
+			logger.Warnf("Failed to write the feature breakdown %s: %v", args.FeatureExportFile, err)
+		} else {
+			logger.Infof("Breakdown of %d features written to %s\n", len(aggregatedResults.Features), args.FeatureExportFile)
+		}
+	}
+
+	// Write the details of every feature for the dashboards loading them on demand
+	if args.FeatureDetailsDir != "" {
+		if count, err := writeFeatureDetails(args.FeatureDetailsDir, aggregatedResults); err != nil {
+			logger.Warnf("Failed to write feature details to %s: %v", args.FeatureDetailsDir, err)
+		} else {
+			logger.Infof("Details of %d features written to %s\n", count, args.FeatureDetailsDir)
+		}
+	}
+
+	// Write the execution timeline revealing the scheduling gaps of the parallel runs
+	if args.TimelineFile != "" {
+		if timeline := buildTimeline(aggregatedResults.Scenarios); timeline == nil {
+			logger.Infof("No scenario has a start timestamp, the timeline is not written\n")
+		} else if err := writeJSONFile(args.TimelineFile, timeline); err != nil {
+			logger.Warnf("Failed to write timeline %s: %v", args.TimelineFile, err)
+		} else {
+			logTimeline(timeline)
+			logger.Infof("Timeline written to %s\n", args.TimelineFile)
+		}
+	}
+
+	// Write the JUnit XML reports ingested by the Harness Tests tab and other CI tools
+	for _, path := range junitOutputPaths(args) {
+		if err := writeJUnitReport(path, aggregatedResults); err != nil {
+			logger.Warnf("Failed to write test report %s: %v", path, err)
+		} else {
+			logger.Infof("Test report written to %s\n", path)
+		}
+	}
+
+	// Write the undefined steps with their suggested definitions
+	if args.StepGapReport != "" {
+		if err := writeStepGapReport(args.StepGapReport, aggregatedResults.StepGaps, args); err != nil {
+			logger.Warnf("Failed to write step gap report %s: %v", args.StepGapReport, err)
+		}
+	}
+
+	// Write the scenario anti-patterns
+	if args.LintReport != "" {
+		if err := writeLintReport(args.LintReport, aggregatedResults.LintWarnings, args); err != nil {
+			logger.Warnf("Failed to write lint report %s: %v", args.LintReport, err)
+		}
+	}
+
+	// Write the usage and failures of the logical steps
+	if args.StepStatsReport != "" {
+		if err := writeStepStatsReport(args.StepStatsReport, aggregatedResults.StepStats, args); err != nil {
+			logger.Warnf("Failed to write step statistics report %s: %v", args.StepStatsReport, err)
+		}
+	}
+
+	// Write the Excel workbook consumed by the QA management
+	if args.XLSXReportPath != "" {
+		if err := writeXLSXReport(args.XLSXReportPath, aggregatedResults); err != nil {
+			logger.Warnf("Failed to write Excel report %s: %v", args.XLSXReportPath, err)
+		} else {
+			logger.Infof("Excel report written to %s\n", args.XLSXReportPath)
+		}
+	}
+
+	// Write a reproduction bundle for every failed scenario
+	if args.ReproDir != "" && aggregatedResults.TotalFailedScenarios > 0 {
+		count, err := writeReproBundles(args.ReproDir, files, args)
+		if err != nil {
+			logger.Warnf("Failed to write reproduction bundles to %s: %v", args.ReproDir, err)
+		} else {
+			logger.Infof("%d reproduction bundles written to %s\n", count, args.ReproDir)
+		}
+		if err := WriteEnvToFile("REPRO_BUNDLES", strconv.Itoa(count), logger); err != nil {
+			logger.Errorf("Error writing REPRO_BUNDLES: %s", err)
+		}
+	}
+
+	// Publish the merged report to the Cucumber Reports service
+	if args.CucumberReportsToken != "" {
+		shareCucumberReport(ctx, args, files)
+	}
+
+	// Report the compliance of the per-tag SLAs
+	if slas, _ := parseSLAs(args.SLAs); len(slas) > 0 {
+		slaResults := evaluateSLAs(slas, aggregatedResults, previous, args)
+		logSLAResults(slaResults)
+		writeSLAStats(slaResults, logger)
+	}
+
+	// Compare the build with the recent builds and record it in the history file
+	var trend *Trend
+	if history != nil {
+		writeFailureClassificationStats(aggregatedResults, logger)
+		writeDurationRegressionStats(aggregatedResults, logger)
+		writeQuarantineStats(aggregatedResults, logger)
+		if args.QuarantineFile != "" {
+			if err := writeQuarantineFile(args.QuarantineFile, aggregatedResults.QuarantineRecommendations); err != nil {
+				logger.Warnf("Failed to write quarantine file %s: %v", args.QuarantineFile, err)
+			}
+		}
+		if args.PendingAgingReport != "" {
+			ages, implemented := history.trackPendingSteps(currentBranch(args), aggregatedResults.UnimplementedSteps, currentBuildNumber(), time.Now())
+			logPendingAging(ages, implemented)
+			writePendingAgingStats(ages, logger)
+			if err := writePendingAgingReport(args.PendingAgingReport, ages, args); err != nil {
+				logger.Warnf("Failed to write pending aging report %s: %v", args.PendingAgingReport, err)
+			}
+		}
+		recorded := recordHistory(history, aggregatedResults, args)
+		trend = &recorded
+
+		if args.HeatmapFile != "" {
+			heatmap := buildHeatmap(history.branchEntries(currentBranch(args)), args.HeatmapBuilds)
+			if err := writeHeatmap(args.HeatmapFile, heatmap); err != nil {
+				logger.Warnf("Failed to write heatmap file %s: %v", args.HeatmapFile, err)
+			}
+		}
+	}
+
+	// Write the scenario durations used to split the next runs by duration, averaged
+	// with the durations of the previous builds when recorded in the history file
+	if args.TimingsFile != "" {
+		timings := scenarioTimings(aggregatedResults.Scenarios)
+		if history != nil {
+			timings = history.timings()
+		}
+		if err := writeTimings(args.TimingsFile, timings); err != nil {
+			logger.Warnf("Failed to write timings file %s: %v", args.TimingsFile, err)
+		}
+	}
+
+	// Skip the notifications when the build fails the same way as the previous build, or
+	// is still green, so that the channels are not flooded by every nightly run
+	notify := true
+	if args.NotifyOnlyOnChange && history != nil && !outcomeChanged(previous, aggregatedResults) {
+		logger.Infof("Notifications skipped, the outcome is unchanged from the previous build\n")
+		notify = false
+	}
+
+	// Evaluate the quality gates and notify the configured services if any fails. The
+	// alerts are left to the notification routes when there are
+	endGroup = startLogGroup("Quality Gates")
+	gateErr := wrapError(ErrGateViolation, evaluateGates(aggregatedResults, args, history != nil))
+	endGroup()
+	if gateErr != nil {
+		notifyGateFailure(ctx, aggregatedResults, args, gateErr, notify && args.NotificationRoutes == "")
+	}
+
+	// Write the gate evaluation for the reviewers who do not open the build logs
+	if args.GateReport != "" {
+		if err := writeGateReport(args.GateReport, aggregatedResults, args, trend, gateErr); err != nil {
+			logger.Warnf("Failed to write gate report %s: %v", args.GateReport, err)
+		} else {
+			logger.Infof("Gate report written to %s\n", args.GateReport)
+		}
+	}
+
+	// Append the summary to the job summary shown on the GitHub Actions run page
+	if path := githubStepSummary(args); path != "" {
+		if err := appendStepSummary(path, aggregatedResults, args, trend, gateErr); err != nil {
+			logger.Warnf("Failed to write GitHub step summary %s: %v", path, err)
+		}
+	}
+
+	// Render the HTML summary attached to the build
+	if args.HTMLReportPath != "" {
+		if err := writeHTMLReport(args.HTMLReportPath, aggregatedResults, args, trend, gateErr); err != nil {
+			logger.Warnf("Failed to write HTML report %s: %v", args.HTMLReportPath, err)
+		} else {
+			logger.Infof("HTML report written to %s\n", args.HTMLReportPath)
+		}
+	}
+
+	// Render the canonical Cucumber HTML report next to the plugin's own
+	if args.CucumberHTMLReportPath != "" {
+		if err := writeCucumberHTMLReport(ctx, args.CucumberHTMLReportPath, files, args); err != nil {
+			logger.Warnf("Failed to write Cucumber HTML report %s: %v", args.CucumberHTMLReportPath, err)
+		} else {
+			logger.Infof("Cucumber HTML report written to %s\n", args.CucumberHTMLReportPath)
+		}
+	}
+
+	// Write the selected output formats to the shared output directory
+	if args.OutputFormats != "" {
+		writeOutputFormats(reportOutput{results: aggregatedResults, args: args, trend: trend, gateErr: gateErr})
+	}
+
+	// Upload the report to object storage
+	var reportURL string
+	if args.UploadBucket != "" {
+		reportURL = publishReport(ctx, args)
+	}
+
+	if notify && args.NotificationRoutes != "" {
+		// Let the routes decide which channels fire
+		routes, _ := parseNotificationRoutes(args)
+		routeNotifications(ctx, routes, aggregatedResults, args, reportURL, trend, gateErr)
+	} else if notify {
+		// Send the results to Slack
+		if args.SlackWebhook != "" {
+			notifySlack(ctx, aggregatedResults, args, reportURL, gateErr)
+		}
+
+		// Send the results to Google Chat
+		if args.GoogleChatWebhook != "" {
+			notifyGoogleChat(ctx, aggregatedResults, args, reportURL, gateErr)
+		}
+	}
+
+	// Report the test case results to TestLink
+	if args.TestLinkURL != "" {
+		reportTestLinkResults(ctx, aggregatedResults, args)
+	}
+
+	// Publish the summary to Confluence
+	if args.ConfluenceURL != "" {
+		publishConfluencePage(ctx, aggregatedResults, args, trend, gateErr)
+	}
+
+	// Comment the summary on the pull request, updating the comment of a previous run
+	if args.GitHubToken != "" {
+		publishGitHubComment(ctx, aggregatedResults, args, reportURL, trend, gateErr)
+	}
+
+	// Render the PDF summary attached to the release sign-off documents
+	if args.PDFReportPath != "" {
+		if err := writePDFReport(args.PDFReportPath, aggregatedResults, args, trend, gateErr); err != nil {
+			logger.Warnf("Failed to write PDF report %s: %v", args.PDFReportPath, err)
+		} else {
+			logger.Infof("PDF report written to %s\n", args.PDFReportPath)
+		}
+	}
+
+	// Record the evidence of the build required by regulated release processes
+	if args.AuditFile != "" {
+		if err := writeAuditLog(args.AuditFile, aggregatedResults, args, files, gateErr); err != nil {
+			logger.Warnf("Failed to write audit log %s: %v", args.AuditFile, err)
+		} else {
+			logger.Infof("Audit log written to %s\n", args.AuditFile)
+		}
+	}
+
+	// Record the checksums of the artifacts so that the next stages can verify them
+	if args.ChecksumFile != "" {
+		count, checksum, err := writeChecksums(args.ChecksumFile, args)
+		if err != nil {
+			logger.Warnf("Failed to write checksum file %s: %v", args.ChecksumFile, err)
+		} else {
+			logger.Infof("Checksums of %d artifacts written to %s\n", count, args.ChecksumFile)
+			if err := WriteEnvToFile("CHECKSUMS_SHA256", checksum, logger); err != nil {
+				logger.Errorf("Error writing CHECKSUMS_SHA256: %s", err)
+			}
+		}
+	}
+
+	writeErrorFlag(gateErr, logger)
+	return gateErr
+}
+
+// newFileError logs the error of a report file and describes it for the summary,
+// including the stack of a recovered panic.
+func newFileError(file string, err error) FileError {
+	fileErr := FileError{File: file, Code: ErrorCode(err), Message: err.Error()}
+
+	var panicErr *panicError
+	if errors.As(err, &panicErr) {
+		fileErr.Stack = panicErr.stack
+		logger.WithFields(map[string]interface{}{"file": file, "stack": panicErr.stack}).Errorf("Recovered from a panic while processing file %s: %v", file, panicErr.value)
+		return fileErr
+	}
+	logger.Warnf("%v", &fileError{file: file, err: err})
+	return fileErr
+}
+
+// aggregateResults adds the results of a report file to the aggregated results.
+func aggregateResults(total *Results, res Results) {
+	total.FeatureCount += res.FeatureCount
+	total.ScenarioCount += res.ScenarioCount
+	total.StepCount += res.StepCount
+	total.PassedTests += res.PassedTests
+	total.FailedTests += res.FailedTests
+	total.SkippedTests += res.SkippedTests
+	total.PendingTests += res.PendingTests
+	total.UndefinedTests += res.UndefinedTests
+	total.DurationMS += res.DurationMS
+	total.FailedSteps = append(total.FailedSteps, res.FailedSteps...)
+	total.AllFailures = append(total.AllFailures, failureRecords(res)...)
+	total.TotalFailedFeatures += res.TotalFailedFeatures
+	total.TotalPassedFeatures += res.TotalPassedFeatures
+	total.TotalFailedScenarios += res.TotalFailedScenarios
+	total.TotalPassedScenarios += res.TotalPassedScenarios
+	total.TotalFailedSteps += res.TotalFailedSteps
+	total.TotalPassedSteps += res.TotalPassedSteps
+	total.Scenarios = append(total.Scenarios, res.Scenarios...)
+	total.StepRetries += res.StepRetries
+	total.StepGaps = mergeStepGaps(total.StepGaps, res.StepGaps)
+	total.LintWarnings = append(total.LintWarnings, res.LintWarnings...)
+	total.StepStats = mergeStepStats(total.StepStats, res.StepStats)
+	total.UnimplementedSteps = append(total.UnimplementedSteps, res.UnimplementedSteps...)
+}
+
+// evaluateGates checks whether the build should be stopped or thresholds are exceeded.
+func evaluateGates(results Results, args Args, hasHistory bool) error {
+	// Check that no report is missing, since a crashed shard makes the suite look smaller and greener
+	if err := validateReportCount(results, args); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check that every scenario of the manifest ran, since a wrong tag filter silently drops coverage
+	if err := validateManifest(results, args); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check that the suite did not shrink since the baseline, since a tag filter or config
+	// change silently drops scenarios that absolute thresholds cannot see
+	if err := validateScenarioDrift(results, args); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check that no scenario ran in several shards with conflicting statuses, since the
+	// suite is then partitioned wrongly and its verdict depends on the report order
+	if err := validateConsistency(results, args); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check if the build should be stopped due to new failures, compared with the baseline
+	// file when there is one and with the recent builds otherwise
+	newFailuresOnly := args.FailOnNewFailuresOnly && (hasHistory || results.BaselineDelta != nil)
+	newFailures := results.NewFailures
+	if results.BaselineDelta != nil {
+		newFailures = len(results.BaselineDelta.NewFailures)
+	}
+	if newFailuresOnly && newFailures > 0 {
+		logger.Errorf("Build failed due to new failures. Total new failed scenarios: %d", newFailures)
+		return fmt.Errorf("build failed due to new failures. Total new failed scenarios: %d", newFailures)
+	}
 
 	// Check if the build should be stopped due to failed tests
-	if args.StopBuildOnFailedReport && aggregatedResults.FailedTests > 0 {
-		logrus.Errorf("Build failed due to failed tests. Total failed tests: %d", aggregatedResults.FailedTests)
-		return fmt.Errorf("build failed due to failed tests. Total failed tests: %d", aggregatedResults.FailedTests)
+	if !newFailuresOnly && args.StopBuildOnFailedReport && results.FailedTests > 0 {
+		logger.Errorf("Build failed due to failed tests. Total failed tests: %d", results.FailedTests)
+		return fmt.Errorf("build failed due to failed tests. Total failed tests: %d", results.FailedTests)
+	}
+
+	// Check if scenarios got significantly slower than in previous builds
+	if err := validateDurationRegressions(results, args); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check the scenarios for anti-patterns when they fail the build
+	if err := validateLintWarnings(results, args); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check the gates of the custom categories
+	if err := validateCategories(results.Categories); err != nil {
+		logger.Errorf("%s", err)
+		return err
+	}
+
+	// Check the gates of the report groups, the thresholds apply to their roll-up
+	if err := validateReportGroups(results.ReportGroups); err != nil {
+		logger.Errorf("%s", err)
+		return err
 	}
 
 	// Validate thresholds at the aggregate level
-	if err := validateThresholds(aggregatedResults, args); err != nil {
-		logger := logrus.WithFields(logrus.Fields{
-			"Feature Count":  aggregatedResults.FeatureCount,
-			"Scenario Count": aggregatedResults.ScenarioCount,
-			"Step Count":     aggregatedResults.StepCount,
-			"Failed":         aggregatedResults.FailedTests,
-			"Skipped":        aggregatedResults.SkippedTests,
-			"Pending":        aggregatedResults.PendingTests,
-			"Undefined":      aggregatedResults.UndefinedTests,
-		})
-		logger.Error(err.Error())
+	if err := validateThresholds(results, args); err != nil {
+		logger.WithFields(map[string]interface{}{
+			"Feature Count":  results.FeatureCount,
+			"Scenario Count": results.ScenarioCount,
+			"Step Count":     results.StepCount,
+			"Failed":         results.FailedTests,
+			"Skipped":        results.SkippedTests,
+			"Pending":        results.PendingTests,
+			"Undefined":      results.UndefinedTests,
+		}).Errorf("%s", err)
 		return err
 	}
 
@@ -182,14 +1001,19 @@ func Exec(ctx context.Context, args Args) error {
 
 // locateFiles identifies files matching the given pattern and checks read permissions.
 func locateFiles(directory, includePattern, excludePattern string) ([]string, error) {
-	matches, err := filepath.Glob(filepath.Join(directory, includePattern))
+	matches, err := globFiles(directory, includePattern)
 	if err != nil {
-		logger := logrus.WithError(err).WithField("Pattern", includePattern)
-		logger.Error("Error occurred while searching for files")
+		logger.WithFields(map[string]interface{}{"error": err, "Pattern": includePattern}).Errorf("Error occurred while searching for files")
 		return nil, errors.New("failed to search for files: " + err.Error())
 	}
 
-	logrus.Infof("Found %d files matching the pattern: %s", len(matches), includePattern)
+	logger.Infof("Found %d files matching the pattern: %s", len(matches), includePattern)
+
+	if excludePattern != "" {
+		count := len(matches)
+		matches = excludeFiles(directory, matches, excludePattern)
+		logger.Infof("Excluded %d files matching the pattern: %s", count-len(matches), excludePattern)
+	}
 
 	if len(matches) == 0 {
 		return nil, errors.New("no files found matching the report filename pattern")
@@ -201,14 +1025,14 @@ func locateFiles(directory, includePattern, excludePattern string) ([]string, er
 			if fileInfo.Mode().Perm()&(1<<(uint(7))) != 0 {
 				validFiles = append(validFiles, file)
 			} else {
-				logrus.Warnf("File found but not readable: %s", file)
+				logger.Warnf("File found but not readable: %s", file)
 			}
 		} else {
-			logrus.Warnf("Error accessing file: %s. Error: %v", file, err)
+			logger.Warnf("Error accessing file: %s. Error: %v", file, err)
 		}
 	}
 
-	logrus.Infof("Number of readable files: %d", len(validFiles))
+	logger.Infof("Number of readable files: %d", len(validFiles))
 
 	if len(validFiles) == 0 {
 		return nil, errors.New("no readable files found matching the report filename pattern")
@@ -219,32 +1043,75 @@ func locateFiles(directory, includePattern, excludePattern string) ([]string, er
 
 // processFile reads a Cucumber JSON report and computes statistics.
 func processFile(filename string, skipEmptyFiles bool, args Args) (Results, error) {
-	logrus.Infof("Processing file: %s", filename)
+	logger.Infof("Processing file: %s", filename)
+
+	features, err := readFeatures(filename, skipEmptyFiles, args)
+	if err != nil {
+		return Results{}, err
+	}
+	return computeStats(features, args), nil
+}
 
-	fileContent, err := os.ReadFile(filename)
+// readFeatures reads the features of a Cucumber JSON report file.
+func readFeatures(filename string, skipEmptyFiles bool, args Args) ([]Feature, error) {
+	file, err := os.Open(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			logrus.Errorf("File not found: %s", filename)
-			return Results{}, fmt.Errorf("file not found: %s", filename)
+			logger.Errorf("File not found: %s", filename)
+			return nil, wrapError(ErrReadReport, fmt.Errorf("file not found: %s", filename))
 		}
 		if os.IsPermission(err) {
-			logrus.Errorf("Permission denied for file: %s", filename)
-			return Results{}, fmt.Errorf("permission denied for file: %s", filename)
+			logger.Errorf("Permission denied for file: %s", filename)
+			return nil, wrapError(ErrReadReport, fmt.Errorf("permission denied for file: %s", filename))
 		}
-		logrus.Errorf("Error opening file: %s. Error: %v", filename, err)
-		return Results{}, fmt.Errorf("error opening file: %s. Error: %v", filename, err)
+		logger.Errorf("Error opening file: %s. Error: %v", filename, err)
+		return nil, wrapError(ErrReadReport, fmt.Errorf("error opening file: %s. Error: %v", filename, err))
 	}
+	defer file.Close()
 
-	if skipEmptyFiles && len(fileContent) == 0 {
-		logrus.Infof("Skipping empty file: %s", filename)
-		return Results{}, nil
+	info, err := file.Stat()
+	if err != nil {
+		logger.Errorf("Error opening file: %s. Error: %v", filename, err)
+		return nil, wrapError(ErrReadReport, fmt.Errorf("error opening file: %s. Error: %v", filename, err))
+	}
+
+	if skipEmptyFiles && info.Size() == 0 {
+		logger.Infof("Skipping empty file: %s", filename)
+		return nil, nil
 	}
 
+	// Large reports are decoded feature by feature to avoid a single giant allocation
 	var features []Feature
-	if err := json.Unmarshal(fileContent, &features); err != nil {
-		logrus.WithError(err).WithField("File", filename).Error("Failed to parse Cucumber JSON")
-		return Results{}, fmt.Errorf("failed to parse Cucumber JSON for file: %s. Error: %v", filename, err)
+	streamed := info.Size() > streamingThreshold && streamableDialect(args.ReportDialect)
+	if streamed {
+		logger.Infof("Streaming large file: %s (%d bytes)", filename, info.Size())
+		features, err = decodeFeatures(file)
+		// Reports of other dialects are not arrays of features and are decoded as a whole
+		if errors.Is(err, errNotFeatureArray) {
+			if _, err = file.Seek(0, io.SeekStart); err == nil {
+				streamed = false
+			}
+		}
 	}
+	if !streamed && err == nil {
+		var fileContent []byte
+		if fileContent, err = io.ReadAll(file); err == nil {
+			features, err = decodeReport(fileContent, args.ReportDialect)
+		}
+		if err == nil && checkMemoryBudget() >= degradeEmbeddings {
+			for i := range features {
+				stripStepDetails(&features[i])
+			}
+		}
+	}
+	if err != nil {
+		logger.WithFields(map[string]interface{}{"error": err, "File": filename}).Errorf("Failed to parse Cucumber JSON")
+		return nil, wrapError(ErrParse, fmt.Errorf("failed to parse Cucumber JSON for file: %s. Error: %v", filename, err))
+	}
+
+	synthesizeIDs(features)
+	fillEmptyResults(features)
+	interpolateArguments(features)
 
 	// Merge features by ID if required
 	if args.MergeFeaturesById {
@@ -256,7 +1123,7 @@ func processFile(filename string, skipEmptyFiles bool, args Args) (Results, erro
 		sortFeaturesAlphabetically(features)
 	}
 
-	return computeStats(features, args), nil
+	return features, nil
 }
 
 // mergeFeaturesById merges features with the same ID into a single feature.
@@ -290,8 +1157,10 @@ func sortFeaturesAlphabetically(features []Feature) {
 // computeStats computes statistics from the parsed Cucumber JSON report.
 func computeStats(features []Feature, args Args) Results {
 	results := Results{}
+	categories, _ := parseCategories(args.Categories)
+	stepIndex := make(map[string]int)
 
-	for _, feature := range features {
+	for _, feature := range filterFeatures(features, args) {
 		results.FeatureCount++
 		featureFailed := false
 
@@ -299,8 +1168,27 @@ func computeStats(features []Feature, args Args) Results {
 			results.ScenarioCount++
 			scenarioFailed := false
 
+			scenario := ScenarioResult{
+				ID:             scenarioID(feature, element),
+				Feature:        feature.Name,
+				Scenario:       element.Name,
+				Environment:    featureEnvironment(feature.Metadata),
+				StartTimestamp: element.StartTimestamp,
+				Tags:           scenarioTags(feature, element),
+				Status:         "passed",
+			}
+			if len(categories) > 0 {
+				scenario.Category = categorize(categories, feature.URI, element.Name, scenario.Tags)
+			}
+
+			results.StepRetries += countStepRetries(element.Steps)
+			keyword := ""
 			for _, step := range element.Steps {
 				results.StepCount++
+				keyword = primaryKeyword(keyword, step.Keyword)
+				if args.StepStatsReport != "" {
+					results.StepStats = addStepStats(results.StepStats, stepIndex, keyword, step, scenarioIdentifier(feature.Name, element.Name))
+				}
 				switch step.Result.Status {
 				case "passed":
 					results.PassedTests++
@@ -315,8 +1203,18 @@ func computeStats(features []Feature, args Args) Results {
 							Feature:      feature.Name,
 							Scenario:     element.Name,
 							Step:         step.Name,
+							StepPattern:  normalizeStepText(step.Name),
+							URI:          feature.URI,
+							ScenarioLine: element.Line,
+							StepLine:     step.Line,
 							ErrorMessage: step.Result.ErrorMessage,
+							DurationMS:   float64(step.Result.Duration) / 1e6,
+							Fingerprint:  failureFingerprint(feature.Name, element.Name, step.Result.ErrorMessage),
+							SessionURL:   scenarioSessionURL(element),
 						})
+						if args.HTMLEmbedScreenshots {
+							results.FailedSteps[len(results.FailedSteps)-1].Screenshots = failureScreenshots(element, step)
+						}
 					}
 				case "skipped":
 					if !args.SkippedAsNotFailingStatus {
@@ -326,12 +1224,32 @@ func computeStats(features []Feature, args Args) Results {
 					if !args.PendingAsNotFailingStatus {
 						results.PendingTests++
 					}
+					if args.PendingAgingReport != "" {
+						results.UnimplementedSteps = append(results.UnimplementedSteps, UnimplementedStep{Feature: feature.Name, Scenario: element.Name, Step: step.Name, Status: "pending"})
+					}
 				case "undefined":
 					if !args.UndefinedAsNotFailingStatus {
 						results.UndefinedTests++
+						scenario.UndefinedSteps++
+					}
+					results.StepGaps = addStepGap(results.StepGaps, keyword, step.Name, scenarioIdentifier(feature.Name, element.Name))
+					if args.PendingAgingReport != "" {
+						results.UnimplementedSteps = append(results.UnimplementedSteps, UnimplementedStep{Feature: feature.Name, Scenario: element.Name, Step: step.Name, Status: "undefined"})
 					}
 				}
 				results.DurationMS += float64(step.Result.Duration) / 1e6 // Convert nanoseconds to milliseconds
+				scenario.DurationMS += float64(step.Result.Duration) / 1e6
+				scenario.Steps++
+
+				status := step.Result.Status
+				if status == "failed" && args.FailedAsNotFailingStatus {
+					status = "passed"
+				}
+				scenario.Status = worseStatus(scenario.Status, status)
+			}
+			results.Scenarios = append(results.Scenarios, scenario)
+			if lintEnabled(args) {
+				results.LintWarnings = append(results.LintWarnings, lintScenario(feature, element)...)
 			}
 
 			if scenarioFailed {
@@ -351,160 +1269,216 @@ func computeStats(features []Feature, args Args) Results {
 	return results
 }
 
+// statusSeverity orders step statuses from the least to the most severe.
+var statusSeverity = map[string]int{
+	"passed":    0,
+	"skipped":   1,
+	"pending":   2,
+	"undefined": 3,
+	"failed":    4,
+}
+
+// worseStatus returns the more severe of two step statuses.
+func worseStatus(current, status string) string {
+	if severity, ok := statusSeverity[status]; ok && severity > statusSeverity[current] {
+		return status
+	}
+	return current
+}
+
 // logAggregatedResults logs the aggregated results in a structured and informative way.
-func logAggregatedResults(results Results) {
-	logrus.Infof("\n===============================================\n")
-	logrus.Infof("Cucumber Test Report Summary\n")
-	logrus.Infof("===============================================\n")
-	logrus.Infof("📁 Total Features: %d\n", results.FeatureCount)
-	logrus.Infof("📄 Total Scenarios: %d\n", results.ScenarioCount)
-	logrus.Infof("🔍 Total Steps: %d\n", results.StepCount)
-	logrus.Infof("❌ Total Failed Features: %d\n", results.TotalFailedFeatures)
-	logrus.Infof("❌ Total Failed Scenarios: %d\n", results.TotalFailedScenarios)
-	logrus.Infof("❌ Total Failed Steps: %d\n", results.TotalFailedSteps)
-	logrus.Infof("✅ Total Passed Features: %d\n", results.TotalPassedFeatures)
-	logrus.Infof("✅ Total Passed Scenarios: %d\n", results.TotalPassedScenarios)
-	logrus.Infof("✅ Total Passed Steps: %d\n", results.TotalPassedSteps)
-	logrus.Infof("✅ Total Passed Tests: %d\n", results.PassedTests)
-	logrus.Infof("❌ Total Failed Tests: %d\n", results.FailedTests)
-	logrus.Infof("⏸️ Total Skipped Tests: %d\n", results.SkippedTests)
-	logrus.Infof("🔄 Total Pending Tests: %d\n", results.PendingTests)
-	logrus.Infof("❓ Total Undefined Tests: %d\n", results.UndefinedTests)
-	logrus.Infof("⏱️ Total Duration: %.2f ms\n", results.DurationMS)
-	logrus.Infof("===============================================\n")
+// At most MaxFailedDetailsLogged failed steps are detailed when set.
+func logAggregatedResults(results Results, args Args) {
+	logger.Infof("\n===============================================\n")
+	logger.Infof("Cucumber Test Report Summary\n")
+	logger.Infof("===============================================\n")
+	logger.Infof("📁 Total Features: %d\n", results.FeatureCount)
+	logger.Infof("📄 Total Scenarios: %d\n", results.ScenarioCount)
+	logger.Infof("🔍 Total Steps: %d\n", results.StepCount)
+	logger.Infof("❌ Total Failed Features: %d\n", results.TotalFailedFeatures)
+	logger.Infof("❌ Total Failed Scenarios: %d\n", results.TotalFailedScenarios)
+	logger.Infof("❌ Total Failed Steps: %d\n", results.TotalFailedSteps)
+	logger.Infof("✅ Total Passed Features: %d\n", results.TotalPassedFeatures)
+	logger.Infof("✅ Total Passed Scenarios: %d\n", results.TotalPassedScenarios)
+	logger.Infof("✅ Total Passed Steps: %d\n", results.TotalPassedSteps)
+	logger.Infof("✅ Total Passed Tests: %d\n", results.PassedTests)
+	logger.Infof("❌ Total Failed Tests: %d\n", results.FailedTests)
+	logger.Infof("⏸️ Total Skipped Tests: %d\n", results.SkippedTests)
+	logger.Infof("🔄 Total Pending Tests: %d\n", results.PendingTests)
+	logger.Infof("❓ Total Undefined Tests: %d\n", results.UndefinedTests)
+	logger.Infof("⏱️ Total Duration: %s ms\n", formatNumber(results.DurationMS))
+	if results.TotalRetries > 0 {
+		logger.Infof("🔄 Total Retries: %d (%d scenario reruns of %d scenarios, %d step retries)\n", results.TotalRetries, results.ScenarioRetries, results.RetriedScenarios, results.StepRetries)
+	}
+	classified := results.NewFailures+results.RecurringFailures > 0
+	if classified {
+		logger.Infof("🆕 New Failed Scenarios: %d\n", results.NewFailures)
+		logger.Infof("🔁 Recurring Failed Scenarios: %d\n", results.RecurringFailures)
+	}
+	logger.Infof("===============================================\n")
+
+	// Log duration regressions
+	logDurationRegressions(results.DurationRegressions)
+
+	// Log quarantine recommendations
+	logQuarantineRecommendations(results.QuarantineRecommendations)
 
 	// Log failed step details
 	if len(results.FailedSteps) > 0 {
-		logrus.Infof("Failed Step Details:\n")
-		logrus.Infof("-----------------------------------------------\n")
+		logger.Infof("Failed Step Details:\n")
+		logger.Infof("-----------------------------------------------\n")
 		for i, step := range results.FailedSteps {
-			logrus.Infof("%d. Feature: %s\n", i+1, step.Feature)
-			logrus.Infof("   Scenario: %s\n", step.Scenario)
-			logrus.Infof("   Step: %s\n", step.Step)
-			logrus.Infof("   Error: %s\n", step.ErrorMessage)
-			logrus.Infof("-----------------------------------------------\n")
+			if args.MaxFailedDetailsLogged > 0 && i == args.MaxFailedDetailsLogged {
+				logFailedDetailsOverflow(len(results.FailedSteps)-i, args.SummaryFile)
+				break
+			}
+			label := ""
+			if classified {
+				label = failureLabel(step)
+			}
+			logger.Infof("%d. Feature: %s%s\n", i+1, step.Feature, label)
+			logger.Infof("   Scenario: %s\n", step.Scenario)
+			logger.Infof("   Step: %s\n", step.Step)
+			if location := failureLocation(step); location != "" {
+				logger.Infof("   Location: %s (scenario line %d)\n", location, step.ScenarioLine)
+			}
+			if step.SourceURL != "" {
+				logger.Infof("   Source: %s\n", step.SourceURL)
+			}
+			logger.Infof("   Error: %s\n", step.ErrorMessage)
+			logger.Infof("   Fingerprint: %s\n", step.Fingerprint)
+			if step.SessionURL != "" {
+				logger.Infof("   Session: %s\n", step.SessionURL)
+			}
+			logger.Infof("-----------------------------------------------\n")
 		}
 	}
 }
 
-// validateThresholds validates test report thresholds based on aggregate results.
-func validateThresholds(results Results, args Args) error {
-	logrus.Infof("Threshold Validation:\n")
-	logrus.Infof("-----------------------------------------------\n")
-
-	// Validate absolute thresholds
-	if args.FailedFeaturesNumber > 0 {
-		if results.FailedTests > args.FailedFeaturesNumber {
-			logrus.Infof("Failed Features: %d (Threshold: %d) ❌\n", results.FailedTests, args.FailedFeaturesNumber)
-			return fmt.Errorf("failed features count (%d) exceeds the threshold (%d)", results.FailedTests, args.FailedFeaturesNumber)
-		}
-		logrus.Infof("Failed Features: %d (Threshold: %d) ✅\n", results.FailedTests, args.FailedFeaturesNumber)
+// logFailedDetailsOverflow logs the number of failed steps left out of the details.
+func logFailedDetailsOverflow(count int, summaryFile string) {
+	if summaryFile != "" {
+		logger.Infof("…and %d more (see %s)\n", count, summaryFile)
+	} else {
+		logger.Infof("…and %d more\n", count)
 	}
+	logger.Infof("-----------------------------------------------\n")
+}
 
-	if args.FailedScenariosNumber > 0 {
-		if results.FailedTests > args.FailedScenariosNumber {
-			logrus.Infof("Failed Scenarios: %d (Threshold: %d) ❌\n", results.FailedTests, args.FailedScenariosNumber)
-			return fmt.Errorf("failed scenarios count (%d) exceeds the threshold (%d)", results.FailedTests, args.FailedScenariosNumber)
-		}
-		logrus.Infof("Failed Scenarios: %d (Threshold: %d) ✅\n", results.FailedTests, args.FailedScenariosNumber)
-	}
+// thresholdCheck describes a configured threshold and the observed value it applies to.
+type thresholdCheck struct {
+	name       string
+	message    string
+	observed   float64
+	threshold  float64
+	percentage bool
+}
 
-	if args.FailedStepsNumber > 0 {
-		if results.FailedTests > args.FailedStepsNumber {
-			logrus.Infof("Failed Steps: %d (Threshold: %d) ❌\n", results.FailedTests, args.FailedStepsNumber)
-			return fmt.Errorf("failed steps count (%d) exceeds the threshold (%d)", results.FailedTests, args.FailedStepsNumber)
-		}
-		logrus.Infof("Failed Steps: %d (Threshold: %d) ✅\n", results.FailedTests, args.FailedStepsNumber)
+// percentageOf returns count as a percentage of total.
+func percentageOf(count, total int) float64 {
+	if total == 0 {
+		return 0
 	}
+	return float64(count) / float64(total) * 100
+}
 
-	// Validate percentage thresholds
-	if args.FailedFeaturesPercentage > 0 {
-		failureRate := float64(results.FailedTests) / float64(results.FeatureCount) * 100
-		if failureRate > args.FailedFeaturesPercentage {
-			logrus.Infof("Failed Features Percentage: %.2f%% (Threshold: %.2f%%) ❌\n", failureRate, args.FailedFeaturesPercentage)
-			return fmt.Errorf("failed features percentage (%.2f%%) exceeds the threshold (%.2f%%)", failureRate, args.FailedFeaturesPercentage)
-		}
-		logrus.Infof("Failed Features Percentage: %.2f%% (Threshold: %.2f%%) ✅\n", failureRate, args.FailedFeaturesPercentage)
+// evaluateThresholds evaluates the configured thresholds against the aggregate results.
+func evaluateThresholds(results Results, args Args) []GateResult {
+	checks := []thresholdCheck{
+		// Absolute thresholds
+		{"Failed Features", "failed features count", float64(results.FailedTests), float64(args.FailedFeaturesNumber), false},
+		{"Failed Scenarios", "failed scenarios count", float64(results.FailedTests), float64(args.FailedScenariosNumber), false},
+		{"Failed Steps", "failed steps count", float64(results.FailedTests), float64(args.FailedStepsNumber), false},
+		// Percentage thresholds
+		{"Failed Features Percentage", "failed features percentage", percentageOf(results.FailedTests, results.FeatureCount), args.FailedFeaturesPercentage, true},
+		{"Failed Scenarios Percentage", "failed scenarios percentage", percentageOf(results.FailedTests, results.ScenarioCount), args.FailedScenariosPercentage, true},
+		{"Failed Steps Percentage", "failed steps percentage", percentageOf(results.FailedTests, results.StepCount), args.FailedStepsPercentage, true},
+		// Pending steps thresholds
+		{"Pending Steps", "pending steps count", float64(results.PendingTests), float64(args.PendingStepsNumber), false},
+		{"Pending Steps Percentage", "pending steps percentage", percentageOf(results.PendingTests, results.StepCount), args.PendingStepsPercentage, true},
+		// Skipped steps thresholds
+		{"Skipped Steps", "skipped steps count", float64(results.SkippedTests), float64(args.SkippedStepsNumber), false},
+		{"Skipped Steps Percentage", "skipped steps percentage", percentageOf(results.SkippedTests, results.StepCount), args.SkippedStepsPercentage, true},
+		// Undefined steps thresholds
+		{"Undefined Steps", "undefined steps count", float64(results.UndefinedTests), float64(args.UndefinedStepsNumber), false},
+		{"Undefined Steps Percentage", "undefined steps percentage", percentageOf(results.UndefinedTests, results.StepCount), args.UndefinedStepsPercentage, true},
+		// Retries budget, the scenarios only passing after reruns
+		{"Retried Scenarios", "retried scenarios count", float64(results.RetriedScenarios), float64(args.MaxRetriedScenarios), false},
 	}
 
-	if args.FailedScenariosPercentage > 0 {
-		failureRate := float64(results.FailedTests) / float64(results.ScenarioCount) * 100
-		if failureRate > args.FailedScenariosPercentage {
-			logrus.Infof("Failed Scenarios Percentage: %.2f%% (Threshold: %.2f%%) ❌\n", failureRate, args.FailedScenariosPercentage)
-			return fmt.Errorf("failed scenarios percentage (%.2f%%) exceeds the threshold (%.2f%%)", failureRate, args.FailedScenariosPercentage)
+	var gates []GateResult
+	for _, check := range checks {
+		if check.threshold <= 0 {
+			continue
 		}
-		logrus.Infof("Failed Scenarios Percentage: %.2f%% (Threshold: %.2f%%) ✅\n", failureRate, args.FailedScenariosPercentage)
-	}
 
-	if args.FailedStepsPercentage > 0 {
-		failureRate := float64(results.FailedTests) / float64(results.StepCount) * 100
-		if failureRate > args.FailedStepsPercentage {
-			logrus.Infof("Failed Steps Percentage: %.2f%% (Threshold: %.2f%%) ❌\n", failureRate, args.FailedStepsPercentage)
-			return fmt.Errorf("failed steps percentage (%.2f%%) exceeds the threshold (%.2f%%)", failureRate, args.FailedStepsPercentage)
+		gate := GateResult{
+			Name:       check.name,
+			Observed:   check.observed,
+			Threshold:  check.threshold,
+			Margin:     check.threshold - check.observed,
+			Percentage: check.percentage,
+			Passed:     check.observed <= check.threshold,
 		}
-		logrus.Infof("Failed Steps Percentage: %.2f%% (Threshold: %.2f%%) ✅\n", failureRate, args.FailedStepsPercentage)
-	}
-
-	// Validate pending steps thresholds
-	if args.PendingStepsNumber > 0 {
-		if results.PendingTests > args.PendingStepsNumber {
-			logrus.Infof("Pending Steps: %d (Threshold: %d) ❌\n", results.PendingTests, args.PendingStepsNumber)
-			return fmt.Errorf("pending steps count (%d) exceeds the threshold (%d)", results.PendingTests, args.PendingStepsNumber)
+		if !gate.Passed {
+			gate.Message = fmt.Sprintf("%s (%s) exceeds the threshold (%s)", check.message, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold))
 		}
-		logrus.Infof("Pending Steps: %d (Threshold: %d) ✅\n", results.PendingTests, args.PendingStepsNumber)
+		gates = append(gates, gate)
 	}
+	gates = append(gates, evaluateFeatureUndefinedSteps(results, args)...)
+	gates = append(gates, evaluateDurationBudgets(results, args)...)
+	return append(gates, evaluateFeatureDuration(results, args)...)
+}
 
-	if args.PendingStepsPercentage > 0 {
-		pendingRate := float64(results.PendingTests) / float64(results.StepCount) * 100
-		if pendingRate > args.PendingStepsPercentage {
-			logrus.Infof("Pending Steps Percentage: %.2f%% (Threshold: %.2f%%) ❌\n", pendingRate, args.PendingStepsPercentage)
-			return fmt.Errorf("pending steps percentage (%.2f%%) exceeds the threshold (%.2f%%)", pendingRate, args.PendingStepsPercentage)
-		}
-		logrus.Infof("Pending Steps Percentage: %.2f%% (Threshold: %.2f%%) ✅\n", pendingRate, args.PendingStepsPercentage)
+// formatValue formats an observed or threshold value of the gate.
+func (g GateResult) formatValue(value float64) string {
+	if g.Percentage {
+		return formatNumber(value) + "%"
 	}
-
-	// Validate skipped steps thresholds
-	if args.SkippedStepsNumber > 0 {
-		if results.SkippedTests > args.SkippedStepsNumber {
-			logrus.Infof("Skipped Steps: %d (Threshold: %d) ❌\n", results.SkippedTests, args.SkippedStepsNumber)
-			return fmt.Errorf("skipped steps count (%d) exceeds the threshold (%d)", results.SkippedTests, args.SkippedStepsNumber)
-		}
-		logrus.Infof("Skipped Steps: %d (Threshold: %d) ✅\n", results.SkippedTests, args.SkippedStepsNumber)
+	if g.Duration {
+		return formatDurationMS(value)
 	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
 
-	if args.SkippedStepsPercentage > 0 {
-		skipRate := float64(results.SkippedTests) / float64(results.StepCount) * 100
-		if skipRate > args.SkippedStepsPercentage {
-			logrus.Infof("Skipped Steps Percentage: %.2f%% (Threshold: %.2f%%) ❌\n", skipRate, args.SkippedStepsPercentage)
-			return fmt.Errorf("skipped steps percentage (%.2f%%) exceeds the threshold (%.2f%%)", skipRate, args.SkippedStepsPercentage)
-		}
-		logrus.Infof("Skipped Steps Percentage: %.2f%% (Threshold: %.2f%%) ✅\n", skipRate, args.SkippedStepsPercentage)
+// formatMargin formats the margin of the gate with its sign.
+func (g GateResult) formatMargin() string {
+	if g.Percentage {
+		return formatSignedNumber(g.Margin) + "%"
+	}
+	if g.Margin < 0 {
+		return g.formatValue(g.Margin)
 	}
+	return "+" + g.formatValue(g.Margin)
+}
 
-	// Validate undefined steps thresholds
-	if args.UndefinedStepsNumber > 0 {
-		if results.UndefinedTests > args.UndefinedStepsNumber {
-			logrus.Infof("Undefined Steps: %d (Threshold: %d) ❌\n", results.UndefinedTests, args.UndefinedStepsNumber)
-			return fmt.Errorf("undefined steps count (%d) exceeds the threshold (%d)", results.UndefinedTests, args.UndefinedStepsNumber)
-		}
-		logrus.Infof("Undefined Steps: %d (Threshold: %d) ✅\n", results.UndefinedTests, args.UndefinedStepsNumber)
+// gateSymbol returns the log symbol of a gate verdict.
+func gateSymbol(passed bool) string {
+	if passed {
+		return "✅"
 	}
+	return "❌"
+}
 
-	if args.UndefinedStepsPercentage > 0 {
-		undefinedRate := float64(results.UndefinedTests) / float64(results.StepCount) * 100
-		if undefinedRate > args.UndefinedStepsPercentage {
-			logrus.Infof("Undefined Steps Percentage: %.2f%% (Threshold: %.2f%%) ❌\n", undefinedRate, args.UndefinedStepsPercentage)
-			return fmt.Errorf("undefined steps percentage (%.2f%%) exceeds the threshold (%.2f%%)", undefinedRate, args.UndefinedStepsPercentage)
+// validateThresholds validates test report thresholds based on aggregate results.
+func validateThresholds(results Results, args Args) error {
+	logger.Infof("Threshold Validation:\n")
+	logger.Infof("-----------------------------------------------\n")
+
+	var failed error
+	for _, gate := range evaluateThresholds(results, args) {
+		logger.Infof("%s: %s (Threshold: %s) %s\n", gate.Name, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold), gateSymbol(gate.Passed))
+		if !gate.Passed && failed == nil {
+			failed = errors.New(gate.Message)
 		}
-		logrus.Infof("Undefined Steps Percentage: %.2f%% (Threshold: %.2f%%) ✅\n", undefinedRate, args.UndefinedStepsPercentage)
 	}
 
-	logrus.Infof("===============================================")
-	return nil
+	logger.Infof("===============================================")
+	return failed
 }
 
 // writeTestStats writes the test statistics to a file.
-func writeTestStats(results Results, log *logrus.Logger) {
+func writeTestStats(results Results, log Logger) {
 	// Calculate failure rate and skipped rate
 	failureRate := 0.0
 	if results.StepCount > 0 {
@@ -518,20 +1492,30 @@ func writeTestStats(results Results, log *logrus.Logger) {
 
 	// Prepare stats map
 	statsMap := map[string]string{
-		"FAILED_FEATURES":  strconv.Itoa(results.TotalFailedFeatures),
-		"FAILED_SCENARIOS": strconv.Itoa(results.TotalFailedScenarios),
-		"FAILED_STEPS":     strconv.Itoa(results.TotalFailedSteps),
-		"PASSED_FEATURES":  strconv.Itoa(results.TotalPassedFeatures),
-		"PASSED_SCENARIOS": strconv.Itoa(results.TotalPassedScenarios),
-		"PASSED_STEPS":     strconv.Itoa(results.TotalPassedSteps),
-		"SKIPPED_STEPS":    strconv.Itoa(results.SkippedTests),
-		"PENDING_STEPS":    strconv.Itoa(results.PendingTests),
-		"UNDEFINED_STEPS":  strconv.Itoa(results.UndefinedTests),
-		"TOTAL_FEATURES":   strconv.Itoa(results.FeatureCount),
-		"TOTAL_SCENARIOS":  strconv.Itoa(results.ScenarioCount),
-		"TOTAL_STEPS":      strconv.Itoa(results.StepCount),
-		"FAILURE_RATE":     fmt.Sprintf("%.2f", failureRate),
-		"SKIPPED_RATE":     fmt.Sprintf("%.2f", skippedRate),
+		"FAILED_FEATURES":         strconv.Itoa(results.TotalFailedFeatures),
+		"FAILED_SCENARIOS":        strconv.Itoa(results.TotalFailedScenarios),
+		"FAILED_STEPS":            strconv.Itoa(results.TotalFailedSteps),
+		"PASSED_FEATURES":         strconv.Itoa(results.TotalPassedFeatures),
+		"PASSED_SCENARIOS":        strconv.Itoa(results.TotalPassedScenarios),
+		"PASSED_STEPS":            strconv.Itoa(results.TotalPassedSteps),
+		"SKIPPED_STEPS":           strconv.Itoa(results.SkippedTests),
+		"PENDING_STEPS":           strconv.Itoa(results.PendingTests),
+		"UNDEFINED_STEPS":         strconv.Itoa(results.UndefinedTests),
+		"TOTAL_FEATURES":          strconv.Itoa(results.FeatureCount),
+		"TOTAL_SCENARIOS":         strconv.Itoa(results.ScenarioCount),
+		"TOTAL_STEPS":             strconv.Itoa(results.StepCount),
+		"FAILURE_RATE":            formatNumber(failureRate),
+		"SKIPPED_RATE":            formatNumber(skippedRate),
+		"FAILURE_FINGERPRINTS":    strings.Join(failureFingerprints(results), ","),
+		"REPORT_COUNT":            strconv.Itoa(results.ReportCount),
+		"MISSING_SCENARIOS":       strconv.Itoa(len(results.MissingScenarios)),
+		"CONSISTENCY_VIOLATIONS":  strconv.Itoa(len(results.ConsistencyViolations)),
+		"TOTAL_RETRIES":           strconv.Itoa(results.TotalRetries),
+		"SCENARIO_RETRIES":        strconv.Itoa(results.ScenarioRetries),
+		"RETRIED_SCENARIOS":       strconv.Itoa(results.RetriedScenarios),
+		"STEP_RETRIES":            strconv.Itoa(results.StepRetries),
+		"STEP_DEFINITION_GAPS":    strconv.Itoa(len(results.StepGaps)),
+		"OVERFLOWED_FAILED_STEPS": strconv.Itoa(results.OverflowedFailedSteps),
 	}
 
 	// Write stats to file
@@ -542,17 +1526,41 @@ func writeTestStats(results Results, log *logrus.Logger) {
 	}
 }
 
-// WriteEnvToFile writes a key-value pair to the output file.
-func WriteEnvToFile(key, value string, log *logrus.Logger) error {
-	outputFile, err := os.OpenFile(os.Getenv("DRONE_OUTPUT"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Errorf("Failed to open output file: %v", err)
-		return err
+// writePartialResults writes the stats and summary computed before a fatal error, so that
+// downstream notification steps have data to report, and returns the error.
+func writePartialResults(results Results, args Args, err error) error {
+	writeTestStats(results, logger)
+	if args.SummaryFile != "" {
+		if summaryErr := writeSummary(args.SummaryFile, results); summaryErr != nil {
+			logger.Warnf("Failed to write summary %s: %v", args.SummaryFile, summaryErr)
+		}
 	}
-	defer outputFile.Close()
-	
-	_, err = outputFile.WriteString(key + "=" + value + "\n")
+	writeErrorFlag(err, logger)
+	return err
+}
+
+// writeErrorFlag writes the ERROR, ERROR_CODE and ERROR_MESSAGE output variables, telling
+// downstream steps whether the plugin failed the build and why.
+func writeErrorFlag(err error, log Logger) {
+	flag, message := "false", ""
 	if err != nil {
+		flag, message = "true", firstLine(err.Error())
+	}
+	if writeErr := WriteEnvToFile("ERROR_CODE", ErrorCode(err), log); writeErr != nil {
+		log.Errorf("Error writing ERROR_CODE: %s", writeErr)
+	}
+	if writeErr := WriteEnvToFile("ERROR", flag, log); writeErr != nil {
+		log.Errorf("Error writing ERROR: %s", writeErr)
+	}
+	if writeErr := WriteEnvToFile("ERROR_MESSAGE", message, log); writeErr != nil {
+		log.Errorf("Error writing ERROR_MESSAGE: %s", writeErr)
+	}
+}
+
+// WriteEnvToFile writes a key-value pair to the output file, prefixing the key with the
+// output namespace.
+func WriteEnvToFile(key, value string, log Logger) error {
+	if err := writeOutput(key, value); err != nil {
 		log.Errorf("Failed to write to env: %v", err)
 		return err
 	}
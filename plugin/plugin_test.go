@@ -46,6 +46,22 @@ func TestValidateInputs(t *testing.T) {
 			expectErr: true,
 			errMsg:    "threshold values must be non-negative",
 		},
+		{
+			name: "Percentage Above 100",
+			args: Args{
+				FailedScenariosPercentage: 150,
+			},
+			expectErr: true,
+			errMsg:    "invalid FailedScenariosPercentage value 150. It must be a percentage between 0 and 100",
+		},
+		{
+			name: "Quarantine Threshold Above 100",
+			args: Args{
+				QuarantineThreshold: 150,
+			},
+			expectErr: true,
+			errMsg:    "invalid QuarantineThreshold value 150. It must be a percentage between 0 and 100",
+		},
 	}
 
 	for _, tc := range tests {
@@ -162,21 +178,45 @@ func TestProcessFile(t *testing.T) {
 						Feature:      "Browserstack test",
 						Scenario:     "Can add the product in cart",
 						Step:         "I click on orders",
+						StepPattern:  "I click on orders",
+						URI:          "features/sample.feature",
+						ScenarioLine: 3,
+						StepLine:     5,
 						ErrorMessage: "Orders page did not load.",
+						DurationMS:   518.495,
+						Fingerprint:  "dd9536fb5288",
 					},
 					{
 						Feature:      "Browserstack test",
 						Scenario:     "Search Wikipedia",
 						Step:         "I should see BrowserStack page",
+						StepPattern:  "I should see BrowserStack page",
+						URI:          "features/sample.feature",
+						ScenarioLine: 8,
+						StepLine:     11,
 						ErrorMessage: "Expected page not found.",
+						DurationMS:   33.933,
+						Fingerprint:  "003f5cf0b47e",
 					},
 					{
 						Feature:      "Payment Gateway",
 						Scenario:     "Failed payment",
 						Step:         "I enter invalid payment details",
+						StepPattern:  "I enter invalid payment details",
+						URI:          "features/payment.feature",
+						ScenarioLine: 8,
+						StepLine:     10,
 						ErrorMessage: "Payment details are invalid.",
+						DurationMS:   2345.678,
+						Fingerprint:  "6325e43d8893",
 					},
 				},
+				Scenarios: []ScenarioResult{
+					{ID: "browserstack-test;can-add-the-product-in-cart", Feature: "Browserstack test", Scenario: "Can add the product in cart", Status: "failed", DurationMS: 5119.423, Steps: 3},
+					{ID: "browserstack-test;search-wikipedia", Feature: "Browserstack test", Scenario: "Search Wikipedia", Status: "failed", DurationMS: 10851.198, Steps: 3},
+					{ID: "payment-feature;process-payment", Feature: "Payment Gateway", Scenario: "Process payment", Status: "passed", DurationMS: 7037.034, Steps: 3},
+					{ID: "payment-feature;failed-payment", Feature: "Payment Gateway", Scenario: "Failed payment", Status: "failed", DurationMS: 3580.245, Steps: 3},
+				},
 			},
 		},
 		{
@@ -246,6 +286,54 @@ func TestExec(t *testing.T) {
 	}
 }
 
+// TestExecPartialResults tests that the stats and the error flag are written on a fatal error
+func TestExecPartialResults(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output.env")
+	t.Setenv("DRONE_OUTPUT", output)
+	summary := filepath.Join(t.TempDir(), "summary.json")
+
+	err := Exec(context.Background(), Args{
+		JSONReportDirectory: "../testdata",
+		FileIncludePattern:  "*.invalid",
+		SummaryFile:         summary,
+	})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	content, readErr := os.ReadFile(output)
+	if readErr != nil {
+		t.Fatalf("Unexpected error: %v", readErr)
+	}
+	for _, want := range []string{"TOTAL_SCENARIOS=0\n", "ERROR=true\n", "ERROR_CODE=NO_REPORTS\n", "ERROR_MESSAGE=failed to locate files"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, content)
+		}
+	}
+	if _, statErr := os.Stat(summary); statErr != nil {
+		t.Errorf("Expected the summary to be written: %v", statErr)
+	}
+}
+
+// TestAggregateResults tests that the results of the files are summed
+func TestAggregateResults(t *testing.T) {
+	var total Results
+	aggregateResults(&total, Results{ScenarioCount: 2, FailedTests: 1, DurationMS: 10, FailedSteps: []FailedStepDetails{{Scenario: "Pay"}}, Scenarios: []ScenarioResult{{Scenario: "Pay"}, {Scenario: "Ship"}}})
+	aggregateResults(&total, Results{ScenarioCount: 1, DurationMS: 5, Scenarios: []ScenarioResult{{Scenario: "Search"}}})
+
+	expected := Results{
+		ScenarioCount: 3,
+		FailedTests:   1,
+		DurationMS:    15,
+		FailedSteps:   []FailedStepDetails{{Scenario: "Pay"}},
+		Scenarios:     []ScenarioResult{{Scenario: "Pay"}, {Scenario: "Ship"}, {Scenario: "Search"}},
+		AllFailures:   []HistoryFailure{{Scenario: "Pay"}},
+	}
+	if diff := cmp.Diff(expected, total); diff != "" {
+		t.Errorf("Results mismatch (-want +got):\n%s", diff)
+	}
+}
+
 // TestValidateThresholds tests the threshold validation logic
 func TestValidateThresholds(t *testing.T) {
 	tests := []struct {
@@ -293,6 +381,27 @@ func TestValidateThresholds(t *testing.T) {
 			expectErr: true,
 			errMsg:    "failed steps percentage (21.00%) exceeds the threshold (20.00%)",
 		},
+		{
+			name: "Retried Scenarios Within Budget",
+			results: Results{
+				RetriedScenarios: 3,
+			},
+			args: Args{
+				MaxRetriedScenarios: 3,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Retried Scenarios Exceed Budget",
+			results: Results{
+				RetriedScenarios: 4,
+			},
+			args: Args{
+				MaxRetriedScenarios: 3,
+			},
+			expectErr: true,
+			errMsg:    "retried scenarios count (4) exceeds the threshold (3)",
+		},
 	}
 
 	for _, tc := range tests {
@@ -308,3 +417,37 @@ func TestValidateThresholds(t *testing.T) {
 		})
 	}
 }
+
+// TestEvaluateThresholds tests evaluating every configured gate, so that the notifications
+// and reports list each verdict while the build fails on the first failing gate
+func TestEvaluateThresholds(t *testing.T) {
+	results := Results{FeatureCount: 4, ScenarioCount: 10, StepCount: 40, FailedTests: 3, SkippedTests: 8}
+	args := Args{FailedScenariosNumber: 2, FailedStepsPercentage: 10, SkippedStepsNumber: 5, PendingStepsNumber: 1}
+
+	gates := evaluateThresholds(results, args)
+	expected := []GateResult{
+		{Name: "Failed Scenarios", Observed: 3, Threshold: 2, Margin: -1, Message: "failed scenarios count (3) exceeds the threshold (2)"},
+		{Name: "Failed Steps Percentage", Observed: 7.5, Threshold: 10, Margin: 2.5, Percentage: true, Passed: true},
+		{Name: "Pending Steps", Observed: 0, Threshold: 1, Margin: 1, Passed: true},
+		{Name: "Skipped Steps", Observed: 8, Threshold: 5, Margin: -3, Message: "skipped steps count (8) exceeds the threshold (5)"},
+	}
+	if diff := cmp.Diff(expected, gates); diff != "" {
+		t.Errorf("Gates mismatch (-want +got):\n%s", diff)
+	}
+
+	// Every gate is evaluated but the first failing one fails the build
+	if err := validateThresholds(results, args); err == nil || err.Error() != "failed scenarios count (3) exceeds the threshold (2)" {
+		t.Errorf("Expected the first failing gate, got %v", err)
+	}
+
+	// Percentages of empty results are zero rather than NaN
+	gates = evaluateThresholds(Results{}, Args{FailedScenariosPercentage: 10, UndefinedStepsPercentage: 5})
+	for _, gate := range gates {
+		if gate.Observed != 0 || !gate.Passed {
+			t.Errorf("Expected gate %s to pass at 0%%, got %+v", gate.Name, gate)
+		}
+	}
+	if len(gates) != 2 {
+		t.Errorf("Expected 2 gates, got %d", len(gates))
+	}
+}
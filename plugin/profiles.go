@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readConfigFile reads the JSON config file holding the threshold profiles.
+func readConfigFile(path string) (ConfigFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+	var config ConfigFile
+	if err := json.Unmarshal(content, &config); err != nil {
+		return ConfigFile{}, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// profileSettings returns the settings of the profile, with the settings of the profiles it
+// extends overridden by its own.
+func profileSettings(config ConfigFile, name string) (map[string]string, error) {
+	var chain []Profile
+	visited := make(map[string]bool)
+	for current := name; current != ""; current = config.Profiles[current].Extends {
+		if visited[current] {
+			return nil, fmt.Errorf("profile %s extends itself through %s", name, current)
+		}
+		visited[current] = true
+		profile, ok := config.Profiles[current]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %s", current)
+		}
+		chain = append(chain, profile)
+	}
+
+	settings := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for env, value := range chain[i].Settings {
+			setting, err := settingString(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value in profile %s: %v", env, name, err)
+			}
+			settings[env] = setting
+		}
+	}
+	return settings, nil
+}
+
+// settingString returns a JSON value of a profile as the value of its environment variable.
+func settingString(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	default:
+		return "", errors.New("not a string, number or boolean")
+	}
+}
+
+// ApplyProfile applies the threshold profile selected by PLUGIN_PROFILE from the config file,
+// read from its path or URL, to the settings that are not set in the environment, given as KEY=VALUE pairs like
+// os.Environ returns them, nor by the command-line flags, given as the names ParseFlags
+// returns, so that pipelines share the quality bars of the profile and still override
+// some of them.
+func ApplyProfile(ctx context.Context, args *Args, environ, overrides []string) error {
+	if args.Profile == "" {
+		return nil
+	}
+	if args.ConfigFile == "" {
+		return wrapError(ErrInvalidConfig, errors.New("a Profile requires a ConfigFile"))
+	}
+
+	config, err := loadConfigFile(ctx, *args)
+	if err != nil {
+		return wrapError(ErrInvalidConfig, err)
+	}
+	settings, err := profileSettings(config, args.Profile)
+	if err != nil {
+		return wrapError(ErrInvalidConfig, err)
+	}
+
+	set := make(map[string]bool, len(environ))
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		set[name] = true
+	}
+	for _, name := range overrides {
+		set[name] = true
+	}
+	fields := make(map[string]reflect.Value)
+	value := reflect.ValueOf(args).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if env := value.Type().Field(i).Tag.Get("envconfig"); env != "" {
+			fields[env] = value.Field(i)
+		}
+	}
+
+	names := make([]string, 0, len(settings))
+	for env := range settings {
+		names = append(names, env)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	applied := 0
+	for _, env := range names {
+		field, ok := fields[env]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("unknown setting %s in profile %s", env, args.Profile))
+		case env == "PLUGIN_PROFILE" || env == "PLUGIN_CONFIG_FILE":
+			errs = append(errs, fmt.Errorf("profile %s cannot set %s", args.Profile, env))
+		case set[env]:
+			logger.Debugf("Profile setting %s overridden by the environment or a flag", env)
+		default:
+			if err := validateSettingValue(field.Kind(), settings[env]); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s value '%s' in profile %s: %v", env, settings[env], args.Profile, err))
+			} else if err := (fieldFlag{field}).Set(settings[env]); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s value '%s' in profile %s: %v", env, settings[env], args.Profile, err))
+			} else {
+				applied++
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return wrapError(ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	logger.Infof("Applied %d settings of the %s profile from %s\n", applied, args.Profile, redactURL(args.ConfigFile))
+	return nil
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cucumber.json")
+	config := `{"profiles": {
+		"strict": {"settings": {"PLUGIN_FAILED_SCENARIOS_NUMBER": 0, "PLUGIN_FAILED_STEPS_PERCENTAGE": 0, "PLUGIN_STOP_BUILD_ON_FAILED_REPORT": true}},
+		"nightly": {"extends": "strict", "settings": {"PLUGIN_FAILED_STEPS_PERCENTAGE": 2.5, "PLUGIN_INCLUDE_TAGS": "@nightly"}},
+		"loop": {"extends": "cycle", "settings": {}},
+		"cycle": {"extends": "loop", "settings": {}},
+		"typo": {"settings": {"PLUGIN_FAILED_SCENARIO_NUMBER": 1}},
+		"fraction": {"settings": {"PLUGIN_FAILED_SCENARIOS_NUMBER": 1.5}}
+	}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("inherited settings", func(t *testing.T) {
+		args := Args{ConfigFile: path, Profile: "nightly", FailedScenariosNumber: 3}
+		if err := ApplyProfile(context.Background(), &args, []string{"PLUGIN_FAILED_SCENARIOS_NUMBER=3"}, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if args.FailedScenariosNumber != 3 || args.FailedStepsPercentage != 2.5 || !args.StopBuildOnFailedReport || args.IncludeTags != "@nightly" {
+			t.Errorf("Unexpected settings: %+v", args)
+		}
+	})
+
+	t.Run("flags override", func(t *testing.T) {
+		var args Args
+		overrides, err := ParseFlags(&args, []string{"--config-file", path, "--profile=nightly", "--failed-steps-percentage", "10"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := ApplyProfile(context.Background(), &args, nil, overrides); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if args.FailedStepsPercentage != 10 || args.FailedScenariosNumber != 0 || !args.StopBuildOnFailedReport || args.IncludeTags != "@nightly" {
+			t.Errorf("Unexpected settings: %+v", args)
+		}
+	})
+
+	tests := []struct {
+		profile  string
+		expected string
+	}{
+		{"weekly", "unknown profile weekly"},
+		{"loop", "profile loop extends itself through loop"},
+		{"typo", "unknown setting PLUGIN_FAILED_SCENARIO_NUMBER in profile typo"},
+		{"fraction", "invalid PLUGIN_FAILED_SCENARIOS_NUMBER value '1.5' in profile fraction: not an integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			err := ApplyProfile(context.Background(), &Args{ConfigFile: path, Profile: tt.profile}, nil, nil)
+			if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
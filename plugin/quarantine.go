@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Defaults for quarantine recommendations
+const (
+	defaultQuarantineBuilds    = 20
+	defaultQuarantineThreshold = 30.0
+)
+
+// recommendQuarantine lists scenarios that failed intermittently in at least the configured
+// percentage of the recent builds, including the current build.
+func recommendQuarantine(results *Results, previous []HistoryEntry, args Args) {
+	builds := args.QuarantineBuilds
+	if builds <= 0 {
+		builds = defaultQuarantineBuilds
+	}
+	threshold := args.QuarantineThreshold
+	if threshold <= 0 {
+		threshold = defaultQuarantineThreshold
+	}
+
+	type counter struct {
+		feature, scenario string
+		builds, failed    int
+	}
+	counters := make(map[string]*counter)
+	observe := func(feature, scenario string, failed bool) {
+		key := failureKey(feature, scenario)
+		c, ok := counters[key]
+		if !ok {
+			c = &counter{feature: feature, scenario: scenario}
+			counters[key] = c
+		}
+		c.builds++
+		if failed {
+			c.failed++
+		}
+	}
+
+	for _, entry := range lastEntries(previous, builds-1) {
+		failed := make(map[string]bool)
+		for _, failure := range entry.Failures {
+			failed[failureKey(failure.Feature, failure.Scenario)] = true
+		}
+		for _, scenario := range entry.Scenarios {
+			observe(scenario.Feature, scenario.Scenario, failed[failureKey(scenario.Feature, scenario.Scenario)])
+		}
+	}
+	for _, scenario := range results.Scenarios {
+		observe(scenario.Feature, scenario.Scenario, scenario.Status == "failed")
+	}
+
+	results.QuarantineRecommendations = nil
+	for _, c := range counters {
+		// Scenarios failing in every build are broken rather than flaky
+		if c.builds < 2 || c.failed == 0 || c.failed == c.builds {
+			continue
+		}
+		rate := float64(c.failed) / float64(c.builds) * 100
+		if rate < threshold {
+			continue
+		}
+		results.QuarantineRecommendations = append(results.QuarantineRecommendations, QuarantineRecommendation{
+			Feature:      c.feature,
+			Scenario:     c.scenario,
+			Builds:       c.builds,
+			FailedBuilds: c.failed,
+			FailureRate:  rate,
+		})
+	}
+
+	sort.Slice(results.QuarantineRecommendations, func(i, j int) bool {
+		a, b := results.QuarantineRecommendations[i], results.QuarantineRecommendations[j]
+		if a.FailureRate != b.FailureRate {
+			return a.FailureRate > b.FailureRate
+		}
+		return failureKey(a.Feature, a.Scenario) < failureKey(b.Feature, b.Scenario)
+	})
+}
+
+// logQuarantineRecommendations logs the scenarios recommended for quarantine.
+func logQuarantineRecommendations(recommendations []QuarantineRecommendation) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	logger.Infof("Quarantine Recommendations:\n")
+	logger.Infof("-----------------------------------------------\n")
+	logger.Infof("%d scenarios failed intermittently in the recent builds; consider quarantining:\n", len(recommendations))
+	for i, recommendation := range recommendations {
+		logger.Infof("%d. %s / %s: failed in %d of %d builds (%s%%) ⚠️\n", i+1, recommendation.Feature, recommendation.Scenario,
+			recommendation.FailedBuilds, recommendation.Builds, formatNumber(recommendation.FailureRate))
+	}
+	logger.Infof("===============================================\n")
+}
+
+// writeQuarantineFile writes the recommended scenarios merged with the scenarios already
+// listed in the quarantine file.
+func writeQuarantineFile(path string, recommendations []QuarantineRecommendation) error {
+	quarantine := QuarantineFile{}
+	if content, err := os.ReadFile(path); err == nil && len(content) > 0 {
+		if err := json.Unmarshal(content, &quarantine); err != nil {
+			return err
+		}
+	}
+
+	listed := make(map[string]int)
+	for i, scenario := range quarantine.Scenarios {
+		listed[failureKey(scenario.Feature, scenario.Scenario)] = i
+	}
+	for _, recommendation := range recommendations {
+		if i, ok := listed[failureKey(recommendation.Feature, recommendation.Scenario)]; ok {
+			quarantine.Scenarios[i] = recommendation
+			continue
+		}
+		quarantine.Scenarios = append(quarantine.Scenarios, recommendation)
+	}
+
+	content, err := json.MarshalIndent(quarantine, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// writeQuarantineStats writes the number of quarantine recommendations to the output file.
+func writeQuarantineStats(results Results, log Logger) {
+	if err := WriteEnvToFile("QUARANTINE_RECOMMENDATIONS", strconv.Itoa(len(results.QuarantineRecommendations)), log); err != nil {
+		log.Errorf("Error writing %s: %s", "QUARANTINE_RECOMMENDATIONS", err)
+	}
+}
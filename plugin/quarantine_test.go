@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRecommendQuarantine tests recommending intermittently failing scenarios
+func TestRecommendQuarantine(t *testing.T) {
+	scenarios := []HistoryScenario{{Feature: "F", Scenario: "Flaky"}, {Feature: "F", Scenario: "Broken"}, {Feature: "F", Scenario: "Stable"}}
+	previous := []HistoryEntry{
+		{Scenarios: scenarios, Failures: []HistoryFailure{{Feature: "F", Scenario: "Flaky"}, {Feature: "F", Scenario: "Broken"}}},
+		{Scenarios: scenarios, Failures: []HistoryFailure{{Feature: "F", Scenario: "Broken"}}},
+		{Scenarios: scenarios, Failures: []HistoryFailure{{Feature: "F", Scenario: "Broken"}, {Feature: "F", Scenario: "Stable"}}},
+	}
+
+	results := Results{
+		Scenarios: []ScenarioResult{
+			{Feature: "F", Scenario: "Flaky", Status: "failed"},
+			{Feature: "F", Scenario: "Broken", Status: "failed"},
+			{Feature: "F", Scenario: "Stable", Status: "passed"},
+		},
+	}
+
+	recommendQuarantine(&results, previous, Args{QuarantineThreshold: 30})
+
+	if len(results.QuarantineRecommendations) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %v", results.QuarantineRecommendations)
+	}
+	recommendation := results.QuarantineRecommendations[0]
+	if recommendation.Scenario != "Flaky" || recommendation.Builds != 4 || recommendation.FailedBuilds != 2 {
+		t.Errorf("Unexpected recommendation: %+v", recommendation)
+	}
+
+	// A window of a single build only holds the current build, never the history
+	recommendQuarantine(&results, previous, Args{QuarantineThreshold: 30, QuarantineBuilds: 1})
+	if len(results.QuarantineRecommendations) != 0 {
+		t.Errorf("Expected no recommendation within a single build, got %v", results.QuarantineRecommendations)
+	}
+	recommendQuarantine(&results, previous, Args{QuarantineThreshold: 30})
+
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+	os.WriteFile(path, []byte(`{"scenarios":[{"feature":"G","scenario":"Existing"}]}`), 0644)
+	if err := writeQuarantineFile(path, results.QuarantineRecommendations); err != nil {
+		t.Fatalf("Unexpected error writing quarantine file: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	var quarantine QuarantineFile
+	if err := json.Unmarshal(content, &quarantine); err != nil {
+		t.Fatalf("Failed to parse quarantine file: %v", err)
+	}
+	if len(quarantine.Scenarios) != 2 {
+		t.Errorf("Expected 2 quarantined scenarios, got %v", quarantine.Scenarios)
+	}
+}
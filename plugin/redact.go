@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Constants for Name Redaction
+const (
+	RedactNamesNone   = "NONE"
+	RedactNamesHash   = "HASH"
+	RedactNamesRedact = "REDACT"
+)
+
+// nameRules are the scrub rules replacing the feature and scenario names and the feature
+// file paths of the results in everything sent to external services.
+var nameRules scrubber
+
+// templateEscaper escapes a text like html/template does in the HTML reports.
+var templateEscaper = strings.NewReplacer(`"`, "&#34;", "'", "&#39;", "&", "&amp;", "+", "&#43;", "<", "&lt;", ">", "&gt;")
+
+// validateRedactNames checks the name redaction setting.
+func validateRedactNames(args Args) error {
+	switch strings.ToUpper(args.RedactNames) {
+	case "", RedactNamesNone, RedactNamesHash, RedactNamesRedact:
+		return nil
+	}
+	return fmt.Errorf("invalid RedactNames value. It must be '%s', '%s' or '%s'", RedactNamesNone, RedactNamesHash, RedactNamesRedact)
+}
+
+// configureNameRedaction builds the scrub rule of the names of the results. The names and
+// their escaped forms are matched by a single alternation, longest first, so that a name
+// containing another is replaced as a whole and the replacements are never matched again.
+func configureNameRedaction(args Args, results Results) {
+	nameRules = nil
+	mode := strings.ToUpper(args.RedactNames)
+	if mode == "" || mode == RedactNamesNone {
+		return
+	}
+
+	kinds := make(map[string]string)
+	add := func(name, kind string) {
+		if strings.TrimSpace(name) != "" && kinds[name] == "" {
+			kinds[name] = kind
+		}
+	}
+	for _, scenario := range results.Scenarios {
+		add(scenario.Feature, "feature")
+		add(scenario.Scenario, "scenario")
+	}
+	for _, step := range results.FailedSteps {
+		add(step.Feature, "feature")
+		add(step.Scenario, "scenario")
+		add(step.URI, "file")
+	}
+
+	if len(kinds) == 0 {
+		return
+	}
+
+	matches := make(map[string]string)
+	for name, kind := range kinds {
+		matches[name] = redacted
+		if mode == RedactNamesHash {
+			matches[name] = hashName(kind, name)
+		}
+	}
+	for name := range kinds {
+		for _, form := range nameForms(name) {
+			if _, ok := matches[form]; !ok {
+				matches[form] = matches[name]
+			}
+		}
+	}
+
+	forms := make([]string, 0, len(matches))
+	for form := range matches {
+		forms = append(forms, form)
+	}
+	sort.Slice(forms, func(i, j int) bool {
+		if len(forms[i]) != len(forms[j]) {
+			return len(forms[i]) > len(forms[j])
+		}
+		return forms[i] < forms[j]
+	})
+	for i, form := range forms {
+		forms[i] = regexp.QuoteMeta(form)
+	}
+	nameRules = scrubber{{pattern: regexp.MustCompile(strings.Join(forms, "|")), matches: matches}}
+}
+
+// nameForms returns the escaped forms of a name in the texts and documents sent to
+// external services: escaped in HTML by html/template or by the other renderers, and
+// escaped in a JSON string.
+func nameForms(name string) []string {
+	quoted, _ := json.Marshal(name)
+	return []string{templateEscaper.Replace(name), html.EscapeString(name), string(quoted[1 : len(quoted)-1])}
+}
+
+// hashName returns a stable pseudonym of a name, such as feature-3f2a9c1d, so that the
+// same feature or scenario can be followed across builds without revealing its name.
+func hashName(kind, name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return kind + "-" + hex.EncodeToString(sum[:4])
+}
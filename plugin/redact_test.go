@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestConfigureNameRedaction tests hashing and redacting the names of the results
+func TestConfigureNameRedaction(t *testing.T) {
+	t.Cleanup(func() { nameRules = nil })
+	results := Results{
+		Scenarios: []ScenarioResult{
+			{Feature: "Checkout", Scenario: "Pay with Checkout Pro"},
+			{Feature: "Checkout", Scenario: "Pay by card"},
+		},
+		FailedSteps: []FailedStepDetails{{Feature: "Checkout", Scenario: "Pay with Checkout Pro", URI: "features/checkout-pro.feature"}},
+	}
+	text := "Pay with Checkout Pro failed in Checkout (features/checkout-pro.feature)"
+
+	tests := []struct {
+		mode     string
+		expected string
+	}{
+		{"", text},
+		{RedactNamesNone, text},
+		{"redact", "[REDACTED] failed in [REDACTED] ([REDACTED])"},
+		{RedactNamesHash, hashName("scenario", "Pay with Checkout Pro") + " failed in " + hashName("feature", "Checkout") + " (" + hashName("file", "features/checkout-pro.feature") + ")"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.mode, func(t *testing.T) {
+			configureNameRedaction(Args{RedactNames: tc.mode}, results)
+			if scrubbed := newScrubber(Args{}).text(text); scrubbed != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, scrubbed)
+			}
+		})
+	}
+
+	if hash := hashName("feature", "Checkout"); hash != hashName("feature", "Checkout") || len(hash) != len("feature-")+8 {
+		t.Errorf("Expected a stable pseudonym, got %s", hash)
+	}
+}
+
+// TestConfigureNameRedactionEscaped tests replacing the names escaped in the HTML and JSON
+// documents
+func TestConfigureNameRedactionEscaped(t *testing.T) {
+	t.Cleanup(func() { nameRules = nil })
+	configureNameRedaction(Args{RedactNames: RedactNamesRedact}, Results{Scenarios: []ScenarioResult{{Feature: "Tom & Jerry's <chase>", Scenario: `Say "hi" + wave`}}})
+
+	tests := []string{
+		`<td>Tom &amp; Jerry&#39;s &lt;chase&gt;</td><td>Say &#34;hi&#34; &#43; wave</td>`,
+		`<td>Tom &amp; Jerry&#39;s &lt;chase&gt;</td><td>Say &#34;hi&#34; + wave</td>`,
+		`{"feature":"Tom \u0026 Jerry's \u003cchase\u003e","scenario":"Say \"hi\" + wave"}`,
+	}
+	expected := []string{
+		`<td>[REDACTED]</td><td>[REDACTED]</td>`,
+		`<td>[REDACTED]</td><td>[REDACTED]</td>`,
+		`{"feature":"[REDACTED]","scenario":"[REDACTED]"}`,
+	}
+	for i, text := range tests {
+		if scrubbed := newScrubber(Args{}).text(text); scrubbed != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], scrubbed)
+		}
+	}
+}
+
+// TestConfigureNameRedactionOverlapping tests that the names overlapping other names or
+// their pseudonyms are replaced once
+func TestConfigureNameRedactionOverlapping(t *testing.T) {
+	t.Cleanup(func() { nameRules = nil })
+	configureNameRedaction(Args{RedactNames: RedactNamesHash}, Results{Scenarios: []ScenarioResult{
+		{Feature: "Checkout", Scenario: "feature"},
+		{Feature: "Checkout", Scenario: "Pay"},
+		{Feature: "Checkout", Scenario: "Pay by card"},
+	}})
+
+	text := "feature, Pay and Pay by card failed in Checkout"
+	expected := hashName("scenario", "feature") + ", " + hashName("scenario", "Pay") + " and " + hashName("scenario", "Pay by card") + " failed in " + hashName("feature", "Checkout")
+	if scrubbed := newScrubber(Args{}).text(text); scrubbed != expected {
+		t.Errorf("Expected %q, got %q", expected, scrubbed)
+	}
+}
+
+// TestPostJSONRedactsNames tests that the webhook payloads hide the names
+func TestPostJSONRedactsNames(t *testing.T) {
+	t.Cleanup(func() { nameRules = nil })
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+	}))
+	defer server.Close()
+
+	args := Args{RedactNames: RedactNamesRedact}
+	configureNameRedaction(args, Results{Scenarios: []ScenarioResult{{Feature: "Secret launch", Scenario: "Unveil"}}})
+	if err := postJSON(context.Background(), args, server.URL, map[string]string{"text": "Secret launch :: Unveil failed"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if body != `{"text":"[REDACTED] :: [REDACTED] failed"}` {
+		t.Errorf("Unexpected payload %s", body)
+	}
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isRemoteConfig reports whether the config file is an HTTP(S) URL, such as the raw URL of
+// a file in a git repository.
+func isRemoteConfig(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// loadConfigFile reads the config file from its path or URL.
+func loadConfigFile(ctx context.Context, args Args) (ConfigFile, error) {
+	if !isRemoteConfig(args.ConfigFile) {
+		return readConfigFile(args.ConfigFile)
+	}
+
+	content, err := fetchRemoteConfig(ctx, args)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+	var config ConfigFile
+	if err := json.Unmarshal(content, &config); err != nil {
+		return ConfigFile{}, fmt.Errorf("invalid config file %s: %v", redactURL(args.ConfigFile), err)
+	}
+	return config, nil
+}
+
+// fetchRemoteConfig downloads the remote config file and checks it against the pinned
+// checksum. With a cache directory, the downloaded file is cached: a cached file matching
+// the pinned checksum is used without downloading it again, and the cached file is used
+// when the download fails. A file not matching the pinned checksum is never used.
+func fetchRemoteConfig(ctx context.Context, args Args) ([]byte, error) {
+	location := redactURL(args.ConfigFile)
+	pin := strings.ToLower(strings.TrimPrefix(args.ConfigSHA256, "sha256:"))
+	var cachePath string
+	if args.ConfigCacheDir != "" {
+		cachePath = filepath.Join(args.ConfigCacheDir, "config-"+contentChecksum([]byte(args.ConfigFile))[:16]+".json")
+	}
+
+	if pin != "" && cachePath != "" {
+		if cached, err := os.ReadFile(cachePath); err == nil && contentChecksum(cached) == pin {
+			logger.Infof("Using the cached config file of %s\n", location)
+			return cached, nil
+		}
+	}
+
+	content, err := downloadConfig(ctx, args)
+	if err == nil {
+		if checksum := contentChecksum(content); pin != "" && checksum != pin {
+			return nil, fmt.Errorf("checksum mismatch of the config file %s: expected %s, got %s", location, pin, checksum)
+		}
+		if cachePath != "" {
+			if err := cacheConfig(cachePath, content); err != nil {
+				logger.Warnf("Failed to cache the config file of %s: %v", location, err)
+			}
+		}
+		return content, nil
+	}
+
+	if cachePath != "" {
+		if cached, cacheErr := os.ReadFile(cachePath); cacheErr == nil && (pin == "" || contentChecksum(cached) == pin) {
+			logger.Warnf("Failed to fetch the config file %s, using the cached one: %v", location, err)
+			return cached, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch the config file %s: %v", location, err)
+}
+
+// downloadConfig downloads the remote config file, with the config token as a bearer
+// token when one is set.
+func downloadConfig(ctx context.Context, args Args) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, args.ConfigFile, nil)
+	if err != nil {
+		return nil, err
+	}
+	if args.ConfigToken != "" {
+		req.Header.Set("Authorization", "Bearer "+args.ConfigToken)
+	}
+	return readResponse(newHTTPClient(args), req)
+}
+
+// cacheConfig writes the downloaded config file to the cache.
+func cacheConfig(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// contentChecksum returns the hex encoded SHA-256 checksum of the content.
+func contentChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchRemoteConfig(t *testing.T) {
+	config := `{"profiles": {"strict": {"settings": {"PLUGIN_FAILED_SCENARIOS_NUMBER": 0}}}}`
+	checksum := contentChecksum([]byte(config))
+	requests := 0
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !available {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(config))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	args := Args{ConfigFile: server.URL + "/profiles.json", ConfigToken: "secret", ConfigCacheDir: cacheDir, HTTPMaxAttempts: 1}
+
+	// Downloaded and cached
+	if content, err := fetchRemoteConfig(context.Background(), args); err != nil || string(content) != config {
+		t.Fatalf("Expected the config, got %q, %v", content, err)
+	}
+
+	// Cached copy used when the download fails
+	available = false
+	if content, err := fetchRemoteConfig(context.Background(), args); err != nil || string(content) != config {
+		t.Errorf("Expected the cached config, got %q, %v", content, err)
+	}
+
+	// Pinned cached copy used without downloading it
+	requests = 0
+	args.ConfigSHA256 = "sha256:" + checksum
+	if content, err := fetchRemoteConfig(context.Background(), args); err != nil || string(content) != config || requests != 0 {
+		t.Errorf("Expected the cached config without a request, got %q, %v after %d requests", content, err, requests)
+	}
+
+	// Checksum mismatch
+	available = true
+	args.ConfigSHA256 = strings.Repeat("0", 64)
+	if _, err := fetchRemoteConfig(context.Background(), args); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constants for the action on missing reports or scenarios
+const (
+	ActionFail = "FAIL"
+	ActionWarn = "WARN"
+)
+
+// validateReportCount returns an error if fewer report files than expected were
+// processed, or only logs a warning when configured to.
+func validateReportCount(results Results, args Args) error {
+	if args.ExpectedReportCount == 0 || results.ReportCount >= args.ExpectedReportCount {
+		return nil
+	}
+
+	err := wrapError(ErrMissingReports, fmt.Errorf("only %d of the %d expected report files were processed. A test shard may have crashed before writing its report",
+		results.ReportCount, args.ExpectedReportCount))
+	if strings.EqualFold(args.MissingReportsAction, ActionWarn) {
+		logger.Warnf("%s", err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,35 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateReportCount tests the verification of the number of processed report files
+func TestValidateReportCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		processed int
+		args      Args
+		expectErr bool
+	}{
+		{"Not configured", 1, Args{}, false},
+		{"All reports", 4, Args{ExpectedReportCount: 4}, false},
+		{"More reports", 5, Args{ExpectedReportCount: 4}, false},
+		{"Missing report", 3, Args{ExpectedReportCount: 4}, true},
+		{"Missing report with explicit action", 3, Args{ExpectedReportCount: 4, MissingReportsAction: "fail"}, true},
+		{"Missing report only warns", 3, Args{ExpectedReportCount: 4, MissingReportsAction: ActionWarn}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateReportCount(Results{ReportCount: tc.processed}, tc.args)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("Expected error: %v, got %v", tc.expectErr, err)
+			}
+			if err != nil && (!errors.Is(err, ErrMissingReports) || ErrorCode(wrapError(ErrGateViolation, err)) != "MISSING_REPORTS") {
+				t.Errorf("Expected a missing reports error, got %v", err)
+			}
+		})
+	}
+}
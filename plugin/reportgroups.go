@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseReportGroups parses the JSON list of report groups and checks their gates.
+func parseReportGroups(value string) ([]ReportGroup, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var groups []ReportGroup
+	if err := json.Unmarshal([]byte(value), &groups); err != nil {
+		return nil, fmt.Errorf("invalid report groups: %v", err)
+	}
+
+	keys := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		if envKey(group.Name) == "" {
+			return nil, errors.New("invalid report groups: every group requires a name")
+		}
+		if keys[envKey(group.Name)] {
+			return nil, fmt.Errorf("invalid report group %s: its name is not unique", group.Name)
+		}
+		keys[envKey(group.Name)] = true
+		if group.Directory == "" {
+			return nil, fmt.Errorf("invalid report group %s: it requires a directory", group.Name)
+		}
+		if err := validateFilePattern("include_pattern", group.IncludePattern); err != nil {
+			return nil, fmt.Errorf("invalid report group %s: %v", group.Name, err)
+		}
+		if err := validateExcludePatterns("exclude_pattern", group.ExcludePattern); err != nil {
+			return nil, fmt.Errorf("invalid report group %s: %v", group.Name, err)
+		}
+
+		gates := group.gateArgs()
+		if gates.FailedFeaturesNumber < 0 || gates.FailedScenariosNumber < 0 || gates.FailedStepsNumber < 0 ||
+			gates.PendingStepsNumber < 0 || gates.SkippedStepsNumber < 0 || gates.UndefinedStepsNumber < 0 {
+			return nil, fmt.Errorf("invalid report group %s: threshold values must be non-negative", group.Name)
+		}
+		if err := validatePercentages(gates); err != nil {
+			return nil, fmt.Errorf("invalid report group %s: %v", group.Name, err)
+		}
+		if _, err := parseDurationBudgets(gates.DurationBudgets); err != nil {
+			return nil, fmt.Errorf("invalid report group %s: %v", group.Name, err)
+		}
+		if _, err := parseMaxFeatureDuration(gates.MaxFeatureDuration); err != nil {
+			return nil, fmt.Errorf("invalid report group %s: %v", group.Name, err)
+		}
+	}
+	return groups, nil
+}
+
+// gateArgs returns the thresholds of the group as settings, so that its gates are
+// evaluated like the gates of the whole run.
+func (g ReportGroup) gateArgs() Args {
+	return Args{
+		FailedFeaturesNumber:      g.FailedFeaturesNumber,
+		FailedFeaturesPercentage:  g.FailedFeaturesPercentage,
+		FailedScenariosNumber:     g.FailedScenariosNumber,
+		FailedScenariosPercentage: g.FailedScenariosPercentage,
+		FailedStepsNumber:         g.FailedStepsNumber,
+		FailedStepsPercentage:     g.FailedStepsPercentage,
+		PendingStepsNumber:        g.PendingStepsNumber,
+		PendingStepsPercentage:    g.PendingStepsPercentage,
+		SkippedStepsNumber:        g.SkippedStepsNumber,
+		SkippedStepsPercentage:    g.SkippedStepsPercentage,
+		UndefinedStepsNumber:      g.UndefinedStepsNumber,
+		UndefinedStepsPercentage:  g.UndefinedStepsPercentage,
+		DurationBudgets:           g.DurationBudgets,
+		MaxFeatureDuration:        g.MaxFeatureDuration,
+	}
+}
+
+// locateGroupFiles locates the report files of every group, with the include and exclude
+// patterns of the settings unless the group has its own. It returns every file once, in
+// the order of the groups, and the groups of every file. A group without report files is
+// an error, like a report directory without any.
+func locateGroupFiles(groups []ReportGroup, args Args) ([]string, map[string][]string, error) {
+	var files []string
+	fileGroups := make(map[string][]string)
+	for _, group := range groups {
+		matches, err := locateFiles(group.Directory, firstNonEmpty(group.IncludePattern, args.FileIncludePattern), firstNonEmpty(group.ExcludePattern, args.FileExcludePattern))
+		if err != nil {
+			return nil, nil, fmt.Errorf("report group %s: %v", group.Name, err)
+		}
+		for _, file := range matches {
+			if _, ok := fileGroups[file]; !ok {
+				files = append(files, file)
+			}
+			fileGroups[file] = append(fileGroups[file], group.Name)
+		}
+	}
+	return files, fileGroups, nil
+}
+
+// reportGroupResults summarizes the results of every group and evaluates its gates.
+func reportGroupResults(groups []ReportGroup, totals map[string]*Results) []ReportGroupResult {
+	results := make([]ReportGroupResult, 0, len(groups))
+	for _, group := range groups {
+		total := totals[group.Name]
+		if total == nil {
+			total = &Results{}
+		}
+		result := ReportGroupResult{
+			Name:            group.Name,
+			Reports:         total.ReportCount,
+			Features:        total.FeatureCount,
+			Scenarios:       total.ScenarioCount,
+			PassedScenarios: total.TotalPassedScenarios,
+			FailedScenarios: total.TotalFailedScenarios,
+			Steps:           total.StepCount,
+			FailedSteps:     total.TotalFailedSteps,
+			PassRate:        percentageOf(total.TotalPassedScenarios, total.ScenarioCount),
+			DurationMS:      total.DurationMS,
+			Gates:           evaluateThresholds(*total, group.gateArgs()),
+			Passed:          true,
+		}
+		for _, gate := range result.Gates {
+			result.Passed = result.Passed && gate.Passed
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// logReportGroups logs the scenario counts and gate verdicts of each report group.
+func logReportGroups(groups []ReportGroupResult) {
+	if len(groups) == 0 {
+		return
+	}
+
+	logger.Infof("Report Groups:\n")
+	logger.Infof("-----------------------------------------------\n")
+	for _, group := range groups {
+		logger.Infof("%s %s: %d reports, %d scenarios (%d passed, %d failed), pass rate %s%%, %s ms\n", gateSymbol(group.Passed), group.Name, group.Reports,
+			group.Scenarios, group.PassedScenarios, group.FailedScenarios, formatNumber(group.PassRate), formatNumber(group.DurationMS))
+		for _, gate := range group.Gates {
+			logger.Infof("   %s: %s (Threshold: %s) %s\n", gate.Name, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold), gateSymbol(gate.Passed))
+		}
+	}
+	logger.Infof("===============================================\n")
+}
+
+// validateReportGroups returns an error listing the report groups failing their gates.
+func validateReportGroups(groups []ReportGroupResult) error {
+	var violations []string
+	for _, group := range groups {
+		for _, gate := range group.Gates {
+			if !gate.Passed {
+				violations = append(violations, fmt.Sprintf("%s (%s)", group.Name, gate.Message))
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("report groups failed their gates: %s", strings.Join(violations, ", "))
+}
+
+// writeReportGroupStats writes the statistics of each report group to the output variables.
+func writeReportGroupStats(groups []ReportGroupResult, log Logger) {
+	if len(groups) == 0 {
+		return
+	}
+
+	violations := 0
+	stats := map[string]string{}
+	for _, group := range groups {
+		prefix := "GROUP_" + envKey(group.Name)
+		stats[prefix+"_TOTAL_SCENARIOS"] = strconv.Itoa(group.Scenarios)
+		stats[prefix+"_PASSED_SCENARIOS"] = strconv.Itoa(group.PassedScenarios)
+		stats[prefix+"_FAILED_SCENARIOS"] = strconv.Itoa(group.FailedScenarios)
+		stats[prefix+"_FAILED_STEPS"] = strconv.Itoa(group.FailedSteps)
+		stats[prefix+"_PASS_RATE"] = formatNumber(group.PassRate)
+		stats[prefix+"_DURATION_MS"] = formatNumber(group.DurationMS)
+		stats[prefix+"_PASSED"] = strconv.FormatBool(group.Passed)
+		if !group.Passed {
+			violations++
+		}
+	}
+	stats["GROUP_VIOLATIONS"] = strconv.Itoa(violations)
+
+	for key, value := range stats {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseReportGroups(t *testing.T) {
+	tests := []struct {
+		value  string
+		errMsg string
+	}{
+		{``, ""},
+		{`[{"name": "api", "directory": "api"}, {"name": "ui", "directory": "ui", "failed_scenarios_number": 2}]`, ""},
+		{`{"name": "api"}`, "invalid report groups"},
+		{`[{"directory": "api"}]`, "every group requires a name"},
+		{`[{"name": "api", "directory": "a"}, {"name": "API", "directory": "b"}]`, "its name is not unique"},
+		{`[{"name": "api"}]`, "it requires a directory"},
+		{`[{"name": "api", "directory": "api", "failed_steps_number": -1}]`, "threshold values must be non-negative"},
+		{`[{"name": "api", "directory": "api", "failed_scenarios_percentage": 120}]`, "between 0 and 100"},
+		{`[{"name": "api", "directory": "api", "max_feature_duration": "long"}]`, "invalid MaxFeatureDuration"},
+	}
+	for _, tc := range tests {
+		_, err := parseReportGroups(tc.value)
+		if tc.errMsg == "" && err != nil {
+			t.Errorf("parseReportGroups(%s) unexpected error: %v", tc.value, err)
+		}
+		if tc.errMsg != "" && (err == nil || !strings.Contains(err.Error(), tc.errMsg)) {
+			t.Errorf("parseReportGroups(%s) error = %v, want %q", tc.value, err, tc.errMsg)
+		}
+	}
+}
+
+func TestLocateGroupFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"api/a.json", "api/shared.json", "ui/b.json", "ui/b.xml"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("[]"), 0644)
+	}
+	groups := []ReportGroup{
+		{Name: "api", Directory: filepath.Join(dir, "api")},
+		{Name: "ui", Directory: filepath.Join(dir, "ui"), IncludePattern: "*.xml"},
+		{Name: "shared", Directory: filepath.Join(dir, "api"), IncludePattern: "shared.json"},
+	}
+
+	files, fileGroups, err := locateGroupFiles(groups, Args{FileIncludePattern: "*.json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "api", "a.json"), filepath.Join(dir, "api", "shared.json"), filepath.Join(dir, "ui", "b.xml")}
+	if diff := cmp.Diff(expected, files); diff != "" {
+		t.Errorf("Files mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"api", "shared"}, fileGroups[expected[1]]); diff != "" {
+		t.Errorf("Groups mismatch (-want +got):\n%s", diff)
+	}
+
+	groups = append(groups, ReportGroup{Name: "mobile", Directory: filepath.Join(dir, "mobile")})
+	if _, _, err := locateGroupFiles(groups, Args{FileIncludePattern: "*.json"}); err == nil || !strings.Contains(err.Error(), "report group mobile") {
+		t.Errorf("Expected an error for the group without reports, got %v", err)
+	}
+}
+
+func TestReportGroupResults(t *testing.T) {
+	groups := []ReportGroup{
+		{Name: "api", FailedScenariosNumber: 1},
+		{Name: "ui", FailedScenariosPercentage: 10},
+		{Name: "empty"},
+	}
+	totals := map[string]*Results{
+		"api": {ReportCount: 2, ScenarioCount: 10, TotalPassedScenarios: 9, TotalFailedScenarios: 1, FailedTests: 1},
+		"ui":  {ReportCount: 1, ScenarioCount: 4, TotalPassedScenarios: 3, TotalFailedScenarios: 1, FailedTests: 1},
+	}
+
+	results := reportGroupResults(groups, totals)
+	if len(results) != 3 || !results[0].Passed || results[1].Passed || !results[2].Passed || results[2].Scenarios != 0 {
+		t.Fatalf("Unexpected group results: %+v", results)
+	}
+	if results[0].PassRate != 90 || results[0].Reports != 2 || len(results[0].Gates) != 1 {
+		t.Errorf("Unexpected api group: %+v", results[0])
+	}
+
+	err := validateReportGroups(results)
+	if expected := "report groups failed their gates: ui (failed scenarios percentage (25.00%) exceeds the threshold (10.00%))"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,261 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// defaultReproRerunCommand is the rerun command template used when none is configured.
+const defaultReproRerunCommand = "cucumber {{.Location}}"
+
+// reproMaxNameLength limits the length of the bundle directory names.
+const reproMaxNameLength = 80
+
+// attachmentExtensions maps the MIME types of the embeddings to file extensions.
+var attachmentExtensions = map[string]string{
+	"image/png":        ".png",
+	"image/jpeg":       ".jpg",
+	"image/gif":        ".gif",
+	"image/svg+xml":    ".svg",
+	"video/mp4":        ".mp4",
+	"video/webm":       ".webm",
+	"text/plain":       ".txt",
+	"text/html":        ".html",
+	"text/csv":         ".csv",
+	"application/json": ".json",
+	"application/xml":  ".xml",
+	"application/pdf":  ".pdf",
+}
+
+// reproScenario holds the fields available to the rerun command template.
+type reproScenario struct {
+	Feature  string
+	Scenario string
+	URI      string
+	Line     int
+	Location string
+	Tags     string
+}
+
+// validateReproArgs checks the reproduction bundle settings.
+func validateReproArgs(args Args) error {
+	if args.ReproDir == "" || args.ReproRerunCommand == "" {
+		return nil
+	}
+	if _, err := template.New("rerun").Parse(args.ReproRerunCommand); err != nil {
+		return fmt.Errorf("invalid ReproRerunCommand template: %v", err)
+	}
+	return nil
+}
+
+// writeReproBundles writes a reproduction bundle for every failed scenario of the
+// report files and returns the number of bundles. Each bundle is a directory holding
+// the scenario reconstructed as Gherkin, the error messages, the attachments and the
+// command rerunning the scenario.
+func writeReproBundles(dir string, files []string, args Args) (int, error) {
+	tmpl, err := template.New("rerun").Parse(firstNonEmpty(args.ReproRerunCommand, defaultReproRerunCommand))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, feature := range loadFeatures(files, args) {
+		var background *Element
+		for i := range feature.Elements {
+			element := &feature.Elements[i]
+			if element.Type == "background" {
+				background = element
+				continue
+			}
+			if !scenarioFailed(*element, args) {
+				continue
+			}
+
+			count++
+			name := fmt.Sprintf("%03d-%s", count, slug(feature.Name+" "+element.Name))
+			if len(name) > reproMaxNameLength {
+				name = strings.TrimRight(name[:reproMaxNameLength], "-")
+			}
+			if err := writeReproBundle(filepath.Join(dir, name), feature, background, *element, tmpl); err != nil {
+				return count, err
+			}
+		}
+	}
+	return count, nil
+}
+
+// scenarioFailed reports whether a step of the scenario failed and counts as a failure.
+func scenarioFailed(element Element, args Args) bool {
+	if args.FailedAsNotFailingStatus {
+		return false
+	}
+	for _, step := range element.Steps {
+		if step.Result.Status == "failed" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeReproBundle writes the files of the reproduction bundle of a failed scenario.
+func writeReproBundle(dir string, feature Feature, background *Element, element Element, tmpl *template.Template) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	scenario := reproScenario{
+		Feature:  feature.Name,
+		Scenario: element.Name,
+		URI:      feature.URI,
+		Line:     element.Line,
+		Location: fmt.Sprintf("%s:%d", feature.URI, element.Line),
+		Tags:     strings.Join(scenarioTags(feature, element), " "),
+	}
+	var command bytes.Buffer
+	if err := tmpl.Execute(&command, scenario); err != nil {
+		return err
+	}
+
+	var messages strings.Builder
+	for _, step := range element.Steps {
+		if step.Result.Status == "failed" {
+			fmt.Fprintf(&messages, "Step: %s%s\nLocation: %s:%d\n\n%s\n\n", step.Keyword, step.Name, feature.URI, step.Line, step.Result.ErrorMessage)
+		}
+	}
+
+	files := map[string]string{
+		"scenario.feature": reconstructGherkin(feature, background, element),
+		"error.txt":        messages.String(),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "rerun.sh"), []byte("#!/bin/sh\n"+strings.TrimSpace(command.String())+"\n"), 0755); err != nil {
+		return err
+	}
+	return writeReproAttachments(filepath.Join(dir, "attachments"), element)
+}
+
+// reconstructGherkin writes the scenario back as Gherkin, with the result of each step
+// as a comment.
+func reconstructGherkin(feature Feature, background *Element, element Element) string {
+	var gherkin strings.Builder
+	fmt.Fprintf(&gherkin, "# Reconstructed from the report of %s:%d\n", feature.URI, element.Line)
+	writeGherkinTags(&gherkin, "", feature.Tags)
+	fmt.Fprintf(&gherkin, "%s: %s\n", firstNonEmpty(feature.Keyword, "Feature"), feature.Name)
+
+	if background != nil {
+		fmt.Fprintf(&gherkin, "\n  %s\n", strings.TrimSpace(firstNonEmpty(background.Keyword, "Background")+": "+background.Name))
+		writeGherkinSteps(&gherkin, background.Steps)
+	}
+
+	gherkin.WriteString("\n")
+	writeGherkinTags(&gherkin, "  ", element.Tags)
+	fmt.Fprintf(&gherkin, "  %s: %s\n", firstNonEmpty(element.Keyword, "Scenario"), element.Name)
+	writeGherkinSteps(&gherkin, element.Steps)
+	return gherkin.String()
+}
+
+// writeGherkinTags writes the tags on a line when there are any.
+func writeGherkinTags(gherkin *strings.Builder, indent string, tags []Tag) {
+	if len(tags) == 0 {
+		return
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	fmt.Fprintf(gherkin, "%s%s\n", indent, strings.Join(names, " "))
+}
+
+// writeGherkinSteps writes the steps with their data tables and doc strings.
+func writeGherkinSteps(gherkin *strings.Builder, steps []Step) {
+	for _, step := range steps {
+		fmt.Fprintf(gherkin, "    %s%s # %s\n", step.Keyword, step.Name, firstNonEmpty(step.Result.Status, "unknown"))
+		for _, argument := range step.Arguments {
+			if len(argument.Rows) > 0 {
+				writeGherkinTable(gherkin, argument.Rows)
+			} else if argument.Content != "" {
+				gherkin.WriteString("      \"\"\"\n")
+				for _, line := range strings.Split(strings.TrimRight(argument.Content, "\n"), "\n") {
+					fmt.Fprintf(gherkin, "      %s\n", line)
+				}
+				gherkin.WriteString("      \"\"\"\n")
+			}
+		}
+	}
+}
+
+// writeGherkinTable writes a data table with aligned columns.
+func writeGherkinTable(gherkin *strings.Builder, rows []Row) {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row.Cells {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if length := utf8.RuneCountInString(cell); length > widths[i] {
+				widths[i] = length
+			}
+		}
+	}
+	for _, row := range rows {
+		gherkin.WriteString("      |")
+		for i, cell := range row.Cells {
+			fmt.Fprintf(gherkin, " %s%s |", cell, strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+		gherkin.WriteString("\n")
+	}
+}
+
+// writeReproAttachments writes the embeddings and the output of the steps and hooks of
+// the scenario to the attachments directory, when there are any.
+func writeReproAttachments(dir string, element Element) error {
+	var output []string
+	index := 0
+	for _, steps := range [][]Step{element.Before, element.Steps, element.After} {
+		for _, step := range steps {
+			output = append(output, step.Output...)
+			for _, embedding := range step.Embeddings {
+				data, err := base64.StdEncoding.DecodeString(embedding.Data)
+				if err != nil {
+					// Some formatters embed text without encoding it
+					data = []byte(embedding.Data)
+				}
+				index++
+				name := strconv.Itoa(index)
+				if embedding.Name != "" {
+					name += "-" + slug(embedding.Name)
+				}
+				extension, ok := attachmentExtensions[embedding.MimeType]
+				if !ok {
+					extension = ".bin"
+				}
+				if err := writeAttachment(dir, name+extension, data); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(output) > 0 {
+		return writeAttachment(dir, "output.txt", []byte(strings.Join(output, "\n")+"\n"))
+	}
+	return nil
+}
+
+// writeAttachment writes a file to the attachments directory, creating it.
+func writeAttachment(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0644)
+}
@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWriteReproBundles tests writing the reproduction bundle of a failed scenario
+func TestWriteReproBundles(t *testing.T) {
+	dir := t.TempDir()
+	args := Args{ReproRerunCommand: "mvn test -Dcucumber.features={{.Location}} # {{.Tags}}"}
+	count, err := writeReproBundles(dir, []string{"../testdata/repro/report.json"}, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1 bundle, got %d", count)
+	}
+
+	bundle := filepath.Join(dir, "001-checkout-pay-by-card")
+	expected := map[string]string{
+		"scenario.feature": `# Reconstructed from the report of features/checkout.feature:8
+@shop
+Feature: Checkout
+
+  Background:
+    Given I am logged in # passed
+
+  @payments
+  Scenario: Pay by card
+    Given the cart contains # passed
+      | product | qty |
+      | Lamp    | 1   |
+    When I pay with # failed
+      """
+      {"card": "4111"}
+      """
+    Then the order is confirmed # skipped
+`,
+		"error.txt":                     "Step: When I pay with\nLocation: features/checkout.feature:12\n\ncard declined\n\tat Payments.pay(Payments.java:42)\n\n",
+		"rerun.sh":                      "#!/bin/sh\nmvn test -Dcucumber.features=features/checkout.feature:8 # @shop @payments\n",
+		"attachments/output.txt":        "payment id 42\n",
+		"attachments/1-screen-shot.png": "\x89PNG\r\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(bundle, name))
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if diff := cmp.Diff(content, string(data)); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+// TestValidateReproArgs tests the validation of the rerun command template
+func TestValidateReproArgs(t *testing.T) {
+	if err := validateReproArgs(Args{ReproDir: "repro", ReproRerunCommand: "cucumber {{.Location}}"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateReproArgs(Args{ReproDir: "repro", ReproRerunCommand: "cucumber {{.Location"}); err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,46 @@
+package plugin
+
+import "strconv"
+
+// scenarioID identifies an execution of a scenario, using the id set by the reporter or
+// the location of the scenario, so that the examples of an outline are distinguished.
+func scenarioID(feature Feature, element Element) string {
+	if element.ID != "" {
+		return element.ID
+	}
+	return feature.URI + ":" + strconv.Itoa(element.Line)
+}
+
+// countStepRetries counts the steps executed again right after failing, as recorded
+// by the reporters retrying steps in place.
+func countStepRetries(steps []Step) int {
+	retries := 0
+	for i := 1; i < len(steps); i++ {
+		previous := steps[i-1]
+		if previous.Result.Status == "failed" && previous.Line == steps[i].Line && previous.Name == steps[i].Name {
+			retries++
+		}
+	}
+	return retries
+}
+
+// countRetries counts the scenarios executed more than once across the reports, such as
+// the reruns of failed scenarios, and the total number of retries.
+func countRetries(results *Results) {
+	executions := make(map[string]int)
+	for _, scenario := range results.Scenarios {
+		if scenario.ID != "" {
+			executions[scenario.ID]++
+		}
+	}
+
+	results.ScenarioRetries = 0
+	results.RetriedScenarios = 0
+	for _, count := range executions {
+		if count > 1 {
+			results.ScenarioRetries += count - 1
+			results.RetriedScenarios++
+		}
+	}
+	results.TotalRetries = results.ScenarioRetries + results.StepRetries
+}
@@ -0,0 +1,30 @@
+package plugin
+
+import (
+	"testing"
+)
+
+// TestCountRetries tests counting the scenario reruns and step retries
+func TestCountRetries(t *testing.T) {
+	failed := Result{Status: "failed"}
+	passed := Result{Status: "passed"}
+	features := []Feature{{
+		URI: "features/checkout.feature",
+		Elements: []Element{
+			{ID: "checkout;pay", Line: 3, Steps: []Step{{Name: "I pay", Line: 4, Result: failed}, {Name: "I pay", Line: 4, Result: passed}, {Name: "I see the receipt", Line: 5, Result: passed}}},
+			{ID: "checkout;pay", Line: 3, Steps: []Step{{Name: "I pay", Line: 4, Result: passed}}},
+			{ID: "checkout;pay", Line: 3, Steps: []Step{{Name: "I pay", Line: 4, Result: passed}}},
+			{Line: 10, Steps: []Step{{Name: "I refund", Line: 11, Result: passed}, {Name: "I refund", Line: 11, Result: passed}}},
+			{Line: 10, Steps: []Step{{Name: "I refund", Line: 11, Result: passed}}},
+			{Line: 20, Steps: []Step{{Name: "I cancel", Line: 21, Result: failed}}},
+		},
+	}}
+
+	results := computeStats(features, Args{})
+	countRetries(&results)
+
+	if results.StepRetries != 1 || results.ScenarioRetries != 3 || results.RetriedScenarios != 2 || results.TotalRetries != 4 {
+		t.Errorf("Expected 1 step retry and 3 reruns of 2 scenarios, got %d step retries and %d reruns of %d scenarios (total %d)",
+			results.StepRetries, results.ScenarioRetries, results.RetriedScenarios, results.TotalRetries)
+	}
+}
@@ -0,0 +1,281 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Constants for Notification Channels
+const (
+	ChannelSlack      = "slack"
+	ChannelGoogleChat = "google_chat"
+	ChannelAlert      = "alert"
+)
+
+// Constants for Route Conditions
+const (
+	RouteOnAlways      = "ALWAYS"
+	RouteOnFailure     = "FAILURE"
+	RouteOnGateFailure = "GATE_FAILURE"
+)
+
+// parseNotificationRoutes parses the JSON list of notification routes and checks that
+// their channels are configured and their templates parse.
+func parseNotificationRoutes(args Args) ([]NotificationRoute, error) {
+	if strings.TrimSpace(args.NotificationRoutes) == "" {
+		return nil, nil
+	}
+
+	var routes []NotificationRoute
+	if err := json.Unmarshal([]byte(args.NotificationRoutes), &routes); err != nil {
+		return nil, fmt.Errorf("invalid notification routes: %v", err)
+	}
+
+	configured := map[string]bool{
+		ChannelSlack:      args.SlackWebhook != "",
+		ChannelGoogleChat: args.GoogleChatWebhook != "",
+		ChannelAlert:      args.AlertProvider != "",
+	}
+	for i, route := range routes {
+		name := firstNonEmpty(route.Name, fmt.Sprintf("#%d", i+1))
+		if len(route.Channels) == 0 {
+			return nil, fmt.Errorf("invalid notification route %s: it requires channels", name)
+		}
+		for _, channel := range route.Channels {
+			enabled, ok := configured[channel]
+			if !ok {
+				return nil, fmt.Errorf("invalid notification route %s: unknown channel %q. It must be '%s', '%s' or '%s'", name, channel, ChannelSlack, ChannelGoogleChat, ChannelAlert)
+			}
+			if !enabled {
+				return nil, fmt.Errorf("invalid notification route %s: the %s channel is not configured", name, channel)
+			}
+		}
+		switch strings.ToUpper(route.On) {
+		case "", RouteOnAlways, RouteOnFailure, RouteOnGateFailure:
+		default:
+			return nil, fmt.Errorf("invalid notification route %s: invalid on value. It must be '%s', '%s' or '%s'", name, RouteOnAlways, RouteOnFailure, RouteOnGateFailure)
+		}
+		for _, pattern := range route.Branches {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid notification route %s: invalid branch %q", name, pattern)
+			}
+		}
+		if route.Template != "" {
+			if _, err := parseMessageTemplate(route.Template, args.TemplateDir); err != nil {
+				return nil, fmt.Errorf("invalid notification route %s: %v", name, err)
+			}
+		}
+	}
+	return routes, nil
+}
+
+// parseMessageTemplate parses a message template file of the template directory.
+func parseMessageTemplate(name, dir string) (*template.Template, error) {
+	if dir == "" {
+		return nil, errors.New("message templates require a template directory")
+	}
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	return template.New(name).Funcs(templateFuncs).Parse(string(content))
+}
+
+// scope returns the scenarios the route is restricted to by its tags and categories, and
+// whether it is restricted.
+func (r NotificationRoute) scope(scenarios []ScenarioResult) ([]ScenarioResult, bool) {
+	if len(r.Tags) == 0 && len(r.Categories) == 0 {
+		return scenarios, false
+	}
+
+	var scoped []ScenarioResult
+	for _, scenario := range scenarios {
+		matches := slices.Contains(r.Categories, scenario.Category)
+		for _, tag := range r.Tags {
+			if !strings.HasPrefix(tag, "@") {
+				tag = "@" + tag
+			}
+			matches = matches || hasTag(scenario.Tags, tag)
+		}
+		if matches {
+			scoped = append(scoped, scenario)
+		}
+	}
+	return scoped, true
+}
+
+// scopedResults returns the results of the scenarios of the scope of a route, so that its
+// messages count and list only them. The skipped and pending steps are not recorded by
+// scenario and are left out.
+func scopedResults(results Results, scenarios []ScenarioResult) Results {
+	scoped := Results{Scenarios: scenarios, ScenarioCount: len(scenarios), Annotations: results.Annotations}
+	identifiers := make(map[string]bool)
+	features := make(map[string]bool)
+	for _, scenario := range scenarios {
+		identifiers[scenarioIdentifier(scenario.Feature, scenario.Scenario)] = true
+		failed := scenario.Status == "failed"
+		features[scenario.Feature] = features[scenario.Feature] || failed
+		if failed {
+			scoped.TotalFailedScenarios++
+		} else {
+			scoped.TotalPassedScenarios++
+		}
+		scoped.StepCount += scenario.Steps
+		scoped.UndefinedTests += scenario.UndefinedSteps
+		scoped.DurationMS += scenario.DurationMS
+	}
+	scoped.FeatureCount = len(features)
+	for _, failed := range features {
+		if failed {
+			scoped.TotalFailedFeatures++
+		}
+	}
+	scoped.TotalPassedFeatures = scoped.FeatureCount - scoped.TotalFailedFeatures
+
+	for _, step := range results.FailedSteps {
+		if identifiers[scenarioIdentifier(step.Feature, step.Scenario)] {
+			scoped.FailedSteps = append(scoped.FailedSteps, step)
+		}
+	}
+	failures := len(scoped.FailedSteps)
+	if results.OverflowedFailedSteps > 0 {
+		failures = 0
+		for _, failure := range results.AllFailures {
+			if identifiers[scenarioIdentifier(failure.Feature, failure.Scenario)] {
+				failures++
+			}
+		}
+		scoped.OverflowedFailedSteps = max(failures-len(scoped.FailedSteps), 0)
+		scoped.FailedStepsOverflowFile = results.FailedStepsOverflowFile
+	}
+	scoped.FailedTests, scoped.TotalFailedSteps = failures, failures
+	return scoped
+}
+
+// gateFailed reports whether the quality gates failed for the route. A route naming gates
+// only fires on the failures of those gates; the other failures of the build, such as a
+// missing scenario of the manifest, are not gates of the table and never match them.
+func (r NotificationRoute) gateFailed(gates []GateResult, gateErr error) bool {
+	if len(r.Gates) == 0 {
+		return gateErr != nil
+	}
+	return slices.ContainsFunc(gates, func(gate GateResult) bool {
+		return !gate.Passed && slices.ContainsFunc(r.Gates, func(name string) bool { return strings.EqualFold(name, gate.Name) })
+	})
+}
+
+// matches reports whether the route fires for the build on the branch, and the number of
+// failed scenarios of its scope.
+func (r NotificationRoute) matches(results Results, gates []GateResult, branch string, gateErr error) (bool, int) {
+	if len(r.Branches) > 0 && !slices.ContainsFunc(r.Branches, func(pattern string) bool {
+		matched, _ := path.Match(pattern, branch)
+		return matched
+	}) {
+		return false, 0
+	}
+
+	scenarios, scoped := r.scope(results.Scenarios)
+	failed := 0
+	for _, scenario := range scenarios {
+		if scenario.Status == "failed" {
+			failed++
+		}
+	}
+
+	gateFailed := r.gateFailed(gates, gateErr)
+	switch strings.ToUpper(r.On) {
+	case RouteOnAlways:
+		return !scoped || len(scenarios) > 0, failed
+	case RouteOnGateFailure:
+		return gateFailed && (!scoped || failed > 0), failed
+	default:
+		return failed > 0 || (!scoped && gateFailed), failed
+	}
+}
+
+// routeNotifications sends the notifications of every route matching the build to its
+// channels, with the message rendered from its template when it has one. The messages of
+// a route restricted by tags or categories count and list the scenarios of its scope,
+// along with the quality gates of the build.
+func routeNotifications(ctx context.Context, routes []NotificationRoute, results Results, args Args, reportURL string, trend *Trend, gateErr error) {
+	branch := currentBranch(args)
+	gates := evaluateThresholds(results, args)
+	for i, route := range routes {
+		name := firstNonEmpty(route.Name, fmt.Sprintf("#%d", i+1))
+		fire, failed := route.matches(results, gates, branch, gateErr)
+		if !fire {
+			logger.Debugf("Notification route %s does not match the build", name)
+			continue
+		}
+		logger.Infof("Notification route %s matches the build\n", name)
+
+		routeResults := results
+		if scenarios, scoped := route.scope(results.Scenarios); scoped {
+			routeResults = scopedResults(results, scenarios)
+		}
+
+		message := ""
+		if route.Template != "" {
+			data := newReportTemplateData(routeResults, args, trend, gateErr, time.Now())
+			data.Gates = gates
+			rendered, err := renderMessageTemplate(route.Template, data, args)
+			if err != nil {
+				logger.Warnf("Failed to render the template of notification route %s: %v", name, err)
+				continue
+			}
+			message = rendered
+		}
+
+		routeArgs := args
+		routeArgs.SlackChannel = firstNonEmpty(route.SlackChannel, args.SlackChannel)
+		for _, channel := range route.Channels {
+			switch channel {
+			case ChannelSlack:
+				if message == "" {
+					postSlack(ctx, routeArgs, slackMessage(routeResults, gates, routeArgs, reportURL, gateErr))
+				} else {
+					payload := map[string]interface{}{"text": message}
+					if routeArgs.SlackChannel != "" {
+						payload["channel"] = routeArgs.SlackChannel
+					}
+					postSlack(ctx, routeArgs, payload)
+				}
+			case ChannelGoogleChat:
+				if message == "" {
+					postGoogleChat(ctx, routeArgs, googleChatMessage(routeResults, gates, routeArgs, reportURL, gateErr))
+				} else {
+					postGoogleChat(ctx, routeArgs, map[string]interface{}{"text": message})
+				}
+			case ChannelAlert:
+				reason := gateErr
+				if reason == nil {
+					reason = fmt.Errorf("%d scenarios failed", failed)
+				}
+				triggerAlert(ctx, routeResults, routeArgs, branch, firstNonEmpty(message, gateFailureSummary(branch, reason)))
+			}
+		}
+	}
+}
+
+// renderMessageTemplate renders the message template of a notification route.
+func renderMessageTemplate(name string, data reportTemplateData, args Args) (string, error) {
+	tmpl, err := parseMessageTemplate(name, args.TemplateDir)
+	if err != nil {
+		return "", err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(rendered.String()), nil
+}
@@ -0,0 +1,187 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestNotificationRouteMatches tests the branch, scope and gate conditions of the routes
+func TestNotificationRouteMatches(t *testing.T) {
+	results := Results{Scenarios: []ScenarioResult{
+		{Feature: "Checkout", Scenario: "Pay", Tags: []string{"@critical"}, Status: "passed"},
+		{Feature: "Search", Scenario: "Filter", Tags: []string{"@smoke"}, Category: "Search", Status: "failed"},
+	}}
+	gateErr := errors.New("failed scenarios count (1) exceeds the threshold (0)")
+	gates := []GateResult{{Name: "Failed Scenarios", Observed: 1}, {Name: "Skipped Steps", Passed: true}}
+
+	tests := []struct {
+		name    string
+		route   NotificationRoute
+		branch  string
+		gateErr error
+		want    bool
+	}{
+		{"any failure", NotificationRoute{}, "feature/x", nil, true},
+		{"always", NotificationRoute{On: "always"}, "main", nil, true},
+		{"branch not matching", NotificationRoute{Branches: []string{"main", "release/*"}}, "feature/x", nil, false},
+		{"branch glob", NotificationRoute{Branches: []string{"main", "release/*"}}, "release/1.2", nil, true},
+		{"tag without failures", NotificationRoute{Tags: []string{"critical"}}, "main", gateErr, false},
+		{"tag with failures", NotificationRoute{Tags: []string{"@smoke"}}, "main", nil, true},
+		{"category with failures", NotificationRoute{Categories: []string{"Search"}}, "main", nil, true},
+		{"always restricted to missing tag", NotificationRoute{On: RouteOnAlways, Tags: []string{"@wip"}}, "main", nil, false},
+		{"gate failure without gate error", NotificationRoute{On: RouteOnGateFailure}, "main", nil, false},
+		{"gate failure", NotificationRoute{On: RouteOnGateFailure}, "main", gateErr, true},
+		{"named gate failure", NotificationRoute{On: RouteOnGateFailure, Gates: []string{"failed scenarios"}}, "main", gateErr, true},
+		{"named gate passed", NotificationRoute{On: RouteOnGateFailure, Gates: []string{"Skipped Steps"}}, "main", gateErr, false},
+		{"named gate without gate error", NotificationRoute{On: RouteOnGateFailure, Gates: []string{"Failed Scenarios"}}, "main", nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, _ := tc.route.matches(results, gates, tc.branch, tc.gateErr); got != tc.want {
+				t.Errorf("matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestParseNotificationRoutes tests the validation of the notification routes
+func TestParseNotificationRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes string
+		errMsg string
+	}{
+		{"valid", `[{"channels":["slack"],"on":"failure","branches":["main"]}]`, ""},
+		{"no channels", `[{"name":"qa"}]`, "invalid notification route qa: it requires channels"},
+		{"unknown channel", `[{"channels":["teams"]}]`, `invalid notification route #1: unknown channel "teams"`},
+		{"channel not configured", `[{"channels":["alert"]}]`, "invalid notification route #1: the alert channel is not configured"},
+		{"invalid condition", `[{"channels":["slack"],"on":"sometimes"}]`, "invalid notification route #1: invalid on value"},
+		{"template without directory", `[{"channels":["slack"],"template":"qa.txt"}]`, "message templates require a template directory"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseNotificationRoutes(Args{NotificationRoutes: tc.routes, SlackWebhook: "https://hooks.slack.com/x"})
+			if tc.errMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected error %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+// TestRouteNotifications tests sending the notifications of the matching routes
+func TestRouteNotifications(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "critical.txt"), []byte("{{.Results.TotalFailedScenarios}} critical failures on {{.Branch}}\n"), 0644)
+
+	args := Args{
+		Branch:          "main",
+		SlackWebhook:    server.URL + "/slack",
+		AlertProvider:   AlertProviderPagerDuty,
+		AlertRoutingKey: "key",
+		AlertAPIURL:     server.URL + "/alert",
+		TemplateDir:     dir,
+		NotificationRoutes: `[
+			{"name": "qa", "channels": ["slack"], "slack_channel": "#qa"},
+			{"name": "on-call", "channels": ["alert"], "branches": ["main"], "tags": ["@critical"], "template": "critical.txt"},
+			{"name": "releases", "channels": ["slack"], "branches": ["release/*"]}
+		]`,
+	}
+	routes, err := parseNotificationRoutes(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	results := Results{
+		TotalFailedScenarios: 1,
+		Scenarios:            []ScenarioResult{{Feature: "Checkout", Scenario: "Pay", Tags: []string{"@critical"}, Status: "failed"}},
+	}
+	routeNotifications(context.Background(), routes, results, args, "", nil, nil)
+
+	if len(received["/slack"]) != 1 || received["/slack"][0]["channel"] != "#qa" {
+		t.Errorf("Expected one Slack message to #qa, got %v", received["/slack"])
+	}
+	if len(received["/alert"]) != 1 {
+		t.Fatalf("Expected one alert, got %v", received["/alert"])
+	}
+	summary := received["/alert"][0]["payload"].(map[string]interface{})["summary"]
+	if summary != "1 critical failures on main" {
+		t.Errorf("Expected the alert summary rendered from the template, got %v", summary)
+	}
+}
+
+// TestRouteNotificationsScoped tests that the messages of a route restricted by tags count
+// and list the scenarios of its scope
+func TestRouteNotificationsScoped(t *testing.T) {
+	var messages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		messages = append(messages, r.URL.Path+" "+string(content))
+	}))
+	defer server.Close()
+
+	args := Args{
+		SlackWebhook:       server.URL + "/slack",
+		GoogleChatWebhook:  server.URL + "/chat",
+		NotificationRoutes: `[{"name": "payments", "channels": ["slack", "google_chat"], "tags": ["@critical"], "slack_channel": "#payments"}]`,
+	}
+	routes, err := parseNotificationRoutes(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	results := Results{
+		FeatureCount:         2,
+		ScenarioCount:        3,
+		TotalFailedScenarios: 2,
+		Scenarios: []ScenarioResult{
+			{Feature: "Checkout", Scenario: "Pay", Tags: []string{"@critical"}, Status: "failed", Steps: 3},
+			{Feature: "Checkout", Scenario: "Refund", Tags: []string{"@critical"}, Status: "passed", Steps: 2},
+			{Feature: "Search", Scenario: "Filter", Status: "failed", Steps: 4},
+		},
+		FailedSteps: []FailedStepDetails{
+			{Feature: "Checkout", Scenario: "Pay", Step: "I pay", ErrorMessage: "Card declined"},
+			{Feature: "Search", Scenario: "Filter", Step: "I filter", ErrorMessage: "No results"},
+		},
+	}
+	routeNotifications(context.Background(), routes, results, args, "", nil, nil)
+
+	if len(messages) != 2 {
+		t.Fatalf("Expected a Slack and a Google Chat message, got %v", messages)
+	}
+	for _, message := range messages {
+		if !strings.Contains(message, "Card declined") || strings.Contains(message, "No results") {
+			t.Errorf("Expected only the failures of the scope, got %s", message)
+		}
+	}
+	if !strings.Contains(messages[0], "2 scenarios, 1 failed") || !strings.Contains(messages[0], `"channel":"#payments"`) {
+		t.Errorf("Expected the Slack message to count the scenarios of the scope, got %s", messages[0])
+	}
+
+	scoped := scopedResults(results, results.Scenarios[:2])
+	if scoped.FeatureCount != 1 || scoped.TotalFailedFeatures != 1 || scoped.StepCount != 5 || scoped.TotalFailedSteps != 1 {
+		t.Errorf("Unexpected scoped results %+v", scoped)
+	}
+}
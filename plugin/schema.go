@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// schemaDefault matches the default value documented in the description of a setting.
+var schemaDefault = regexp.MustCompile(`Defaults to (?:'([^']+)'|([^\s'` + "`" + `]+?))(?:[.,]\s|[.,]?$)`)
+
+// settingDoc is the documentation of a setting in the README.
+type settingDoc struct {
+	description string
+	example     string
+}
+
+// parseSettingDocs reads the descriptions and examples of the settings documented in the
+// README as "- `PLUGIN_NAME`" followed by "Description:" and "Example:" lines.
+func parseSettingDocs(readme []byte) map[string]settingDoc {
+	docs := make(map[string]settingDoc)
+	name := ""
+	scanner := bufio.NewScanner(bytes.NewReader(readme))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "- `PLUGIN_") && strings.HasSuffix(line, "`"):
+			name = strings.Trim(strings.TrimPrefix(line, "- "), "`")
+		case name != "" && strings.HasPrefix(line, "Description: "):
+			doc := docs[name]
+			doc.description = strings.TrimPrefix(line, "Description: ")
+			docs[name] = doc
+		case name != "" && strings.HasPrefix(line, "Example: "):
+			doc := docs[name]
+			doc.example = strings.TrimPrefix(line, "Example: ")
+			docs[name] = doc
+			name = ""
+		}
+	}
+	return docs
+}
+
+// schemaType returns the JSON Schema type of a setting field.
+func schemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// schemaValue converts a documented value to the type of the setting, reporting false
+// when it is not a value of the type.
+func schemaValue(kind reflect.Kind, value string) (interface{}, bool) {
+	switch kind {
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		return parsed, err == nil
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		return parsed, err == nil
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		return parsed, err == nil
+	default:
+		return value, true
+	}
+}
+
+// Schema returns the JSON Schema of the plugin settings, generated from the Args struct
+// with the descriptions, defaults and examples documented in the README. The properties
+// are named after the pipeline settings, such as json_report_directory for
+// PLUGIN_JSON_REPORT_DIRECTORY, and the secrets also accept a from_secret reference.
+func Schema(readme []byte) ([]byte, error) {
+	docs := parseSettingDocs(readme)
+	properties := make(map[string]interface{})
+
+	fields := reflect.TypeOf(Args{})
+	for i := 0; i < fields.NumField(); i++ {
+		env := fields.Field(i).Tag.Get("envconfig")
+		if env == "" {
+			continue
+		}
+		kind := fields.Field(i).Type.Kind()
+		property := map[string]interface{}{
+			"type":   schemaType(kind),
+			"x-env":  env,
+			"x-flag": "--" + flagName(env),
+		}
+
+		doc := docs[env]
+		if doc.description != "" {
+			property["description"] = doc.description
+			if match := schemaDefault.FindStringSubmatch(doc.description); match != nil {
+				if value, ok := schemaValue(kind, firstNonEmpty(match[1], match[2])); ok {
+					property["default"] = value
+				}
+			}
+		}
+		if value, ok := schemaValue(kind, doc.example); ok && doc.example != "" && !strings.Contains(doc.example, "from_secret") {
+			property["examples"] = []interface{}{value}
+		}
+
+		if isSecretSetting(env) {
+			property = map[string]interface{}{
+				"description": property["description"],
+				"x-env":       env,
+				"x-flag":      property["x-flag"],
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type":                 "object",
+						"properties":           map[string]interface{}{"from_secret": map[string]string{"type": "string"}},
+						"required":             []string{"from_secret"},
+						"additionalProperties": false,
+					},
+				},
+			}
+		}
+		properties[strings.ToLower(strings.TrimPrefix(env, "PLUGIN_"))] = property
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "drone-cucumber settings",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, "", "  ")
+}
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchema(t *testing.T) {
+	readme := []byte("## Plugin Settings\n" +
+		"- `PLUGIN_JSON_REPORT_DIRECTORY`\n" +
+		"Description: Directory containing the Cucumber JSON reports. Defaults to 'target/cucumber'.\n" +
+		"Example: target/cucumber\n" +
+		"- `PLUGIN_FAILED_STEPS_NUMBER`\n" +
+		"Description: Number of failed steps failing the build. Defaults to 0, not checked.\n" +
+		"Example: 3\n" +
+		"- `PLUGIN_HISTORY_MAX_BUILDS`\n" +
+		"Description: Number of builds kept. Defaults to the build number.\n" +
+		"Example: many\n" +
+		"- `PLUGIN_SLACK_WEBHOOK`\n" +
+		"Description: Slack incoming webhook URL.\n" +
+		"Example: from_secret: slack_webhook\n")
+
+	content, err := Schema(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		property string
+		want     map[string]interface{}
+	}{
+		{
+			name:     "string with quoted default",
+			property: "json_report_directory",
+			want: map[string]interface{}{
+				"type":        "string",
+				"description": "Directory containing the Cucumber JSON reports. Defaults to 'target/cucumber'.",
+				"default":     "target/cucumber",
+				"examples":    []interface{}{"target/cucumber"},
+				"x-env":       "PLUGIN_JSON_REPORT_DIRECTORY",
+				"x-flag":      "--json-report-directory",
+			},
+		},
+		{
+			name:     "integer default and example",
+			property: "failed_steps_number",
+			want: map[string]interface{}{
+				"type":        "integer",
+				"description": "Number of failed steps failing the build. Defaults to 0, not checked.",
+				"default":     float64(0),
+				"examples":    []interface{}{float64(3)},
+				"x-env":       "PLUGIN_FAILED_STEPS_NUMBER",
+				"x-flag":      "--failed-steps-number",
+			},
+		},
+		{
+			name:     "values not of the type are left out",
+			property: "history_max_builds",
+			want: map[string]interface{}{
+				"type":        "integer",
+				"description": "Number of builds kept. Defaults to the build number.",
+				"x-env":       "PLUGIN_HISTORY_MAX_BUILDS",
+				"x-flag":      "--history-max-builds",
+			},
+		},
+		{
+			name:     "secret accepts from_secret",
+			property: "slack_webhook",
+			want: map[string]interface{}{
+				"description": "Slack incoming webhook URL.",
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type":                 "object",
+						"properties":           map[string]interface{}{"from_secret": map[string]interface{}{"type": "string"}},
+						"required":             []interface{}{"from_secret"},
+						"additionalProperties": false,
+					},
+				},
+				"x-env":  "PLUGIN_SLACK_WEBHOOK",
+				"x-flag": "--slack-webhook",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, schema.Properties[tt.property]); diff != "" {
+				t.Errorf("Schema() property %s mismatch (-want +got):\n%s", tt.property, diff)
+			}
+		})
+	}
+}
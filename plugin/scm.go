@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// Constants for SCM Provider
+const (
+	SCMProviderAuto      = "AUTO"
+	SCMProviderGitHub    = "GITHUB"
+	SCMProviderGitLab    = "GITLAB"
+	SCMProviderBitbucket = "BITBUCKET"
+	SCMProviderNone      = "NONE"
+)
+
+// Links to a line of a file at a commit, by SCM provider. The placeholders are the
+// repository link, the commit, the file path and the line.
+var scmLinkFormats = map[string]string{
+	SCMProviderGitHub:    "%s/blob/%s/%s#L%d",
+	SCMProviderGitLab:    "%s/-/blob/%s/%s#L%d",
+	SCMProviderBitbucket: "%s/src/%s/%s#lines-%d",
+}
+
+// validateSCMArgs checks the SCM provider setting.
+func validateSCMArgs(args Args) error {
+	switch strings.ToUpper(args.SCMProvider) {
+	case "", SCMProviderAuto, SCMProviderGitHub, SCMProviderGitLab, SCMProviderBitbucket, SCMProviderNone:
+		return nil
+	}
+	return fmt.Errorf("invalid SCMProvider value. It must be '%s', '%s', '%s', '%s' or '%s'", SCMProviderAuto, SCMProviderGitHub, SCMProviderGitLab, SCMProviderBitbucket, SCMProviderNone)
+}
+
+// scmProvider returns the SCM provider of the repository, detected from the host of the
+// repository link when set to AUTO.
+func scmProvider(args Args, repoLink string) string {
+	provider := strings.ToUpper(args.SCMProvider)
+	if provider != "" && provider != SCMProviderAuto {
+		return provider
+	}
+
+	parsed, err := url.Parse(repoLink)
+	if err != nil {
+		return SCMProviderNone
+	}
+	host := strings.ToLower(parsed.Hostname())
+	switch {
+	case strings.Contains(host, "github"):
+		return SCMProviderGitHub
+	case strings.Contains(host, "gitlab"):
+		return SCMProviderGitLab
+	case strings.Contains(host, "bitbucket"):
+		return SCMProviderBitbucket
+	}
+	return SCMProviderNone
+}
+
+// currentRepoLink returns the web link of the repository from the Drone environment.
+func currentRepoLink() string {
+	return strings.TrimSuffix(strings.TrimRight(os.Getenv("DRONE_REPO_LINK"), "/"), ".git")
+}
+
+// currentCommit returns the commit SHA from the Drone environment.
+func currentCommit() string {
+	if commit := os.Getenv("DRONE_COMMIT_SHA"); commit != "" {
+		return commit
+	}
+	return os.Getenv("DRONE_COMMIT")
+}
+
+// repositoryPath converts the URI of a feature to a path relative to the repository
+// root, or returns an empty string when the feature is outside the workspace.
+func repositoryPath(uri, prefix string) string {
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "file:"), "classpath:")
+	if strings.HasPrefix(uri, "/") {
+		workspace := strings.TrimRight(os.Getenv("DRONE_WORKSPACE"), "/")
+		if workspace == "" || !strings.HasPrefix(uri, workspace+"/") {
+			return ""
+		}
+		uri = strings.TrimPrefix(uri, workspace+"/")
+	}
+	if prefix != "" {
+		uri = path.Join(prefix, uri)
+	}
+	return path.Clean(strings.TrimPrefix(uri, "./"))
+}
+
+// sourceLink returns the link to the line of the failed step in the repository.
+func sourceLink(step FailedStepDetails, args Args, repoLink, commit, provider string) string {
+	format, ok := scmLinkFormats[provider]
+	if !ok || repoLink == "" || commit == "" || step.URI == "" {
+		return ""
+	}
+	file := repositoryPath(step.URI, args.SCMPathPrefix)
+	if file == "" {
+		return ""
+	}
+
+	line := step.StepLine
+	if line == 0 {
+		line = step.ScenarioLine
+	}
+	link := fmt.Sprintf(format, repoLink, commit, file, line)
+	if line == 0 {
+		link = link[:strings.LastIndex(link, "#")]
+	}
+	return link
+}
+
+// linkFailures sets the link to the feature file of the failed steps, using the
+// repository and commit of the Drone environment.
+func linkFailures(results *Results, args Args) {
+	repoLink, commit := currentRepoLink(), currentCommit()
+	provider := scmProvider(args, repoLink)
+	for i := range results.FailedSteps {
+		results.FailedSteps[i].SourceURL = sourceLink(results.FailedSteps[i], args, repoLink, commit, provider)
+	}
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"testing"
+)
+
+// TestSourceLink tests the links to the failed steps for each SCM provider
+func TestSourceLink(t *testing.T) {
+	t.Setenv("DRONE_WORKSPACE", "/drone/src")
+	step := FailedStepDetails{URI: "features/login.feature", ScenarioLine: 8, StepLine: 12}
+
+	tests := []struct {
+		name     string
+		step     FailedStepDetails
+		args     Args
+		repoLink string
+		expected string
+	}{
+		{
+			name:     "GitHub",
+			step:     step,
+			repoLink: "https://github.com/acme/shop",
+			expected: "https://github.com/acme/shop/blob/abc123/features/login.feature#L12",
+		},
+		{
+			name:     "GitLab",
+			step:     step,
+			repoLink: "https://gitlab.com/acme/shop",
+			expected: "https://gitlab.com/acme/shop/-/blob/abc123/features/login.feature#L12",
+		},
+		{
+			name:     "Bitbucket",
+			step:     step,
+			repoLink: "https://bitbucket.org/acme/shop",
+			expected: "https://bitbucket.org/acme/shop/src/abc123/features/login.feature#lines-12",
+		},
+		{
+			name:     "Explicit provider and path prefix",
+			step:     step,
+			args:     Args{SCMProvider: "gitlab", SCMPathPrefix: "e2e"},
+			repoLink: "https://git.example.com/acme/shop",
+			expected: "https://git.example.com/acme/shop/-/blob/abc123/e2e/features/login.feature#L12",
+		},
+		{
+			name:     "Absolute path in the workspace",
+			step:     FailedStepDetails{URI: "file:/drone/src/features/login.feature", ScenarioLine: 8},
+			repoLink: "https://github.com/acme/shop",
+			expected: "https://github.com/acme/shop/blob/abc123/features/login.feature#L8",
+		},
+		{
+			name:     "Absolute path outside the workspace",
+			step:     FailedStepDetails{URI: "/tmp/login.feature", StepLine: 12},
+			repoLink: "https://github.com/acme/shop",
+		},
+		{
+			name:     "Unknown provider",
+			step:     step,
+			repoLink: "https://git.example.com/acme/shop",
+		},
+		{
+			name:     "Disabled",
+			step:     step,
+			args:     Args{SCMProvider: SCMProviderNone},
+			repoLink: "https://github.com/acme/shop",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			link := sourceLink(tc.step, tc.args, tc.repoLink, "abc123", scmProvider(tc.args, tc.repoLink))
+			if link != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, link)
+			}
+		})
+	}
+}
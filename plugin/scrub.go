@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// scrubRule is a parsed scrub rule. The matches are replaced by the replacement, or by
+// their entry in matches when it is set.
+type scrubRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+	matches     map[string]string
+}
+
+// textFields are the fields of the notification payloads holding human-readable text,
+// such as the message texts, the summaries and the failure details. The other fields,
+// such as the credentials, keys, identifiers and link targets, are sent as is.
+var textFields = map[string]bool{
+	"text":        true,
+	"summary":     true,
+	"message":     true,
+	"description": true,
+	"subtitle":    true,
+	"topLabel":    true,
+	"bottomLabel": true,
+	"value":       true,
+}
+
+// scrubber replaces the content matching the scrub rules in everything sent to external
+// services, in the order of the rules.
+type scrubber []scrubRule
+
+// parseScrubRules parses the JSON list of scrub rules.
+func parseScrubRules(value string) (scrubber, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var rules []ScrubRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("invalid scrub rules: %v", err)
+	}
+
+	scrubber := make(scrubber, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Pattern == "" {
+			return nil, errors.New("invalid scrub rules: every rule requires a pattern")
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrub rule %q: %v", rule.Pattern, err)
+		}
+		replacement := redacted
+		if rule.Replacement != nil {
+			replacement = *rule.Replacement
+		}
+		scrubber = append(scrubber, scrubRule{pattern: pattern, replacement: replacement})
+	}
+	return scrubber, nil
+}
+
+// newScrubber returns the scrubber of the validated scrub rules, followed by the rules
+// redacting the names when configured.
+func newScrubber(args Args) scrubber {
+	scrubber, _ := parseScrubRules(args.ScrubRules)
+	return append(scrubber, nameRules...)
+}
+
+// text scrubs a text. The replacements can refer to the groups of the patterns as $1.
+func (s scrubber) text(text string) string {
+	for _, rule := range s {
+		if rule.matches != nil {
+			text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string { return rule.matches[match] })
+			continue
+		}
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	return text
+}
+
+// json scrubs the string values of a JSON document, leaving its structure intact.
+func (s scrubber) json(content []byte) ([]byte, error) {
+	return s.rewrite(content, s.value)
+}
+
+// payload scrubs the string values of the text fields of a JSON notification payload,
+// leaving the other values and its structure intact.
+func (s scrubber) payload(content []byte) ([]byte, error) {
+	return s.rewrite(content, s.fields)
+}
+
+// rewrite decodes a JSON document, scrubs its decoded value with scrub and encodes it
+// back.
+func (s scrubber) rewrite(content []byte, scrub func(interface{}) interface{}) ([]byte, error) {
+	if len(s) == 0 {
+		return content, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var scrubbed bytes.Buffer
+	encoder := json.NewEncoder(&scrubbed)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(scrub(document)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(scrubbed.Bytes(), []byte("\n")), nil
+}
+
+// ndjson scrubs the string values of every JSON document of newline-delimited JSON.
+func (s scrubber) ndjson(content []byte) ([]byte, error) {
+	if len(s) == 0 {
+		return content, nil
+	}
+
+	var scrubbed bytes.Buffer
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		document, err := s.json(line)
+		if err != nil {
+			return nil, err
+		}
+		scrubbed.Write(document)
+		scrubbed.WriteByte('\n')
+	}
+	return scrubbed.Bytes(), nil
+}
+
+// value scrubs the strings of a decoded JSON value.
+func (s scrubber) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return s.text(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = s.value(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = s.value(v[key])
+		}
+	}
+	return value
+}
+
+// fields scrubs the strings of the text fields of a decoded JSON value.
+func (s scrubber) fields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = s.fields(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			if text, ok := v[key].(string); ok {
+				if textFields[key] {
+					v[key] = s.text(text)
+				}
+				continue
+			}
+			v[key] = s.fields(v[key])
+		}
+	}
+	return value
+}
+
+// isTextContent reports whether the content type is text that can be scrubbed, such as
+// an HTML or JSON report.
+func isTextContent(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml")
+}
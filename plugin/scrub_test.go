@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestParseScrubRules tests the validation of the scrub rules
+func TestParseScrubRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		rules   int
+		wantErr bool
+	}{
+		{"Empty", "", 0, false},
+		{"Valid", `[{"pattern": "[a-z0-9-]+\\.corp\\.internal"}, {"pattern": "CUST-(\\d+)", "replacement": "CUST-***"}]`, 2, false},
+		{"Missing pattern", `[{"replacement": "x"}]`, 0, true},
+		{"Invalid pattern", `[{"pattern": "("}]`, 0, true},
+		{"Invalid JSON", `{`, 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := parseScrubRules(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+			}
+			if len(rules) != tc.rules {
+				t.Errorf("Expected %d rules, got %d", tc.rules, len(rules))
+			}
+		})
+	}
+}
+
+// TestScrubber tests scrubbing texts, JSON documents and newline-delimited JSON
+func TestScrubber(t *testing.T) {
+	scrub, err := parseScrubRules(`[{"pattern": "[a-z0-9-]+\\.corp\\.internal"}, {"pattern": "customer (\\d+)", "replacement": "customer <id>"}, {"pattern": "\"", "replacement": "'"}]`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := scrub.text(`GET https://db-01.corp.internal/customer 4711 failed`); got != "GET https://[REDACTED]/customer <id> failed" {
+		t.Errorf("Unexpected text %q", got)
+	}
+
+	document, err := scrub.json([]byte(`{"text":"customer 4711 on api.corp.internal said \"no\"","count":12345678901234567890,"blocks":[{"ok":true}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"blocks":[{"ok":true}],"count":12345678901234567890,"text":"customer <id> on [REDACTED] said 'no'"}`
+	if string(document) != expected {
+		t.Errorf("Expected %s, got %s", expected, document)
+	}
+
+	messages, err := scrub.ndjson([]byte("{\"uri\":\"features/a.feature\"}\n{\"message\":\"host web.corp.internal\"}\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(messages) != "{\"uri\":\"features/a.feature\"}\n{\"message\":\"host [REDACTED]\"}\n" {
+		t.Errorf("Unexpected messages %q", messages)
+	}
+}
+
+// TestPostJSONScrubsPayload tests that the webhook payloads are scrubbed
+func TestPostJSONScrubsPayload(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+	}))
+	defer server.Close()
+
+	args := Args{ScrubRules: `[{"pattern": "acme-\\d+", "replacement": "customer"}]`}
+	if err := postJSON(context.Background(), args, server.URL, map[string]string{"text": "Checkout failed for acme-42"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if body != `{"text":"Checkout failed for customer"}` {
+		t.Errorf("Unexpected payload %s", body)
+	}
+}
+
+// TestPostJSONKeepsLinksAndKeys tests that a rule matching the host of the webhook leaves
+// the routing key and the links of the payload intact
+func TestPostJSONKeepsLinksAndKeys(t *testing.T) {
+	var event struct {
+		RoutingKey string `json:"routing_key"`
+		Payload    struct {
+			Summary string `json:"summary"`
+		} `json:"payload"`
+		Links []struct {
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&event)
+	}))
+	defer server.Close()
+	t.Setenv("DRONE_BUILD_LINK", server.URL+"/build/42")
+
+	args := Args{AlertAPIURL: server.URL, AlertRoutingKey: "https://127.0.0.1/key", ScrubRules: `[{"pattern": "127\\.0\\.0\\.1"}]`}
+	if err := sendPagerDutyAlert(context.Background(), Results{}, args, "main", "Checkout failed calling 127.0.0.1:8443"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Payload.Summary != "Checkout failed calling [REDACTED]:8443" {
+		t.Errorf("Expected a scrubbed summary, got %q", event.Payload.Summary)
+	}
+	if event.RoutingKey != args.AlertRoutingKey || len(event.Links) != 1 || event.Links[0].Href != server.URL+"/build/42" {
+		t.Errorf("Expected the routing key and the build link as is, got %+v", event)
+	}
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// serenityOutcome represents a Serenity BDD test outcome, written to its own JSON file.
+// Unlike the Cucumber JSON format, the durations are expressed in milliseconds.
+type serenityOutcome struct {
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	UserStory struct {
+		ID        string `json:"id"`
+		StoryName string `json:"storyName"`
+		Path      string `json:"path"`
+	} `json:"userStory"`
+	Tags []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"tags"`
+	Result           string          `json:"result"`
+	Duration         float64         `json:"duration"`
+	TestSteps        []serenityStep  `json:"testSteps"`
+	TestFailureCause *serenityError  `json:"testFailureCause"`
+	DataTable        json.RawMessage `json:"dataTable"`
+}
+
+// serenityStep represents a step of a Serenity BDD test outcome. The examples of a
+// data-driven test are steps nesting the steps of the example.
+type serenityStep struct {
+	Description string         `json:"description"`
+	Result      string         `json:"result"`
+	Duration    float64        `json:"duration"`
+	Exception   *serenityError `json:"exception"`
+	Children    []serenityStep `json:"children"`
+}
+
+// serenityError represents the cause of a failed Serenity BDD step or test.
+type serenityError struct {
+	ErrorType string `json:"errorType"`
+	Message   string `json:"message"`
+}
+
+// serenityStatuses maps the Serenity BDD results to the Cucumber statuses.
+var serenityStatuses = map[string]string{
+	"SUCCESS":     "passed",
+	"FAILURE":     "failed",
+	"ERROR":       "failed",
+	"COMPROMISED": "failed",
+	"PENDING":     "pending",
+	"IGNORED":     "skipped",
+	"SKIPPED":     "skipped",
+	"ABORTED":     "skipped",
+	"UNDEFINED":   "undefined",
+}
+
+// isSerenityOutcome reports whether the content is a Serenity BDD test outcome.
+func isSerenityOutcome(content []byte) bool {
+	return hasJSONKey(content, "testSteps") || hasJSONKey(content, "userStory")
+}
+
+// decodeSerenityReport decodes a Serenity BDD test outcome into a Cucumber feature
+// holding the scenario, or a scenario per example of a data-driven test.
+func decodeSerenityReport(content []byte) ([]Feature, error) {
+	if firstByte(content) == '[' {
+		return decodeCucumberReport(content)
+	}
+
+	var outcome serenityOutcome
+	if err := json.Unmarshal(content, &outcome); err != nil {
+		return nil, err
+	}
+	return []Feature{serenityToFeature(outcome)}, nil
+}
+
+// serenityToFeature maps a Serenity BDD test outcome to a Cucumber feature.
+func serenityToFeature(outcome serenityOutcome) Feature {
+	feature := Feature{
+		ID:      outcome.UserStory.ID,
+		URI:     outcome.UserStory.Path,
+		Keyword: "Feature",
+		Name:    outcome.UserStory.StoryName,
+	}
+	if feature.ID == "" {
+		feature.ID = slug(feature.Name)
+	}
+
+	name := outcome.Title
+	if name == "" {
+		name = outcome.Name
+	}
+	var tags []Tag
+	for _, tag := range outcome.Tags {
+		if tag.Type != "tag" {
+			continue
+		}
+		if !strings.HasPrefix(tag.Name, "@") {
+			tag.Name = "@" + tag.Name
+		}
+		tags = append(tags, Tag{Name: tag.Name})
+	}
+
+	message := ""
+	if outcome.TestFailureCause != nil {
+		message = outcome.TestFailureCause.Message
+	}
+
+	// Data-driven tests nest the steps of each example in a step of the test
+	examples := [][]serenityStep{outcome.TestSteps}
+	if len(outcome.DataTable) > 0 && string(outcome.DataTable) != "null" {
+		examples = examples[:0]
+		for _, example := range outcome.TestSteps {
+			examples = append(examples, example.Children)
+		}
+	}
+
+	for i, steps := range examples {
+		element := Element{Keyword: "Scenario", Name: name, Type: "scenario", Tags: tags}
+		if len(examples) > 1 {
+			element.ID = fmt.Sprintf("%s;%s;%d", feature.ID, slug(name), i+1)
+		}
+		for _, serenity := range steps {
+			element.Steps = append(element.Steps, serenityToStep(serenity, message))
+		}
+		// Tests without steps, such as plain JUnit tests, count as a single step
+		if len(element.Steps) == 0 {
+			element.Steps = append(element.Steps, serenityToStep(serenityStep{
+				Description: name,
+				Result:      outcome.Result,
+				Duration:    outcome.Duration,
+			}, message))
+		}
+		feature.Elements = append(feature.Elements, element)
+	}
+	return feature
+}
+
+// serenityToStep maps a Serenity BDD step to a Cucumber step, with the error message of
+// the test when the step has none.
+func serenityToStep(serenity serenityStep, message string) Step {
+	keyword, name := "", serenity.Description
+	if first, rest, ok := strings.Cut(serenity.Description, " "); ok && isGherkinKeyword(first) {
+		keyword, name = first+" ", rest
+	}
+
+	step := Step{
+		Keyword: keyword,
+		Name:    name,
+		Result: Result{
+			Status:   serenityStatuses[strings.ToUpper(serenity.Result)],
+			Duration: int64(serenity.Duration * 1e6),
+		},
+	}
+	if step.Result.Status == "failed" {
+		step.Result.ErrorMessage = message
+		if serenity.Exception != nil && serenity.Exception.Message != "" {
+			step.Result.ErrorMessage = serenity.Exception.Message
+		}
+	}
+	return step
+}
+
+// isGherkinKeyword reports whether the word is an English Gherkin step keyword.
+func isGherkinKeyword(word string) bool {
+	switch word {
+	case "Given", "When", "Then", "And", "But", "*":
+		return true
+	}
+	return false
+}
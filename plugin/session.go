@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+)
+
+// Patterns of the BrowserStack and Sauce Labs session URLs and IDs in the step output.
+var (
+	sessionURLPattern = regexp.MustCompile(`https://(?:app-)?automate\.browserstack\.com/[^\s"'<>]*?sessions/[0-9a-f]{40}|https://(?:app\.(?:[a-z0-9-]+\.)?|www\.)?saucelabs\.com/(?:beta/)?tests/[0-9a-f]{32}`)
+	sauceSessionID    = regexp.MustCompile(`SauceOnDemandSessionID=([0-9a-f]{32})`)
+	browserStackID    = regexp.MustCompile(`(?i)browserstack[^\n]*?session[ _-]?id[\s:=]+([0-9a-f]{40})`)
+)
+
+// scenarioSessionURL returns the first browser session URL found in the output and the
+// text embeddings of the steps and hooks of the scenario.
+func scenarioSessionURL(element Element) string {
+	for _, steps := range [][]Step{element.Before, element.Steps, element.After} {
+		for _, step := range steps {
+			for _, output := range step.Output {
+				if url := sessionURL(output); url != "" {
+					return url
+				}
+			}
+			for _, embedding := range step.Embeddings {
+				if url := sessionURL(embeddingText(embedding)); url != "" {
+					return url
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// sessionURL returns the browser session URL in the text, built from the session ID
+// when only the ID is logged.
+func sessionURL(text string) string {
+	if text == "" {
+		return ""
+	}
+	if match := sessionURLPattern.FindString(text); match != "" {
+		return match
+	}
+	if match := sauceSessionID.FindStringSubmatch(text); match != nil {
+		return "https://app.saucelabs.com/tests/" + match[1]
+	}
+	if match := browserStackID.FindStringSubmatch(text); match != nil {
+		return "https://automate.browserstack.com/dashboard/v2/sessions/" + match[1]
+	}
+	return ""
+}
+
+// embeddingText returns the decoded content of a text embedding.
+func embeddingText(embedding Embedding) string {
+	if !strings.HasPrefix(embedding.MimeType, "text/") && embedding.MimeType != "application/json" {
+		return ""
+	}
+	data, err := base64.StdEncoding.DecodeString(embedding.Data)
+	if err != nil {
+		// Some formatters embed text without encoding it
+		return embedding.Data
+	}
+	return string(data)
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+// TestSessionURL tests detecting browser session links in the step output
+func TestSessionURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "BrowserStack URL",
+			text:     "View session at https://automate.browserstack.com/dashboard/v2/builds/abc/sessions/0123456789abcdef0123456789abcdef01234567.",
+			expected: "https://automate.browserstack.com/dashboard/v2/builds/abc/sessions/0123456789abcdef0123456789abcdef01234567",
+		},
+		{
+			name:     "Sauce Labs URL",
+			text:     "Job: https://app.eu-central-1.saucelabs.com/tests/0123456789abcdef0123456789abcdef",
+			expected: "https://app.eu-central-1.saucelabs.com/tests/0123456789abcdef0123456789abcdef",
+		},
+		{
+			name:     "Sauce Labs Session ID",
+			text:     "SauceOnDemandSessionID=0123456789abcdef0123456789abcdef job-name=checkout",
+			expected: "https://app.saucelabs.com/tests/0123456789abcdef0123456789abcdef",
+		},
+		{
+			name:     "BrowserStack Session ID",
+			text:     "BrowserStack session id: 0123456789abcdef0123456789abcdef01234567",
+			expected: "https://automate.browserstack.com/dashboard/v2/sessions/0123456789abcdef0123456789abcdef01234567",
+		},
+		{
+			name: "No Session",
+			text: "Visit https://www.browserstack.com/docs for details",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sessionURL(tc.text); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestScenarioSessionURL tests finding the session link in the hooks and embeddings of a scenario
+func TestScenarioSessionURL(t *testing.T) {
+	link := "https://app.saucelabs.com/tests/0123456789abcdef0123456789abcdef"
+	element := Element{
+		Steps: []Step{{Name: "I log in", Output: []string{"Logged in"}}},
+		After: []Step{{
+			Embeddings: []Embedding{
+				{MimeType: "image/png", Data: base64.StdEncoding.EncodeToString([]byte(link))},
+				{MimeType: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte("Sauce job: " + link))},
+			},
+		}},
+	}
+
+	if got := scenarioSessionURL(element); got != link {
+		t.Errorf("Expected %q, got %q", link, got)
+	}
+}
@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// defaultShardImbalanceFactor is the duration ratio to the fastest shard above which a
+// shard is flagged as imbalanced when none is configured.
+const defaultShardImbalanceFactor = 3.0
+
+// validateShardArgs checks the shard settings.
+func validateShardArgs(args Args) error {
+	if args.ShardPattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(args.ShardPattern); err != nil {
+		return fmt.Errorf("invalid ShardPattern value: %v", err)
+	}
+	if args.ShardImbalanceFactor != 0 && args.ShardImbalanceFactor < 1 {
+		return fmt.Errorf("invalid ShardImbalanceFactor value. It must be at least 1")
+	}
+	return nil
+}
+
+// shardLabel extracts the shard label from the path of a report file, using the first
+// capture group of the pattern or the whole match.
+func shardLabel(pattern *regexp.Regexp, file string) string {
+	match := pattern.FindStringSubmatch(file)
+	switch {
+	case len(match) > 1:
+		return match[1]
+	case len(match) == 1:
+		return match[0]
+	}
+	return ""
+}
+
+// addShardResults adds the results of a report file to the statistics of its shard.
+func addShardResults(shards map[string]*ShardResult, label string, results Results) {
+	shard, ok := shards[label]
+	if !ok {
+		shard = &ShardResult{Label: label}
+		shards[label] = shard
+	}
+	shard.Reports++
+	shard.Scenarios += results.ScenarioCount
+	shard.PassedScenarios += results.TotalPassedScenarios
+	shard.FailedScenarios += results.TotalFailedScenarios
+	shard.DurationMS += results.DurationMS
+}
+
+// shardResults sorts the shards by label and flags the shards taking at least the
+// imbalance factor times longer than the fastest shard.
+func shardResults(shards map[string]*ShardResult, factor float64) []ShardResult {
+	if factor <= 0 {
+		factor = defaultShardImbalanceFactor
+	}
+
+	var results []ShardResult
+	fastest := 0.0
+	for _, shard := range shards {
+		results = append(results, *shard)
+		if shard.DurationMS > 0 && (fastest == 0 || shard.DurationMS < fastest) {
+			fastest = shard.DurationMS
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Label < results[j].Label })
+
+	for i := range results {
+		if fastest > 0 {
+			results[i].DurationRatio = results[i].DurationMS / fastest
+			results[i].Imbalanced = len(results) > 1 && results[i].DurationRatio >= factor
+		}
+	}
+	return results
+}
+
+// logShardResults logs the statistics of the shards and warns about imbalanced shards.
+func logShardResults(shards []ShardResult) {
+	if len(shards) == 0 {
+		return
+	}
+
+	logger.Infof("Shards:\n")
+	for _, shard := range shards {
+		logger.Infof("   %s: %d scenarios (%d passed, %d failed) in %s ms, %sx the fastest shard\n", shard.Label,
+			shard.Scenarios, shard.PassedScenarios, shard.FailedScenarios, formatNumber(shard.DurationMS), formatNumber(shard.DurationRatio))
+		if shard.Imbalanced {
+			logger.Warnf("Shard %s takes %sx longer than the fastest shard. Consider repartitioning the scenarios", shard.Label, formatNumber(shard.DurationRatio))
+		}
+	}
+}
+
+// writeShardStats writes the statistics of the shards to the output variables.
+func writeShardStats(shards []ShardResult, log Logger) {
+	if len(shards) == 0 {
+		return
+	}
+
+	imbalanced := false
+	stats := map[string]string{"SHARD_COUNT": strconv.Itoa(len(shards))}
+	for _, shard := range shards {
+		prefix := "SHARD_" + envKey(shard.Label) + "_"
+		stats[prefix+"PASSED_SCENARIOS"] = strconv.Itoa(shard.PassedScenarios)
+		stats[prefix+"FAILED_SCENARIOS"] = strconv.Itoa(shard.FailedScenarios)
+		stats[prefix+"DURATION_MS"] = formatNumber(shard.DurationMS)
+		imbalanced = imbalanced || shard.Imbalanced
+	}
+	stats["SHARD_IMBALANCE"] = strconv.FormatBool(imbalanced)
+
+	for key, value := range stats {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestShardResults tests the per-shard statistics and the detection of imbalanced shards
+func TestShardResults(t *testing.T) {
+	pattern := regexp.MustCompile(`shard-(\w+)`)
+	files := map[string]Results{
+		"reports/shard-1/a.json": {ScenarioCount: 4, TotalPassedScenarios: 4, DurationMS: 1000},
+		"reports/shard-1/b.json": {ScenarioCount: 2, TotalPassedScenarios: 1, TotalFailedScenarios: 1, DurationMS: 500},
+		"reports/shard-2/a.json": {ScenarioCount: 5, TotalPassedScenarios: 5, DurationMS: 4500},
+		"reports/other.json":     {ScenarioCount: 1, TotalPassedScenarios: 1, DurationMS: 100},
+	}
+
+	shards := make(map[string]*ShardResult)
+	for file, results := range files {
+		if label := shardLabel(pattern, file); label != "" {
+			addShardResults(shards, label, results)
+		}
+	}
+
+	expected := []ShardResult{
+		{Label: "1", Reports: 2, Scenarios: 6, PassedScenarios: 5, FailedScenarios: 1, DurationMS: 1500, DurationRatio: 1},
+		{Label: "2", Reports: 1, Scenarios: 5, PassedScenarios: 5, DurationMS: 4500, DurationRatio: 3, Imbalanced: true},
+	}
+	if diff := cmp.Diff(expected, shardResults(shards, 0)); diff != "" {
+		t.Errorf("Shards mismatch (-want +got):\n%s", diff)
+	}
+	if results := shardResults(shards, 3.5); results[1].Imbalanced {
+		t.Errorf("Expected no imbalance below the configured factor, got %+v", results[1])
+	}
+}
+
+// TestWriteShardStats tests the output variables of the shards
+func TestWriteShardStats(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output.env")
+	t.Setenv("DRONE_OUTPUT", output)
+
+	writeShardStats([]ShardResult{{Label: "linux-chrome", PassedScenarios: 5, FailedScenarios: 1, DurationMS: 1500, Imbalanced: true}}, logger)
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"SHARD_COUNT=1\n", "SHARD_IMBALANCE=true\n", "SHARD_LINUX_CHROME_PASSED_SCENARIOS=5\n", "SHARD_LINUX_CHROME_FAILED_SCENARIOS=1\n", "SHARD_LINUX_CHROME_DURATION_MS=1500.00\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseSLAs parses the JSON list of per-tag SLA definitions.
+func parseSLAs(value string) ([]SLA, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var slas []SLA
+	if err := json.Unmarshal([]byte(value), &slas); err != nil {
+		return nil, fmt.Errorf("invalid SLA definitions: %v", err)
+	}
+
+	for _, sla := range slas {
+		if sla.Tag == "" {
+			return nil, errors.New("invalid SLA definitions: every SLA requires a tag")
+		}
+		if sla.MaxDurationMS < 0 || sla.MinPassRate < 0 || sla.MinPassRate > 100 || sla.Window < 0 {
+			return nil, fmt.Errorf("invalid SLA definition for tag %s: values must be non-negative and pass rates at most 100", sla.Tag)
+		}
+	}
+	return slas, nil
+}
+
+// scenarioTags returns the distinct tags of a feature and one of its elements.
+func scenarioTags(feature Feature, element Element) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]Tag{}, feature.Tags...), element.Tags...) {
+		if !seen[tag.Name] {
+			seen[tag.Name] = true
+			tags = append(tags, tag.Name)
+		}
+	}
+	return tags
+}
+
+// hasTag reports whether the tags contain the given tag.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// historyTags summarizes the scenarios of each tag for the history file.
+func historyTags(scenarios []ScenarioResult) map[string]HistoryTag {
+	tags := make(map[string]HistoryTag)
+	for _, scenario := range scenarios {
+		for _, tag := range scenario.Tags {
+			summary := tags[tag]
+			summary.Scenarios++
+			if scenario.Status != "failed" {
+				summary.Passed++
+			}
+			summary.DurationMS += scenario.DurationMS
+			tags[tag] = summary
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// evaluateSLAs evaluates the SLAs against the current build and, for the pass rate,
+// the rolling window of previous builds.
+func evaluateSLAs(slas []SLA, results Results, previous []HistoryEntry, args Args) []SLAResult {
+	current := historyTags(results.Scenarios)
+
+	var evaluated []SLAResult
+	for _, sla := range slas {
+		window := sla.Window
+		if window <= 0 {
+			window = args.TrendBuilds
+		}
+		if window <= 0 {
+			window = defaultTrendBuilds
+		}
+
+		result := SLAResult{SLA: sla, Builds: 1, Compliant: true}
+		result.DurationMS = current[sla.Tag].DurationMS
+
+		scenarios, passed := current[sla.Tag].Scenarios, current[sla.Tag].Passed
+		for _, entry := range lastEntries(previous, window-1) {
+			if summary, ok := entry.Tags[sla.Tag]; ok {
+				scenarios += summary.Scenarios
+				passed += summary.Passed
+				result.Builds++
+			}
+		}
+		if scenarios > 0 {
+			result.PassRate = float64(passed) / float64(scenarios) * 100
+		}
+
+		if sla.MaxDurationMS > 0 && result.DurationMS > sla.MaxDurationMS {
+			result.Compliant = false
+		}
+		if sla.MinPassRate > 0 && scenarios > 0 && result.PassRate < sla.MinPassRate {
+			result.Compliant = false
+		}
+		evaluated = append(evaluated, result)
+	}
+	return evaluated
+}
+
+// logSLAResults logs the compliance of each SLA.
+func logSLAResults(results []SLAResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	logger.Infof("SLA Compliance:\n")
+	logger.Infof("-----------------------------------------------\n")
+	for _, result := range results {
+		symbol := "✅"
+		if !result.Compliant {
+			symbol = "❌"
+		}
+		logger.Infof("%s %s\n", result.SLA.Tag, symbol)
+		if result.SLA.MaxDurationMS > 0 {
+			logger.Infof("   Duration: %s ms (Max: %s ms)\n", formatNumber(result.DurationMS), formatNumber(result.SLA.MaxDurationMS))
+		}
+		if result.SLA.MinPassRate > 0 {
+			logger.Infof("   Pass Rate: %s%% over %d builds (Min: %s%%)\n", formatNumber(result.PassRate), result.Builds, formatNumber(result.SLA.MinPassRate))
+		}
+	}
+	logger.Infof("===============================================\n")
+}
+
+// envKeyPattern matches characters that are not allowed in output variable names.
+var envKeyPattern = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// envKey converts a name such as a tag into an output variable name segment.
+func envKey(name string) string {
+	return strings.Trim(envKeyPattern.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+// writeSLAStats writes the SLA compliance to the output file.
+func writeSLAStats(results []SLAResult, log Logger) {
+	violations := 0
+	statsMap := map[string]string{}
+	for _, result := range results {
+		if !result.Compliant {
+			violations++
+		}
+		prefix := "SLA_" + envKey(result.SLA.Tag)
+		statsMap[prefix+"_COMPLIANT"] = strconv.FormatBool(result.Compliant)
+		statsMap[prefix+"_PASS_RATE"] = formatNumber(result.PassRate)
+		statsMap[prefix+"_DURATION_MS"] = formatNumber(result.DurationMS)
+	}
+	statsMap["SLA_VIOLATIONS"] = strconv.Itoa(violations)
+	statsMap["SLA_COMPLIANT"] = strconv.FormatBool(violations == 0)
+
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
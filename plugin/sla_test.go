@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestParseSLAs tests parsing of the SLA definitions
+func TestParseSLAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  int
+		expectErr bool
+		errMsg    string
+	}{
+		{
+			name:     "Empty",
+			value:    "",
+			expected: 0,
+		},
+		{
+			name:     "Valid Definitions",
+			value:    `[{"tag":"@smoke","max_duration_ms":300000},{"tag":"@regression","min_pass_rate":95,"window":5}]`,
+			expected: 2,
+		},
+		{
+			name:      "Missing Tag",
+			value:     `[{"min_pass_rate":95}]`,
+			expectErr: true,
+			errMsg:    "every SLA requires a tag",
+		},
+		{
+			name:      "Invalid Pass Rate",
+			value:     `[{"tag":"@smoke","min_pass_rate":120}]`,
+			expectErr: true,
+			errMsg:    "invalid SLA definition for tag @smoke",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slas, err := parseSLAs(tc.value)
+			if tc.expectErr {
+				if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+					t.Errorf("Expected error '%s', but got %v", tc.errMsg, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if len(slas) != tc.expected {
+				t.Errorf("Expected %d SLAs, got %d", tc.expected, len(slas))
+			}
+		})
+	}
+}
+
+// TestEvaluateSLAs tests SLA compliance over the current and previous builds
+func TestEvaluateSLAs(t *testing.T) {
+	results := Results{
+		Scenarios: []ScenarioResult{
+			{Tags: []string{"@smoke"}, Status: "passed", DurationMS: 400},
+			{Tags: []string{"@smoke", "@checkout"}, Status: "failed", DurationMS: 200},
+			{Tags: []string{"@checkout"}, Status: "passed", DurationMS: 100},
+		},
+	}
+	previous := []HistoryEntry{
+		{Tags: map[string]HistoryTag{"@smoke": {Scenarios: 2, Passed: 2}}},
+		{Tags: map[string]HistoryTag{"@smoke": {Scenarios: 2, Passed: 2}}},
+	}
+	slas := []SLA{
+		{Tag: "@smoke", MaxDurationMS: 500},
+		{Tag: "@smoke", MinPassRate: 80},
+		{Tag: "@checkout", MinPassRate: 60, Window: 1},
+	}
+
+	evaluated := evaluateSLAs(slas, results, previous, Args{})
+
+	if evaluated[0].Compliant || evaluated[0].DurationMS != 600 {
+		t.Errorf("Expected duration SLA violation, got %+v", evaluated[0])
+	}
+	if !evaluated[1].Compliant || evaluated[1].Builds != 3 || fmt.Sprintf("%.2f", evaluated[1].PassRate) != "83.33" {
+		t.Errorf("Expected compliant pass rate SLA over 3 builds, got %+v", evaluated[1])
+	}
+	if evaluated[2].Compliant || evaluated[2].PassRate != 50 {
+		t.Errorf("Expected pass rate SLA violation, got %+v", evaluated[2])
+	}
+}
@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Constants for Notify On
+const (
+	NotifyOnAlways  = "ALWAYS"
+	NotifyOnFailure = "FAILURE"
+)
+
+// Slack attachment colors and limits
+const (
+	slackColorPassed        = "#2eb886"
+	slackColorFailed        = "#e01e5a"
+	defaultSlackMaxFailures = 5
+	slackTextLimit          = 2900
+)
+
+// shouldNotify reports whether a notification is sent for the build outcome.
+func shouldNotify(notifyOn string, gateErr error) bool {
+	return gateErr != nil || !strings.EqualFold(notifyOn, NotifyOnFailure)
+}
+
+// slackMessage builds the Block Kit message for the results and the quality gates of the
+// build.
+func slackMessage(results Results, gates []GateResult, args Args, reportURL string, gateErr error) map[string]interface{} {
+	status, color := "passed ✅", slackColorPassed
+	if gateErr != nil {
+		status, color = "failed ❌", slackColorFailed
+	}
+
+	title := "Cucumber results " + status
+	if repo := currentRepo(); repo != "" {
+		title = fmt.Sprintf("%s: Cucumber results %s", repo, status)
+	}
+
+	var details []string
+	if branch := currentBranch(args); branch != "" {
+		details = append(details, "*Branch:* "+branch)
+	}
+	if build := currentBuildNumber(); build != "" {
+		details = append(details, "*Build:* #"+build)
+	}
+	for _, annotation := range results.Annotations {
+		details = append(details, "*"+annotation.Name+":* "+annotation.Value)
+	}
+
+	blocks := []interface{}{
+		map[string]interface{}{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": title},
+		},
+	}
+	if len(details) > 0 {
+		blocks = append(blocks, map[string]interface{}{
+			"type":     "context",
+			"elements": []interface{}{slackMarkdown(strings.Join(details, "  |  "))},
+		})
+	}
+
+	blocks = append(blocks, map[string]interface{}{
+		"type": "section",
+		"fields": []interface{}{
+			slackMarkdown(fmt.Sprintf("*Features*\n%d (%d failed)", results.FeatureCount, results.TotalFailedFeatures)),
+			slackMarkdown(fmt.Sprintf("*Scenarios*\n%d (%d failed)", results.ScenarioCount, results.TotalFailedScenarios)),
+			slackMarkdown(fmt.Sprintf("*Steps*\n%d (%d failed)", results.StepCount, results.TotalFailedSteps)),
+			slackMarkdown("*Pass Rate*\n" + formatNumber(percentageOf(results.TotalPassedScenarios, results.ScenarioCount)) + "%"),
+			slackMarkdown(fmt.Sprintf("*Skipped / Pending / Undefined*\n%d / %d / %d", results.SkippedTests, results.PendingTests, results.UndefinedTests)),
+			slackMarkdown("*Duration*\n" + formatNumber(results.DurationMS/1000) + " s"),
+		},
+	})
+
+	if len(gates) > 0 {
+		var table strings.Builder
+		for _, gate := range gates {
+			fmt.Fprintf(&table, "%-28s %10s / %-10s %s\n", gate.Name, gate.formatValue(gate.Observed), gate.formatValue(gate.Threshold), gateSymbol(gate.Passed))
+		}
+		blocks = append(blocks, slackSection("*Quality Gates*\n```"+table.String()+"```"))
+	}
+	if gateErr != nil {
+		blocks = append(blocks, slackSection(truncateRunes("*Gate failure:* "+gateErr.Error(), slackTextLimit, "…")))
+	}
+
+	if failures := slackFailures(results, args.SlackMaxFailures); failures != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "divider"}, slackSection(failures))
+	}
+
+	var buttons []interface{}
+	if link := currentBuildLink(); link != "" {
+		buttons = append(buttons, slackButton("View Build", link))
+	}
+	if reportURL != "" {
+		buttons = append(buttons, slackButton("View Report", reportURL))
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": buttons})
+	}
+
+	message := map[string]interface{}{
+		"text": fmt.Sprintf("%s: %d scenarios, %d failed", title, results.ScenarioCount, results.TotalFailedScenarios),
+		"attachments": []interface{}{
+			map[string]interface{}{"color": color, "blocks": blocks},
+		},
+	}
+	if args.SlackChannel != "" {
+		message["channel"] = args.SlackChannel
+	}
+	return message
+}
+
+// slackFailures lists the top failures, new failures first.
+func slackFailures(results Results, max int) string {
+	steps := results.FailedSteps
+	if len(steps) == 0 && results.OverflowedFailedSteps == 0 {
+		return ""
+	}
+	if max <= 0 {
+		max = defaultSlackMaxFailures
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "*Top Failures*\n")
+	for i, step := range steps {
+		if i == max {
+			break
+		}
+		label := ""
+		if step.New {
+			label = " 🆕"
+		}
+		fmt.Fprintf(&text, "• *%s* › %s%s\n    _%s_: %s\n", step.Feature, step.Scenario, label, step.Step, firstLine(step.ErrorMessage))
+		if location := failureLocation(step); location != "" && step.SourceURL != "" {
+			fmt.Fprintf(&text, "    <%s|%s>\n", step.SourceURL, location)
+		} else if location != "" {
+			fmt.Fprintf(&text, "    `%s`\n", location)
+		}
+		if step.SessionURL != "" {
+			fmt.Fprintf(&text, "    <%s|🎥 Browser session>\n", step.SessionURL)
+		}
+	}
+
+	if more := moreFailures(len(steps)-min(len(steps), max), results); more != "" {
+		fmt.Fprintf(&text, "_%s_\n", more)
+	}
+
+	return truncateRunes(text.String(), slackTextLimit, "…")
+}
+
+// firstLine returns the first line of a multi-line text.
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return strings.TrimSpace(text[:i])
+	}
+	return strings.TrimSpace(text)
+}
+
+// slackMarkdown returns a Block Kit markdown text object.
+func slackMarkdown(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": text}
+}
+
+// slackSection returns a Block Kit section block with markdown text.
+func slackSection(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "section", "text": slackMarkdown(text)}
+}
+
+// slackButton returns a Block Kit link button.
+func slackButton(text, url string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "button",
+		"text": map[string]interface{}{"type": "plain_text", "text": text},
+		"url":  url,
+	}
+}
+
+// notifySlack posts the results to the Slack webhook.
+func notifySlack(ctx context.Context, results Results, args Args, reportURL string, gateErr error) {
+	if !shouldNotify(args.SlackNotifyOn, gateErr) {
+		return
+	}
+	postSlack(ctx, args, slackMessage(results, evaluateThresholds(results, args), args, reportURL, gateErr))
+}
+
+// postSlack posts the message to the Slack webhook.
+func postSlack(ctx context.Context, args Args, message map[string]interface{}) {
+	if err := postJSON(ctx, args, args.SlackWebhook, message, nil); err != nil {
+		logger.Warnf("Failed to send Slack notification: %v", err)
+		return
+	}
+	logger.Infof("Slack notification sent\n")
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestNotifySlack tests the Block Kit message posted to the Slack webhook
+func TestNotifySlack(t *testing.T) {
+	t.Setenv("DRONE_BUILD_LINK", "https://drone.example.com/octocat/hello/42")
+
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&message)
+		content, _ := json.Marshal(message)
+		body = string(content)
+	}))
+	defer server.Close()
+
+	results := Results{
+		ScenarioCount:        2,
+		TotalFailedScenarios: 1,
+		FailedTests:          1,
+		StepCount:            4,
+		FailedSteps: []FailedStepDetails{
+			{Feature: "Checkout", Scenario: "Pay", Step: "I pay", ErrorMessage: "Card declined\nstack trace", New: true},
+		},
+	}
+	args := Args{SlackWebhook: server.URL, SlackChannel: "#qa", FailedStepsNumber: 0, FailedStepsPercentage: 10}
+
+	notifySlack(context.Background(), results, args, "https://reports.example.com/42.html", errors.New("failed steps percentage (25.00%) exceeds the threshold (10.00%)"))
+
+	for _, expected := range []string{
+		`"channel":"#qa"`,
+		`"color":"#e01e5a"`,
+		"Failed Steps Percentage",
+		"Card declined",
+		"https://reports.example.com/42.html",
+		"https://drone.example.com/octocat/hello/42",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected message to contain %q, got %s", expected, body)
+		}
+	}
+	if strings.Contains(body, "stack trace") {
+		t.Errorf("Expected only the first line of the error message, got %s", body)
+	}
+
+	// Successful builds are skipped when notifying on failures only
+	body = ""
+	args.SlackNotifyOn = "failure"
+	notifySlack(context.Background(), Results{}, args, "", nil)
+	if body != "" {
+		t.Errorf("Expected no notification for a successful build, got %s", body)
+	}
+}
+
+// TestSlackFailuresLimit tests cutting the failures text on a character boundary
+func TestSlackFailuresLimit(t *testing.T) {
+	var steps []FailedStepDetails
+	for i := 0; i < 100; i++ {
+		steps = append(steps, FailedStepDetails{Feature: "Café", Scenario: "検索の結果", Step: "l'étape", ErrorMessage: "échec de la vérification"})
+	}
+	text := slackFailures(Results{FailedSteps: steps}, len(steps))
+	if utf8.RuneCountInString(text) != slackTextLimit || !strings.HasSuffix(text, "…") || !utf8.ValidString(text) {
+		t.Errorf("Expected a valid text of %d characters ending with an ellipsis, got %d characters", slackTextLimit, utf8.RuneCountInString(text))
+	}
+}
+
+// TestSlackGateFailureLimit tests cutting a long gate failure to the length of a section
+func TestSlackGateFailureLimit(t *testing.T) {
+	message := slackMessage(Results{}, nil, Args{}, "", errors.New(strings.Repeat("l'étape a échoué; ", 500)))
+	blocks := message["attachments"].([]interface{})[0].(map[string]interface{})["blocks"].([]interface{})
+	for _, block := range blocks {
+		text, ok := block.(map[string]interface{})["text"].(map[string]interface{})
+		if !ok || !strings.HasPrefix(text["text"].(string), "*Gate failure:*") {
+			continue
+		}
+		if got := text["text"].(string); utf8.RuneCountInString(got) != slackTextLimit || !strings.HasSuffix(got, "…") {
+			t.Errorf("Expected a gate failure of %d characters ending with an ellipsis, got %d characters", slackTextLimit, utf8.RuneCountInString(got))
+		}
+		return
+	}
+	t.Errorf("Expected a gate failure section")
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxFailingStepsLogged is the number of logical steps logged as failing the most.
+const maxFailingStepsLogged = 5
+
+// addStepStats records an execution of a step, aggregated with the executions of the
+// same logical step, the step text with its parameters replaced by placeholders.
+func addStepStats(stats []StepStats, index map[string]int, keyword string, step Step, scenario string) []StepStats {
+	pattern := normalizeStepText(step.Name)
+	key := keyword + " " + pattern
+	i, ok := index[key]
+	if !ok {
+		i = len(stats)
+		index[key] = i
+		stats = append(stats, StepStats{Keyword: keyword, Step: pattern, Example: step.Name, Statuses: map[string]int{}})
+	}
+
+	entry := &stats[i]
+	entry.Executions++
+	entry.Statuses[step.Result.Status]++
+	entry.DurationMS += float64(step.Result.Duration) / 1e6
+	if step.Result.Status == "failed" && !slices.Contains(entry.FailedScenarios, scenario) {
+		entry.FailedScenarios = append(entry.FailedScenarios, scenario)
+	}
+	return stats
+}
+
+// mergeStepStats adds the executions of the logical steps to the total.
+func mergeStepStats(total, stats []StepStats) []StepStats {
+	index := make(map[string]int, len(total))
+	for i, entry := range total {
+		index[entry.Keyword+" "+entry.Step] = i
+	}
+
+	for _, entry := range stats {
+		i, ok := index[entry.Keyword+" "+entry.Step]
+		if !ok {
+			i = len(total)
+			index[entry.Keyword+" "+entry.Step] = i
+			total = append(total, StepStats{Keyword: entry.Keyword, Step: entry.Step, Example: entry.Example, Statuses: map[string]int{}})
+		}
+
+		existing := &total[i]
+		existing.Executions += entry.Executions
+		existing.DurationMS += entry.DurationMS
+		for status, count := range entry.Statuses {
+			existing.Statuses[status] += count
+		}
+		for _, scenario := range entry.FailedScenarios {
+			if !slices.Contains(existing.FailedScenarios, scenario) {
+				existing.FailedScenarios = append(existing.FailedScenarios, scenario)
+			}
+		}
+	}
+	return total
+}
+
+// sortStepStats orders the logical steps by the number of failed executions, then by the
+// number of executions, the most failing and used first.
+func sortStepStats(stats []StepStats) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		if failed, other := stats[i].Statuses["failed"], stats[j].Statuses["failed"]; failed != other {
+			return failed > other
+		}
+		if stats[i].Executions != stats[j].Executions {
+			return stats[i].Executions > stats[j].Executions
+		}
+		return stats[i].Keyword+" "+stats[i].Step < stats[j].Keyword+" "+stats[j].Step
+	})
+}
+
+// logFailingSteps logs the logical steps failing the most.
+func logFailingSteps(stats []StepStats) {
+	if len(stats) == 0 || stats[0].Statuses["failed"] == 0 {
+		return
+	}
+
+	logger.Infof("Most Failing Steps:\n")
+	for i, entry := range stats {
+		if i == maxFailingStepsLogged || entry.Statuses["failed"] == 0 {
+			break
+		}
+		logger.Infof("%d. %s %s: %d of %d executions failed in %d scenarios\n", i+1, entry.Keyword, entry.Step,
+			entry.Statuses["failed"], entry.Executions, len(entry.FailedScenarios))
+	}
+}
+
+// writeStepStatsReport writes the statistics of the logical steps as Markdown when the
+// path ends with .md and as JSON otherwise.
+func writeStepStatsReport(path string, stats []StepStats, args Args) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".md") {
+		if stats == nil {
+			stats = []StepStats{}
+		}
+		content, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0644)
+	}
+
+	report, err := renderReportTemplate(templateStepStats, struct{ Steps []StepStats }{stats}, args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
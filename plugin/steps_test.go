@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeStepText(t *testing.T) {
+	tests := map[string]string{
+		"I wait 5 seconds":                      "I wait {int} seconds",
+		"I wait  10   seconds":                  "I wait {int} seconds",
+		`I search for "shoes" under 9.99 (USD)`: "I search for {string} under {float} (USD)",
+		"I log in as 'admin'":                   "I log in as {string}",
+		"I open the cart":                       "I open the cart",
+	}
+	for text, expected := range tests {
+		if got := normalizeStepText(text); got != expected {
+			t.Errorf("normalizeStepText(%q) = %q, want %q", text, got, expected)
+		}
+	}
+}
+
+func TestStepStats(t *testing.T) {
+	features := []Feature{{Name: "Checkout", Elements: []Element{
+		{Name: "Card", Steps: []Step{
+			{Keyword: "Given ", Name: "I wait 5 seconds", Result: Result{Status: "passed", Duration: 5e9}},
+			{Keyword: "And ", Name: "I wait 10 seconds", Result: Result{Status: "failed", Duration: 10e9}},
+		}},
+		{Name: "Refund", Steps: []Step{
+			{Keyword: "Given ", Name: "I wait 2 seconds", Result: Result{Status: "failed", Duration: 2e9}},
+			{Keyword: "When ", Name: "I refund", Result: Result{Status: "skipped"}},
+		}},
+	}}}
+
+	if results := computeStats(features, Args{}); results.StepStats != nil {
+		t.Errorf("Expected no step statistics without a report, got %+v", results.StepStats)
+	}
+	results := computeStats(features, Args{StepStatsReport: "steps.json"})
+	if results.FailedSteps[0].StepPattern != "I wait {int} seconds" {
+		t.Errorf("Unexpected step pattern %q", results.FailedSteps[0].StepPattern)
+	}
+
+	stats := mergeStepStats(nil, results.StepStats)
+	stats = mergeStepStats(stats, results.StepStats)
+	sortStepStats(stats)
+	expected := []StepStats{
+		{Keyword: "Given", Step: "I wait {int} seconds", Example: "I wait 5 seconds", Executions: 6, Statuses: map[string]int{"passed": 2, "failed": 4},
+			DurationMS: 34000, FailedScenarios: []string{"Checkout :: Card", "Checkout :: Refund"}},
+		{Keyword: "When", Step: "I refund", Example: "I refund", Executions: 2, Statuses: map[string]int{"skipped": 2}},
+	}
+	if diff := cmp.Diff(expected, stats); diff != "" {
+		t.Errorf("Step statistics mismatch (-want +got):\n%s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), "steps.md")
+	if err := writeStepStatsReport(path, stats, Args{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if row := "| Given I wait {int} seconds | 6 | 4 | 66.67% | 34000.00 ms | 2 |"; !strings.Contains(string(content), row) {
+		t.Errorf("Expected the report to contain %q, got:\n%s", row, content)
+	}
+}
@@ -0,0 +1,33 @@
+package plugin
+
+import (
+	"os"
+	"time"
+)
+
+// githubStepSummary returns the path of the GitHub Actions job summary file, empty
+// outside of GitHub Actions or when the summary is skipped.
+func githubStepSummary(args Args) string {
+	if args.SkipStepSummary {
+		return ""
+	}
+	return os.Getenv("GITHUB_STEP_SUMMARY")
+}
+
+// appendStepSummary appends the Markdown summary of the results to the job summary file,
+// which the other steps of the job also append to.
+func appendStepSummary(path string, results Results, args Args, trend *Trend, gateErr error) error {
+	summary, err := renderReportTemplate(templateSummary, newReportTemplateData(results, args, trend, gateErr, time.Now()), args)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(summary + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "step_summary.md")
+	if err := os.WriteFile(path, []byte("## Build\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Setenv("DRONE_REPO", "")
+	t.Setenv("GITHUB_REPOSITORY", "")
+
+	results := Results{
+		FeatureCount: 1, ScenarioCount: 2, StepCount: 4, PassedTests: 3, FailedTests: 1, TotalFailedScenarios: 1, TotalPassedScenarios: 1, TotalFailedSteps: 1, DurationMS: 1500,
+		FailedSteps: []FailedStepDetails{{Feature: "Checkout", Scenario: "Pay | refund", Step: "I pay", URI: "features/checkout.feature", StepLine: 7,
+			ErrorMessage: "expected 2\nbut was 3", SourceURL: "https://github.com/acme/shop/blob/main/features/checkout.feature#L7"}},
+	}
+	err := appendStepSummary(path, results, Args{FailedScenariosNumber: 1}, nil, errors.New("1 failed scenario"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `## Build
+## Cucumber Test Report
+
+❌ **FAILED**: 1 failed scenario
+
+| Features | Scenarios | Steps | Failed Scenarios | Failed Steps | Skipped | Pending | Undefined | Pass Rate | Duration |
+| ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: |
+| 1 | 2 | 4 | 1 | 1 | 0 | 0 | 0 | 50.00% | 1500.00 ms |
+
+### Quality Gates
+
+| Gate | Observed | Threshold | Margin | Verdict |
+| --- | ---: | ---: | ---: | :---: |
+| Failed Scenarios | 1 | 1 | +0 | ✅ |
+
+### Failed Steps
+
+| Feature | Scenario | Step | Location | Error |
+| --- | --- | --- | --- | --- |
+| Checkout | Pay \| refund | I pay | [features/checkout.feature:7](https://github.com/acme/shop/blob/main/features/checkout.feature#L7) | expected 2 |
+
+`
+	if string(content) != expected {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", expected, content)
+	}
+
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+	if githubStepSummary(Args{SkipStepSummary: true}) != "" || githubStepSummary(Args{}) != path {
+		t.Errorf("Expected the step summary to be written unless skipped")
+	}
+}
@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamingThreshold is the file size above which reports are decoded as a stream.
+var streamingThreshold int64 = 64 << 20
+
+// streamBufferSize is the size of the read buffer used when decoding a stream.
+const streamBufferSize = 1 << 20
+
+// errNotFeatureArray is returned when a streamed report is not an array of features.
+var errNotFeatureArray = errors.New("expected an array of features")
+
+// decodeFeatures decodes a Cucumber JSON report one feature at a time, so that the
+// raw content of the report never has to be held in memory as a whole.
+func decodeFeatures(r io.Reader) ([]Feature, error) {
+	decoder := json.NewDecoder(bufio.NewReaderSize(r, streamBufferSize))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("%w, got %v", errNotFeatureArray, token)
+	}
+
+	var features []Feature
+	for decoder.More() {
+		var feature Feature
+		if err := decoder.Decode(&feature); err != nil {
+			return nil, err
+		}
+		if checkMemoryBudget() >= degradeEmbeddings {
+			stripStepDetails(&feature)
+		}
+		features = append(features, feature)
+	}
+
+	// Consume the closing bracket to detect truncated reports
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
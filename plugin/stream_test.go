@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestStreamedProcessFile tests that streamed and buffered decoding compute the same results
+func TestStreamedProcessFile(t *testing.T) {
+	buffered, err := processFile("../testdata/cucumber_report.json", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	oldThreshold := streamingThreshold
+	streamingThreshold = 0
+	defer func() { streamingThreshold = oldThreshold }()
+
+	streamed, err := processFile("../testdata/cucumber_report.json", false, Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(buffered, streamed); diff != "" {
+		t.Errorf("Results mismatch (-buffered +streamed):\n%s", diff)
+	}
+}
+
+// TestDecodeFeatures tests decoding malformed reports as a stream
+func TestDecodeFeatures(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    int
+		expectError bool
+	}{
+		{name: "Features", content: `[{"name":"A"},{"name":"B"}]`, expected: 2},
+		{name: "Empty Array", content: `[]`},
+		{name: "Not An Array", content: `{"name":"A"}`, expectError: true},
+		{name: "Truncated", content: `[{"name":"A"},{"na`, expectError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			features, err := decodeFeatures(strings.NewReader(tc.content))
+			if (err != nil) != tc.expectError {
+				t.Fatalf("Expected error: %v, got %v", tc.expectError, err)
+			}
+			if len(features) != tc.expected {
+				t.Errorf("Expected %d features, got %d", tc.expected, len(features))
+			}
+		})
+	}
+}
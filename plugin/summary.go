@@ -0,0 +1,22 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// writeSummary writes the aggregated results, including every failed step, as JSON.
+func writeSummary(path string, results Results) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWriteSummary tests writing the JSON summary
+func TestWriteSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "summary.json")
+	results := Results{
+		ScenarioCount:        2,
+		TotalFailedScenarios: 1,
+		FailedSteps:          []FailedStepDetails{{Feature: "Checkout", Scenario: "Pay", Step: "I pay", Fingerprint: "abc"}},
+	}
+
+	if err := writeSummary(path, results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(content), `"total_failed_scenarios": 1`) {
+		t.Errorf("Expected snake case fields, got %s", content)
+	}
+
+	var loaded Results
+	if err := json.Unmarshal(content, &loaded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(results, loaded); diff != "" {
+		t.Errorf("Summary mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestLogFailedDetailsLimit tests capping the failed step details in the log
+func TestLogFailedDetailsLimit(t *testing.T) {
+	previous := logger
+	recorder := newRecordingLogger()
+	logger = recorder
+	defer func() { logger = previous }()
+
+	var results Results
+	for i := 0; i < 5; i++ {
+		results.FailedSteps = append(results.FailedSteps, FailedStepDetails{Feature: "Feature", Scenario: "Scenario", Step: "Step"})
+	}
+	logAggregatedResults(results, Args{MaxFailedDetailsLogged: 2, SummaryFile: "reports/summary.json"})
+
+	details, overflow := 0, ""
+	for _, message := range *recorder.messages {
+		if strings.Contains(message, "Feature: Feature") {
+			details++
+		}
+		if strings.Contains(message, "more") {
+			overflow = message
+		}
+	}
+	if details != 2 {
+		t.Errorf("Expected 2 detailed failures, got %d", details)
+	}
+	if overflow != "INFO …and 3 more (see reports/summary.json)" {
+		t.Errorf("Unexpected overflow message %q", overflow)
+	}
+}
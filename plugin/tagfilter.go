@@ -0,0 +1,63 @@
+package plugin
+
+import "strings"
+
+// parseTagList parses a comma separated list of tags, adding the @ prefix the reports
+// use when it is missing.
+func parseTagList(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if !strings.HasPrefix(tag, "@") {
+			tag = "@" + tag
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// matchesTags reports whether scenario tags have one of the included tags, when there
+// are any, and none of the excluded tags.
+func matchesTags(tags, include, exclude []string) bool {
+	for _, tag := range exclude {
+		if hasTag(tags, tag) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, tag := range include {
+		if hasTag(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFeatures keeps the scenarios matching the included and excluded tags, with the
+// feature tags inherited by the scenarios, and drops the features left without any.
+func filterFeatures(features []Feature, args Args) []Feature {
+	include, exclude := parseTagList(args.IncludeTags), parseTagList(args.ExcludeTags)
+	if len(include) == 0 && len(exclude) == 0 {
+		return features
+	}
+
+	filtered := make([]Feature, 0, len(features))
+	for _, feature := range features {
+		var elements []Element
+		for _, element := range feature.Elements {
+			if matchesTags(scenarioTags(feature, element), include, exclude) {
+				elements = append(elements, element)
+			}
+		}
+		if len(elements) > 0 {
+			feature.Elements = elements
+			filtered = append(filtered, feature)
+		}
+	}
+	return filtered
+}
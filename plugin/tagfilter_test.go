@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTagList(t *testing.T) {
+	expected := []string{"@smoke", "@regression"}
+	if diff := cmp.Diff(expected, parseTagList(" @smoke, regression ,")); diff != "" {
+		t.Errorf("Tags mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestComputeStatsTagFilter(t *testing.T) {
+	passed := []Step{{Name: "it passes", Result: Result{Status: "passed"}}}
+	failed := []Step{{Name: "it fails", Result: Result{Status: "failed"}}}
+	features := []Feature{
+		{Name: "Checkout", Tags: []Tag{{Name: "@smoke"}}, Elements: []Element{
+			{Name: "Pay by card", Steps: passed},
+			{Name: "Pay by voucher", Tags: []Tag{{Name: "@wip"}}, Steps: failed},
+		}},
+		{Name: "Search", Elements: []Element{
+			{Name: "Find a book", Tags: []Tag{{Name: "@smoke"}}, Steps: passed},
+			{Name: "Find an author", Steps: failed},
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		include   string
+		exclude   string
+		features  int
+		scenarios int
+		failed    int
+	}{
+		{name: "no filter", features: 2, scenarios: 4, failed: 2},
+		{name: "include", include: "@smoke", features: 2, scenarios: 3, failed: 1},
+		{name: "exclude", exclude: "wip", features: 2, scenarios: 3, failed: 1},
+		{name: "include and exclude", include: "@smoke", exclude: "@wip", features: 2, scenarios: 2, failed: 0},
+		{name: "feature left without scenarios", include: "@wip", features: 1, scenarios: 1, failed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := computeStats(features, Args{IncludeTags: tt.include, ExcludeTags: tt.exclude})
+			if results.FeatureCount != tt.features || results.ScenarioCount != tt.scenarios || results.TotalFailedScenarios != tt.failed {
+				t.Errorf("Expected %d features, %d scenarios and %d failed, got %d, %d and %d", tt.features, tt.scenarios, tt.failed,
+					results.FeatureCount, results.ScenarioCount, results.TotalFailedScenarios)
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Names of the built-in report templates, which are also the file names overriding them
+// in the template directory.
+const (
+	templateStepGaps     = "step-gaps.md"
+	templateStepStats    = "step-stats.md"
+	templatePendingAging = "pending-aging.md"
+	templateConfluence   = "confluence.html"
+	templateGates        = "gates.md"
+	templateGatesHTML    = "gates.html"
+	templateHTMLReport   = "report.html"
+	templateSummary      = "summary.md"
+	templateLint         = "lint.md"
+)
+
+// builtinTemplates are the built-in templates of the generated reports by name. The
+// templates with a .html name are HTML templates escaping the values, the others are
+// text templates.
+var builtinTemplates = map[string]string{
+	templateStepGaps: `# Step Definition Gaps
+
+{{if not .Gaps}}Every step has a definition.
+{{end}}
+{{- range .Gaps}}## {{.Keyword}} {{.Expression}}
+
+{{.Count}} occurrences in {{len .Scenarios}} scenarios, for example ` + "`{{.Example}}`" + `.
+
+Suggested definition:
+
+` + "```" + `
+{{.Keyword}}("{{replace "\"" "\\\"" .Expression}}")
+` + "```" + `
+
+Affected scenarios:
+
+{{range .Scenarios}}- {{.}}
+{{end}}
+{{end}}`,
+
+	templateLint: `# Scenario Lint
+
+{{if not .Warnings}}No scenario anti-pattern was found.
+{{else}}| Rule | Scenario | Location | Message |
+| --- | --- | --- | --- |
+{{range .Warnings}}| {{.Rule}} | {{replace "|" "\\|" .Feature}} :: {{replace "|" "\\|" .Scenario}} | {{.URI}}:{{.Line}} | {{replace "|" "\\|" .Message}} |
+{{end}}{{end}}`,
+
+	templateStepStats: `# Step Statistics
+
+{{if not .Steps}}No step was executed.
+{{else}}| Step | Executions | Failed | Failure Rate | Duration | Failed Scenarios |
+| --- | ---: | ---: | ---: | ---: | ---: |
+{{range .Steps}}| {{.Keyword}} {{.Step}} | {{.Executions}} | {{index .Statuses "failed"}} | {{formatNumber (percentage (index .Statuses "failed") .Executions)}}% | {{formatNumber .DurationMS}} ms | {{len .FailedScenarios}} |
+{{end}}{{end}}`,
+
+	templatePendingAging: `# Unimplemented Steps
+
+{{if not .Steps}}Every step is implemented.
+{{else}}| Step | Status | Scenario | Age | Builds | First Seen |
+| --- | --- | --- | ---: | ---: | --- |
+{{range .Steps}}| {{.Step}} | {{.Status}} | {{.Feature}} :: {{.Scenario}} | {{.AgeDays}} days | {{.Builds}} | #{{.FirstSeenBuild}} on {{slice .FirstSeen 0 10}} |
+{{end}}{{end}}`,
+
+	templateConfluence: `<p><ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">{{if .GateError}}Red{{else}}Green{{end}}</ac:parameter><ac:parameter ac:name="title">{{if .GateError}}FAILED{{else}}PASSED{{end}}</ac:parameter></ac:structured-macro> Updated {{.Now.Format "Mon, 02 Jan 2006 15:04:05 MST"}}
+{{- with .BuildLink}} by <a href="{{.}}">build #{{$.BuildNumber}}</a>{{end}}</p>
+{{- with .GateError}}<p><strong>Gate failure:</strong> {{.}}</p>{{end}}
+{{- with .Results}}<h2>Summary</h2><table><tbody>
+<tr><th>Total Features</th><td>{{.FeatureCount}}</td></tr>
+<tr><th>Total Scenarios</th><td>{{.ScenarioCount}}</td></tr>
+<tr><th>Total Steps</th><td>{{.StepCount}}</td></tr>
+<tr><th>Failed Features</th><td>{{.TotalFailedFeatures}}</td></tr>
+<tr><th>Failed Scenarios</th><td>{{.TotalFailedScenarios}}</td></tr>
+<tr><th>Failed Steps</th><td>{{.TotalFailedSteps}}</td></tr>
+<tr><th>Skipped Steps</th><td>{{.SkippedTests}}</td></tr>
+<tr><th>Pending Steps</th><td>{{.PendingTests}}</td></tr>
+<tr><th>Undefined Steps</th><td>{{.UndefinedTests}}</td></tr>
+<tr><th>Pass Rate</th><td>{{formatNumber (percentage .TotalPassedScenarios .ScenarioCount)}}%</td></tr>
+<tr><th>Duration</th><td>{{formatNumber .DurationMS}} ms</td></tr>
+</tbody></table>{{end}}
+{{- with .Gates}}<h2>Quality Gates</h2><table><tbody><tr><th>Gate</th><th>Observed</th><th>Threshold</th><th>Margin</th><th>Verdict</th></tr>
+{{- range .}}<tr><td>{{.Name}}</td><td>{{gateValue . .Observed}}</td><td>{{gateValue . .Threshold}}</td><td>{{gateMargin .}}</td><td>{{gateSymbol .Passed}}</td></tr>{{end -}}
+</tbody></table>{{end}}
+{{- if and .Trend .Trend.Entries}}<h2>Trend</h2><table><tbody><tr><th>Build</th><th>Pass Rate</th><th>Failed Scenarios</th><th>Duration</th></tr>
+{{- range .Trend.Entries}}<tr><td>#{{.BuildNumber}}</td><td>{{formatNumber .PassRate}}%</td><td>{{.FailedScenarios}}</td><td>{{formatNumber .DurationMS}} ms</td></tr>{{end}}
+{{- with .Trend.Current}}<tr><td>#{{.BuildNumber}}</td><td>{{formatNumber .PassRate}}%</td><td>{{.FailedScenarios}}</td><td>{{formatNumber .DurationMS}} ms</td></tr>{{end -}}
+</tbody></table><p>Average pass rate: {{formatNumber .Trend.AveragePassRate}}% ({{formatSignedNumber .Trend.PassRateDelta}}%, {{.Trend.Direction}})</p>{{end}}
+{{- with .Results.ConsistencyViolations}}<h2>Consistency Violations</h2><table><tbody><tr><th>Feature</th><th>Scenario</th><th>Statuses</th></tr>
+{{- range .}}<tr><td>{{.Feature}}</td><td>{{.Scenario}}</td><td>{{range $i, $shard := .Shards}}{{if $i}}, {{end}}{{.Status}} in {{.Shard}}{{end}}</td></tr>{{end -}}
+</tbody></table>{{end}}
+{{- with .Results.FailedSteps}}<h2>Failed Steps</h2><table><tbody><tr><th>Feature</th><th>Scenario</th><th>Step</th><th>Location</th><th>Error</th></tr>
+{{- range .}}<tr><td>{{.Feature}}</td><td>{{.Scenario}}</td><td>{{.Step}}</td><td>{{if and .SourceURL (failureLocation .)}}<a href="{{.SourceURL}}">{{failureLocation .}}</a>{{else}}{{failureLocation .}}{{end}}</td><td>{{firstLine .ErrorMessage}}</td></tr>{{end -}}
+</tbody></table>{{end}}
+{{- with moreFailures 0 .Results}}<p>{{.}}</p>{{end}}`,
+
+	templateGates: `# Quality Gates
+
+{{if .GateError}}**FAILED**: {{.GateError}}{{else}}**PASSED**{{end}}
+
+{{if .Gates}}| Gate | Observed | Threshold | Margin | Verdict |
+| --- | ---: | ---: | ---: | :---: |
+{{range .Gates}}| {{.Name}} | {{gateValue . .Observed}} | {{gateValue . .Threshold}} | {{gateMargin .}} | {{gateSymbol .Passed}} |
+{{end}}{{else}}No threshold is configured.
+{{end}}
+{{- with .Results.ConsistencyViolations}}
+## Consistency Violations
+
+Scenarios executed in several shards with conflicting statuses:
+
+{{range .}}- {{.Feature}} :: {{.Scenario}}: {{range $i, $shard := .Shards}}{{if $i}}, {{end}}{{.Status}} in {{.Shard}}{{end}}
+{{end}}{{end}}`,
+
+	templateGatesHTML: `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Quality Gates{{with .Repo}} - {{.}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; }
+td.number { text-align: right; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Quality Gates</h1>
+<p>{{if .GateError}}<strong class="failed">FAILED</strong>: {{.GateError}}{{else}}<strong class="passed">PASSED</strong>{{end}}</p>
+{{if .Gates}}<table>
+<thead><tr><th scope="col">Gate</th><th scope="col">Observed</th><th scope="col">Threshold</th><th scope="col">Margin</th><th scope="col">Verdict</th></tr></thead>
+<tbody>
+{{range .Gates}}<tr class="{{if .Passed}}passed{{else}}failed{{end}}"><td>{{.Name}}</td><td class="number">{{gateValue . .Observed}}</td><td class="number">{{gateValue . .Threshold}}</td><td class="number">{{gateMargin .}}</td><td>{{gateSymbol .Passed}} {{if .Passed}}Passed{{else}}Failed{{end}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p>No threshold is configured.</p>{{end}}
+{{- with .Results.ConsistencyViolations}}
+<h2>Consistency Violations</h2>
+<p>Scenarios executed in several shards with conflicting statuses:</p>
+<ul>
+{{range .}}<li>{{.Feature}} :: {{.Scenario}}: {{range $i, $shard := .Shards}}{{if $i}}, {{end}}{{.Status}} in {{.Shard}}{{end}}</li>
+{{end}}</ul>
+{{- end}}
+{{- if and .Trend .Trend.Entries}}
+<h2>Trend</h2>
+{{trendChart .Trend}}
+<p>Build #{{.Trend.Current.BuildNumber}}: pass rate {{formatNumber .Trend.Current.PassRate}}%, average of the last {{len .Trend.Entries}} builds {{formatNumber .Trend.AveragePassRate}}% ({{formatSignedNumber .Trend.PassRateDelta}}%, {{.Trend.Direction}})</p>
+{{- end}}
+{{- with .Timeline}}
+<h2>Timeline</h2>
+{{timelineChart .}}
+<p>{{len .Scenarios}} scenarios in {{formatNumber .DurationMS}} ms, {{.MaxConcurrency}} at most and {{formatNumber .AverageConcurrency}} on average running at once{{with .Gaps}}, no scenario running during {{len .}} gaps{{end}}.{{with .Untimed}} {{.}} scenarios without a start timestamp are not shown.{{end}}</p>
+{{- end}}
+</body>
+</html>
+`,
+
+	templateHTMLReport: `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Cucumber Report{{with .Repo}} - {{.}}{{end}}{{with .BuildNumber}} #{{.}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; color: #1f2328; line-height: 1.4; }
+header, main { margin: 0 2em; }
+a:focus, summary:focus { outline: 3px solid #0969da; outline-offset: 2px; }
+.skip { position: absolute; left: -999em; }
+.skip:focus { left: 1em; top: 1em; background: #fff; padding: 0.4em; }
+.visually-hidden { position: absolute; width: 1px; height: 1px; overflow: hidden; clip: rect(0 0 0 0); white-space: nowrap; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+caption { text-align: left; font-weight: bold; padding: 0.4em 0; }
+th, td { border: 1px solid #d0d7de; padding: 0.4em 0.8em; text-align: left; vertical-align: top; }
+td.number { text-align: right; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+details { margin: 0.4em 0; }
+summary { cursor: pointer; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; white-space: pre-wrap; }
+figure { margin: 0.5em 0; }
+figure img { max-width: 100%; border: 1px solid #d0d7de; }
+</style>
+</head>
+<body>
+<a class="skip" href="#main">Skip to the results</a>
+<header>
+<h1>Cucumber Report{{with .Repo}} - {{.}}{{end}}</h1>
+<p>{{if .GateError}}<strong class="failed">❌ FAILED</strong>: {{.GateError}}{{else}}<strong class="passed">✅ PASSED</strong>{{end}}</p>
+<p>{{with .Branch}}Branch {{.}}, {{end}}{{with .BuildLink}}<a href="{{.}}">build #{{$.BuildNumber}}</a>{{else}}{{with .BuildNumber}}build #{{.}}{{end}}{{end}}, generated {{.Now.Format "Mon, 02 Jan 2006 15:04:05 MST"}}</p>
+{{- with .Results.Annotations}}
+<dl>
+{{range .}}<dt>{{.Name}}</dt><dd>{{.Value}}</dd>
+{{end}}</dl>
+{{- end}}
+</header>
+<main id="main">
+{{- with .Results}}
+<h2>Summary</h2>
+{{statusChart .}}
+<table>
+<caption>Totals</caption>
+<tbody>
+<tr><th scope="row">Features</th><td class="number">{{.FeatureCount}}</td><td class="number">{{.TotalFailedFeatures}} failed</td></tr>
+<tr><th scope="row">Scenarios</th><td class="number">{{.ScenarioCount}}</td><td class="number">{{.TotalFailedScenarios}} failed</td></tr>
+<tr><th scope="row">Steps</th><td class="number">{{.StepCount}}</td><td class="number">{{.FailedTests}} failed, {{.SkippedTests}} skipped, {{.PendingTests}} pending, {{.UndefinedTests}} undefined</td></tr>
+<tr><th scope="row">Pass Rate</th><td class="number" colspan="2">{{formatNumber (percentage .TotalPassedScenarios .ScenarioCount)}}%</td></tr>
+<tr><th scope="row">Duration</th><td class="number" colspan="2">{{formatNumber .DurationMS}} ms</td></tr>
+</tbody>
+</table>
+{{- end}}
+{{- if .Gates}}
+<h2>Quality Gates</h2>
+<table>
+<caption>Thresholds of the build</caption>
+<thead><tr><th scope="col">Gate</th><th scope="col">Observed</th><th scope="col">Threshold</th><th scope="col">Margin</th><th scope="col">Verdict</th></tr></thead>
+<tbody>
+{{range .Gates}}<tr><th scope="row">{{.Name}}</th><td class="number">{{gateValue . .Observed}}</td><td class="number">{{gateValue . .Threshold}}</td><td class="number">{{gateMargin .}}</td><td class="{{if .Passed}}passed{{else}}failed{{end}}">{{gateSymbol .Passed}} {{if .Passed}}Passed{{else}}Failed{{end}}</td></tr>
+{{end}}</tbody>
+</table>
+{{- end}}
+{{- with .Results.ReportGroups}}
+<h2>Report Groups</h2>
+<table>
+<caption>Results of the report groups</caption>
+<thead><tr><th scope="col">Group</th><th scope="col">Reports</th><th scope="col">Scenarios</th><th scope="col">Failed Scenarios</th><th scope="col">Pass Rate</th><th scope="col">Duration</th><th scope="col">Verdict</th></tr></thead>
+<tbody>
+{{range .}}<tr><th scope="row">{{.Name}}</th><td class="number">{{.Reports}}</td><td class="number">{{.Scenarios}}</td><td class="number">{{.FailedScenarios}}</td><td class="number">{{formatNumber .PassRate}}%</td><td class="number">{{formatNumber .DurationMS}} ms</td><td class="{{if .Passed}}passed{{else}}failed{{end}}">{{gateSymbol .Passed}} {{if .Passed}}Passed{{else}}Failed{{end}}</td></tr>
+{{end}}</tbody>
+</table>
+{{- end}}
+{{- with .Results.Environments}}
+<h2>Environments</h2>
+<table>
+<caption>Scenarios by browser, platform and device</caption>
+<thead><tr><th scope="col">Environment</th><th scope="col">Scenarios</th><th scope="col">Failed Scenarios</th></tr></thead>
+<tbody>
+{{range .}}<tr><th scope="row">{{.Environment}}</th><td class="number">{{.Scenarios}}</td><td class="number">{{.FailedScenarios}}</td></tr>
+{{end}}</tbody>
+</table>
+{{- end}}
+{{- if and .Trend .Trend.Entries}}
+<h2>Trend</h2>
+{{trendChart .Trend}}
+<p>Build #{{.Trend.Current.BuildNumber}}: pass rate {{formatNumber .Trend.Current.PassRate}}%, average of the last {{len .Trend.Entries}} builds {{formatNumber .Trend.AveragePassRate}}% ({{formatSignedNumber .Trend.PassRateDelta}}%, {{.Trend.Direction}})</p>
+{{- end}}
+{{- with .Timeline}}
+<h2>Timeline</h2>
+{{timelineChart .}}
+<p>{{len .Scenarios}} scenarios in {{formatNumber .DurationMS}} ms, {{.MaxConcurrency}} at most and {{formatNumber .AverageConcurrency}} on average running at once{{with .Gaps}}, no scenario running during {{len .}} gaps{{end}}.</p>
+{{- end}}
+{{- with .Results.ConsistencyViolations}}
+<h2>Consistency Violations</h2>
+<ul>
+{{range .}}<li>{{.Feature}} :: {{.Scenario}}: {{range $i, $shard := .Shards}}{{if $i}}, {{end}}{{.Status}} in {{.Shard}}{{end}}</li>
+{{end}}</ul>
+{{- end}}
+{{- with .Results.FailedSteps}}
+<h2>Failed Steps</h2>
+{{range .}}<details>
+<summary><span class="failed" aria-hidden="true">❌</span><span class="visually-hidden">Failed:</span> {{.Feature}} :: {{.Scenario}}: {{.Step}}{{if .New}} (new){{end}}</summary>
+<dl>
+{{- with failureLocation .}}<dt>Location</dt><dd>{{.}}</dd>{{end}}
+{{- with .SourceURL}}<dt>Source</dt><dd><a href="{{.}}">{{.}}</a></dd>{{end}}
+{{- with .SessionURL}}<dt>Session</dt><dd><a href="{{.}}">Recorded browser session</a></dd>{{end}}
+<dt>Duration</dt><dd>{{formatNumber .DurationMS}} ms</dd>
+</dl>
+<pre>{{.ErrorMessage}}</pre>
+{{- range $screenshot := .Screenshots}}{{with imageDataURI $screenshot}}
+<figure><img src="{{.}}" alt="Screenshot of the failed step">{{with $screenshot.Name}}<figcaption>{{.}}</figcaption>{{end}}</figure>{{end}}{{end}}
+</details>
+{{end}}
+{{- end}}
+{{- with moreFailures 0 .Results}}
+<p>{{.}}</p>
+{{- end}}
+{{- with featureBreakdown .Results.Scenarios}}
+<h2>Features</h2>
+{{range .}}<details{{if .FailedScenarios}} open{{end}}>
+<summary>{{if .FailedScenarios}}<span class="failed" aria-hidden="true">❌</span>{{else}}<span class="passed" aria-hidden="true">✅</span>{{end}} {{.Name}}: {{len .Scenarios}} scenarios, {{.FailedScenarios}} failed, {{formatNumber .DurationMS}} ms</summary>
+<table>
+<caption>Scenarios of {{.Name}}</caption>
+<thead><tr><th scope="col">Scenario</th><th scope="col">Status</th><th scope="col">Duration</th><th scope="col">Tags</th><th scope="col">Environment</th></tr></thead>
+<tbody>
+{{range .Scenarios}}<tr><th scope="row">{{.Scenario}}</th><td class="{{.Status}}">{{if eq .Status "failed"}}❌{{else if eq .Status "passed"}}✅{{end}} {{.Status}}</td><td class="number">{{formatNumber .DurationMS}} ms</td><td>{{join " " .Tags}}</td><td>{{.Environment}}</td></tr>
+{{end}}</tbody>
+</table>
+</details>
+{{end}}
+{{- end}}
+</main>
+</body>
+</html>
+`,
+
+	templateSummary: `## Cucumber Test Report{{with .Repo}} - {{.}}{{end}}
+
+{{if .GateError}}❌ **FAILED**: {{.GateError}}{{else}}✅ **PASSED**{{end}}
+{{with .Results.Annotations}}
+| Annotation | Value |
+| --- | --- |
+{{range .}}| {{replace "|" "\\|" .Name}} | {{replace "|" "\\|" .Value}} |
+{{end}}{{end}}{{with .Results}}
+| Features | Scenarios | Steps | Failed Scenarios | Failed Steps | Skipped | Pending | Undefined | Pass Rate | Duration |
+| ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: | ---: |
+| {{.FeatureCount}} | {{.ScenarioCount}} | {{.StepCount}} | {{.TotalFailedScenarios}} | {{.TotalFailedSteps}} | {{.SkippedTests}} | {{.PendingTests}} | {{.UndefinedTests}} | {{formatNumber (percentage .TotalPassedScenarios .ScenarioCount)}}% | {{formatNumber .DurationMS}} ms |
+{{end}}
+{{- if .Gates}}
+### Quality Gates
+
+| Gate | Observed | Threshold | Margin | Verdict |
+| --- | ---: | ---: | ---: | :---: |
+{{range .Gates}}| {{.Name}} | {{gateValue . .Observed}} | {{gateValue . .Threshold}} | {{gateMargin .}} | {{gateSymbol .Passed}} |
+{{end}}{{end}}
+{{- with .Results.FailedSteps}}
+### Failed Steps
+
+| Feature | Scenario | Step | Location | Error |
+| --- | --- | --- | --- | --- |
+{{range .}}| {{replace "|" "\\|" .Feature}} | {{replace "|" "\\|" .Scenario}} | {{replace "|" "\\|" .Step}} | {{if and .SourceURL (failureLocation .)}}[{{failureLocation .}}]({{.SourceURL}}){{else}}{{failureLocation .}}{{end}} | {{replace "|" "\\|" (firstLine .ErrorMessage)}} |
+{{end}}{{end}}
+{{- with moreFailures 0 .Results}}
+_{{.}}_
+{{end}}`,
+}
+
+// templateFuncs are the functions available to the report templates.
+var templateFuncs = map[string]interface{}{
+	"formatNumber":       formatNumber,
+	"formatSignedNumber": formatSignedNumber,
+	"percentage":         percentageOf,
+	"gateSymbol":         gateSymbol,
+	"gateValue":          func(gate GateResult, value float64) string { return gate.formatValue(value) },
+	"gateMargin":         func(gate GateResult) string { return gate.formatMargin() },
+	"trendChart":         trendChart,
+	"timelineChart":      timelineChart,
+	"statusChart":        statusChart,
+	"featureBreakdown":   featureBreakdown,
+	"failureLocation":    failureLocation,
+	"firstLine":          firstLine,
+	"imageDataURI":       imageDataURI,
+	"moreFailures":       moreFailures,
+	"join":               func(separator string, values []string) string { return strings.Join(values, separator) },
+	"replace":            func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+}
+
+// reportTemplateData holds the values available to the report templates rendered after
+// the quality gates.
+type reportTemplateData struct {
+	Results     Results
+	Gates       []GateResult
+	Trend       *Trend
+	Timeline    *Timeline
+	GateError   string
+	Repo        string
+	Branch      string
+	BuildNumber string
+	BuildLink   string
+	Now         time.Time
+}
+
+// newReportTemplateData collects the values available to the report templates.
+func newReportTemplateData(results Results, args Args, trend *Trend, gateErr error, now time.Time) reportTemplateData {
+	data := reportTemplateData{
+		Results:     results,
+		Gates:       evaluateThresholds(results, args),
+		Trend:       trend,
+		Timeline:    buildTimeline(results.Scenarios),
+		Repo:        currentRepo(),
+		Branch:      currentBranch(args),
+		BuildNumber: currentBuildNumber(),
+		BuildLink:   currentBuildLink(),
+		Now:         now,
+	}
+	if gateErr != nil {
+		data.GateError = gateErr.Error()
+	}
+	return data
+}
+
+// executor renders a parsed text or HTML template.
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// parseReportTemplate parses the template of the name, overridden by the file of the same
+// name in the template directory when there is one.
+func parseReportTemplate(name, dir string) (executor, error) {
+	text, ok := builtinTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %s", name)
+	}
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			text = string(content)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if strings.HasSuffix(name, ".html") {
+		return htmltemplate.New(name).Funcs(templateFuncs).Parse(text)
+	}
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// renderReportTemplate renders the template of the name with the data.
+func renderReportTemplate(name string, data interface{}, args Args) (string, error) {
+	tmpl, err := parseReportTemplate(name, args.TemplateDir)
+	if err != nil {
+		return "", err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %v", name, err)
+	}
+	return rendered.String(), nil
+}
+
+// validateTemplateDir checks that the template directory exists and that the templates
+// it overrides parse.
+func validateTemplateDir(args Args) error {
+	if args.TemplateDir == "" {
+		return nil
+	}
+	if info, err := os.Stat(args.TemplateDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("invalid TemplateDir %s: not a directory", args.TemplateDir)
+	}
+
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, err := parseReportTemplate(name, args.TemplateDir); err != nil {
+			errs = append(errs, fmt.Errorf("invalid template %s: %v", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
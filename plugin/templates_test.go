@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestConfluenceTemplate tests the built-in Confluence template
+func TestConfluenceTemplate(t *testing.T) {
+	t.Setenv("DRONE_BUILD_LINK", "https://drone.example.com/acme/shop/42")
+	t.Setenv("DRONE_BUILD_NUMBER", "42")
+	results := Results{
+		ScenarioCount:        2,
+		TotalPassedScenarios: 1,
+		TotalFailedScenarios: 1,
+		FailedSteps: []FailedStepDetails{{Feature: "Cart", Scenario: "Add <item>", Step: "Given I add", URI: "features/cart.feature",
+			StepLine: 7, SourceURL: "https://github.com/acme/shop/blob/main/features/cart.feature#L7", ErrorMessage: "expected 1\nstack"}},
+	}
+	trend := &Trend{Entries: []HistoryEntry{{BuildNumber: "41", PassRate: 100}}, Current: HistoryEntry{BuildNumber: "42", PassRate: 50}}
+	body, err := confluenceStorageBody(results, Args{FailedScenariosNumber: 0}, trend, errors.New("too many failures"), time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<ac:parameter ac:name="colour">Red</ac:parameter><ac:parameter ac:name="title">FAILED</ac:parameter>`,
+		`Updated Wed, 01 May 2024 12:00:00 UTC by <a href="https://drone.example.com/acme/shop/42">build #42</a>`,
+		`<p><strong>Gate failure:</strong> too many failures</p>`,
+		`<tr><th>Pass Rate</th><td>50.00%</td></tr>`,
+		`<tr><td>#41</td><td>100.00%</td><td>0</td><td>0.00 ms</td></tr><tr><td>#42</td><td>50.00%</td>`,
+		`<td>Add &lt;item&gt;</td>`,
+		`<a href="https://github.com/acme/shop/blob/main/features/cart.feature#L7">features/cart.feature:7</a>`,
+		`<td>expected 1</td>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected body to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
+
+// TestTemplateOverrides tests overriding the built-in templates with the template directory
+func TestTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, templateStepGaps), []byte(`{{range .Gaps}}* {{.Keyword}} {{.Expression}} ({{.Count}}){{"\n"}}{{end}}`), 0644)
+	args := Args{TemplateDir: dir}
+	if err := validateTemplateDir(args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := filepath.Join(t.TempDir(), "gaps.md")
+	gaps := []StepGap{{Keyword: "Given", Expression: "I have {int} products", Count: 3}}
+	if err := writeStepGapReport(report, gaps, args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(report); string(content) != "* Given I have {int} products (3)\n" {
+		t.Errorf("Unexpected report %q", content)
+	}
+
+	os.WriteFile(filepath.Join(dir, templateConfluence), []byte(`{{.Missing`), 0644)
+	if err := validateTemplateDir(args); err == nil || !strings.Contains(err.Error(), templateConfluence) {
+		t.Errorf("Expected an error for the invalid Confluence template, got %v", err)
+	}
+	if err := validateTemplateDir(Args{TemplateDir: filepath.Join(dir, "missing")}); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
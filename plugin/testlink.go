@@ -0,0 +1,233 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// defaultTestLinkTagPrefix is the prefix of the tags holding TestLink test case external IDs.
+const defaultTestLinkTagPrefix = "@testlink:"
+
+// Constants for TestLink execution statuses
+const (
+	testLinkPassed  = "p"
+	testLinkFailed  = "f"
+	testLinkBlocked = "b"
+)
+
+// xmlRPCMember represents a member of an XML-RPC struct.
+type xmlRPCMember struct {
+	Name  string      `xml:"name"`
+	Value xmlRPCValue `xml:"value"`
+}
+
+// xmlRPCValue represents the XML-RPC values used by the TestLink API.
+type xmlRPCValue struct {
+	String *string       `xml:"string,omitempty"`
+	Int    *int          `xml:"int,omitempty"`
+	Struct *xmlRPCStruct `xml:"struct,omitempty"`
+	Array  *xmlRPCArray  `xml:"array,omitempty"`
+	Text   string        `xml:",chardata"`
+}
+
+// xmlRPCStruct represents an XML-RPC struct.
+type xmlRPCStruct struct {
+	Members []xmlRPCMember `xml:"member"`
+}
+
+// xmlRPCArray represents an XML-RPC array.
+type xmlRPCArray struct {
+	Values []xmlRPCValue `xml:"data>value"`
+}
+
+// xmlRPCResponse represents an XML-RPC method response.
+type xmlRPCResponse struct {
+	Params []xmlRPCValue `xml:"params>param>value"`
+	Fault  *xmlRPCValue  `xml:"fault>value"`
+}
+
+// validateTestLinkArgs checks the TestLink settings.
+func validateTestLinkArgs(args Args) error {
+	if args.TestLinkURL == "" {
+		return nil
+	}
+	if args.TestLinkDevKey == "" {
+		return errors.New("a TestLink developer key is required to report results")
+	}
+	if args.TestLinkPlanID <= 0 {
+		return errors.New("a TestLink test plan ID is required to report results")
+	}
+	return nil
+}
+
+// testLinkResults maps the scenarios to TestLink test cases by their tags. A test case
+// linked to several scenarios, such as the examples of an outline, gets the worst status.
+func testLinkResults(scenarios []ScenarioResult, prefix string) []TestLinkResult {
+	if prefix == "" {
+		prefix = defaultTestLinkTagPrefix
+	}
+
+	statuses := make(map[string]string)
+	notes := make(map[string][]string)
+	for _, scenario := range scenarios {
+		for _, tag := range scenario.Tags {
+			if !strings.HasPrefix(tag, prefix) || len(tag) == len(prefix) {
+				continue
+			}
+			id := strings.TrimPrefix(tag, prefix)
+			status, ok := statuses[id]
+			if !ok {
+				status = scenario.Status
+			}
+			statuses[id] = worseStatus(status, scenario.Status)
+			notes[id] = append(notes[id], fmt.Sprintf("%s › %s: %s", scenario.Feature, scenario.Scenario, scenario.Status))
+		}
+	}
+
+	results := make([]TestLinkResult, 0, len(statuses))
+	for id, status := range statuses {
+		results = append(results, TestLinkResult{
+			ExternalID: id,
+			Status:     testLinkStatus(status),
+			Notes:      strings.Join(notes[id], "\n"),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ExternalID < results[j].ExternalID })
+	return results
+}
+
+// testLinkStatus maps a scenario status to a TestLink execution status.
+func testLinkStatus(status string) string {
+	switch status {
+	case "passed":
+		return testLinkPassed
+	case "failed":
+		return testLinkFailed
+	default:
+		return testLinkBlocked
+	}
+}
+
+// xmlRPCString returns an XML-RPC string value.
+func xmlRPCString(value string) xmlRPCValue {
+	return xmlRPCValue{String: &value}
+}
+
+// xmlRPCInt returns an XML-RPC integer value.
+func xmlRPCInt(value int) xmlRPCValue {
+	return xmlRPCValue{Int: &value}
+}
+
+// callTestLink calls a TestLink XML-RPC method with the members as its single struct parameter.
+func callTestLink(ctx context.Context, args Args, method string, members []xmlRPCMember) error {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	call := struct {
+		XMLName    xml.Name      `xml:"methodCall"`
+		MethodName string        `xml:"methodName"`
+		Params     []xmlRPCValue `xml:"params>param>value"`
+	}{
+		MethodName: method,
+		Params:     []xmlRPCValue{{Struct: &xmlRPCStruct{Members: members}}},
+	}
+	if err := xml.NewEncoder(&body).Encode(call); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, args.TestLinkURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+
+	resp, err := newHTTPClient(args).Do(req)
+	if err != nil {
+		return sendError(req, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(req, resp); err != nil {
+		return err
+	}
+
+	var response xmlRPCResponse
+	if err := xml.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("invalid XML-RPC response: %w", err)
+	}
+	if response.Fault != nil {
+		return fmt.Errorf("XML-RPC fault: %s", xmlRPCMessage(response.Fault))
+	}
+
+	// TestLink reports errors as an array of structs with a code and a message
+	for _, param := range response.Params {
+		if param.Array == nil {
+			continue
+		}
+		for _, value := range param.Array.Values {
+			if value.Struct == nil {
+				continue
+			}
+			for _, member := range value.Struct.Members {
+				if member.Name == "code" {
+					return fmt.Errorf("TestLink error: %s", xmlRPCMessage(&value))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// xmlRPCMessage returns the message of an error struct.
+func xmlRPCMessage(value *xmlRPCValue) string {
+	if value.Struct == nil {
+		return "unknown error"
+	}
+	for _, member := range value.Struct.Members {
+		if member.Name == "message" || member.Name == "faultString" {
+			if member.Value.String != nil {
+				return *member.Value.String
+			}
+			return strings.TrimSpace(member.Value.Text)
+		}
+	}
+	return "unknown error"
+}
+
+// reportTestLinkResults reports the execution results of the tagged scenarios to TestLink.
+func reportTestLinkResults(ctx context.Context, results Results, args Args) {
+	testCases := testLinkResults(results.Scenarios, args.TestLinkTagPrefix)
+	if len(testCases) == 0 {
+		logger.Infof("No scenarios tagged with TestLink test cases\n")
+		return
+	}
+
+	build := firstNonEmpty(args.TestLinkBuild, currentBuildNumber())
+	scrub := newScrubber(args)
+	reported := 0
+	for _, testCase := range testCases {
+		members := []xmlRPCMember{
+			{Name: "devKey", Value: xmlRPCString(args.TestLinkDevKey)},
+			{Name: "testcaseexternalid", Value: xmlRPCString(testCase.ExternalID)},
+			{Name: "testplanid", Value: xmlRPCInt(args.TestLinkPlanID)},
+			{Name: "buildname", Value: xmlRPCString(build)},
+			{Name: "status", Value: xmlRPCString(testCase.Status)},
+			{Name: "notes", Value: xmlRPCString(scrub.text(testCase.Notes))},
+		}
+		if args.TestLinkPlatform != "" {
+			members = append(members, xmlRPCMember{Name: "platformname", Value: xmlRPCString(args.TestLinkPlatform)})
+		}
+
+		if err := callTestLink(ctx, args, "tl.reportTCResult", members); err != nil {
+			logger.Warnf("Failed to report TestLink test case %s: %v", testCase.ExternalID, err)
+			continue
+		}
+		reported++
+	}
+	logger.Infof("Reported %d of %d TestLink test cases to build %s\n", reported, len(testCases), build)
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestTestLinkResults tests mapping the tagged scenarios to TestLink test cases
+func TestTestLinkResults(t *testing.T) {
+	scenarios := []ScenarioResult{
+		{Feature: "Login", Scenario: "Valid", Tags: []string{"@smoke", "@testlink:QA-1"}, Status: "passed"},
+		{Feature: "Login", Scenario: "Outline 1", Tags: []string{"@testlink:QA-2"}, Status: "passed"},
+		{Feature: "Login", Scenario: "Outline 2", Tags: []string{"@testlink:QA-2"}, Status: "failed"},
+		{Feature: "Login", Scenario: "Later", Tags: []string{"@testlink:QA-3"}, Status: "pending"},
+		{Feature: "Login", Scenario: "Untracked", Tags: []string{"@testlink:"}, Status: "failed"},
+	}
+
+	expected := []TestLinkResult{
+		{ExternalID: "QA-1", Status: "p", Notes: "Login › Valid: passed"},
+		{ExternalID: "QA-2", Status: "f", Notes: "Login › Outline 1: passed\nLogin › Outline 2: failed"},
+		{ExternalID: "QA-3", Status: "b", Notes: "Login › Later: pending"},
+	}
+	if diff := cmp.Diff(expected, testLinkResults(scenarios, "")); diff != "" {
+		t.Errorf("TestLink results mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestCallTestLink tests the XML-RPC calls to the TestLink API
+func TestCallTestLink(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		expectError string
+	}{
+		{
+			name:     "Success",
+			response: `<?xml version="1.0"?><methodResponse><params><param><value><array><data><value><struct><member><name>status</name><value><boolean>1</boolean></value></member><member><name>message</name><value><string>Success!</string></value></member></struct></value></data></array></value></param></params></methodResponse>`,
+		},
+		{
+			name:        "TestLink Error",
+			response:    `<?xml version="1.0"?><methodResponse><params><param><value><array><data><value><struct><member><name>code</name><value><int>3030</int></value></member><member><name>message</name><value><string>Test case not in plan</string></value></member></struct></value></data></array></value></param></params></methodResponse>`,
+			expectError: "Test case not in plan",
+		},
+		{
+			name:        "Fault",
+			response:    `<?xml version="1.0"?><methodResponse><fault><value><struct><member><name>faultCode</name><value><int>2</int></value></member><member><name>faultString</name><value><string>Invalid method</string></value></member></struct></value></fault></methodResponse>`,
+			expectError: "Invalid method",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var request string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				request = string(body)
+				w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			err := callTestLink(context.Background(), Args{TestLinkURL: server.URL}, "tl.reportTCResult", []xmlRPCMember{
+				{Name: "testcaseexternalid", Value: xmlRPCString("QA-1")},
+				{Name: "testplanid", Value: xmlRPCInt(7)},
+			})
+
+			for _, expected := range []string{
+				"<methodName>tl.reportTCResult</methodName>",
+				"<member><name>testcaseexternalid</name><value><string>QA-1</string></value></member>",
+				"<member><name>testplanid</name><value><int>7</int></value></member>",
+			} {
+				if !strings.Contains(request, expected) {
+					t.Errorf("Expected request to contain %q, got %s", expected, request)
+				}
+			}
+
+			if tc.expectError == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tc.expectError != "" && (err == nil || !strings.Contains(err.Error(), tc.expectError)) {
+				t.Errorf("Expected error containing %q, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package plugin
+
+import (
+	"sort"
+	"time"
+)
+
+// buildTimeline reconstructs the execution timeline of the scenarios with a start
+// timestamp, placing the overlapping scenarios on separate lanes, and returns nil when
+// no scenario has one.
+func buildTimeline(scenarios []ScenarioResult) *Timeline {
+	timeline := &Timeline{}
+	for _, scenario := range scenarios {
+		start, err := time.Parse(time.RFC3339Nano, scenario.StartTimestamp)
+		if scenario.StartTimestamp == "" || err != nil {
+			timeline.Untimed++
+			continue
+		}
+		timeline.Scenarios = append(timeline.Scenarios, TimelineScenario{
+			ID:         scenario.ID,
+			Feature:    scenario.Feature,
+			Scenario:   scenario.Scenario,
+			Shard:      scenario.Shard,
+			Status:     scenario.Status,
+			Start:      start.UTC(),
+			DurationMS: scenario.DurationMS,
+		})
+	}
+	if len(timeline.Scenarios) == 0 {
+		return nil
+	}
+	sort.SliceStable(timeline.Scenarios, func(i, j int) bool { return timeline.Scenarios[i].Start.Before(timeline.Scenarios[j].Start) })
+
+	timeline.Start = timeline.Scenarios[0].Start
+	timeline.End = timeline.Start
+	var laneEnds []time.Time
+	for i := range timeline.Scenarios {
+		scenario := &timeline.Scenarios[i]
+		end := scenario.end()
+		scenario.OffsetMS = durationMS(scenario.Start.Sub(timeline.Start))
+		scenario.Lane = len(laneEnds)
+		for lane, laneEnd := range laneEnds {
+			if !laneEnd.After(scenario.Start) {
+				scenario.Lane = lane
+				break
+			}
+		}
+		if scenario.Lane == len(laneEnds) {
+			laneEnds = append(laneEnds, end)
+		} else {
+			laneEnds[scenario.Lane] = end
+		}
+		if end.After(timeline.End) {
+			timeline.End = end
+		}
+		timeline.BusyMS += scenario.DurationMS
+	}
+	timeline.DurationMS = durationMS(timeline.End.Sub(timeline.Start))
+	if timeline.DurationMS > 0 {
+		timeline.AverageConcurrency = timeline.BusyMS / timeline.DurationMS
+	}
+	timeline.Concurrency, timeline.Gaps = concurrencyChanges(timeline)
+	for _, change := range timeline.Concurrency {
+		timeline.MaxConcurrency = max(timeline.MaxConcurrency, change.Running)
+	}
+	return timeline
+}
+
+// end returns the time the scenario finished.
+func (s TimelineScenario) end() time.Time {
+	return s.Start.Add(time.Duration(s.DurationMS * float64(time.Millisecond)))
+}
+
+// durationMS converts a duration to milliseconds.
+func durationMS(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// concurrencyChanges returns the number of scenarios running from every point of the
+// timeline where it changes, and the gaps during which no scenario runs.
+func concurrencyChanges(timeline *Timeline) ([]ConcurrencyChange, []TimelineGap) {
+	type event struct {
+		offsetMS float64
+		delta    int
+	}
+	events := make([]event, 0, 2*len(timeline.Scenarios))
+	for _, scenario := range timeline.Scenarios {
+		events = append(events, event{scenario.OffsetMS, 1}, event{scenario.OffsetMS + scenario.DurationMS, -1})
+	}
+	// Finish the scenarios before starting the ones starting at the same time
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].offsetMS != events[j].offsetMS {
+			return events[i].offsetMS < events[j].offsetMS
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	var changes []ConcurrencyChange
+	var gaps []TimelineGap
+	running := 0
+	for i, e := range events {
+		running += e.delta
+		if i+1 < len(events) && events[i+1].offsetMS == e.offsetMS {
+			continue
+		}
+		if len(changes) > 0 && changes[len(changes)-1].Running == running {
+			continue
+		}
+		changes = append(changes, ConcurrencyChange{OffsetMS: e.offsetMS, Running: running})
+		if running == 0 && i+1 < len(events) {
+			gaps = append(gaps, TimelineGap{OffsetMS: e.offsetMS, DurationMS: events[i+1].offsetMS - e.offsetMS})
+		}
+	}
+	return changes, gaps
+}
+
+// logTimeline logs the wall-clock duration and concurrency of the run and the gaps during
+// which no scenario ran.
+func logTimeline(timeline *Timeline) {
+	idle := 0.0
+	for _, gap := range timeline.Gaps {
+		idle += gap.DurationMS
+	}
+	logger.Infof("Timeline: %d scenarios in %s ms, %d at most and %s on average running at once\n", len(timeline.Scenarios),
+		formatNumber(timeline.DurationMS), timeline.MaxConcurrency, formatNumber(timeline.AverageConcurrency))
+	if len(timeline.Gaps) > 0 {
+		logger.Warnf("No scenario ran during %d gaps totalling %s ms. Check the scheduling of the parallel runs", len(timeline.Gaps), formatNumber(idle))
+	}
+	if timeline.Untimed > 0 {
+		logger.Infof("%d scenarios without a start timestamp are missing from the timeline\n", timeline.Untimed)
+	}
+}
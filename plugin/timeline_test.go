@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	if buildTimeline([]ScenarioResult{{Scenario: "Untimed"}}) != nil {
+		t.Errorf("Expected no timeline without start timestamps")
+	}
+
+	timeline := buildTimeline([]ScenarioResult{
+		{Feature: "Cart", Scenario: "Add", Shard: "2", Status: "failed", StartTimestamp: "2024-05-14T09:00:00.500Z", DurationMS: 1000},
+		{Feature: "Login", Scenario: "Valid", Shard: "1", Status: "passed", StartTimestamp: "2024-05-14T09:00:00Z", DurationMS: 1000},
+		{Feature: "Search", Scenario: "Find", Shard: "1", Status: "passed", StartTimestamp: "2024-05-14T11:00:03+02:00", DurationMS: 1000},
+		{Feature: "Search", Scenario: "Untimed", Status: "passed", DurationMS: 1000},
+	})
+	if timeline == nil {
+		t.Fatalf("Expected a timeline")
+	}
+
+	var lanes []int
+	var offsets []float64
+	for _, scenario := range timeline.Scenarios {
+		lanes = append(lanes, scenario.Lane)
+		offsets = append(offsets, scenario.OffsetMS)
+	}
+	if diff := cmp.Diff([]int{0, 1, 0}, lanes); diff != "" {
+		t.Errorf("Lanes mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]float64{0, 500, 3000}, offsets); diff != "" {
+		t.Errorf("Offsets mismatch (-want +got):\n%s", diff)
+	}
+	if timeline.DurationMS != 4000 || timeline.BusyMS != 3000 || timeline.AverageConcurrency != 0.75 || timeline.MaxConcurrency != 2 || timeline.Untimed != 1 {
+		t.Errorf("Unexpected timeline statistics: %+v", timeline)
+	}
+	expectedConcurrency := []ConcurrencyChange{{0, 1}, {500, 2}, {1000, 1}, {1500, 0}, {3000, 1}, {4000, 0}}
+	if diff := cmp.Diff(expectedConcurrency, timeline.Concurrency); diff != "" {
+		t.Errorf("Concurrency mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]TimelineGap{{OffsetMS: 1500, DurationMS: 1500}}, timeline.Gaps); diff != "" {
+		t.Errorf("Gaps mismatch (-want +got):\n%s", diff)
+	}
+
+	chart := string(timelineChart(timeline))
+	for _, expected := range []string{
+		`<title id="timeline-chart-title">Timeline of 3 scenarios over 4000.00 ms, with 1 gaps during which no scenario ran</title>`,
+		`<title>No scenario running for 1500.00 ms</title>`,
+		`<title>Cart :: Add: failed on shard 2, from 0.50 s for 1000.00 ms</title>`,
+	} {
+		if !strings.Contains(chart, expected) {
+			t.Errorf("Expected the chart to contain %s, got %s", expected, chart)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimingsDecay is the weight of the recorded duration of a scenario when averaged
+// with its duration in the current build.
+const defaultTimingsDecay = 0.5
+
+// validateTimingsDecay checks the weight of the recorded timings.
+func validateTimingsDecay(args Args) error {
+	if args.TimingsDecay < 0 || args.TimingsDecay >= 1 {
+		return errors.New("invalid TimingsDecay value. It must be at least 0 and less than 1")
+	}
+	return nil
+}
+
+// scenarioTimings maps the scenario IDs to their duration in milliseconds. A scenario
+// executed several times, such as a rerun, takes the duration of its last execution.
+func scenarioTimings(scenarios []ScenarioResult) map[string]float64 {
+	timings := make(map[string]float64, len(scenarios))
+	for _, scenario := range scenarios {
+		if scenario.ID != "" {
+			timings[scenario.ID] = scenario.DurationMS
+		}
+	}
+	return timings
+}
+
+// writeTimings writes the durations of the scenarios by ID for the test splitting tools,
+// as CSV rows when the path ends with .csv and as a JSON object otherwise.
+func writeTimings(path string, timings map[string]float64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		content, err := json.MarshalIndent(timings, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0644)
+	}
+
+	ids := make([]string, 0, len(timings))
+	for id := range timings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"id", "duration_ms"})
+	for _, id := range ids {
+		writer.Write([]string{id, strconv.FormatFloat(timings[id], 'f', -1, 64)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// mergeTimings averages the durations of the current build with the recorded timings,
+// weighting the recorded duration by the decay so that older builds weigh exponentially
+// less. Scenarios not executed in the current build keep their recorded duration.
+func (h *History) mergeTimings(timings map[string]float64, decay float64, now time.Time) {
+	if decay == 0 {
+		decay = defaultTimingsDecay
+	}
+	if h.Timings == nil {
+		h.Timings = make(map[string]HistoryTiming, len(timings))
+	}
+	for id, duration := range timings {
+		timing, ok := h.Timings[id]
+		if ok {
+			timing.DurationMS = decay*timing.DurationMS + (1-decay)*duration
+		} else {
+			timing.DurationMS = duration
+		}
+		timing.Runs++
+		timing.LastSeen = now.Unix()
+		h.Timings[id] = timing
+	}
+}
+
+// timings returns the recorded duration of each scenario by ID.
+func (h *History) timings() map[string]float64 {
+	timings := make(map[string]float64, len(h.Timings))
+	for id, timing := range h.Timings {
+		timings[id] = timing.DurationMS
+	}
+	return timings
+}
@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWriteTimings tests exporting the scenario durations as JSON and CSV
+func TestWriteTimings(t *testing.T) {
+	scenarios := []ScenarioResult{
+		{ID: "login;valid-password", DurationMS: 120.5},
+		{ID: "cart;add", DurationMS: 30},
+		{ID: "login;valid-password", DurationMS: 100},
+		{DurationMS: 5},
+	}
+	timings := scenarioTimings(scenarios)
+	dir := t.TempDir()
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"timings.json", "{\n  \"cart;add\": 30,\n  \"login;valid-password\": 100\n}"},
+		{"timings.csv", "id,duration_ms\ncart;add,30\nlogin;valid-password,100\n"},
+	}
+	for _, tc := range tests {
+		path := filepath.Join(dir, tc.file)
+		if err := writeTimings(path, timings); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, _ := os.ReadFile(path)
+		if string(content) != tc.expected {
+			t.Errorf("Expected %s to be %q, got %q", tc.file, tc.expected, content)
+		}
+	}
+}
+
+// TestMergeTimings tests averaging the durations with the recorded timings and pruning them
+func TestMergeTimings(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	history := &History{Timings: map[string]HistoryTiming{
+		"login;valid-password": {DurationMS: 200, Runs: 3, LastSeen: now.AddDate(0, 0, -1).Unix()},
+		"search;by-name":       {DurationMS: 50, Runs: 1, LastSeen: now.AddDate(0, 0, -2).Unix()},
+		"legacy;removed":       {DurationMS: 10, Runs: 5, LastSeen: now.AddDate(0, 0, -60).Unix()},
+	}}
+
+	history.mergeTimings(map[string]float64{"login;valid-password": 100, "cart;add": 30}, 0.75, now)
+	history.prune(0, 30, now)
+
+	expected := map[string]HistoryTiming{
+		"login;valid-password": {DurationMS: 175, Runs: 4, LastSeen: now.Unix()},
+		"cart;add":             {DurationMS: 30, Runs: 1, LastSeen: now.Unix()},
+		"search;by-name":       {DurationMS: 50, Runs: 1, LastSeen: now.AddDate(0, 0, -2).Unix()},
+	}
+	if diff := cmp.Diff(expected, history.Timings); diff != "" {
+		t.Errorf("Timings mismatch (-want +got):\n%s", diff)
+	}
+	if timings := history.timings(); timings["login;valid-password"] != 175 || len(timings) != 3 {
+		t.Errorf("Unexpected timings %v", timings)
+	}
+}
@@ -0,0 +1,330 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the resilience settings of the requests to external services.
+const (
+	defaultHTTPMaxAttempts             = 3
+	defaultHTTPRetryBackoff            = 500 * time.Millisecond
+	maxHTTPRetryBackoff                = 30 * time.Second
+	defaultHTTPCircuitBreakerThreshold = 5
+	defaultHTTPCircuitCooldown         = 30 * time.Second
+)
+
+// errCircuitOpen is returned without sending the request when the circuit breaker of
+// the host is open.
+var errCircuitOpen = errors.New("circuit breaker open after consecutive failures, request skipped")
+
+// hostState tracks the rate limit and the consecutive failures of a host, shared by
+// the HTTP clients of all integrations.
+type hostState struct {
+	mu       sync.Mutex
+	next     time.Time // Earliest time of the next request allowed by the rate limit
+	failures int       // Consecutive failed requests
+	opened   time.Time // Time of the failure opening the circuit breaker
+	probing  bool      // True while the probe request of the half-open circuit breaker is in flight
+}
+
+// hostStates holds the state of every host the plugin sent requests to.
+var hostStates sync.Map
+
+// stateOf returns the shared state of a host.
+func stateOf(host string) *hostState {
+	state, _ := hostStates.LoadOrStore(host, &hostState{})
+	return state.(*hostState)
+}
+
+// reserve returns the delay before the next request allowed by the rate limit of
+// requests per second, and reserves its slot.
+func (s *hostState) reserve(rate float64, now time.Time) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot := s.next
+	if slot.Before(now) {
+		slot = now
+	}
+	s.next = slot.Add(time.Duration(float64(time.Second) / rate))
+	return slot.Sub(now)
+}
+
+// open reports whether the requests to the host are skipped because it failed the
+// threshold number of consecutive requests, and whether the request is a probe. Once the
+// cool-down has elapsed, the circuit breaker is half-open: a single probe request is let
+// through, closing it when it succeeds and opening it for another cool-down when it fails.
+func (s *hostState) open(threshold int, cooldown time.Duration, now time.Time) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures < threshold {
+		return false, false
+	}
+	if s.probing || now.Sub(s.opened) < cooldown {
+		return true, false
+	}
+	s.probing = true
+	return false, true
+}
+
+// endProbe lets the next probe through when the probe request ended without a response
+// to record, such as when its context was canceled.
+func (s *hostState) endProbe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.probing = false
+}
+
+// record counts the consecutive failed requests of the host.
+func (s *hostState) record(failed bool, threshold int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !failed {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if s.failures >= threshold {
+		s.opened = now
+	}
+}
+
+// resilientTransport retries the failed requests with a jittered exponential backoff,
+// limits the rate of requests per host, stops sending requests to a host failing
+// repeatedly until its cool-down has elapsed and bounds the duration of every attempt.
+type resilientTransport struct {
+	next      http.RoundTripper
+	timeout   time.Duration
+	attempts  int
+	backoff   time.Duration
+	rate      float64
+	threshold int
+	cooldown  time.Duration
+}
+
+// newResilientTransport wraps the transport with the resilience settings.
+func newResilientTransport(next http.RoundTripper, args Args) *resilientTransport {
+	transport := &resilientTransport{
+		next:      next,
+		timeout:   defaultHTTPTimeout,
+		attempts:  defaultHTTPMaxAttempts,
+		backoff:   defaultHTTPRetryBackoff,
+		rate:      args.HTTPRateLimit,
+		threshold: defaultHTTPCircuitBreakerThreshold,
+		cooldown:  defaultHTTPCircuitCooldown,
+	}
+	if args.HTTPTimeoutSeconds > 0 {
+		transport.timeout = time.Duration(args.HTTPTimeoutSeconds) * time.Second
+	}
+	if args.HTTPMaxAttempts > 0 {
+		transport.attempts = args.HTTPMaxAttempts
+	}
+	if args.HTTPRetryBackoffMS > 0 {
+		transport.backoff = time.Duration(args.HTTPRetryBackoffMS) * time.Millisecond
+	}
+	if args.HTTPCircuitBreakerThreshold > 0 {
+		transport.threshold = args.HTTPCircuitBreakerThreshold
+	}
+	if args.HTTPCircuitCooldownSeconds > 0 {
+		transport.cooldown = time.Duration(args.HTTPCircuitCooldownSeconds) * time.Second
+	}
+	return transport
+}
+
+// idempotentMethods are the methods whose requests can be repeated without side effects.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryable reports whether the failed request can be retried. Idempotent requests are
+// retried on network errors, 429 and 5xx responses. The others, such as a POST creating
+// an alert or a comment, are only retried when the server did not process them: on
+// 429 and 503 responses and when the connection could not be established.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if idempotentMethods[req.Method] || req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+	if err != nil {
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// RoundTrip sends the request, retrying it on network errors, 429 and 5xx responses
+// when it is retryable.
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	state := stateOf(req.URL.Host)
+	open, probe := state.open(t.threshold, t.cooldown, time.Now())
+	if open {
+		return nil, errCircuitOpen
+	}
+	if probe {
+		defer state.endProbe()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := sleepContext(req.Context(), state.reserve(t.rate, time.Now())); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.attempt(req, attempt)
+		failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !failed || !retryable(req, resp, err) || req.Context().Err() != nil {
+			state.record(failed, t.threshold, time.Now())
+			return resp, err
+		}
+		if attempt+1 >= t.attempts || (req.Body != nil && req.GetBody == nil) {
+			state.record(true, t.threshold, time.Now())
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		logger.Debugf("Retrying %s request to %s in %s (attempt %d of %d)", req.Method, req.URL.Host, delay, attempt+2, t.attempts)
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends the request once, within the timeout of an attempt.
+func (t *resilientTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(attemptReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout covers reading the body, so it is canceled once the body is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// delay returns the jittered exponential backoff before the next attempt, or the
+// delay requested by the Retry-After header of the response, in seconds or as an HTTP
+// date.
+func (t *resilientTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		retryAfter := resp.Header.Get("Retry-After")
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxHTTPRetryBackoff)
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return min(max(time.Until(date), 0), maxHTTPRetryBackoff)
+		}
+	}
+	backoff := min(t.backoff<<attempt, maxHTTPRetryBackoff)
+	// Pick a delay between half and the full backoff so that clients do not retry in sync
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// cancelBody cancels the context of a request when its response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// sleepContext waits for the duration unless the context is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// requestError describes a failed request by its method and host, leaving out the path
+// and query of the URL since webhook URLs contain secrets.
+type requestError struct {
+	method string
+	host   string
+	status string // Status of the response, empty for network errors
+	body   string
+	err    error
+}
+
+func (e *requestError) Error() string {
+	message := fmt.Sprintf("%s %s", e.method, e.host)
+	if e.status != "" {
+		message += ": " + e.status
+	}
+	if e.body != "" {
+		message += ": " + e.body
+	}
+	if e.err != nil {
+		message += ": " + e.err.Error()
+	}
+	return message
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// sendError describes the error of sending a request to an external service.
+func sendError(req *http.Request, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return &requestError{method: req.Method, host: req.URL.Host, err: err}
+}
+
+// statusError describes an unexpected response of an external service.
+func statusError(req *http.Request, resp *http.Response, body []byte) error {
+	return &requestError{method: req.Method, host: req.URL.Host, status: resp.Status, body: strings.TrimSpace(string(body))}
+}
+
+// checkResponse returns an error describing the response of an external service unless
+// its status is successful.
+func checkResponse(req *http.Request, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return statusError(req, resp, body)
+}
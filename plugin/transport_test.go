@@ -0,0 +1,213 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestResilientTransportRetries tests retrying the failed requests with their body
+func TestResilientTransportRetries(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content := make([]byte, r.ContentLength)
+		r.Body.Read(content)
+		bodies = append(bodies, string(content))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	args := Args{HTTPRetryBackoffMS: 1}
+	if err := postJSON(context.Background(), args, server.URL, map[string]string{"text": "hello"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(bodies) != 3 || bodies[2] != `{"text":"hello"}` {
+		t.Errorf("Expected 3 attempts with the payload, got %q", bodies)
+	}
+}
+
+// roundTripFunc is a transport answering the requests with a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TestResilientTransportRetryable tests retrying the requests that are not idempotent
+// only when the server did not process them
+func TestResilientTransportRetryable(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		err      error
+		attempts int
+	}{
+		{name: "GET On 500", method: http.MethodGet, status: http.StatusInternalServerError, attempts: 3},
+		{name: "GET On Reset", method: http.MethodGet, err: readErr, attempts: 3},
+		{name: "POST On 500", method: http.MethodPost, status: http.StatusInternalServerError, attempts: 1},
+		{name: "POST On 502", method: http.MethodPost, status: http.StatusBadGateway, attempts: 1},
+		{name: "POST On Reset", method: http.MethodPost, err: readErr, attempts: 1},
+		{name: "POST On 503", method: http.MethodPost, status: http.StatusServiceUnavailable, attempts: 3},
+		{name: "POST On 429", method: http.MethodPost, status: http.StatusTooManyRequests, attempts: 3},
+		{name: "POST On Dial Error", method: http.MethodPost, err: dialErr, attempts: 3},
+		{name: "PATCH Passed", method: http.MethodPatch, status: http.StatusOK, attempts: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &http.Response{StatusCode: tc.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})
+			transport := newResilientTransport(next, Args{HTTPRetryBackoffMS: 1, HTTPCircuitBreakerThreshold: 100})
+			req, _ := http.NewRequest(tc.method, "http://"+strings.ReplaceAll(tc.name, " ", "-")+".test/hook", strings.NewReader("{}"))
+			if resp, err := transport.RoundTrip(req); err == nil {
+				resp.Body.Close()
+			}
+			if attempts != tc.attempts {
+				t.Errorf("Expected %d attempts, got %d", tc.attempts, attempts)
+			}
+		})
+	}
+}
+
+// TestResilientTransportRetryAfter tests the delays requested in seconds and as an HTTP date
+func TestResilientTransportRetryAfter(t *testing.T) {
+	transport := newResilientTransport(http.DefaultTransport, Args{})
+	tests := []struct {
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{"2", 2 * time.Second, 2 * time.Second},
+		{time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), maxHTTPRetryBackoff, maxHTTPRetryBackoff},
+	}
+	for _, tc := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{tc.retryAfter}}}
+		if delay := transport.delay(0, resp); delay < tc.min || delay > tc.max {
+			t.Errorf("Retry-After %q: expected a delay between %s and %s, got %s", tc.retryAfter, tc.min, tc.max, delay)
+		}
+	}
+}
+
+// TestResilientTransportCircuitBreaker tests skipping the requests to a failing host
+// and describing the errors without the path of the URL
+func TestResilientTransportCircuitBreaker(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	args := Args{HTTPMaxAttempts: 1, HTTPCircuitBreakerThreshold: 2}
+	url := server.URL + "/services/T000/B000/secret"
+	err := postJSON(context.Background(), args, url, map[string]string{}, nil)
+	if err == nil || strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "500 Internal Server Error: boom") {
+		t.Errorf("Unexpected error %v", err)
+	}
+	postJSON(context.Background(), args, url, map[string]string{}, nil)
+
+	err = postJSON(context.Background(), args, url, map[string]string{}, nil)
+	if err == nil || !strings.Contains(err.Error(), errCircuitOpen.Error()) {
+		t.Errorf("Expected the circuit breaker to be open, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+// TestHostStateCooldown tests probing a host once the cool-down of its open circuit
+// breaker has elapsed
+func TestHostStateCooldown(t *testing.T) {
+	var state hostState
+	start := time.Now()
+	state.record(true, 2, start)
+	if open, _ := state.open(2, time.Minute, start); open {
+		t.Fatalf("Expected the circuit breaker to be closed below the threshold")
+	}
+	state.record(true, 2, start)
+	if open, _ := state.open(2, time.Minute, start.Add(30*time.Second)); !open {
+		t.Fatalf("Expected the circuit breaker to be open during the cool-down")
+	}
+
+	// A single probe is let through once the cool-down has elapsed
+	if open, probe := state.open(2, time.Minute, start.Add(time.Minute)); open || !probe {
+		t.Fatalf("Expected a probe after the cool-down, got open %v and probe %v", open, probe)
+	}
+	if open, _ := state.open(2, time.Minute, start.Add(time.Minute)); !open {
+		t.Fatalf("Expected the circuit breaker to be open while the probe is in flight")
+	}
+
+	// A failed probe opens the circuit breaker for another cool-down
+	state.record(true, 2, start.Add(time.Minute))
+	state.endProbe()
+	if open, _ := state.open(2, time.Minute, start.Add(90*time.Second)); !open {
+		t.Fatalf("Expected the circuit breaker to open again after a failed probe")
+	}
+
+	// A successful probe closes it
+	if open, probe := state.open(2, time.Minute, start.Add(2*time.Minute)); open || !probe {
+		t.Fatalf("Expected a probe after the second cool-down")
+	}
+	state.record(false, 2, start.Add(2*time.Minute))
+	state.endProbe()
+	if open, probe := state.open(2, time.Minute, start.Add(2*time.Minute)); open || probe {
+		t.Errorf("Expected the circuit breaker to be closed after a successful probe")
+	}
+}
+
+// TestResilientTransportTimeout tests the timeout of an attempt
+func TestResilientTransportTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	transport := newResilientTransport(http.DefaultTransport, Args{HTTPMaxAttempts: 1})
+	transport.timeout = 50 * time.Millisecond
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	start := time.Now()
+	if _, err := (&http.Client{Transport: transport}).Do(req); err == nil {
+		t.Fatal("Expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the request to time out, took %s", elapsed)
+	}
+}
+
+// TestHostStateReserve tests the slots reserved by the rate limit
+func TestHostStateReserve(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	state := &hostState{}
+	for i, expected := range []time.Duration{0, 500 * time.Millisecond, time.Second} {
+		if delay := state.reserve(2, now); delay != expected {
+			t.Errorf("Expected request %d to wait %s, got %s", i+1, expected, delay)
+		}
+	}
+	if delay := state.reserve(0, now); delay != 0 {
+		t.Errorf("Expected no delay without a rate limit, got %s", delay)
+	}
+}
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Constants for Trend Direction
+const (
+	TrendImproved  = "IMPROVED"
+	TrendRegressed = "REGRESSED"
+	TrendStable    = "STABLE"
+)
+
+// defaultTrendBuilds is the number of previous builds used when none is configured.
+const defaultTrendBuilds = 10
+
+// computeTrend compares the current build with the last n builds of the same branch.
+func computeTrend(previous []HistoryEntry, current HistoryEntry, n int) Trend {
+	previous = recentEntries(previous, n)
+
+	trend := Trend{
+		Entries:   previous,
+		Current:   current,
+		Direction: TrendStable,
+	}
+
+	if len(previous) == 0 {
+		return trend
+	}
+
+	total := 0.0
+	for _, entry := range previous {
+		total += entry.PassRate
+	}
+	trend.AveragePassRate = total / float64(len(previous))
+	trend.PassRateDelta = current.PassRate - trend.AveragePassRate
+
+	switch {
+	case trend.PassRateDelta > 0.005:
+		trend.Direction = TrendImproved
+	case trend.PassRateDelta < -0.005:
+		trend.Direction = TrendRegressed
+	}
+
+	return trend
+}
+
+// logTrend logs the pass rate trend of the recent builds.
+func logTrend(trend Trend) {
+	logger.Infof("Trend (last %d builds on branch %s):\n", len(trend.Entries), trend.Current.Branch)
+	logger.Infof("-----------------------------------------------\n")
+	if len(trend.Entries) == 0 {
+		logger.Infof("No previous builds recorded\n")
+		logger.Infof("===============================================\n")
+		return
+	}
+
+	for _, entry := range trend.Entries {
+		logger.Infof("Build #%s: pass rate %s%%, failed scenarios %d\n", entry.BuildNumber, formatNumber(entry.PassRate), entry.FailedScenarios)
+	}
+	logger.Infof("Build #%s (current): pass rate %s%%, failed scenarios %d\n", trend.Current.BuildNumber, formatNumber(trend.Current.PassRate), trend.Current.FailedScenarios)
+	logger.Infof("Average pass rate: %s%% (%s%%) %s\n", formatNumber(trend.AveragePassRate), formatSignedNumber(trend.PassRateDelta), trendSymbol(trend.Direction))
+	logger.Infof("===============================================\n")
+}
+
+// trendSymbol returns the log symbol for a trend direction.
+func trendSymbol(direction string) string {
+	switch direction {
+	case TrendImproved:
+		return "📈"
+	case TrendRegressed:
+		return "📉"
+	default:
+		return "➖"
+	}
+}
+
+// writeTrendStats writes the trend statistics to the output file.
+func writeTrendStats(trend Trend, log Logger) {
+	passRates := make([]string, 0, len(trend.Entries)+1)
+	failedScenarios := make([]string, 0, len(trend.Entries)+1)
+	for _, entry := range append(trend.Entries, trend.Current) {
+		passRates = append(passRates, formatNumber(entry.PassRate))
+		failedScenarios = append(failedScenarios, strconv.Itoa(entry.FailedScenarios))
+	}
+
+	statsMap := map[string]string{
+		"TREND_BUILDS":           strconv.Itoa(len(trend.Entries)),
+		"TREND_PASS_RATES":       strings.Join(passRates, ","),
+		"TREND_FAILED_SCENARIOS": strings.Join(failedScenarios, ","),
+		"TREND_AVG_PASS_RATE":    formatNumber(trend.AveragePassRate),
+		"TREND_PASS_RATE_DELTA":  formatNumber(trend.PassRateDelta),
+		"TREND_DIRECTION":        trend.Direction,
+	}
+
+	for key, value := range statsMap {
+		if err := WriteEnvToFile(key, value, log); err != nil {
+			log.Errorf("Error writing %s: %s", key, err)
+		}
+	}
+}
@@ -1,33 +1,94 @@
 package plugin
 
+import (
+	"encoding/xml"
+	"time"
+)
+
 // Feature represents a single feature in the Cucumber JSON report.
 type Feature struct {
-	ID          string    `json:"id"`
-	URI         string    `json:"uri"`
-	Keyword     string    `json:"keyword"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Line        int       `json:"line"`
-	Elements    []Element `json:"elements"`
+	ID          string           `json:"id"`
+	URI         string           `json:"uri"`
+	Keyword     string           `json:"keyword"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Line        int              `json:"line"`
+	Tags        []Tag            `json:"tags,omitempty"`
+	Elements    []Element        `json:"elements"`
+	Metadata    *FeatureMetadata `json:"metadata,omitempty"` // Environment recorded by the WebdriverIO reporter
+}
+
+// FeatureMetadata represents the browser, platform and device a feature ran on.
+type FeatureMetadata struct {
+	Browser struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"browser"`
+	Device   string `json:"device"`
+	Platform struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"platform"`
 }
 
 // Element represents a scenario or scenario outline in the Cucumber JSON report.
 type Element struct {
-	ID          string `json:"id"`
-	Keyword     string `json:"keyword"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Line        int    `json:"line"`
-	Type        string `json:"type"`
-	Steps       []Step `json:"steps"`
+	ID             string `json:"id"`
+	Keyword        string `json:"keyword"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Line           int    `json:"line"`
+	Type           string `json:"type"`
+	StartTimestamp string `json:"start_timestamp,omitempty"` // Start of the scenario, set by some reporters
+	Tags           []Tag  `json:"tags,omitempty"`
+	Before         []Step `json:"before,omitempty"`
+	Steps          []Step `json:"steps"`
+	After          []Step `json:"after,omitempty"`
+}
+
+// Tag represents a tag applied to a feature or scenario.
+type Tag struct {
+	Name string `json:"name"`
+	Line int    `json:"line,omitempty"`
 }
 
-// Step represents a single step in a scenario.
+// Step represents a single step or hook in a scenario.
 type Step struct {
-	Keyword string `json:"keyword"`
-	Name    string `json:"name"`
-	Line    int    `json:"line"`
-	Result  Result `json:"result"`
+	Keyword    string      `json:"keyword"`
+	Name       string      `json:"name"`
+	Line       int         `json:"line"`
+	Result     Result      `json:"result"`
+	Output     []string    `json:"output,omitempty"`
+	Embeddings []Embedding `json:"embeddings,omitempty"`
+	Arguments  []Argument  `json:"arguments,omitempty"` // Matched values, or the data table or doc string
+	Match      *StepMatch  `json:"match,omitempty"`
+}
+
+// StepMatch represents the step definition matching a step.
+type StepMatch struct {
+	Location  string     `json:"location,omitempty"`
+	Arguments []Argument `json:"arguments,omitempty"`
+}
+
+// Argument represents a value matched in the step text at its offset, or a data table
+// or a doc string passed to the step.
+type Argument struct {
+	Val     *string `json:"val,omitempty"`
+	Offset  int     `json:"offset,omitempty"`
+	Rows    []Row   `json:"rows,omitempty"`
+	Content string  `json:"content,omitempty"`
+}
+
+// Row represents a row of a data table argument.
+type Row struct {
+	Cells []string `json:"cells"`
+}
+
+// Embedding represents data attached to a step, such as a screenshot or a log.
+type Embedding struct {
+	Data     string `json:"data"`
+	MimeType string `json:"mime_type"`
+	Name     string `json:"name,omitempty"`
 }
 
 // Result represents the result of a step execution.
@@ -39,28 +100,588 @@ type Result struct {
 
 // Results represents the aggregated results of the Cucumber report.
 type Results struct {
-	FeatureCount         int                 // Total number of features
-	ScenarioCount        int                 // Total number of scenarios
-	StepCount            int                 // Total number of steps
-	PassedTests          int                 // Number of passed steps
-	FailedTests          int                 // Number of failed steps
-	SkippedTests         int                 // Number of skipped steps
-	PendingTests         int                 // Number of pending steps
-	UndefinedTests       int                 // Number of undefined steps
-	DurationMS           float64             // Total duration in milliseconds
-	FailedSteps          []FailedStepDetails // Details of failed steps
-	TotalFailedFeatures  int                 // Total number of failed features
-	TotalPassedFeatures  int                 // Total number of passed features
-	TotalFailedScenarios int                 // Total number of failed scenarios
-	TotalPassedScenarios int                 // Total number of passed scenarios
-	TotalFailedSteps     int                 // Total number of failed steps
-	TotalPassedSteps     int                 // Total number of passed steps
+	FeatureCount              int                        `json:"feature_count"`                        // Total number of features
+	ScenarioCount             int                        `json:"scenario_count"`                       // Total number of scenarios
+	StepCount                 int                        `json:"step_count"`                           // Total number of steps
+	PassedTests               int                        `json:"passed_tests"`                         // Number of passed steps
+	FailedTests               int                        `json:"failed_tests"`                         // Number of failed steps
+	SkippedTests              int                        `json:"skipped_tests"`                        // Number of skipped steps
+	PendingTests              int                        `json:"pending_tests"`                        // Number of pending steps
+	UndefinedTests            int                        `json:"undefined_tests"`                      // Number of undefined steps
+	DurationMS                float64                    `json:"duration_ms"`                          // Total duration in milliseconds
+	FailedSteps               []FailedStepDetails        `json:"failed_steps,omitempty"`               // Details of failed steps
+	TotalFailedFeatures       int                        `json:"total_failed_features"`                // Total number of failed features
+	TotalPassedFeatures       int                        `json:"total_passed_features"`                // Total number of passed features
+	TotalFailedScenarios      int                        `json:"total_failed_scenarios"`               // Total number of failed scenarios
+	TotalPassedScenarios      int                        `json:"total_passed_scenarios"`               // Total number of passed scenarios
+	TotalFailedSteps          int                        `json:"total_failed_steps"`                   // Total number of failed steps
+	TotalPassedSteps          int                        `json:"total_passed_steps"`                   // Total number of passed steps
+	Scenarios                 []ScenarioResult           `json:"scenarios,omitempty"`                  // Results of the individual scenarios
+	DurationRegressions       []DurationRegression       `json:"duration_regressions,omitempty"`       // Scenarios significantly slower than in previous builds
+	QuarantineRecommendations []QuarantineRecommendation `json:"quarantine_recommendations,omitempty"` // Scenarios failing intermittently in the recent builds
+	NewFailures               int                        `json:"new_failures"`                         // Number of failed scenarios not failing in previous builds
+	RecurringFailures         int                        `json:"recurring_failures"`                   // Number of failed scenarios also failing in previous builds
+	ReportCount               int                        `json:"report_count"`                         // Number of processed report files
+	FileErrors                []FileError                `json:"file_errors,omitempty"`                // Report files that could not be processed
+	MissingScenarios          []string                   `json:"missing_scenarios,omitempty"`          // Scenarios of the manifest missing from the reports
+	Shards                    []ShardResult              `json:"shards,omitempty"`                     // Statistics of the test shards
+	ReportGroups              []ReportGroupResult        `json:"report_groups,omitempty"`              // Statistics and gate verdicts of the report groups
+	ConsistencyViolations     []ConsistencyViolation     `json:"consistency_violations,omitempty"`     // Scenarios with conflicting statuses across shards
+	OverflowedFailedSteps     int                        `json:"overflowed_failed_steps,omitempty"`    // Failed step details beyond MaxFailedDetails written to the overflow file
+	FailedStepsOverflowFile   string                     `json:"failed_steps_overflow_file,omitempty"` // Newline-delimited JSON file of the overflowed failed step details
+	ScenarioRetries           int                        `json:"scenario_retries"`                     // Additional executions of scenarios executed more than once
+	RetriedScenarios          int                        `json:"retried_scenarios"`                    // Number of scenarios executed more than once
+	StepRetries               int                        `json:"step_retries"`                         // Steps executed again right after failing
+	TotalRetries              int                        `json:"total_retries"`                        // Scenario and step retries
+	Environments              []EnvironmentResult        `json:"environments,omitempty"`               // Scenario counts by browser, platform and device
+	StepGaps                  []StepGap                  `json:"step_gaps,omitempty"`                  // Undefined steps deduplicated by suggested expression
+	StepStats                 []StepStats                `json:"step_stats,omitempty"`                 // Usage and failures of the logical steps, when a step statistics report is configured
+	UnimplementedSteps        []UnimplementedStep        `json:"unimplemented_steps,omitempty"`        // Pending and undefined steps, when a pending aging report is configured
+	Categories                []CategoryResult           `json:"categories,omitempty"`                 // Scenario counts of the custom categories
+	Baseline                  *BaselineComparison        `json:"baseline,omitempty"`                   // Comparison with the target branch
+	BaselineDelta             *BaselineDelta             `json:"baseline_delta,omitempty"`             // Changes since the baseline file
+	ScenarioDrift             *ScenarioDrift             `json:"scenario_drift,omitempty"`             // Change of the scenario count since the baseline
+	FlakyScenarios            []string                   `json:"flaky_scenarios,omitempty"`            // "Feature :: Scenario" identifiers of the scenarios failing and then passing on rerun
+	Annotations               []Annotation               `json:"annotations,omitempty"`                // Metadata written by the previous steps, such as the deployed version
+	Features                  []FeatureStats             `json:"features,omitempty"`                   // Statistics of the individual features
+	LintWarnings              []LintWarning              `json:"lint_warnings,omitempty"`              // Scenario anti-patterns, when linting is enabled
+	AllFailures               []HistoryFailure           `json:"-"`                                    // Every failure, kept when the failed step details are trimmed
+}
+
+// FeatureStats represents the statistics of the scenarios of a feature.
+type FeatureStats struct {
+	Feature    string         `json:"feature"`
+	Status     string         `json:"status"`      // Worst status of the scenarios
+	Scenarios  int            `json:"scenarios"`   // Number of scenarios
+	Statuses   map[string]int `json:"statuses"`    // Number of scenarios by status
+	Failures   int            `json:"failures"`    // Number of failed steps
+	DurationMS float64        `json:"duration_ms"` // Total duration in milliseconds
+}
+
+// FeatureIndexEntry represents a feature in the index of the feature details directory.
+type FeatureIndexEntry struct {
+	FeatureStats
+	File string `json:"file"` // Path of the details file, relative to the directory
+}
+
+// FeatureDetails represents the drill-down results of a feature.
+type FeatureDetails struct {
+	FeatureStats
+	ScenarioResults []ScenarioResult    `json:"scenario_results"`
+	FailedSteps     []FailedStepDetails `json:"failed_steps"`
+}
+
+// BaselineComparison represents the comparison of the build with the last build of the
+// target branch.
+type BaselineComparison struct {
+	TargetBranch       string   `json:"target_branch"`
+	Source             string   `json:"source"`         // History file or summary the baseline was read from
+	NewFailures        []string `json:"new_failures"`   // "Feature :: Scenario" identifiers failing only in the build
+	FixedFailures      []string `json:"fixed_failures"` // "Feature :: Scenario" identifiers failing only in the baseline
+	DurationMS         float64  `json:"duration_ms"`    // Duration of the build
+	BaselineDurationMS float64  `json:"baseline_duration_ms"`
+	DurationChange     float64  `json:"duration_change"` // Duration change in percent
+	ScenarioCount      int      `json:"scenario_count"`  // Scenarios executed by the build
+	BaselineScenarios  int      `json:"baseline_scenarios"`
+	Summary            string   `json:"summary"`
+}
+
+// Annotation represents a metadata value written by a previous step of the pipeline.
+type Annotation struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ConfigFile represents the config file shared by the pipelines.
+type ConfigFile struct {
+	Profiles map[string]Profile `json:"profiles"` // Threshold profiles by name
+}
+
+// Profile represents a named set of settings, such as the thresholds of a quality bar.
+type Profile struct {
+	Extends  string                 `json:"extends,omitempty"` // Profile whose settings are inherited and overridden
+	Settings map[string]interface{} `json:"settings"`          // Values by PLUGIN_ environment variable
+}
+
+// ExportedResults represents the scenario results exported for a later run to compare with.
+type ExportedResults struct {
+	Branch      string           `json:"branch,omitempty"`
+	BuildNumber string           `json:"build_number,omitempty"`
+	Scenarios   []ScenarioResult `json:"scenarios"`
+}
+
+// BaselineDelta represents the changes of the scenarios since the results exported by a
+// previous run.
+type BaselineDelta struct {
+	Source              string          `json:"source"`               // Baseline file and build the results were exported from
+	NewFailures         []string        `json:"new_failures"`         // "Feature :: Scenario" identifiers failing only in the build
+	NewPasses           []string        `json:"new_passes"`           // "Feature :: Scenario" identifiers failing only in the baseline
+	DurationRegressions []DurationDelta `json:"duration_regressions"` // Scenarios slower than in the baseline by the regression factor
+	ScenarioCount       int             `json:"scenario_count"`       // Scenarios executed by the build
+	BaselineScenarios   int             `json:"baseline_scenarios"`   // Scenarios executed by the baseline build
+}
+
+// ScenarioDrift represents the change of the number of executed scenarios since the
+// baseline build.
+type ScenarioDrift struct {
+	Source            string  `json:"source"` // Baseline the scenario count is compared with
+	Scenarios         int     `json:"scenarios"`
+	BaselineScenarios int     `json:"baseline_scenarios"`
+	Change            float64 `json:"change"` // Scenario count change in percent
+}
+
+// DurationDelta represents a scenario slower than in the baseline.
+type DurationDelta struct {
+	Scenario           string  `json:"scenario"` // "Feature :: Scenario" identifier
+	DurationMS         float64 `json:"duration_ms"`
+	BaselineDurationMS float64 `json:"baseline_duration_ms"`
+	Factor             float64 `json:"factor"` // Duration divided by the baseline duration
+}
+
+// LintWarning represents a scenario anti-pattern visible in the report.
+type LintWarning struct {
+	Rule     string `json:"rule"` // then-before-when, repeated-step or no-then
+	Feature  string `json:"feature"`
+	Scenario string `json:"scenario"`
+	URI      string `json:"uri,omitempty"`
+	Line     int    `json:"line,omitempty"` // Line of the offending step or scenario
+	Message  string `json:"message"`
+}
+
+// StepGap represents an undefined step, deduplicated by its suggested Cucumber expression.
+type StepGap struct {
+	Keyword    string   `json:"keyword"`    // Given, When or Then
+	Expression string   `json:"expression"` // Suggested Cucumber expression of the definition
+	Example    string   `json:"example"`    // Text of the first occurrence
+	Count      int      `json:"count"`      // Number of occurrences
+	Scenarios  []string `json:"scenarios"`  // "Feature :: Scenario" identifiers of the affected scenarios
+}
+
+// StepStats represents the executions of a logical step, the steps whose texts only
+// differ by their quoted strings and numbers.
+type StepStats struct {
+	Keyword         string         `json:"keyword"`          // Given, When or Then
+	Step            string         `json:"step"`             // Step text with {string}, {float} and {int} placeholders
+	Example         string         `json:"example"`          // Text of the first execution
+	Executions      int            `json:"executions"`       // Number of executions
+	Statuses        map[string]int `json:"statuses"`         // Number of executions by status
+	DurationMS      float64        `json:"duration_ms"`      // Total duration of the executions
+	FailedScenarios []string       `json:"failed_scenarios"` // "Feature :: Scenario" identifiers of the scenarios where it failed
+}
+
+// ScenarioResult represents the result of a single scenario.
+type ScenarioResult struct {
+	ID             string   `json:"id,omitempty"` // Identifier of the scenario or example
+	Feature        string   `json:"feature"`
+	Scenario       string   `json:"scenario"`
+	Environment    string   `json:"environment,omitempty"`     // Browser, platform and device the scenario ran on
+	Category       string   `json:"category,omitempty"`        // Category of the first matching category rule
+	Shard          string   `json:"shard,omitempty"`           // Label of the shard whose report holds the execution
+	StartTimestamp string   `json:"start_timestamp,omitempty"` // Start of the execution when the report records it
+	Tags           []string `json:"tags,omitempty"`
+	Status         string   `json:"status"`
+	DurationMS     float64  `json:"duration_ms"`
+	Flaky          bool     `json:"flaky,omitempty"` // True when the scenario failed and then passed on rerun
+	Steps          int      `json:"steps,omitempty"`
+	UndefinedSteps int      `json:"undefined_steps,omitempty"` // Undefined steps counted by the thresholds
+}
+
+// SLA represents the service level agreement of the scenarios with a tag.
+type SLA struct {
+	Tag           string  `json:"tag"`
+	MaxDurationMS float64 `json:"max_duration_ms"` // Maximum total duration in the current build
+	MinPassRate   float64 `json:"min_pass_rate"`   // Minimum scenario pass rate over the window
+	Window        int     `json:"window"`          // Number of builds, including the current build
+}
+
+// ScrubRule represents a regular expression removing sensitive content, such as internal
+// hostnames or customer identifiers, from everything sent to external services.
+type ScrubRule struct {
+	Pattern     string  `json:"pattern"`
+	Replacement *string `json:"replacement"` // Defaults to [REDACTED]
+}
+
+// Category represents a rule mapping the scenarios with one of the tags, in the feature
+// files matching one of the paths or with a name matching the pattern to a category.
+type Category struct {
+	Name               string   `json:"name"`
+	Tags               []string `json:"tags,omitempty"`
+	Paths              []string `json:"paths,omitempty"`                // Globs matching the feature files or their directories
+	NamePattern        string   `json:"name_pattern,omitempty"`         // Regular expression matching the scenario names
+	MinPassRate        float64  `json:"min_pass_rate,omitempty"`        // Minimum scenario pass rate of the category
+	MaxFailedScenarios *int     `json:"max_failed_scenarios,omitempty"` // Maximum number of failed scenarios of the category
+}
+
+// ReportGroup represents a named group of report files, such as the API or UI test
+// reports, with its own gates.
+type ReportGroup struct {
+	Name                      string  `json:"name"`
+	Directory                 string  `json:"directory"`
+	IncludePattern            string  `json:"include_pattern,omitempty"` // Defaults to PLUGIN_FILE_INCLUDE_PATTERN
+	ExcludePattern            string  `json:"exclude_pattern,omitempty"` // Defaults to PLUGIN_FILE_EXCLUDE_PATTERN
+	FailedFeaturesNumber      int     `json:"failed_features_number,omitempty"`
+	FailedFeaturesPercentage  float64 `json:"failed_features_percentage,omitempty"`
+	FailedScenariosNumber     int     `json:"failed_scenarios_number,omitempty"`
+	FailedScenariosPercentage float64 `json:"failed_scenarios_percentage,omitempty"`
+	FailedStepsNumber         int     `json:"failed_steps_number,omitempty"`
+	FailedStepsPercentage     float64 `json:"failed_steps_percentage,omitempty"`
+	PendingStepsNumber        int     `json:"pending_steps_number,omitempty"`
+	PendingStepsPercentage    float64 `json:"pending_steps_percentage,omitempty"`
+	SkippedStepsNumber        int     `json:"skipped_steps_number,omitempty"`
+	SkippedStepsPercentage    float64 `json:"skipped_steps_percentage,omitempty"`
+	UndefinedStepsNumber      int     `json:"undefined_steps_number,omitempty"`
+	UndefinedStepsPercentage  float64 `json:"undefined_steps_percentage,omitempty"`
+	DurationBudgets           string  `json:"duration_budgets,omitempty"`
+	MaxFeatureDuration        string  `json:"max_feature_duration,omitempty"`
+}
+
+// ReportGroupResult represents the statistics and gate verdicts of a report group.
+type ReportGroupResult struct {
+	Name            string       `json:"name"`
+	Reports         int          `json:"reports"`
+	Features        int          `json:"features"`
+	Scenarios       int          `json:"scenarios"`
+	PassedScenarios int          `json:"passed_scenarios"`
+	FailedScenarios int          `json:"failed_scenarios"`
+	Steps           int          `json:"steps"`
+	FailedSteps     int          `json:"failed_steps"`
+	PassRate        float64      `json:"pass_rate"`
+	DurationMS      float64      `json:"duration_ms"`
+	Gates           []GateResult `json:"gates,omitempty"`
+	Passed          bool         `json:"passed"`
+}
+
+// NotificationRoute represents a rule deciding which notification channels fire for the
+// build and with which message template.
+type NotificationRoute struct {
+	Name         string   `json:"name,omitempty"`
+	Channels     []string `json:"channels"`                // slack, google_chat or alert
+	On           string   `json:"on,omitempty"`            // ALWAYS, FAILURE or GATE_FAILURE, defaults to FAILURE
+	Branches     []string `json:"branches,omitempty"`      // Globs matching the branch, any branch when empty
+	Tags         []string `json:"tags,omitempty"`          // Restricts the route to the scenarios with one of the tags
+	Categories   []string `json:"categories,omitempty"`    // Restricts the route to the scenarios of one of the categories
+	Gates        []string `json:"gates,omitempty"`         // Restricts the gate conditions to the failures of the named quality gates
+	SlackChannel string   `json:"slack_channel,omitempty"` // Overrides the Slack channel
+	Template     string   `json:"template,omitempty"`      // Message template file of the template directory
+}
+
+// CategoryResult represents the scenario counts of a category and its gate verdict.
+type CategoryResult struct {
+	Name               string  `json:"name"`
+	Scenarios          int     `json:"scenarios"`
+	PassedScenarios    int     `json:"passed_scenarios"`
+	FailedScenarios    int     `json:"failed_scenarios"`
+	PassRate           float64 `json:"pass_rate"`
+	DurationMS         float64 `json:"duration_ms"`
+	MinPassRate        float64 `json:"min_pass_rate,omitempty"`
+	MaxFailedScenarios *int    `json:"max_failed_scenarios,omitempty"`
+	Passed             bool    `json:"passed"`
+}
+
+// SLAResult represents the compliance of an SLA.
+type SLAResult struct {
+	SLA        SLA
+	DurationMS float64 // Total duration of the tagged scenarios in the current build
+	PassRate   float64 // Pass rate of the tagged scenarios over the window
+	Builds     int     // Number of builds containing the tag within the window
+	Compliant  bool
+}
+
+// DurationRegression represents a scenario that got significantly slower than in previous builds.
+type DurationRegression struct {
+	Feature    string  `json:"feature"`
+	Scenario   string  `json:"scenario"`
+	DurationMS float64 `json:"duration_ms"` // Duration in the current build
+	MedianMS   float64 `json:"median_ms"`   // Median duration in the previous builds
+	Factor     float64 `json:"factor"`      // Current duration divided by the median
+}
+
+// QuarantineRecommendation represents a scenario that failed intermittently in the recent builds.
+type QuarantineRecommendation struct {
+	Feature      string  `json:"feature"`
+	Scenario     string  `json:"scenario"`
+	Builds       int     `json:"builds"`
+	FailedBuilds int     `json:"failed_builds"`
+	FailureRate  float64 `json:"failure_rate"`
+}
+
+// QuarantineFile represents the generated quarantine file.
+type QuarantineFile struct {
+	Scenarios []QuarantineRecommendation `json:"scenarios"`
+}
+
+// GateResult represents the evaluation of a single threshold.
+type GateResult struct {
+	Name       string  `json:"name"`
+	Observed   float64 `json:"observed"`
+	Threshold  float64 `json:"threshold"`
+	Margin     float64 `json:"margin"`             // Threshold minus the observed value, negative when exceeded
+	Percentage bool    `json:"percentage"`         // True when the values are percentages
+	Duration   bool    `json:"duration,omitempty"` // True when the values are durations in milliseconds
+	Passed     bool    `json:"passed"`
+	Message    string  `json:"message,omitempty"` // Reason of the failure
 }
 
 // FailedStepDetails represents details of a failed step.
 type FailedStepDetails struct {
-	Feature      string
-	Scenario     string
-	Step         string
-	ErrorMessage string
+	Feature      string      `json:"feature"`
+	Scenario     string      `json:"scenario"`
+	Step         string      `json:"step"`
+	StepPattern  string      `json:"step_pattern,omitempty"`  // Step text with its parameters replaced by placeholders, grouping the failures of a logical step
+	URI          string      `json:"uri,omitempty"`           // Feature file of the scenario
+	ScenarioLine int         `json:"scenario_line,omitempty"` // Line of the scenario in the feature file
+	StepLine     int         `json:"step_line,omitempty"`     // Line of the step in the feature file
+	ErrorMessage string      `json:"error_message"`
+	DurationMS   float64     `json:"duration_ms"`           // Duration of the failed step
+	Fingerprint  string      `json:"fingerprint"`           // Stable identifier of the failure across builds
+	New          bool        `json:"new,omitempty"`         // True when the failure did not occur in previous builds
+	Flaky        bool        `json:"flaky,omitempty"`       // True when the scenario passed on rerun
+	SessionURL   string      `json:"session_url,omitempty"` // Recorded browser session of the scenario
+	SourceURL    string      `json:"source_url,omitempty"`  // Link to the step in the repository
+	Screenshots  []Embedding `json:"screenshots,omitempty"` // Images attached to the step and the after hooks, when they are embedded in the HTML report
+}
+
+// ShardResult represents the statistics of the reports of a test shard.
+type ShardResult struct {
+	Label           string  `json:"label"`
+	Reports         int     `json:"reports"`
+	Scenarios       int     `json:"scenarios"`
+	PassedScenarios int     `json:"passed_scenarios"`
+	FailedScenarios int     `json:"failed_scenarios"`
+	DurationMS      float64 `json:"duration_ms"`
+	DurationRatio   float64 `json:"duration_ratio"` // Duration relative to the fastest shard
+	Imbalanced      bool    `json:"imbalanced"`     // True when the shard is much slower than the fastest one
+}
+
+// ConsistencyViolation represents a scenario executed in several shards with conflicting
+// statuses, a double execution rather than a rerun.
+type ConsistencyViolation struct {
+	ID       string        `json:"id"`
+	Feature  string        `json:"feature"`
+	Scenario string        `json:"scenario"`
+	Shards   []ShardStatus `json:"shards"` // Status of the last execution in every shard
+}
+
+// ShardStatus represents the status of a scenario in a shard.
+type ShardStatus struct {
+	Shard  string `json:"shard"`
+	Status string `json:"status"`
+}
+
+// Timeline represents the executions of the scenarios over time, reconstructed from
+// their start timestamps.
+type Timeline struct {
+	Start              time.Time           `json:"start"`
+	End                time.Time           `json:"end"`
+	DurationMS         float64             `json:"duration_ms"`         // Wall-clock duration from the first start to the last end
+	BusyMS             float64             `json:"busy_ms"`             // Total duration of the scenarios
+	MaxConcurrency     int                 `json:"max_concurrency"`     // Largest number of scenarios running at once
+	AverageConcurrency float64             `json:"average_concurrency"` // Busy time divided by the wall-clock duration
+	Untimed            int                 `json:"untimed"`             // Scenarios without a start timestamp, missing from the timeline
+	Scenarios          []TimelineScenario  `json:"scenarios"`
+	Concurrency        []ConcurrencyChange `json:"concurrency"` // Number of running scenarios from every change
+	Gaps               []TimelineGap       `json:"gaps"`        // Periods during which no scenario ran
+}
+
+// TimelineScenario represents the execution of a scenario on the timeline.
+type TimelineScenario struct {
+	ID         string    `json:"id,omitempty"`
+	Feature    string    `json:"feature"`
+	Scenario   string    `json:"scenario"`
+	Shard      string    `json:"shard,omitempty"`
+	Status     string    `json:"status"`
+	Lane       int       `json:"lane"` // Row of the Gantt chart, overlapping executions are on separate lanes
+	Start      time.Time `json:"start"`
+	OffsetMS   float64   `json:"offset_ms"` // Start relative to the start of the timeline
+	DurationMS float64   `json:"duration_ms"`
+}
+
+// ConcurrencyChange represents the number of scenarios running from a point of the timeline.
+type ConcurrencyChange struct {
+	OffsetMS float64 `json:"offset_ms"`
+	Running  int     `json:"running"`
+}
+
+// TimelineGap represents a period of the timeline during which no scenario ran.
+type TimelineGap struct {
+	OffsetMS   float64 `json:"offset_ms"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// EnvironmentResult represents the scenario counts of an environment.
+type EnvironmentResult struct {
+	Environment     string `json:"environment"`
+	Scenarios       int    `json:"scenarios"`
+	FailedScenarios int    `json:"failed_scenarios"`
+}
+
+// FileError represents a report file that could not be processed.
+type FileError struct {
+	File    string `json:"file"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Stack   string `json:"stack,omitempty"` // Stack of a recovered panic
+}
+
+// HistoryEntry represents the summary of a single build stored in the history file.
+type HistoryEntry struct {
+	Branch          string                `json:"branch"`
+	BuildNumber     string                `json:"build_number"`
+	Timestamp       int64                 `json:"timestamp"`
+	TotalScenarios  int                   `json:"total_scenarios"`
+	FailedScenarios int                   `json:"failed_scenarios"`
+	PassRate        float64               `json:"pass_rate"`
+	DurationMS      float64               `json:"duration_ms"`
+	Retries         int                   `json:"retries,omitempty"`
+	Failures        []HistoryFailure      `json:"failures,omitempty"`
+	Scenarios       []HistoryScenario     `json:"scenarios,omitempty"`
+	Tags            map[string]HistoryTag `json:"tags,omitempty"`
+}
+
+// Heatmap represents the feature by build pass/fail matrix of the recent builds.
+type Heatmap struct {
+	Builds   []string         `json:"builds"`
+	Features []HeatmapFeature `json:"features"`
+}
+
+// HeatmapFeature represents the results of a feature in each build of the heatmap.
+type HeatmapFeature struct {
+	Feature      string        `json:"feature"`
+	FailedBuilds int           `json:"failed_builds"`
+	Cells        []HeatmapCell `json:"cells"`
+}
+
+// HeatmapCell represents the result of a feature in a single build.
+type HeatmapCell struct {
+	Build     string `json:"build"`
+	Scenarios int    `json:"scenarios"`
+	Failed    int    `json:"failed"`
+	Status    string `json:"status,omitempty"` // passed, failed or empty if the feature did not run
+}
+
+// HistoryTag represents the scenarios of a tag stored in the history file.
+type HistoryTag struct {
+	Scenarios  int     `json:"scenarios"`
+	Passed     int     `json:"passed"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// HistoryScenario represents the duration of a scenario stored in the history file.
+type HistoryScenario struct {
+	Feature    string  `json:"feature"`
+	Scenario   string  `json:"scenario"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// HistoryFailure represents a failed scenario stored in the history file.
+type HistoryFailure struct {
+	Feature     string `json:"feature"`
+	Scenario    string `json:"scenario"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// History represents the persisted collection of previous build entries.
+type History struct {
+	Entries      []HistoryEntry           `json:"entries"`
+	Timings      map[string]HistoryTiming `json:"timings,omitempty"`
+	PendingSteps []HistoryPendingStep     `json:"pending_steps,omitempty"`
+}
+
+// UnimplementedStep represents a pending or undefined step of a scenario.
+type UnimplementedStep struct {
+	Feature  string `json:"feature"`
+	Scenario string `json:"scenario"`
+	Step     string `json:"step"`
+	Status   string `json:"status"` // pending or undefined
+}
+
+// HistoryPendingStep represents an unimplemented step of a branch recorded in the
+// history file since the build it was first seen in.
+type HistoryPendingStep struct {
+	Branch         string `json:"branch"`
+	Feature        string `json:"feature"`
+	Scenario       string `json:"scenario"`
+	Step           string `json:"step"`
+	Status         string `json:"status"`
+	FirstSeenBuild string `json:"first_seen_build"`
+	FirstSeen      int64  `json:"first_seen"`
+	LastSeen       int64  `json:"last_seen"`
+	Builds         int    `json:"builds"` // Number of builds it was unimplemented in
+}
+
+// PendingStepAge represents how long an unimplemented step has remained so.
+type PendingStepAge struct {
+	Feature        string `json:"feature"`
+	Scenario       string `json:"scenario"`
+	Step           string `json:"step"`
+	Status         string `json:"status"`
+	FirstSeenBuild string `json:"first_seen_build"`
+	FirstSeen      string `json:"first_seen"` // RFC 3339 date of the first build it was seen in
+	AgeDays        int    `json:"age_days"`
+	Builds         int    `json:"builds"`
+}
+
+// HistoryTiming represents the duration of a scenario averaged over the recorded builds.
+type HistoryTiming struct {
+	DurationMS float64 `json:"duration_ms"`
+	Runs       int     `json:"runs"`
+	LastSeen   int64   `json:"last_seen"`
+}
+
+// Trend represents the pass rate trend over the most recent builds on a branch.
+type Trend struct {
+	Entries         []HistoryEntry // Previous builds, oldest first
+	Current         HistoryEntry   // The current build
+	AveragePassRate float64        // Average pass rate of the previous builds
+	PassRateDelta   float64        // Current pass rate minus the average
+	Direction       string         // IMPROVED, REGRESSED or STABLE
+}
+
+// JUnitTestSuites represents the root element of a JUnit XML report.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite represents a feature in a JUnit XML report.
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase represents a scenario in a JUnit XML report.
+type JUnitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+// JUnitFailure represents the failure of a test case.
+type JUnitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnitSkipped represents a skipped test case.
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// TestLinkResult represents the execution result of a TestLink test case.
+type TestLinkResult struct {
+	ExternalID string
+	Status     string
+	Notes      string
 }
@@ -0,0 +1,263 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Constants for Upload Provider
+const (
+	UploadProviderS3  = "S3"
+	UploadProviderGCS = "GCS"
+)
+
+// gcsEndpoint is the S3 compatible XML API endpoint of Google Cloud Storage.
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// uploadConfig holds the resolved object storage settings.
+type uploadConfig struct {
+	endpoint     string
+	region       string
+	bucket       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	acl          string
+	pathStyle    bool
+}
+
+// validateUploadArgs checks the object storage settings.
+func validateUploadArgs(args Args) error {
+	if args.UploadBucket == "" {
+		return nil
+	}
+
+	provider := strings.ToUpper(args.UploadProvider)
+	if provider != "" && provider != UploadProviderS3 && provider != UploadProviderGCS {
+		return fmt.Errorf("invalid UploadProvider value. It must be '%s' or '%s'", UploadProviderS3, UploadProviderGCS)
+	}
+	if uploadedReport(args) == "" {
+		return errors.New("an upload bucket is configured but no report to upload")
+	}
+	return nil
+}
+
+// resolveUploadConfig resolves the object storage settings, falling back to the
+// standard AWS environment variables for credentials and region.
+func resolveUploadConfig(args Args) uploadConfig {
+	config := uploadConfig{
+		endpoint:     strings.TrimRight(args.UploadEndpoint, "/"),
+		region:       firstNonEmpty(args.UploadRegion, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")),
+		bucket:       args.UploadBucket,
+		accessKey:    firstNonEmpty(args.UploadAccessKey, os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretKey:    firstNonEmpty(args.UploadSecretKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		sessionToken: firstNonEmpty(args.UploadSessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+		acl:          args.UploadACL,
+		// Custom endpoints such as MinIO generally expect path style requests
+		pathStyle: args.UploadPathStyle || args.UploadEndpoint != "",
+	}
+
+	if strings.EqualFold(args.UploadProvider, UploadProviderGCS) {
+		if config.endpoint == "" {
+			config.endpoint = gcsEndpoint
+		}
+		if config.region == "" {
+			config.region = "auto"
+		}
+		config.pathStyle = true
+	}
+	if config.region == "" {
+		config.region = "us-east-1"
+	}
+	return config
+}
+
+// objectURL returns the URL of an object in the bucket.
+func (c uploadConfig) objectURL(key string) string {
+	escaped := (&url.URL{Path: "/" + key}).EscapedPath()
+	if c.pathStyle {
+		endpoint := c.endpoint
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", c.region)
+		}
+		return endpoint + "/" + c.bucket + escaped
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", c.bucket, c.region, escaped)
+}
+
+// uploadedReport returns the report to upload, defaulting to the HTML report.
+func uploadedReport(args Args) string {
+	return firstNonEmpty(args.UploadReport, args.HTMLReportPath, outputFormatPath(args, "html"))
+}
+
+// uploadReport uploads the configured report to object storage, scrubbing text reports
+// with the scrub rules, and returns its URL.
+func uploadReport(ctx context.Context, args Args) (string, error) {
+	report := uploadedReport(args)
+	content, err := os.ReadFile(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report %s: %w", report, err)
+	}
+
+	key := path.Join(strings.Trim(args.UploadPrefix, "/"), filepath.Base(report))
+	contentType := mime.TypeByExtension(filepath.Ext(report))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if isTextContent(contentType) {
+		content = []byte(newScrubber(args).text(string(content)))
+	}
+
+	config := resolveUploadConfig(args)
+	objectURL := config.objectURL(key)
+	if err := putObject(ctx, newHTTPClient(args), config, objectURL, content, contentType); err != nil {
+		return "", err
+	}
+	return objectURL, nil
+}
+
+// putObject uploads the content with an AWS signature version 4 signed PUT request.
+func putObject(ctx context.Context, client *http.Client, config uploadConfig, objectURL string, content []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if config.acl != "" {
+		req.Header.Set("x-amz-acl", config.acl)
+	}
+	if config.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", config.sessionToken)
+	}
+	signRequest(req, content, config, time.Now().UTC())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload report: %w", sendError(req, err))
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(req, resp); err != nil {
+		return fmt.Errorf("failed to upload report: %w", err)
+	}
+	return nil
+}
+
+// getObject downloads an object with an AWS signature version 4 signed GET request.
+func getObject(ctx context.Context, client *http.Client, config uploadConfig, objectURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", config.sessionToken)
+	}
+	signRequest(req, nil, config, time.Now().UTC())
+	return readResponse(client, req)
+}
+
+// signRequest adds an AWS signature version 4 authorization header to the request.
+func signRequest(req *http.Request, payload []byte, config uploadConfig, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	// Canonical headers must be sorted by lower case name
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + config.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+config.secretKey), date)
+	key = hmacSHA256(key, config.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.accessKey, scope, signedHeaders, signature))
+}
+
+// sha256Hex returns the hex encoded SHA-256 hash of the data.
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// publishReport uploads the report, exports its URL as an output variable and returns it.
+func publishReport(ctx context.Context, args Args) string {
+	reportURL, err := uploadReport(ctx, args)
+	if err != nil {
+		logger.Warnf("Failed to upload report %s: %v", uploadedReport(args), err)
+		return ""
+	}
+
+	logger.Infof("Report uploaded to %s\n", reportURL)
+	if err := WriteEnvToFile("REPORT_URL", reportURL, logger); err != nil {
+		logger.Errorf("Error writing %s: %s", "REPORT_URL", err)
+	}
+	return reportURL
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestUploadReport tests uploading a report to an S3 compatible endpoint
+func TestUploadReport(t *testing.T) {
+	var gotPath, gotAuth, gotACL, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotAuth, gotACL, gotBody = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("x-amz-acl"), string(body)
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	report := filepath.Join(t.TempDir(), "report.html")
+	os.WriteFile(report, []byte("<html></html>"), 0644)
+
+	args := Args{
+		UploadBucket:    "reports",
+		UploadPrefix:    "/builds/42/",
+		UploadACL:       "public-read",
+		UploadEndpoint:  server.URL,
+		UploadAccessKey: "AKIDEXAMPLE",
+		UploadSecretKey: "secret",
+		UploadReport:    report,
+	}
+
+	reportURL, err := uploadReport(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if reportURL != server.URL+"/reports/builds/42/report.html" {
+		t.Errorf("Unexpected report URL: %s", reportURL)
+	}
+	if gotPath != "/reports/builds/42/report.html" || gotBody != "<html></html>" || gotACL != "public-read" {
+		t.Errorf("Unexpected request: path=%s acl=%s body=%s", gotPath, gotACL, gotBody)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		t.Errorf("Unexpected authorization header: %s", gotAuth)
+	}
+}
+
+// TestObjectURL tests the object URLs of the supported providers
+func TestObjectURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     Args
+		expected string
+	}{
+		{
+			name:     "S3",
+			args:     Args{UploadBucket: "reports", UploadRegion: "eu-west-1"},
+			expected: "https://reports.s3.eu-west-1.amazonaws.com/a/report.html",
+		},
+		{
+			name:     "GCS",
+			args:     Args{UploadBucket: "reports", UploadProvider: "gcs"},
+			expected: "https://storage.googleapis.com/reports/a/report.html",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveUploadConfig(tc.args).objectURL("a/report.html"); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// wdioReport represents a report of the WebdriverIO Cucumber JSON reporter wrapping the
+// features with the metadata of the environment they ran in.
+type wdioReport struct {
+	Metadata *FeatureMetadata `json:"metadata"`
+	Features []Feature        `json:"features"`
+}
+
+// isWDIOReport reports whether the content is a WebdriverIO wrapper of the features.
+func isWDIOReport(content []byte) bool {
+	return hasJSONKey(content, "features")
+}
+
+// decodeWDIOReport decodes a WebdriverIO report, either wrapped in an object carrying the
+// metadata or as an array of features with their own metadata.
+func decodeWDIOReport(content []byte) ([]Feature, error) {
+	if firstByte(content) == '[' {
+		return decodeCucumberReport(content)
+	}
+
+	var report wdioReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+	for i := range report.Features {
+		if report.Features[i].Metadata == nil {
+			report.Features[i].Metadata = report.Metadata
+		}
+	}
+	return report.Features, nil
+}
+
+// featureEnvironment describes the browser, platform and device a feature ran on.
+func featureEnvironment(metadata *FeatureMetadata) string {
+	if metadata == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, part := range []string{
+		strings.TrimSpace(metadata.Browser.Name + " " + metadata.Browser.Version),
+		strings.TrimSpace(metadata.Platform.Name + " " + metadata.Platform.Version),
+		metadata.Device,
+	} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// environmentBreakdown counts the scenarios by environment.
+func environmentBreakdown(scenarios []ScenarioResult) []EnvironmentResult {
+	index := make(map[string]int)
+	var environments []EnvironmentResult
+	for _, scenario := range scenarios {
+		if scenario.Environment == "" {
+			continue
+		}
+		i, ok := index[scenario.Environment]
+		if !ok {
+			i = len(environments)
+			index[scenario.Environment] = i
+			environments = append(environments, EnvironmentResult{Environment: scenario.Environment})
+		}
+		environments[i].Scenarios++
+		if scenario.Status == "failed" {
+			environments[i].FailedScenarios++
+		}
+	}
+	sort.Slice(environments, func(i, j int) bool { return environments[i].Environment < environments[j].Environment })
+	return environments
+}
+
+// logEnvironmentBreakdown logs the scenario counts by environment.
+func logEnvironmentBreakdown(environments []EnvironmentResult) {
+	if len(environments) == 0 {
+		return
+	}
+
+	logger.Infof("Environments:\n")
+	for _, environment := range environments {
+		logger.Infof("   %s: %d scenarios (%d failed)\n", environment.Environment, environment.Scenarios, environment.FailedScenarios)
+	}
+}
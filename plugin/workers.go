@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultFileTimeoutSeconds is the per-file processing deadline used when none is configured.
+const defaultFileTimeoutSeconds = 300
+
+// fileMemoryFactor estimates the memory needed to decode a report relative to its size,
+// unless the memory of each file is configured.
+const fileMemoryFactor = 4
+
+// Locations of the memory statistics, overridden in tests.
+var (
+	cgroupRoot  = "/sys/fs/cgroup"
+	meminfoPath = "/proc/meminfo"
+)
+
+// workerCount returns the number of files processed concurrently, derived from the
+// number of CPUs unless the maximum number of workers is configured, and bounded by the
+// memory available to decode the largest file, or the configured memory of each file.
+// The memory budget, when configured, bounds the available memory.
+func workerCount(files []string, args Args) int {
+	procs := runtime.NumCPU()
+	if args.MaxWorkers > 0 {
+		procs = args.MaxWorkers
+	}
+
+	perFile := uint64(args.FileMemoryMB) << 20
+	if perFile == 0 {
+		var largest int64
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && info.Size() > largest {
+				largest = info.Size()
+			}
+		}
+		perFile = uint64(largest) * fileMemoryFactor
+	}
+
+	available := availableMemory()
+	if budget := uint64(args.MemoryBudgetMB) << 20; budget > 0 && (available == 0 || budget < available) {
+		available = budget
+	}
+	workers := tuneWorkers(procs, available, perFile, len(files))
+	if args.MaxWorkers > 0 && workers < min(args.MaxWorkers, len(files)) {
+		logger.Warnf("MaxWorkers reduced from %d to %d workers to fit %d MB of memory per file in the %d MB available",
+			args.MaxWorkers, workers, perFile>>20, available>>20)
+	}
+	return workers
+}
+
+// tuneWorkers bounds the number of workers by the available memory and the number of
+// files. An unknown available memory of zero does not bound the number of workers.
+func tuneWorkers(procs int, available uint64, perFile uint64, files int) int {
+	workers := procs
+	if available > 0 && perFile > 0 {
+		if byMemory := int(available / perFile); byMemory < workers {
+			workers = byMemory
+		}
+	}
+	if workers > files {
+		workers = files
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// availableMemory returns the memory available to the plugin in bytes, taking the
+// container limit into account, or zero when it cannot be determined.
+func availableMemory() uint64 {
+	available := meminfoAvailable()
+
+	// cgroup v2, then cgroup v1
+	limit, limitOK := readMemoryValue(filepath.Join(cgroupRoot, "memory.max"))
+	usage, _ := readMemoryValue(filepath.Join(cgroupRoot, "memory.current"))
+	if !limitOK {
+		limit, limitOK = readMemoryValue(filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes"))
+		usage, _ = readMemoryValue(filepath.Join(cgroupRoot, "memory", "memory.usage_in_bytes"))
+	}
+
+	if limitOK && limit > usage {
+		if container := limit - usage; available == 0 || container < available {
+			available = container
+		}
+	}
+	return available
+}
+
+// readMemoryValue reads a cgroup memory value. Unlimited values are reported as missing.
+func readMemoryValue(path string) (uint64, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	// cgroup v1 reports an unlimited memory as a value close to the maximum int64
+	if err != nil || value >= 1<<62 {
+		return 0, false
+	}
+	return value, true
+}
+
+// meminfoAvailable returns the available memory of the host in bytes.
+func meminfoAvailable() uint64 {
+	file, err := os.Open(meminfoPath)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// fileOutcome is the outcome of processing a single report file.
+type fileOutcome struct {
+	file    string
+	results Results
+	err     error
+}
+
+// processFiles processes the files with a bounded number of workers. It returns exactly
+// one outcome per file, in the order of the files, whether the processing succeeds,
+// fails or panics.
+func processFiles(ctx context.Context, files []string, workers int, process func(context.Context, string) (Results, error)) []fileOutcome {
+	outcomes := make([]fileOutcome, len(files))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		outcomes[i].file = file
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(outcome *fileOutcome) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverFilePanic(&outcome.err)
+			outcome.results, outcome.err = process(ctx, outcome.file)
+		}(&outcomes[i])
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// recoverFilePanic converts a panic raised while processing a file into an error
+// capturing the stack, so that an unexpected report cannot crash the plugin.
+func recoverFilePanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &panicError{value: r, stack: string(debug.Stack())}
+	}
+}
+
+// fileTimeout returns the per-file processing deadline.
+func fileTimeout(args Args) time.Duration {
+	if args.FileTimeoutSeconds <= 0 {
+		return defaultFileTimeoutSeconds * time.Second
+	}
+	return time.Duration(args.FileTimeoutSeconds) * time.Second
+}
+
+// processFileWithDeadline processes a file, giving up when it exceeds the deadline so
+// that a pathological report cannot stall the aggregation. The abandoned processing
+// finishes in the background and its result is discarded.
+func processFileWithDeadline(ctx context.Context, filename string, args Args, timeout time.Duration) (Results, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		results Results
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		var result outcome
+		defer func() { done <- result }()
+		defer recoverFilePanic(&result.err)
+		result.results, result.err = processFileCached(filename, args)
+	}()
+
+	select {
+	case result := <-done:
+		return result.results, result.err
+	case <-ctx.Done():
+		logger.Errorf("Processing file %s exceeded the deadline of %s", filename, timeout)
+		return Results{}, wrapError(ErrTimeout, fmt.Errorf("processing exceeded the deadline of %s: %w", timeout, ctx.Err()))
+	}
+}
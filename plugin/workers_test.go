@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTuneWorkers tests deriving the number of workers from the processors and memory
+func TestTuneWorkers(t *testing.T) {
+	tests := []struct {
+		name      string
+		procs     int
+		available uint64
+		perFile   uint64
+		files     int
+		expected  int
+	}{
+		{name: "Bound By Processors", procs: 4, available: 0, perFile: 4 << 20, files: 10, expected: 4},
+		{name: "Bound By Files", procs: 8, available: 1 << 30, perFile: 4 << 20, files: 3, expected: 3},
+		{name: "Bound By Memory", procs: 8, available: 800 << 20, perFile: 400 << 20, files: 20, expected: 2},
+		{name: "At Least One", procs: 8, available: 10 << 20, perFile: 400 << 20, files: 20, expected: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tuneWorkers(tc.procs, tc.available, tc.perFile, tc.files); got != tc.expected {
+				t.Errorf("Expected %d workers, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestWorkerCount tests overriding the number of workers and the memory of each file
+func TestWorkerCount(t *testing.T) {
+	dir := t.TempDir()
+	oldRoot, oldMeminfo := cgroupRoot, meminfoPath
+	cgroupRoot, meminfoPath = dir, filepath.Join(dir, "meminfo")
+	defer func() { cgroupRoot, meminfoPath = oldRoot, oldMeminfo }()
+	if err := os.WriteFile(meminfoPath, []byte("MemAvailable:    1048576 kB\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := make([]string, 100)
+
+	tests := []struct {
+		name     string
+		args     Args
+		expected int
+	}{
+		{name: "Max Workers", args: Args{MaxWorkers: 16}, expected: 16},
+		{name: "Bound By File Memory", args: Args{MaxWorkers: 16, FileMemoryMB: 256}, expected: 4},
+		{name: "Bound By Memory Budget", args: Args{MaxWorkers: 16, FileMemoryMB: 64, MemoryBudgetMB: 128}, expected: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := workerCount(files, tc.args); got != tc.expected {
+				t.Errorf("Expected %d workers, got %d", tc.expected, got)
+			}
+		})
+	}
+
+	// Reducing the configured maximum is logged
+	recorder := newRecordingLogger()
+	previous := logger
+	logger = recorder
+	defer func() { logger = previous }()
+	workerCount(files, Args{MaxWorkers: 16})
+	workerCount(files[:2], Args{MaxWorkers: 16})
+	if len(*recorder.messages) != 0 {
+		t.Errorf("Expected no warning when the maximum is not reduced by the memory, got %v", *recorder.messages)
+	}
+	workerCount(files, Args{MaxWorkers: 16, FileMemoryMB: 256})
+	if len(*recorder.messages) != 1 || !strings.Contains((*recorder.messages)[0], "MaxWorkers reduced from 16 to 4 workers") {
+		t.Errorf("Expected a warning for the reduced maximum, got %v", *recorder.messages)
+	}
+}
+
+// TestAvailableMemory tests reading the available memory from the cgroup and meminfo files
+func TestAvailableMemory(t *testing.T) {
+	dir := t.TempDir()
+	oldRoot, oldMeminfo := cgroupRoot, meminfoPath
+	cgroupRoot, meminfoPath = dir, filepath.Join(dir, "meminfo")
+	defer func() { cgroupRoot, meminfoPath = oldRoot, oldMeminfo }()
+
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("meminfo", "MemTotal:       16384000 kB\nMemAvailable:    8192000 kB\n")
+	if got := availableMemory(); got != 8192000*1024 {
+		t.Errorf("Expected the host available memory, got %d", got)
+	}
+
+	write("memory.max", "max\n")
+	if got := availableMemory(); got != 8192000*1024 {
+		t.Errorf("Expected an unlimited container to be ignored, got %d", got)
+	}
+
+	write("memory.max", "1073741824\n")
+	write("memory.current", "268435456\n")
+	if got := availableMemory(); got != 805306368 {
+		t.Errorf("Expected the container available memory, got %d", got)
+	}
+}
+
+// TestProcessFiles tests that every file produces exactly one outcome in mixed runs
+func TestProcessFiles(t *testing.T) {
+	files := []string{"pass-1.json", "error-1.json", "panic-1.json", "pass-2.json", "error-2.json", "panic-2.json", "pass-3.json"}
+	process := func(ctx context.Context, file string) (Results, error) {
+		switch {
+		case strings.HasPrefix(file, "error"):
+			return Results{}, errors.New("invalid report")
+		case strings.HasPrefix(file, "panic"):
+			var features []Feature
+			_ = features[1]
+		}
+		return Results{ScenarioCount: 1}, nil
+	}
+
+	for _, workers := range []int{1, 2, len(files)} {
+		outcomes := processFiles(context.Background(), files, workers, process)
+		if len(outcomes) != len(files) {
+			t.Fatalf("Expected %d outcomes with %d workers, got %d", len(files), workers, len(outcomes))
+		}
+		for i, outcome := range outcomes {
+			if outcome.file != files[i] {
+				t.Errorf("Expected outcome %d for %s, got %s", i, files[i], outcome.file)
+			}
+			switch {
+			case strings.HasPrefix(outcome.file, "error"):
+				if outcome.err == nil || outcome.err.Error() != "invalid report" {
+					t.Errorf("Expected the processing error for %s, got %v", outcome.file, outcome.err)
+				}
+			case strings.HasPrefix(outcome.file, "panic"):
+				if !errors.Is(outcome.err, ErrPanic) || !strings.HasPrefix(outcome.err.Error(), "panic: runtime error: index out of range") {
+					t.Errorf("Expected the panic to be recovered for %s, got %v", outcome.file, outcome.err)
+				}
+				fileErr := newFileError(outcome.file, outcome.err)
+				if fileErr.File != outcome.file || fileErr.Code != "PANIC" || !strings.Contains(fileErr.Stack, "TestProcessFiles") {
+					t.Errorf("Expected the file, code and stack of the panic, got %+v", fileErr)
+				}
+			default:
+				if outcome.err != nil || outcome.results.ScenarioCount != 1 {
+					t.Errorf("Expected the results of %s, got %+v and %v", outcome.file, outcome.results, outcome.err)
+				}
+			}
+		}
+	}
+}
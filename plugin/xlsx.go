@@ -0,0 +1,215 @@
+package plugin
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// xlsxMaxCellLength is the maximum number of characters of a cell accepted by Excel.
+const xlsxMaxCellLength = 32767
+
+// xlsxSheet represents a worksheet of the workbook, with a header row.
+type xlsxSheet struct {
+	name   string
+	header []string
+	rows   [][]interface{}
+}
+
+// xlsxFeatureRows counts the scenarios of each feature, in the order of the reports.
+func xlsxFeatureRows(scenarios []ScenarioResult) [][]interface{} {
+	type featureRow struct {
+		scenarios, passed, failed int
+		durationMS                float64
+	}
+	var names []string
+	features := make(map[string]*featureRow)
+	for _, scenario := range scenarios {
+		feature, ok := features[scenario.Feature]
+		if !ok {
+			feature = &featureRow{}
+			features[scenario.Feature] = feature
+			names = append(names, scenario.Feature)
+		}
+		feature.scenarios++
+		if scenario.Status == "failed" {
+			feature.failed++
+		} else {
+			feature.passed++
+		}
+		feature.durationMS += scenario.DurationMS
+	}
+
+	rows := make([][]interface{}, 0, len(names))
+	for _, name := range names {
+		feature := features[name]
+		status := "passed"
+		if feature.failed > 0 {
+			status = "failed"
+		}
+		rows = append(rows, []interface{}{name, status, feature.scenarios, feature.passed, feature.failed, feature.durationMS})
+	}
+	return rows
+}
+
+// xlsxSheets lays out the summary, per-feature, per-scenario and failure sheets.
+func xlsxSheets(results Results) []xlsxSheet {
+	summary := xlsxSheet{name: "Summary", header: []string{"Metric", "Value"}}
+	for _, row := range [][]interface{}{
+		{"Total Features", results.FeatureCount},
+		{"Total Scenarios", results.ScenarioCount},
+		{"Total Steps", results.StepCount},
+		{"Failed Features", results.TotalFailedFeatures},
+		{"Failed Scenarios", results.TotalFailedScenarios},
+		{"Failed Steps", results.TotalFailedSteps},
+		{"Passed Steps", results.TotalPassedSteps},
+		{"Skipped Steps", results.SkippedTests},
+		{"Pending Steps", results.PendingTests},
+		{"Undefined Steps", results.UndefinedTests},
+		{"Pass Rate (%)", percentageOf(results.TotalPassedScenarios, results.ScenarioCount)},
+		{"Duration (ms)", results.DurationMS},
+		{"Repository", currentRepo()},
+		{"Build", currentBuildNumber()},
+	} {
+		summary.rows = append(summary.rows, row)
+	}
+
+	features := xlsxSheet{
+		name:   "Features",
+		header: []string{"Feature", "Status", "Scenarios", "Passed Scenarios", "Failed Scenarios", "Duration (ms)"},
+		rows:   xlsxFeatureRows(results.Scenarios),
+	}
+
+	scenarios := xlsxSheet{name: "Scenarios", header: []string{"Feature", "Scenario", "Status", "Tags", "Environment", "Duration (ms)"}}
+	for _, scenario := range results.Scenarios {
+		scenarios.rows = append(scenarios.rows, []interface{}{scenario.Feature, scenario.Scenario, scenario.Status,
+			strings.Join(scenario.Tags, " "), scenario.Environment, scenario.DurationMS})
+	}
+
+	failures := xlsxSheet{name: "Failures", header: []string{"Feature", "Scenario", "Step", "Location", "Error", "Duration (ms)", "Source"}}
+	for _, step := range results.FailedSteps {
+		failures.rows = append(failures.rows, []interface{}{step.Feature, step.Scenario, step.Step, failureLocation(step),
+			step.ErrorMessage, step.DurationMS, step.SourceURL})
+	}
+
+	return []xlsxSheet{summary, features, scenarios, failures}
+}
+
+// xlsxColumn returns the letters of a column numbered from 0.
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxEscape escapes the text of a cell, replacing the characters XML does not allow and
+// truncating it to the maximum length of a cell.
+func xlsxEscape(s string) string {
+	if runes := []rune(s); len(runes) > xlsxMaxCellLength {
+		s = string(runes[:xlsxMaxCellLength])
+	}
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(s))
+	return escaped.String()
+}
+
+// xlsxSheetXML renders a worksheet with inline strings and a bold frozen header row.
+func xlsxSheetXML(sheet xlsxSheet) string {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	body.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	body.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	body.WriteString(`<sheetData>`)
+
+	header := make([]interface{}, len(sheet.header))
+	for i, name := range sheet.header {
+		header[i] = name
+	}
+	for r, row := range append([][]interface{}{header}, sheet.rows...) {
+		fmt.Fprintf(&body, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			style := ""
+			if r == 0 {
+				style = ` s="1"`
+			}
+			switch v := value.(type) {
+			case int:
+				fmt.Fprintf(&body, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+			case float64:
+				fmt.Fprintf(&body, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				fmt.Fprintf(&body, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xlsxEscape(fmt.Sprint(v)))
+			}
+		}
+		body.WriteString(`</row>`)
+	}
+	body.WriteString(`</sheetData></worksheet>`)
+	return body.String()
+}
+
+// writeXLSXReport writes the results to an Excel workbook with a sheet for the summary,
+// the features, the scenarios and the failed steps.
+func writeXLSXReport(path string, results Results) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	sheets := xlsxSheets(results)
+	var contentTypes, workbook, relationships strings.Builder
+	for i, sheet := range sheets {
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(sheet.name), i+1, i+1)
+		fmt.Fprintf(&relationships, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&relationships, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	const header = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			contentTypes.String() + `</Types>`},
+		{"_rels/.rels", header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			relationships.String() + `</Relationships>`},
+		{"xl/styles.xml", header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheetXML(sheet)})
+	}
+
+	archive := zip.NewWriter(file)
+	for _, part := range parts {
+		writer, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteXLSXReport tests writing the sheets of the Excel workbook
+func TestWriteXLSXReport(t *testing.T) {
+	results := Results{
+		FeatureCount:         2,
+		ScenarioCount:        3,
+		TotalPassedScenarios: 2,
+		TotalFailedScenarios: 1,
+		Scenarios: []ScenarioResult{
+			{Feature: "Login", Scenario: "Valid password", Status: "passed", DurationMS: 12.5},
+			{Feature: "Login", Scenario: "Wrong password", Tags: []string{"@smoke", "@auth"}, Status: "failed", DurationMS: 30},
+			{Feature: "Search", Scenario: "By name", Status: "passed", DurationMS: 7},
+		},
+		FailedSteps: []FailedStepDetails{
+			{Feature: "Login", Scenario: "Wrong password", Step: "I see <error>", URI: "features/login.feature", StepLine: 9, ErrorMessage: "expected \"denied\" & got \x00nothing"},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "reports", "results.xlsx")
+	if err := writeXLSXReport(path, results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer archive.Close()
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		parts[file.Name] = string(content)
+
+		// Every part must be well-formed XML
+		decoder := xml.NewDecoder(strings.NewReader(string(content)))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Malformed part %s: %v", file.Name, err)
+			}
+		}
+	}
+
+	tests := []struct {
+		part     string
+		expected []string
+	}{
+		{"xl/workbook.xml", []string{`<sheet name="Summary"`, `<sheet name="Features"`, `<sheet name="Scenarios"`, `<sheet name="Failures"`}},
+		{"xl/worksheets/sheet1.xml", []string{"Total Scenarios", `<c r="B3"><v>3</v></c>`}},
+		{"xl/worksheets/sheet2.xml", []string{`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Login</t></is></c>`, `<c r="E2"><v>1</v></c>`, `<c r="F2"><v>42.5</v></c>`}},
+		{"xl/worksheets/sheet3.xml", []string{"@smoke @auth", `<c r="F4"><v>7</v></c>`}},
+		{"xl/worksheets/sheet4.xml", []string{"I see &lt;error&gt;", "features/login.feature:9", "expected &#34;denied&#34; &amp; got �nothing"}},
+	}
+	for _, tc := range tests {
+		for _, expected := range tc.expected {
+			if !strings.Contains(parts[tc.part], expected) {
+				t.Errorf("Expected %s to contain %q", tc.part, expected)
+			}
+		}
+	}
+}
+
+// TestXLSXColumn tests naming the columns of a sheet
+func TestXLSXColumn(t *testing.T) {
+	for index, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if column := xlsxColumn(index); column != expected {
+			t.Errorf("Expected column %d to be %s, got %s", index, expected, column)
+		}
+	}
+}